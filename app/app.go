@@ -0,0 +1,95 @@
+package app
+
+import (
+	"time"
+
+	"github.com/Abb133Se/recepieshare/config"
+	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/mailer"
+	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/nutrition"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/search"
+	"github.com/Abb133Se/recepieshare/service"
+	"gorm.io/gorm"
+)
+
+// App owns the long-lived dependencies of the server. Handlers and services
+// receive what they need from here instead of reaching for globals.
+type App struct {
+	Config  *config.Config
+	DB      *gorm.DB
+	Dialect internal.Dialect
+	Storage internal.StorageBackend
+	Clock   internal.Clock
+	Mailer  mailer.Mailer
+
+	Repos     *repository.Repositories
+	Images    *service.ImageService
+	Tokens    *service.TokenService
+	Revisions *service.RevisionService
+	Scheduler *service.PublishScheduler
+	Nutrition *service.NutritionService
+	Jobs      *service.JobQueue
+	Search    *service.SearchService
+	Suggest   *service.SuggestService
+	Pantry    *service.PantryService
+}
+
+// New connects to the database and wires the repositories and services.
+func New(cfg *config.Config) (*App, error) {
+	db, dialect, err := internal.OpenDatabase(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.I18n.Dir != "" {
+		if err := messages.LoadDir(cfg.I18n.Dir); err != nil {
+			return nil, err
+		}
+		if cfg.I18n.HotReload {
+			messages.Watch(cfg.I18n.Dir, time.Second)
+		}
+	}
+
+	mail, err := mailer.New(cfg.Mail)
+	if err != nil {
+		return nil, err
+	}
+
+	storage := internal.NewLocalStorage(cfg.Storage.BasePath)
+	clock := internal.SystemClock{}
+	repos := repository.New(db, dialect)
+
+	provider, err := nutrition.New(cfg.Nutrition, repos, clock)
+	if err != nil {
+		return nil, err
+	}
+
+	nutritionService := service.NewNutritionService(repos, provider)
+	jobs := service.NewJobQueue(repos, clock, cfg.Jobs)
+	jobs.Register(service.JobEstimateNutrition, nutritionService.EstimateJob)
+
+	suggest := service.NewSuggestService(repos, search.NewSuggester(), cfg.Search.RebuildInterval.Duration)
+	scheduler := service.NewPublishScheduler(repos, clock, cfg.Scheduler.Interval.Duration)
+	scheduler.OnPublish(suggest.Invalidate)
+
+	return &App{
+		Config:    cfg,
+		DB:        db,
+		Dialect:   dialect,
+		Storage:   storage,
+		Clock:     clock,
+		Mailer:    mail,
+		Repos:     repos,
+		Images:    service.NewImageService(repos, storage, clock),
+		Tokens:    service.NewTokenService(repos, clock, cfg.JWT.RefreshTTL.Duration),
+		Revisions: service.NewRevisionService(repos),
+		Scheduler: scheduler,
+		Nutrition: nutritionService,
+		Jobs:      jobs,
+		Search:    service.NewSearchService(repos, search.NewMemoryIndex(), cfg.Search.RebuildInterval.Duration),
+		Suggest:   suggest,
+		Pantry:    service.NewPantryService(repos, cfg.Pantry.Staples),
+	}, nil
+}
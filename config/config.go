@@ -0,0 +1,595 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/pantry"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the typed application configuration. Values are resolved in the
+// following order, later sources overriding earlier ones:
+// defaults, config file (YAML or TOML), environment variables, CLI flags.
+type Config struct {
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	Database  DatabaseConfig  `yaml:"database" toml:"database"`
+	JWT       JWTConfig       `yaml:"jwt" toml:"jwt"`
+	Nutrition NutritionConfig `yaml:"nutrition" toml:"nutrition"`
+	Storage   StorageConfig   `yaml:"storage" toml:"storage"`
+	Mail      MailConfig      `yaml:"mail" toml:"mail"`
+	I18n      I18nConfig      `yaml:"i18n" toml:"i18n"`
+	Scheduler SchedulerConfig `yaml:"scheduler" toml:"scheduler"`
+	Jobs      JobsConfig      `yaml:"jobs" toml:"jobs"`
+	Recipes   RecipesConfig   `yaml:"recipes" toml:"recipes"`
+	Search    SearchConfig    `yaml:"search" toml:"search"`
+	Pantry    PantryConfig    `yaml:"pantry" toml:"pantry"`
+}
+
+type ServerConfig struct {
+	Port        int      `yaml:"port" toml:"port"`
+	CORSOrigins []string `yaml:"cors_origins" toml:"cors_origins"`
+}
+
+type DatabaseConfig struct {
+	// Driver is one of mysql, postgres or sqlite.
+	Driver          string   `yaml:"driver" toml:"driver"`
+	DSN             string   `yaml:"dsn" toml:"dsn"`
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+}
+
+type JWTConfig struct {
+	Secret string `yaml:"secret" toml:"secret"`
+	// TTL is the lifetime of access tokens. Keep it short, clients renew
+	// them with a refresh token.
+	TTL        Duration `yaml:"ttl" toml:"ttl"`
+	RefreshTTL Duration `yaml:"refresh_ttl" toml:"refresh_ttl"`
+}
+
+type NutritionConfig struct {
+	// Providers are asked in order, each for the ingredients the previous
+	// ones did not match: calorieninjas and local (the imported food table).
+	Providers []string `yaml:"providers" toml:"providers"`
+	// APIKey is the CalorieNinjas key. The calorieninjas provider is skipped when empty.
+	APIKey string `yaml:"api_key" toml:"api_key"`
+	// Timeout bounds each request to a remote provider.
+	Timeout Duration `yaml:"timeout" toml:"timeout"`
+	// CacheTTL is how long looked up ingredients are reused. Zero disables
+	// the cache.
+	CacheTTL Duration `yaml:"cache_ttl" toml:"cache_ttl"`
+}
+
+type StorageConfig struct {
+	BasePath string `yaml:"base_path" toml:"base_path"`
+}
+
+type MailConfig struct {
+	// Driver is smtp, file (one .eml per message in Dir) or stdout.
+	Driver string `yaml:"driver" toml:"driver"`
+	From   string `yaml:"from" toml:"from"`
+	Dir    string `yaml:"dir" toml:"dir"`
+	// LinkBaseURL is where links in emails point to, usually the frontend.
+	LinkBaseURL string     `yaml:"link_base_url" toml:"link_base_url"`
+	SMTP        SMTPConfig `yaml:"smtp" toml:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     int    `yaml:"port" toml:"port"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+}
+
+type I18nConfig struct {
+	// Dir holds the <locale>.toml or <locale>.json translation catalogs. The
+	// catalogs built into the binary are used when empty.
+	Dir string `yaml:"dir" toml:"dir"`
+	// HotReload reloads the catalogs in Dir when they change. Meant for
+	// development.
+	HotReload bool `yaml:"hot_reload" toml:"hot_reload"`
+}
+
+type SchedulerConfig struct {
+	// Interval is how often scheduled recipes are checked for publication.
+	Interval Duration `yaml:"interval" toml:"interval"`
+}
+
+type JobsConfig struct {
+	// Workers is the number of jobs run at the same time.
+	Workers int `yaml:"workers" toml:"workers"`
+	// PollInterval is how often the queue is checked for due jobs.
+	PollInterval Duration `yaml:"poll_interval" toml:"poll_interval"`
+	// MaxAttempts is how often a job is tried before it is dead-lettered.
+	MaxAttempts int `yaml:"max_attempts" toml:"max_attempts"`
+	// Backoff is the delay before the first retry; it doubles with every
+	// further attempt up to MaxBackoff.
+	Backoff    Duration `yaml:"backoff" toml:"backoff"`
+	MaxBackoff Duration `yaml:"max_backoff" toml:"max_backoff"`
+	// StaleAfter is how long a job may run before it is considered lost,
+	// e.g. because the server stopped, and handed to another worker.
+	StaleAfter Duration `yaml:"stale_after" toml:"stale_after"`
+}
+
+type RecipesConfig struct {
+	// Difficulties are the difficulty levels from easiest to hardest. The
+	// position of a level is stored with each recipe for sorting, so add
+	// new levels rather than reorder existing ones.
+	Difficulties []string `yaml:"difficulties" toml:"difficulties"`
+	// Cuisines and Equipment are the values recipes may be tagged with.
+	Cuisines  []string `yaml:"cuisines" toml:"cuisines"`
+	Equipment []string `yaml:"equipment" toml:"equipment"`
+}
+
+type SearchConfig struct {
+	// RebuildInterval is how often the full-text index and the suggestions
+	// are rebuilt from the database. Edits made through the API are indexed
+	// right away; the rebuild picks up changes made elsewhere, such as by
+	// another server instance, and new favorites and views, which rank
+	// suggestions. Zero disables it.
+	RebuildInterval Duration `yaml:"rebuild_interval" toml:"rebuild_interval"`
+}
+
+type PantryConfig struct {
+	// Staples are ingredients most kitchens have, such as salt and water,
+	// which pantry matching can leave out of the count. Names are compared
+	// the way ingredient names are, and a staple covers every ingredient
+	// with all of its words: "salt" covers "Sea salt, to taste", but
+	// "pepper" would cover "bell pepper" too.
+	Staples []string `yaml:"staples" toml:"staples"`
+}
+
+// Duration wraps time.Duration so it can be written as "24h" or "15m" in config files.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
+
+const envPrefix = "RECIPESHARE_"
+
+// Default returns the configuration used when nothing else is provided.
+// It intentionally leaves secrets and the DSN empty so Validate catches them.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:        3000,
+			CORSOrigins: []string{"http://localhost:4200"},
+		},
+		Database: DatabaseConfig{
+			Driver:       "mysql",
+			MaxOpenConns: 20,
+			MaxIdleConns: 10,
+		},
+		JWT: JWTConfig{
+			TTL:        Duration{15 * time.Minute},
+			RefreshTTL: Duration{30 * 24 * time.Hour},
+		},
+		Nutrition: NutritionConfig{
+			Providers: []string{"calorieninjas", "local"},
+			Timeout:   Duration{10 * time.Second},
+			CacheTTL:  Duration{30 * 24 * time.Hour},
+		},
+		Storage: StorageConfig{
+			BasePath: "uploads",
+		},
+		Mail: MailConfig{
+			Driver:      "stdout",
+			From:        "RecipeShare <no-reply@recipeshare.local>",
+			Dir:         "mail",
+			LinkBaseURL: "http://localhost:4200",
+			SMTP: SMTPConfig{
+				Port: 587,
+			},
+		},
+		Scheduler: SchedulerConfig{
+			Interval: Duration{time.Minute},
+		},
+		Jobs: JobsConfig{
+			Workers:      4,
+			PollInterval: Duration{time.Second},
+			MaxAttempts:  5,
+			Backoff:      Duration{10 * time.Second},
+			MaxBackoff:   Duration{time.Hour},
+			StaleAfter:   Duration{10 * time.Minute},
+		},
+		Recipes: RecipesConfig{
+			Difficulties: []string{"easy", "medium", "hard"},
+			Cuisines: []string{
+				"american", "british", "chinese", "french", "greek", "indian", "italian",
+				"japanese", "korean", "mediterranean", "mexican", "middle_eastern",
+				"persian", "spanish", "thai", "turkish", "vietnamese", "other",
+			},
+			Equipment: []string{
+				"oven", "stovetop", "microwave", "grill", "slow_cooker", "pressure_cooker",
+				"air_fryer", "blender", "food_processor", "stand_mixer", "hand_mixer",
+				"dutch_oven", "cast_iron_skillet", "wok", "baking_sheet", "sheet_pan",
+				"loaf_pan", "muffin_tin", "thermometer", "steamer",
+			},
+		},
+		Search: SearchConfig{
+			RebuildInterval: Duration{time.Hour},
+		},
+		Pantry: PantryConfig{
+			Staples: []string{
+				"salt", "water", "ice cube", "black pepper",
+			},
+		},
+	}
+}
+
+// Load builds the configuration from the config file, the environment and the
+// given command line arguments. It does not validate it, so commands that need
+// neither the database nor the secrets can run without them; everything else
+// must call Validate first. The arguments left over after flag parsing (e.g. a
+// subcommand) are returned alongside the config.
+func Load(args []string) (*Config, []string, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("recipeshare", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv(envPrefix+"CONFIG"), "path to a YAML or TOML config file")
+	port := fs.Int("port", 0, "HTTP port to listen on")
+	driver := fs.String("db-driver", "", "database driver: mysql, postgres or sqlite")
+	dsn := fs.String("db-dsn", "", "database connection string")
+	corsOrigins := fs.String("cors-origins", "", "comma-separated list of allowed CORS origins")
+	jwtSecret := fs.String("jwt-secret", "", "secret used to sign JWTs")
+	storagePath := fs.String("storage-path", "", "base directory for uploaded files")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *configPath != "" {
+		if err := cfg.loadFile(*configPath); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, nil, err
+	}
+
+	// Flags win over everything else, but only when explicitly set.
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "db-driver":
+			cfg.Database.Driver = *driver
+		case "db-dsn":
+			cfg.Database.DSN = *dsn
+		case "cors-origins":
+			cfg.Server.CORSOrigins = splitList(*corsOrigins)
+		case "jwt-secret":
+			cfg.JWT.Secret = *jwtSecret
+		case "storage-path":
+			cfg.Storage.BasePath = *storagePath
+		}
+	})
+
+	return cfg, fs.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".toml":
+		err = toml.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("unsupported config file format: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	bindings := map[string]func(string) error{
+		"PORT": func(v string) error {
+			p, err := strconv.Atoi(v)
+			c.Server.Port = p
+			return err
+		},
+		"CORS_ORIGINS": func(v string) error {
+			c.Server.CORSOrigins = splitList(v)
+			return nil
+		},
+		"DB_DRIVER": func(v string) error {
+			c.Database.Driver = v
+			return nil
+		},
+		"DB_DSN": func(v string) error {
+			c.Database.DSN = v
+			return nil
+		},
+		"DB_MAX_OPEN_CONNS": func(v string) error {
+			n, err := strconv.Atoi(v)
+			c.Database.MaxOpenConns = n
+			return err
+		},
+		"DB_MAX_IDLE_CONNS": func(v string) error {
+			n, err := strconv.Atoi(v)
+			c.Database.MaxIdleConns = n
+			return err
+		},
+		"JWT_SECRET": func(v string) error {
+			c.JWT.Secret = v
+			return nil
+		},
+		"JWT_TTL": func(v string) error {
+			return c.JWT.TTL.UnmarshalText([]byte(v))
+		},
+		"JWT_REFRESH_TTL": func(v string) error {
+			return c.JWT.RefreshTTL.UnmarshalText([]byte(v))
+		},
+		"NUTRITION_PROVIDERS": func(v string) error {
+			c.Nutrition.Providers = splitList(v)
+			return nil
+		},
+		"NUTRITION_API_KEY": func(v string) error {
+			c.Nutrition.APIKey = v
+			return nil
+		},
+		"NUTRITION_TIMEOUT": func(v string) error {
+			return c.Nutrition.Timeout.UnmarshalText([]byte(v))
+		},
+		"NUTRITION_CACHE_TTL": func(v string) error {
+			return c.Nutrition.CacheTTL.UnmarshalText([]byte(v))
+		},
+		"STORAGE_PATH": func(v string) error {
+			c.Storage.BasePath = v
+			return nil
+		},
+		"MAIL_DRIVER": func(v string) error {
+			c.Mail.Driver = v
+			return nil
+		},
+		"MAIL_FROM": func(v string) error {
+			c.Mail.From = v
+			return nil
+		},
+		"MAIL_DIR": func(v string) error {
+			c.Mail.Dir = v
+			return nil
+		},
+		"MAIL_LINK_BASE_URL": func(v string) error {
+			c.Mail.LinkBaseURL = v
+			return nil
+		},
+		"SMTP_HOST": func(v string) error {
+			c.Mail.SMTP.Host = v
+			return nil
+		},
+		"SMTP_PORT": func(v string) error {
+			p, err := strconv.Atoi(v)
+			c.Mail.SMTP.Port = p
+			return err
+		},
+		"SMTP_USERNAME": func(v string) error {
+			c.Mail.SMTP.Username = v
+			return nil
+		},
+		"SMTP_PASSWORD": func(v string) error {
+			c.Mail.SMTP.Password = v
+			return nil
+		},
+		"I18N_DIR": func(v string) error {
+			c.I18n.Dir = v
+			return nil
+		},
+		"I18N_HOT_RELOAD": func(v string) error {
+			b, err := strconv.ParseBool(v)
+			c.I18n.HotReload = b
+			return err
+		},
+		"SCHEDULER_INTERVAL": func(v string) error {
+			return c.Scheduler.Interval.UnmarshalText([]byte(v))
+		},
+		"JOBS_WORKERS": func(v string) error {
+			n, err := strconv.Atoi(v)
+			c.Jobs.Workers = n
+			return err
+		},
+		"JOBS_POLL_INTERVAL": func(v string) error {
+			return c.Jobs.PollInterval.UnmarshalText([]byte(v))
+		},
+		"JOBS_MAX_ATTEMPTS": func(v string) error {
+			n, err := strconv.Atoi(v)
+			c.Jobs.MaxAttempts = n
+			return err
+		},
+		"JOBS_BACKOFF": func(v string) error {
+			return c.Jobs.Backoff.UnmarshalText([]byte(v))
+		},
+		"JOBS_MAX_BACKOFF": func(v string) error {
+			return c.Jobs.MaxBackoff.UnmarshalText([]byte(v))
+		},
+		"JOBS_STALE_AFTER": func(v string) error {
+			return c.Jobs.StaleAfter.UnmarshalText([]byte(v))
+		},
+		"RECIPES_DIFFICULTIES": func(v string) error {
+			c.Recipes.Difficulties = splitList(v)
+			return nil
+		},
+		"RECIPES_CUISINES": func(v string) error {
+			c.Recipes.Cuisines = splitList(v)
+			return nil
+		},
+		"RECIPES_EQUIPMENT": func(v string) error {
+			c.Recipes.Equipment = splitList(v)
+			return nil
+		},
+		"SEARCH_REBUILD_INTERVAL": func(v string) error {
+			return c.Search.RebuildInterval.UnmarshalText([]byte(v))
+		},
+		"PANTRY_STAPLES": func(v string) error {
+			c.Pantry.Staples = splitList(v)
+			return nil
+		},
+	}
+
+	for name, set := range bindings {
+		v, ok := os.LookupEnv(envPrefix + name)
+		if !ok {
+			continue
+		}
+		if err := set(v); err != nil {
+			return fmt.Errorf("invalid value for %s%s: %w", envPrefix, name, err)
+		}
+	}
+	return nil
+}
+
+// Validate reports every missing or invalid required field at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port must be between 1 and 65535, got %d", c.Server.Port))
+	}
+	switch c.Database.Driver {
+	case "mysql", "postgres", "sqlite":
+	default:
+		errs = append(errs, fmt.Errorf("database.driver must be mysql, postgres or sqlite, got %q", c.Database.Driver))
+	}
+	if c.Database.DSN == "" {
+		errs = append(errs, errors.New("database.dsn is required"))
+	}
+	if c.JWT.Secret == "" {
+		errs = append(errs, errors.New("jwt.secret is required"))
+	}
+	if c.JWT.TTL.Duration <= 0 {
+		errs = append(errs, errors.New("jwt.ttl must be positive"))
+	}
+	if c.JWT.RefreshTTL.Duration <= c.JWT.TTL.Duration {
+		errs = append(errs, errors.New("jwt.refresh_ttl must be longer than jwt.ttl"))
+	}
+	for _, name := range c.Nutrition.Providers {
+		switch name {
+		case "calorieninjas", "local":
+		default:
+			errs = append(errs, fmt.Errorf("nutrition.providers must be calorieninjas or local, got %q", name))
+		}
+	}
+	if c.Nutrition.Timeout.Duration <= 0 {
+		errs = append(errs, errors.New("nutrition.timeout must be positive"))
+	}
+	if c.Nutrition.CacheTTL.Duration < 0 {
+		errs = append(errs, errors.New("nutrition.cache_ttl must not be negative"))
+	}
+	if c.Storage.BasePath == "" {
+		errs = append(errs, errors.New("storage.base_path is required"))
+	}
+	switch c.Mail.Driver {
+	case "stdout":
+	case "file":
+		if c.Mail.Dir == "" {
+			errs = append(errs, errors.New("mail.dir is required for the file driver"))
+		}
+	case "smtp":
+		if c.Mail.SMTP.Host == "" {
+			errs = append(errs, errors.New("mail.smtp.host is required for the smtp driver"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("mail.driver must be smtp, file or stdout, got %q", c.Mail.Driver))
+	}
+	if c.Mail.From == "" {
+		errs = append(errs, errors.New("mail.from is required"))
+	}
+	if c.I18n.HotReload && c.I18n.Dir == "" {
+		errs = append(errs, errors.New("i18n.hot_reload needs i18n.dir"))
+	}
+	if c.Scheduler.Interval.Duration <= 0 {
+		errs = append(errs, errors.New("scheduler.interval must be positive"))
+	}
+	if c.Jobs.Workers < 1 {
+		errs = append(errs, errors.New("jobs.workers must be at least 1"))
+	}
+	if c.Jobs.PollInterval.Duration <= 0 {
+		errs = append(errs, errors.New("jobs.poll_interval must be positive"))
+	}
+	if c.Jobs.MaxAttempts < 1 {
+		errs = append(errs, errors.New("jobs.max_attempts must be at least 1"))
+	}
+	if c.Jobs.Backoff.Duration <= 0 {
+		errs = append(errs, errors.New("jobs.backoff must be positive"))
+	}
+	if c.Jobs.MaxBackoff.Duration < c.Jobs.Backoff.Duration {
+		errs = append(errs, errors.New("jobs.max_backoff must not be less than jobs.backoff"))
+	}
+	if c.Jobs.StaleAfter.Duration <= 0 {
+		errs = append(errs, errors.New("jobs.stale_after must be positive"))
+	}
+	errs = append(errs, validateChoices("recipes.difficulties", c.Recipes.Difficulties, 32)...)
+	errs = append(errs, validateChoices("recipes.cuisines", c.Recipes.Cuisines, 64)...)
+	errs = append(errs, validateChoices("recipes.equipment", c.Recipes.Equipment, 64)...)
+	if c.Search.RebuildInterval.Duration < 0 {
+		errs = append(errs, errors.New("search.rebuild_interval must not be negative"))
+	}
+	errs = append(errs, validateChoices("pantry.staples", c.Pantry.Staples, 255)...)
+	for _, v := range c.Pantry.Staples {
+		if strings.TrimSpace(v) != "" && pantry.Key(v) == "" {
+			errs = append(errs, fmt.Errorf("pantry.staples must name an ingredient, got %q", v))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validateChoices checks a list of values a request may pick from. Values
+// are matched case-insensitively, so they must be unique regardless of case.
+func validateChoices(name string, values []string, maxLen int) []error {
+	var errs []error
+	seen := map[string]bool{}
+	for _, v := range values {
+		key := strings.ToLower(v)
+		switch {
+		case strings.TrimSpace(v) == "" || strings.Contains(v, ","):
+			errs = append(errs, fmt.Errorf("%s must not contain blank values or commas, got %q", name, v))
+		case len(v) > maxLen:
+			errs = append(errs, fmt.Errorf("%s values must be at most %d characters, got %q", name, maxLen, v))
+		case seen[key]:
+			errs = append(errs, fmt.Errorf("%s contains %q twice", name, v))
+		}
+		seen[key] = true
+	}
+	return errs
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
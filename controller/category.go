@@ -4,12 +4,12 @@ import (
 	"errors"
 	"net/http"
 
-	"github.com/Abb133Se/recepieshare/internal"
 	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
 	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
 	"github.com/Abb133Se/recepieshare/utils"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // Response structs for Swagger
@@ -43,8 +43,8 @@ type SuccessMessageResponse struct {
 // @Failure      404  {object}  controller.ErrorResponse
 // @Failure      500  {object}  controller.ErrorResponse
 // @Router       /category/{id} [get]
-func GetCategoryHandler(c *gin.Context) {
-	var category model.Category
+func (h *Handler) GetCategoryHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	validID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
@@ -52,22 +52,16 @@ func GetCategoryHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	err = db.First(&category, validID).Error
+	category, err := h.app.Repos.Categories.FindByID(validID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Common.DBConnectionErr)})
 		return
 	}
-	c.JSON(http.StatusOK, CategoryResponse{Message: messages.Common.Success.String(), Data: category})
+	c.JSON(http.StatusOK, CategoryResponse{Message: loc.T(messages.Common.Success), Data: *category})
 }
 
 // PostCategoryHandler godoc
@@ -82,7 +76,9 @@ func GetCategoryHandler(c *gin.Context) {
 // @Failure      409       {object}  controller.ErrorResponse
 // @Failure      500       {object}  controller.ErrorResponse
 // @Router       /category [post]
-func PostCategoryHandler(c *gin.Context) {
+func (h *Handler) PostCategoryHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var category model.Category
 
 	if err := c.ShouldBindJSON(&category); err != nil {
@@ -90,33 +86,29 @@ func PostCategoryHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+	categories := h.app.Repos.Categories
+
+	if _, err := categories.FindByName(category.Name); err == nil {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: loc.T(messages.Category.CatAlreadyExists)})
 		return
 	}
 
-	var existingCategory model.Category
-	if err := db.Where("name = ?", category.Name).First(&existingCategory).Error; err == nil {
-		c.JSON(http.StatusConflict, ErrorResponse{Error: messages.Category.CatAlreadyExists.String()})
-		return
+	// Recipes are linked after creation, and only if they exist.
+	var recipeIDs []uint
+	for _, r := range category.Recipes {
+		recipeIDs = append(recipeIDs, r.ID)
 	}
+	category.Recipes = nil
 
-	if err := db.Create(&category).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Category.CatCreationFailed.String()})
+	if err := categories.Create(&category); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Category.CatCreationFailed)})
 		return
 	}
 
-	if len(category.Recipes) > 0 {
-		for _, r := range category.Recipes {
-			var existingRecipe model.Recipe
-			if err := db.First(&existingRecipe, r.ID).Error; err == nil {
-				db.Model(&category).Association("Recipes").Append(&existingRecipe)
-			}
-		}
-	}
+	_ = categories.AttachRecipes(&category, recipeIDs)
+	h.app.Suggest.Invalidate()
 
-	c.JSON(http.StatusCreated, CategoryResponse{Message: messages.Category.CatCreationOk.String(), Data: category})
+	c.JSON(http.StatusCreated, CategoryResponse{Message: loc.T(messages.Category.CatCreationOk), Data: category})
 }
 
 // GetAllCategoriesHandler godoc
@@ -131,39 +123,24 @@ func PostCategoryHandler(c *gin.Context) {
 // @Failure      400     {object}  controller.ErrorResponse
 // @Failure      500     {object}  controller.ErrorResponse
 // @Router       /categories [get]
-func GetAllCategoriesHandler(c *gin.Context) {
-	var categories []model.Category
+func (h *Handler) GetAllCategoriesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	sort := c.Query("sortOrder")
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	query := db.Model(&model.Category{})
-
-	query = utils.ApplyCatSorting(query, sort)
-
-	queryCount, err := utils.Count(query, "categories")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Comment.CommnetFetchFail.String()})
-		return
-	}
-
 	limit, offset, _ := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
 	if limit == 0 {
 		limit = 10
 	}
 
-	if err := utils.Paginate(query, limit, offset, &categories); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Category.CatFetchFailed.String()})
+	categories, queryCount, err := h.app.Repos.Categories.List(sort, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Category.CatFetchFailed)})
 		return
 	}
 
 	c.JSON(http.StatusOK, CategoriesResponse{
-		Message: messages.Common.Success.String(),
+		Message: loc.T(messages.Common.Success),
 		Data:    categories,
 		Count:   queryCount,
 	})
@@ -182,7 +159,9 @@ func GetAllCategoriesHandler(c *gin.Context) {
 // @Failure      404       {object}  controller.ErrorResponse
 // @Failure      500       {object}  controller.ErrorResponse
 // @Router       /category/{id} [put]
-func PutCategoryHandler(c *gin.Context) {
+func (h *Handler) PutCategoryHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var category model.Category
 	categoryID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
@@ -195,25 +174,20 @@ func PutCategoryHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	existing, err := h.app.Repos.Categories.FindByID(categoryID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	var existing model.Category
-	if err := db.First(&existing, categoryID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Category.CatNotFound.String()})
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Category.CatNotFound)})
 		return
 	}
 
 	existing.Name = category.Name
-	if err := db.Save(&existing).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Category.CatUpdateFail.String()})
+	if err := h.app.Repos.Categories.Save(existing); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Category.CatUpdateFail)})
 		return
 	}
+	h.app.Suggest.Invalidate()
 
-	c.JSON(http.StatusOK, CategoryResponse{Message: messages.Category.CatUpdateOk.String(), Data: existing})
+	c.JSON(http.StatusOK, CategoryResponse{Message: loc.T(messages.Category.CatUpdateOk), Data: *existing})
 }
 
 // DeleteCategoryHandler godoc
@@ -225,30 +199,33 @@ func PutCategoryHandler(c *gin.Context) {
 // @Failure      404  {object}  controller.ErrorResponse
 // @Failure      500  {object}  controller.ErrorResponse
 // @Router       /category/{id} [delete]
-func DeleteCategoryHandler(c *gin.Context) {
-	categoryID := c.Param("id")
-	var category model.Category
+func (h *Handler) DeleteCategoryHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
-	db, err := internal.GetGormInstance()
+	categories := h.app.Repos.Categories
+
+	categoryID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Category.CatNotFound)})
 		return
 	}
 
-	if err := db.Preload("Recipes").First(&category, categoryID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Category.CatNotFound.String()})
+	category, err := categories.FindByID(categoryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Category.CatNotFound)})
 		return
 	}
 
-	if err := db.Model(&category).Association("Recipes").Clear(); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Category.CatFailedAssocioationRemova.String()})
+	if err := categories.DetachRecipes(category); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Category.CatFailedAssocioationRemova)})
 		return
 	}
 
-	if err := db.Delete(&category).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Category.CatDeletionFaied.String()})
+	if err := categories.Delete(category); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Category.CatDeletionFaied)})
 		return
 	}
+	h.app.Suggest.Invalidate()
 
-	c.JSON(http.StatusOK, SuccessMessageResponse{Message: messages.Category.CatDeletionOk.String()})
+	c.JSON(http.StatusOK, SuccessMessageResponse{Message: loc.T(messages.Category.CatDeletionOk)})
 }
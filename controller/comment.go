@@ -4,13 +4,13 @@ import (
 	"errors"
 	"net/http"
 
-	"github.com/Abb133Se/recepieshare/internal"
 	"github.com/Abb133Se/recepieshare/messages"
 	"github.com/Abb133Se/recepieshare/middleware"
 	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/Abb133Se/recepieshare/repository"
 	"github.com/Abb133Se/recepieshare/utils"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type CommentResponse struct {
@@ -24,17 +24,6 @@ type PostCommentRequest struct {
 	RecipeID    uint   `json:"recipe_id" binding:"required"`
 }
 
-type CommentWithDetails struct {
-	CommentID    uint   `json:"comment_id"`
-	CommentTitle string `json:"comment_title"`
-	RecipeID     uint   `json:"recipe_id"`
-	RecipeTitle  string `json:"recipe_title"`
-	UserID       uint   `json:"user_id"`
-	UserName     string `json:"user_name"`
-	Likes        int    `json:"likes"`
-	CreatedAt    string `json:"created_at"`
-}
-
 // PostCommentHandler godoc
 // @Summary      Post a new comment on a recipe
 // @Description  Creates a new comment linked to a recipe by the current user (from JWT). Each user can comment only once per recipe.
@@ -50,7 +39,9 @@ type CommentWithDetails struct {
 // @Failure      409      {object}  ErrorResponse "User has already commented on this recipe"
 // @Failure      500      {object}  ErrorResponse "Internal server error"
 // @Router       /comment [post]
-func PostCommentHandler(c *gin.Context) {
+func (h *Handler) PostCommentHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var req PostCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "bad request"})
@@ -59,26 +50,19 @@ func PostCommentHandler(c *gin.Context) {
 
 	userID := c.GetUint("userID")
 	if userID == 0 {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: loc.T(messages.Common.Unauthorized)})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+	repos := h.app.Repos
 
-	var recipe model.Recipe
-	if err := db.First(&recipe, req.RecipeID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+	if recipe, err := repos.Recipes.FindByID(req.RecipeID); err != nil || !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		return
 	}
 
-	var existingComment model.Comment
-	if err := db.Where("user_id = ? AND recipe_id = ?", userID, req.RecipeID).
-		First(&existingComment).Error; err == nil {
-		c.JSON(http.StatusConflict, ErrorResponse{Error: messages.Comment.CommentAlreadyExists.String()})
+	if _, err := repos.Comments.FindByUserAndRecipe(userID, req.RecipeID); err == nil {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: loc.T(messages.Comment.CommentAlreadyExists)})
 		return
 	}
 
@@ -88,13 +72,13 @@ func PostCommentHandler(c *gin.Context) {
 		RecipeID:    req.RecipeID,
 		UserID:      userID,
 	}
-	if err := db.Create(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Comment.CommentPostFail.String()})
+	if err := repos.Comments.Create(&comment); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Comment.CommentPostFail)})
 		return
 	}
 
 	c.JSON(http.StatusOK, CommentResponse{
-		Message: messages.Comment.CommentPosted.String(),
+		Message: loc.T(messages.Comment.CommentPosted),
 		ID:      comment.ID,
 	})
 }
@@ -127,22 +111,8 @@ func PostCommentHandler(c *gin.Context) {
 // @Failure      404 {object} controller.ErrorResponse
 // @Failure      500 {object} controller.ErrorResponse
 // @Router       /admin/user/{userID}/comment/{id} [delete]
-func DeleteCommentHandler(c *gin.Context) {
-	var userID uint
-	var err error
-	if role := c.GetString("role"); role == "user" {
-		userID = c.GetUint("userID")
-		if userID == 0 {
-			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-			return
-		}
-	} else if role == "admin" {
-		userID, err = middleware.GetEffectiveUserID(c)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-			return
-		}
-	}
+func (h *Handler) DeleteCommentHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	commentID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
@@ -150,28 +120,26 @@ func DeleteCommentHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	comment, err := h.app.Repos.Comments.FindByID(commentID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Comment.CommentNotFound)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Common.DBConnectionErr)})
 		return
 	}
 
-	var comment model.Comment
-	if err := db.Where("id = ? AND user_id = ?", commentID, userID).First(&comment).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusForbidden, ErrorResponse{Error: messages.Comment.CommentDeleteForbidden.String()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+	if !h.authorize(c, policy.Comment, policy.Delete, comment.UserID) {
 		return
 	}
 
-	if err := db.Delete(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Comment.CommentDeleteFail.String()})
+	if err := h.app.Repos.Comments.Delete(comment); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Comment.CommentDeleteFail)})
 		return
 	}
 
-	c.JSON(http.StatusOK, SuccessMessageResponse{Message: messages.Comment.CommentDeleted.String()})
+	c.JSON(http.StatusOK, SuccessMessageResponse{Message: loc.T(messages.Comment.CommentDeleted)})
 }
 
 // PostCommentLikeIncHandler godoc
@@ -184,30 +152,26 @@ func DeleteCommentHandler(c *gin.Context) {
 // @Failure      404  {object}  controller.ErrorResponse
 // @Failure      500  {object}  controller.ErrorResponse
 // @Router       /comment/{id}/like/inc [post]
-func PostCommentLikeIncHandler(c *gin.Context) {
+func (h *Handler) PostCommentLikeIncHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	commentID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	found, err := h.app.Repos.Comments.AddLikes(commentID, 1)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Comment.CommentLikeFail)})
 		return
 	}
-
-	result := db.Exec("update comments set likes = likes + 1 where id = ?", commentID)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Comment.CommentLikeFail.String()})
-		return
-	}
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Comment.CommentNotFound.String()})
+	if !found {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Comment.CommentNotFound)})
 		return
 	}
 
-	c.JSON(http.StatusOK, SuccessMessageResponse{Message: messages.Comment.CommentLikeSuccess.String()})
+	c.JSON(http.StatusOK, SuccessMessageResponse{Message: loc.T(messages.Comment.CommentLikeSuccess)})
 }
 
 // PostCommentLikeDecHandler godoc
@@ -220,39 +184,31 @@ func PostCommentLikeIncHandler(c *gin.Context) {
 // @Failure      404  {object}  controller.ErrorResponse
 // @Failure      500  {object}  controller.ErrorResponse
 // @Router       /comment/{id}/like/dec [post]
-func PostCommentLikeDecHandler(c *gin.Context) {
+func (h *Handler) PostCommentLikeDecHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	commentID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	found, err := h.app.Repos.Comments.AddLikes(commentID, -1)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Comment.CommentDislikeFail)})
 		return
 	}
-
-	result := db.Exec("update comments set likes = likes - 1 where id = ?", commentID)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Comment.CommentDislikeFail.String()})
-		return
-	}
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Comment.CommentNotFound.String()})
+	if !found {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Comment.CommentNotFound)})
 		return
 	}
 
-	c.JSON(http.StatusOK, SuccessMessageResponse{Message: messages.Comment.CommentDislikeSuccess.String()})
+	c.JSON(http.StatusOK, SuccessMessageResponse{Message: loc.T(messages.Comment.CommentDislikeSuccess)})
 }
 
-func GetAllComments(c *gin.Context) {
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection failed"})
-		return
-	}
-
+// GetAllComments lists comments for moderation. Callers who may not read
+// every unpublished and private recipe only see comments on listed ones.
+func (h *Handler) GetAllComments(c *gin.Context) {
 	sort := c.DefaultQuery("sortOrder", "date_desc")
 
 	limit, offset, _ := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
@@ -260,27 +216,8 @@ func GetAllComments(c *gin.Context) {
 		limit = 10
 	}
 
-	query := db.Table("comments").
-		Select(`comments.id as comment_id, 
-                comments.title as comment_title, 
-				comments.likes as likes,
-                recipes.id as recipe_id, 
-                recipes.title as recipe_title, 
-                users.id as user_id, 
-                users.name as user_name`).
-		Joins("JOIN recipes ON comments.recipe_id = recipes.id").
-		Joins("JOIN users ON comments.user_id = users.id")
-
-	query = utils.ApplyCommentSorting(query, sort)
-
-	total, err := utils.Count(query, "comments")
+	comments, total, err := h.app.Repos.Comments.ListAll(recipeStatusFilter(c), recipeVisibilityFilter(c), sort, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count comments"})
-		return
-	}
-
-	var comments []CommentWithDetails
-	if err := utils.Paginate(query, limit, offset, &comments); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch comments"})
 		return
 	}
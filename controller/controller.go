@@ -2,19 +2,23 @@ package controller
 
 import (
 	"errors"
-	"fmt"
+	"log"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/mailer"
 	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
 	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/service"
 	"github.com/Abb133Se/recepieshare/token"
 	"github.com/Abb133Se/recepieshare/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/go-sql-driver/mysql"
-	"gorm.io/gorm"
+	"github.com/golang-jwt/jwt"
 )
 
 type UserSignupRequest struct {
@@ -30,8 +34,19 @@ type UserLoginRequest struct {
 }
 
 type UserLoginResponse struct {
-	Token  string `json:"token"`
-	UserID uint   `json:"user_id"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	RefreshToken string    `json:"refresh_token"`
+	UserID       uint      `json:"user_id"`
+}
+
+type TokenRefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	// Optional; when given the whole refresh-token family is revoked as well.
+	RefreshToken string `json:"refresh_token"`
 }
 
 type ForgotPasswordRequest struct {
@@ -39,8 +54,11 @@ type ForgotPasswordRequest struct {
 }
 
 type ForgotPasswordResponse struct {
-	Message    string `json:"message"`
-	ResetToken string `json:"reset_token,omitempty"`
+	Message string `json:"message"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
 }
 
 type ResetPasswordRequest struct {
@@ -52,9 +70,12 @@ type ResetPasswordResponse struct {
 	Message string `json:"message"`
 }
 
-type TimeSeriesData struct {
-	Label string `json:"label"`
-	Count int64  `json:"count"`
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"` // user|moderator|admin
+}
+
+type UserPreferencesRequest struct {
+	Units string `json:"units"` // metric|imperial|original
 }
 
 type AnalyticsRequest struct {
@@ -86,7 +107,9 @@ type ChartJSDataset struct {
 // @Failure      409   {object}  ErrorResponse
 // @Failure      500   {object}  ErrorResponse
 // @Router       /signup [post]
-func Signup(c *gin.Context) {
+func (h *Handler) Signup(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var req UserSignupRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
@@ -94,54 +117,50 @@ func Signup(c *gin.Context) {
 	}
 
 	if req.Name == "" || req.LastName == "" || req.Email == "" || req.Password == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: messages.User.EmptyInfoErr.String()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.User.EmptyInfoErr)})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+	users := h.app.Repos.Users
 
-	var existingUser model.User
-	if err := db.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusConflict, ErrorResponse{Error: messages.User.UserAlreadyExists.String()})
+	if _, err := users.FindByEmail(req.Email); err == nil {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: loc.T(messages.User.UserAlreadyExists)})
 		return
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.User.EmailCheckErr.String()})
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.EmailCheckErr)})
 		return
 	}
 
-	salt, err := utils.GenerateSalt()
+	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.User.GenerateSaltFail.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.HashPasswordFail)})
 		return
 	}
 
-	hashedPassword := utils.HashPassword(req.Password, salt)
-
 	user := model.User{
 		Name:     req.Name,
 		LastName: req.LastName,
 		Email:    req.Email,
 		Password: hashedPassword,
-		Salt:     salt,
-		Role:     "user",
+		Role:     string(policy.RoleUser),
 	}
 
-	err = db.Create(&user).Error
+	err = users.Create(&user)
 	if err != nil {
-		var mysqlErr *mysql.MySQLError
-		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
-			c.JSON(http.StatusConflict, ErrorResponse{Error: messages.User.EmailExistsErr.String()})
+		if h.app.Dialect.IsUniqueViolation(err) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: loc.T(messages.User.EmailExistsErr)})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.User.UserCreateFailed.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.UserCreateFailed)})
 		return
 	}
 
-	c.JSON(http.StatusOK, SimpleMessageResponse{Message: messages.User.UserCreatedSuccess.String()})
+	// The account exists either way; the user can ask for a new link.
+	if err := h.sendVerificationEmail(loc, &user); err != nil {
+		log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.User.UserCreatedSuccess)})
 }
 
 // Login godoc
@@ -156,44 +175,133 @@ func Signup(c *gin.Context) {
 // @Failure      401          {object}  ErrorResponse
 // @Failure      500          {object}  ErrorResponse
 // @Router       /login [post]
-func Login(c *gin.Context) {
+func (h *Handler) Login(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var req UserLoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+	users := h.app.Repos.Users
 
-	var user model.User
-	if err = db.Where("email = ?", req.Email).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.User.LoginInvalidEmailPass.String()})
+	user, err := users.FindByEmail(req.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: loc.T(messages.User.LoginInvalidEmailPass)})
 			return
 		} else {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.User.UserFetchFail.String()})
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.UserFetchFail)})
 			return
 		}
 	}
 
-	enteredPassword := utils.HashPassword(req.Password, user.Salt)
+	ok, needsRehash, err := utils.VerifyPassword(req.Password, user.Password, user.Salt)
+	if err != nil || !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: loc.T(messages.User.LoginInvalidEmailPass)})
+		return
+	}
+
+	// Upgrade legacy or outdated hashes while we have the plain password.
+	// A failure here must not block the login, the old hash still works.
+	if needsRehash {
+		if rehashed, err := utils.HashPassword(req.Password); err == nil {
+			if err := users.Updates(user, map[string]any{"password": rehashed, "salt": ""}); err != nil {
+				log.Printf("failed to upgrade password hash for user %d: %v", user.ID, err)
+			}
+		}
+	}
+
+	pair, err := h.app.Tokens.Issue(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.GeneratTokenFail)})
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginResponse(pair, user.ID))
+}
+
+func newLoginResponse(pair service.TokenPair, userID uint) UserLoginResponse {
+	return UserLoginResponse{
+		Token:        pair.Access.Token,
+		ExpiresAt:    pair.Access.ExpiresAt,
+		RefreshToken: pair.RefreshToken,
+		UserID:       userID,
+	}
+}
+
+// RefreshTokenHandler godoc
+// @Summary      Refresh access token
+// @Description  Exchanges a refresh token for a new access/refresh token pair. The presented refresh token is invalidated; reusing it revokes every token issued from the same login.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        data  body      TokenRefreshRequest  true  "Refresh token"
+// @Success      200   {object}  UserLoginResponse
+// @Failure      400   {object}  ErrorResponse
+// @Failure      401   {object}  ErrorResponse
+// @Failure      500   {object}  ErrorResponse
+// @Router       /token/refresh [post]
+func (h *Handler) RefreshTokenHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
-	if user.Password != enteredPassword {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.User.LoginInvalidEmailPass.String()})
+	var req TokenRefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	tokenStr, err := token.GenerateToken(user.ID, user.Email, user.Role)
+	pair, userID, err := h.app.Tokens.Refresh(req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.User.GeneratTokenFail.String()})
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: loc.T(messages.User.RefreshTokenReused)})
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: loc.T(messages.User.TokenInvalid)})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.GeneratTokenFail)})
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, UserLoginResponse{Token: tokenStr, UserID: user.ID})
+	c.JSON(http.StatusOK, newLoginResponse(pair, userID))
+}
+
+// LogoutHandler godoc
+// @Summary      Logout
+// @Description  Revokes the current access token and, if provided, the refresh token family
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        data  body      LogoutRequest  false  "Refresh token to revoke"
+// @Success      200   {object}  SimpleMessageResponse
+// @Failure      400   {object}  ErrorResponse
+// @Failure      401   {object}  ErrorResponse
+// @Failure      500   {object}  ErrorResponse
+// @Router       /logout [post]
+func (h *Handler) LogoutHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	var req LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	claims := c.MustGet("claims").(jwt.MapClaims)
+	jti, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+
+	if err := h.app.Tokens.Logout(c.GetUint("userID"), jti, time.Unix(int64(exp), 0), req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.LogoutFail)})
+		return
+	}
+
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.User.LogoutSuccess)})
 }
 
 // ForgotPasswordHandler godoc
@@ -207,49 +315,57 @@ func Login(c *gin.Context) {
 // @Failure      400    {object}  ErrorResponse
 // @Failure      500    {object}  ErrorResponse
 // @Router       /forgot-password [post]
-func ForgotPasswordHandler(c *gin.Context) {
+func (h *Handler) ForgotPasswordHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var req ForgotPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: messages.User.EmailCheckErr.String()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.User.EmailCheckErr)})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+	users := h.app.Repos.Users
 
-	var user model.User
-	if err := db.Where("email = ?", req.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusOK, ForgotPasswordResponse{Message: messages.User.PasswordResetFailed.String()})
+	// Unknown addresses get the same answer so the endpoint cannot be used
+	// to find out who has an account.
+	user, err := users.FindByEmail(req.Email)
+	if err != nil {
+		c.JSON(http.StatusOK, ForgotPasswordResponse{Message: loc.T(messages.User.PasswordResetSent)})
 		return
 	}
 
 	resetToken, err := token.GenerateResetToken()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.User.GeneratTokenFail.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.GeneratTokenFail)})
 		return
 	}
 
+	now := h.app.Clock.Now()
 	user.PasswordResetToken = resetToken
-	expiresAt := time.Now().Add(15 * time.Minute)
+	expiresAt := now.Add(15 * time.Minute)
 	user.PasswordResetExpiresAt = &expiresAt
 
-	err = db.Model(&user).Updates(map[string]any{
+	err = users.Updates(user, map[string]any{
 		"password_reset_token":      resetToken,
 		"password_reset_expires_at": expiresAt,
-		"updated_at":                time.Now()}).Error
+		"updated_at":                now})
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.User.PasswordResetCreateFailed.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.PasswordResetCreateFailed)})
 		return
 	}
 
-	c.JSON(http.StatusOK, ForgotPasswordResponse{
-		Message:    messages.Common.Success.String(),
-		ResetToken: resetToken,
+	err = h.sendMail(loc, user.Email, mailer.PasswordReset, mailer.LinkData{
+		Name: user.Name,
+		Link: h.mailLink("/reset-password", resetToken),
 	})
+	if err != nil {
+		log.Printf("failed to send password reset email to user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.PasswordResetFailed)})
+		return
+	}
+
+	c.JSON(http.StatusOK, ForgotPasswordResponse{Message: loc.T(messages.User.PasswordResetSent)})
 }
 
 // ResetPasswordHandler godoc
@@ -263,48 +379,131 @@ func ForgotPasswordHandler(c *gin.Context) {
 // @Failure      400   {object}  ErrorResponse
 // @Failure      500   {object}  ErrorResponse
 // @Router       /reset-password [post]
-func ResetPasswordHandler(c *gin.Context) {
+func (h *Handler) ResetPasswordHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var req ResetPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input"})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+	users := h.app.Repos.Users
 
-	var user model.User
-	if err := db.Where("password_reset_token = ?", req.Token).First(&user).Error; err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: messages.User.TokenInvalid.String()})
+	user, err := users.FindByResetToken(req.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.User.TokenInvalid)})
 		return
 	}
 
-	if user.PasswordResetExpiresAt == nil || user.PasswordResetExpiresAt.Before(time.Now()) {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: messages.User.TokenExpired.String()})
+	if user.PasswordResetExpiresAt == nil || user.PasswordResetExpiresAt.Before(h.app.Clock.Now()) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.User.TokenExpired)})
 		return
 	}
 
-	salt, err := utils.GenerateSalt()
+	hashed, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.User.GenerateSaltFail.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.HashPasswordFail)})
 		return
 	}
-	hashed := utils.HashPassword(req.NewPassword, salt)
 
 	user.Password = hashed
-	user.Salt = salt
+	user.Salt = ""
 	user.PasswordResetToken = ""
 	user.PasswordResetExpiresAt = nil
 
-	if err := db.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.User.PasswordResetFailed.String()})
+	if err := users.Save(user); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.PasswordResetFailed)})
 		return
 	}
 
-	c.JSON(http.StatusOK, ResetPasswordResponse{Message: messages.User.PasswordResetSuccess.String()})
+	// Whoever knew the old password may still hold a session.
+	if err := h.app.Tokens.RevokeAll(user.ID); err != nil {
+		log.Printf("failed to revoke sessions of user %d: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, ResetPasswordResponse{Message: loc.T(messages.User.PasswordResetSuccess)})
+}
+
+// VerifyEmailHandler godoc
+// @Summary      Verify email address
+// @Description  Confirms the email address using the token sent on signup
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        data  body      VerifyEmailRequest  true  "Verification token"
+// @Success      200   {object}  SimpleMessageResponse
+// @Failure      400   {object}  ErrorResponse
+// @Failure      500   {object}  ErrorResponse
+// @Router       /verify-email [post]
+func (h *Handler) VerifyEmailHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	users := h.app.Repos.Users
+
+	user, err := users.FindByVerificationToken(req.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.User.TokenInvalid)})
+		return
+	}
+
+	now := h.app.Clock.Now()
+	if user.VerificationExpiresAt == nil || user.VerificationExpiresAt.Before(now) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.User.TokenExpired)})
+		return
+	}
+
+	err = users.Updates(user, map[string]any{
+		"email_verified_at":       now,
+		"verification_token":      "",
+		"verification_expires_at": nil,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Common.InternalServerErr)})
+		return
+	}
+
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.User.EmailVerified)})
+}
+
+// ResendVerificationHandler godoc
+// @Summary      Resend verification email
+// @Description  Sends a new verification link to the authenticated user
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  SimpleMessageResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /verify-email/resend [post]
+func (h *Handler) ResendVerificationHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	user, err := h.app.Repos.Users.FindByID(c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.User.UserNotFound)})
+		return
+	}
+
+	if user.EmailVerifiedAt != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.User.EmailAlreadyVerified)})
+		return
+	}
+
+	if err := h.sendVerificationEmail(loc, user); err != nil {
+		log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.VerificationSendFail)})
+		return
+	}
+
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.User.VerificationSent)})
 }
 
 // GetAnalytics godoc
@@ -314,11 +513,11 @@ func ResetPasswordHandler(c *gin.Context) {
 // @Produce      json
 // @Param        metric query string true "Metric to analyze" Enums(views, favorites, ratings, site, recipes)
 // @Param        period query string true "Time period" Enums(week, month, year)
-// @Success      200 {array} TimeSeriesData
+// @Success      200 {array} repository.TimeSeriesData
 // @Failure      400 {object} map[string]string
 // @Failure      500 {object} map[string]string
 // @Router       /admin/analytics [get]
-func GetAnalytics(c *gin.Context) {
+func (h *Handler) GetAnalytics(c *gin.Context) {
 	var req AnalyticsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -331,48 +530,27 @@ func GetAnalytics(c *gin.Context) {
 		return
 	}
 
-	var query string
+	// Buckets start at midnight so the first day/month is complete.
+	now := h.app.Clock.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var since time.Time
+	var bucket internal.DateBucket
 	switch req.Period {
 	case "week":
-		query = fmt.Sprintf(`
-			SELECT DATE_FORMAT(%s, '%%Y-%%m-%%d') as label, COUNT(*) as count
-			FROM %s
-			WHERE %s >= DATE_SUB(CURDATE(), INTERVAL 7 DAY)
-			GROUP BY DATE_FORMAT(%s, '%%Y-%%m-%%d')
-			ORDER BY DATE_FORMAT(%s, '%%Y-%%m-%%d');
-		`, column, table, column, column, column)
-
+		since, bucket = today.AddDate(0, 0, -7), internal.DayBucket
 	case "month":
-		query = fmt.Sprintf(`
-			SELECT DATE_FORMAT(%s, '%%Y-%%m-%%d') as label, COUNT(*) as count
-			FROM %s
-			WHERE %s >= DATE_SUB(CURDATE(), INTERVAL 30 DAY)
-			GROUP BY DATE_FORMAT(%s, '%%Y-%%m-%%d')
-			ORDER BY DATE_FORMAT(%s, '%%Y-%%m-%%d');
-		`, column, table, column, column, column)
-
+		since, bucket = today.AddDate(0, 0, -30), internal.DayBucket
 	case "year":
-		query = fmt.Sprintf(`
-            SELECT DATE_FORMAT(%s, '%%Y-%%m') as label, COUNT(*) as count
-            FROM %s
-            WHERE %s >= DATE_SUB(CURDATE(), INTERVAL 12 MONTH)
-            GROUP BY DATE_FORMAT(%s, '%%Y-%%m')
-            ORDER BY DATE_FORMAT(%s, '%%Y-%%m');
-        `, column, table, column, column, column)
-
+		since, bucket = today.AddDate(0, -12, 0), internal.MonthBucket
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period"})
 		return
 	}
 
 	// Fetch DB results
-	var results []TimeSeriesData
-	db, err := internal.GetGormInstance()
+	results, err := h.app.Repos.Analytics.TimeSeries(table, column, bucket, since)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-	if err := db.Raw(query).Scan(&results).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
 		return
 	}
@@ -1,16 +1,17 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
-	"github.com/Abb133Se/recepieshare/internal"
 	"github.com/Abb133Se/recepieshare/messages"
 	"github.com/Abb133Se/recepieshare/middleware"
 	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/Abb133Se/recepieshare/repository"
 	"github.com/Abb133Se/recepieshare/utils"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type PostFavoriteRequest struct {
@@ -37,7 +38,9 @@ type FavoriteResponse struct {
 // @Failure      409       {object}  ErrorResponse "Favorite already exists"
 // @Failure      500       {object}  ErrorResponse "Internal server error"
 // @Router       /favorite [post]
-func PostFavoriteHandler(c *gin.Context) {
+func (h *Handler) PostFavoriteHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var req PostFavoriteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "bad request"})
@@ -46,31 +49,27 @@ func PostFavoriteHandler(c *gin.Context) {
 
 	userID := c.GetUint("userID")
 	if userID == 0 {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: loc.T(messages.Common.Unauthorized)})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+	repos := h.app.Repos
 
 	// Check if recipe exists
-	if err := db.Select("id").First(&model.Recipe{}, req.RecipeID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+	if recipe, err := repos.Recipes.FindByID(req.RecipeID); err != nil || !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		return
 	}
 
 	// Insert favorite only if not exists
 	favorite := model.Favorite{UserID: userID, RecipeID: req.RecipeID}
-	if err := db.FirstOrCreate(&favorite, model.Favorite{UserID: userID, RecipeID: req.RecipeID}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Favorite.FavoriteAddFail.String()})
+	if err := repos.Favorites.FirstOrCreate(&favorite); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Favorite.FavoriteAddFail)})
 		return
 	}
 
 	c.JSON(http.StatusOK, FavoriteResponse{
-		Message:    messages.Favorite.FavoriteAdded.String(),
+		Message:    loc.T(messages.Favorite.FavoriteAdded),
 		FavoriteID: favorite.ID,
 	})
 }
@@ -101,28 +100,15 @@ func PostFavoriteHandler(c *gin.Context) {
 // @Failure      404 {object} controller.ErrorResponse
 // @Failure      500 {object} controller.ErrorResponse
 // @Router       /admin/user/{userID}/unfavorite/{favoriteID} [delete]
-func DeleteFavoriteHandler(c *gin.Context) {
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+func (h *Handler) DeleteFavoriteHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	// The caller, or the :userID of the admin route
+	userID, ok := h.targetUserID(c, policy.Favorite, policy.Delete)
+	if !ok {
 		return
 	}
 
-	// Get logged-in user ID from context (set by your auth middleware)
-	var userID uint
-	if role := c.GetString("role"); role == "user" {
-		userID = c.GetUint("userID")
-		if userID == 0 {
-			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-			return
-		}
-	} else if role == "admin" {
-		userID, err = middleware.GetEffectiveUserID(c)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-			return
-		}
-	}
 	// Get recipeId from request (query or body depending on design)
 	recipeIDParam := c.Query("recipe_id")
 	if recipeIDParam == "" {
@@ -130,30 +116,30 @@ func DeleteFavoriteHandler(c *gin.Context) {
 		return
 	}
 
-	recipeID, err := strconv.Atoi(recipeIDParam)
+	recipeID, err := strconv.ParseUint(recipeIDParam, 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipeId"})
 		return
 	}
 
 	// Find the favorite entry by userId + recipeId
-	var favorite model.Favorite
-	if err := db.Where("user_id = ? AND recipe_id = ?", userID, recipeID).First(&favorite).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Favorite.FavoriteNotFound.String()})
+	favorite, err := h.app.Repos.Favorites.FindByUserAndRecipe(userID, uint(recipeID))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Favorite.FavoriteNotFound)})
 		} else {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Favorite.FavoriteRemoveQueryFail.String()})
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Favorite.FavoriteRemoveQueryFail)})
 		}
 		return
 	}
 
 	// Delete the favorite
-	if err := db.Delete(&favorite).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Favorite.FavoriteRemoveFail.String()})
+	if err := h.app.Repos.Favorites.Delete(favorite); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Favorite.FavoriteRemoveFail)})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": messages.Favorite.FavoriteRemoved.String()})
+	c.JSON(http.StatusOK, gin.H{"message": loc.T(messages.Favorite.FavoriteRemoved)})
 
 }
 
@@ -170,45 +156,15 @@ func DeleteFavoriteHandler(c *gin.Context) {
 // @Failure      401  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /favorites [get]
-func GetAllFavorites(c *gin.Context) {
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection failed"})
-		return
-	}
-
+func (h *Handler) GetAllFavorites(c *gin.Context) {
 	limit, offset, _ := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
 	if limit == 0 {
 		limit = 10
 	}
 
 	// Select favorites with user and recipe info
-	query := db.Table("favorites").
-		Select(`favorites.id as favorite_id, 
-                users.id as user_id, 
-                users.name as user_name,
-                recipes.id as recipe_id, 
-                recipes.title as recipe_title`).
-		Joins("JOIN users ON favorites.user_id = users.id").
-		Joins("JOIN recipes ON favorites.recipe_id = recipes.id")
-
-	// Count total favorites
-	total, err := utils.Count(query, "favorites")
+	favorites, total, err := h.app.Repos.Favorites.ListAll(limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count favorites"})
-		return
-	}
-
-	// Fetch paginated results
-	var favorites []struct {
-		FavoriteID  uint   `json:"favorite_id"`
-		UserID      uint   `json:"user_id"`
-		UserName    string `json:"user_name"`
-		RecipeID    uint   `json:"recipe_id"`
-		RecipeTitle string `json:"recipe_title"`
-	}
-
-	if err := utils.Paginate(query, limit, offset, &favorites); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch favorites"})
 		return
 	}
@@ -0,0 +1,14 @@
+package controller
+
+import (
+	"github.com/Abb133Se/recepieshare/app"
+)
+
+// Handler carries the application dependencies into the HTTP handlers.
+type Handler struct {
+	app *app.App
+}
+
+func NewHandler(a *app.App) *Handler {
+	return &Handler{app: a}
+}
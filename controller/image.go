@@ -3,10 +3,9 @@ package controller
 import (
 	"net/http"
 
-	"github.com/Abb133Se/recepieshare/internal"
 	"github.com/Abb133Se/recepieshare/messages"
-	"github.com/Abb133Se/recepieshare/model"
-	"github.com/Abb133Se/recepieshare/service"
+	"github.com/Abb133Se/recepieshare/middleware"
+	"github.com/Abb133Se/recepieshare/policy"
 	"github.com/Abb133Se/recepieshare/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -25,26 +24,25 @@ import (
 // @Failure 403 {object} map[string]string "Not authorized to upload for this recipe"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /recipe/{id}/image [post]
-func PostUploadRecipeImageHandler(c *gin.Context) {
-	userID := c.GetUint("userID")
+func (h *Handler) PostUploadRecipeImageHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	recipeID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	db, _ := internal.GetGormInstance()
-	var recipe model.Recipe
-	if err := db.First(&recipe, recipeID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+	recipe, err := h.app.Repos.Recipes.FindByID(recipeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		return
 	}
-	if recipe.UserID != userID {
-		c.JSON(http.StatusForbidden, ErrorResponse{Error: messages.Common.Unauthorized.String()})
+	if !h.authorize(c, policy.Image, policy.Create, recipe.UserID) {
 		return
 	}
 
-	img, err := service.UploadImage(c, "recipe", recipeID, internal.Backend)
+	img, err := h.app.Images.UploadImage(c, "recipe", recipeID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -63,7 +61,7 @@ func PostUploadRecipeImageHandler(c *gin.Context) {
 // @Failure 400 {object} map[string]string "Invalid ID parameters"
 // @Failure 404 {object} map[string]string "Image not found"
 // @Router /recipe/{id}/image/{imageId} [get]
-func GetServeRecipeImageHandler(c *gin.Context) {
+func (h *Handler) GetServeRecipeImageHandler(c *gin.Context) {
 	recipeID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -76,13 +74,28 @@ func GetServeRecipeImageHandler(c *gin.Context) {
 		return
 	}
 
-	err = service.ServeImage(c, "recipe", recipeID, imageID, internal.Backend)
+	if !h.recipeImageVisible(c, recipeID) {
+		return
+	}
+
+	err = h.app.Images.ServeImage(c, "recipe", recipeID, imageID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 }
 
+// recipeImageVisible writes a 404 unless the caller may see the recipe the
+// image belongs to.
+func (h *Handler) recipeImageVisible(c *gin.Context, recipeID uint) bool {
+	recipe, err := h.app.Repos.Recipes.FindByID(recipeID)
+	if err != nil || !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: middleware.Localizer(c).T(messages.Recipe.RecipeNotFound)})
+		return false
+	}
+	return true
+}
+
 // DeleteRecipeImageHandler godoc
 // @Summary Delete a recipe image by ID
 // @Description Deletes an image associated with a recipe (must belong to the authenticated user)
@@ -95,27 +108,26 @@ func GetServeRecipeImageHandler(c *gin.Context) {
 // @Failure 403 {object} map[string]string "Not authorized to delete this recipe image"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /recipe/{id}/image/{imageId} [delete]
-func DeleteRecipeImageHandler(c *gin.Context) {
-	userID := c.GetUint("userID")
+func (h *Handler) DeleteRecipeImageHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	recipeID, _ := utils.ValidateEntityID(c.Param("id"))
 	imageID, _ := utils.ValidateEntityID(c.Param("imageId"))
 
-	db, _ := internal.GetGormInstance()
-	var recipe model.Recipe
-	if err := db.First(&recipe, recipeID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+	recipe, err := h.app.Repos.Recipes.FindByID(recipeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		return
 	}
-	if recipe.UserID != userID {
-		c.JSON(http.StatusForbidden, ErrorResponse{Error: messages.Common.Unauthorized.String()})
+	if !h.authorize(c, policy.Image, policy.Delete, recipe.UserID) {
 		return
 	}
 
-	if err := service.DeleteImage("recipe", recipeID, imageID, internal.Backend); err != nil {
+	if err := h.app.Images.DeleteImage("recipe", recipeID, imageID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": messages.Image.ImageDeleted})
+	c.JSON(http.StatusOK, gin.H{"message": loc.T(messages.Image.ImageDeleted)})
 }
 
 // PostUploadUserProfileImageHandler godoc
@@ -130,9 +142,9 @@ func DeleteRecipeImageHandler(c *gin.Context) {
 // @Failure 400 {object} map[string]string "Invalid input or file error"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /user/profile-image [post]
-func PostUploadUserProfileImageHandler(c *gin.Context) {
+func (h *Handler) PostUploadUserProfileImageHandler(c *gin.Context) {
 	userID := c.GetUint("userID")
-	img, err := service.UploadImage(c, "user", userID, internal.Backend)
+	img, err := h.app.Images.UploadImage(c, "user", userID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -151,11 +163,11 @@ func PostUploadUserProfileImageHandler(c *gin.Context) {
 // @Failure 400 {object} map[string]string "Invalid ID parameters"
 // @Failure 404 {object} map[string]string "Image not found"
 // @Router /user/profile-image/{imageId} [get]
-func GetServeUserProfileImageHandler(c *gin.Context) {
+func (h *Handler) GetServeUserProfileImageHandler(c *gin.Context) {
 	userID := c.GetUint("userID")
 	imageID, _ := utils.ValidateEntityID(c.Param("imageId"))
 
-	if err := service.ServeImage(c, "user", userID, imageID, internal.Backend); err != nil {
+	if err := h.app.Images.ServeImage(c, "user", userID, imageID); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
@@ -171,15 +183,17 @@ func GetServeUserProfileImageHandler(c *gin.Context) {
 // @Failure 400 {object} map[string]string "Invalid ID parameters"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /user/profile-image/{imageId} [delete]
-func DeleteUserProfileImageHandler(c *gin.Context) {
+func (h *Handler) DeleteUserProfileImageHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	userID := c.GetUint("userID")
 	imageID, _ := utils.ValidateEntityID(c.Param("imageId"))
 
-	if err := service.DeleteImage("user", userID, imageID, internal.Backend); err != nil {
+	if err := h.app.Images.DeleteImage("user", userID, imageID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": messages.Image.ImageDeleted.String()})
+	c.JSON(http.StatusOK, gin.H{"message": loc.T(messages.Image.ImageDeleted)})
 }
 
 // GetImageHandler godoc
@@ -194,7 +208,7 @@ func DeleteUserProfileImageHandler(c *gin.Context) {
 // @Failure 400 {object} map[string]string "Invalid ID or entity parameters"
 // @Failure 404 {object} map[string]string "Image not found"
 // @Router /image/{entity}/{entityId}/{imageId} [get]
-func GetImageHandler(c *gin.Context) {
+func (h *Handler) GetImageHandler(c *gin.Context) {
 	entityType := c.Param("entity")
 
 	entityID, err := utils.ValidateEntityID(c.Param("entityId"))
@@ -209,7 +223,11 @@ func GetImageHandler(c *gin.Context) {
 		return
 	}
 
-	err = service.ServeImage(c, entityType, entityID, imageID, internal.Backend)
+	if entityType == "recipe" && !h.recipeImageVisible(c, entityID) {
+		return
+	}
+
+	err = h.app.Images.ServeImage(c, entityType, entityID, imageID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
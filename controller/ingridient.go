@@ -5,12 +5,13 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/Abb133Se/recepieshare/internal"
 	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
 	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/Abb133Se/recepieshare/repository"
 	"github.com/Abb133Se/recepieshare/utils"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // Response structs for Swagger documentation
@@ -27,16 +28,17 @@ type IngredientCreateResponse struct {
 
 // GetIngredientHandler godoc
 // @Summary      Get an ingredient by ID
-// @Description  Retrieve an ingredient by its ID
+// @Description  Retrieve an ingredient by its ID. Ingredients of recipes the caller may not see are not found; pass share_token for unlisted recipes.
 // @Tags         ingredients
-// @Param        id   path      int  true  "Ingredient ID"
-// @Success      200  {object}  controller.IngredientResponse
-// @Failure      400  {object}  controller.ErrorResponse
-// @Failure      404  {object}  controller.ErrorResponse
-// @Failure      500  {object}  controller.ErrorResponse
+// @Param        id           path      int     true   "Ingredient ID"
+// @Param        share_token  query     string  false  "Share token of an unlisted recipe"
+// @Success      200          {object}  controller.IngredientResponse
+// @Failure      400          {object}  controller.ErrorResponse
+// @Failure      404          {object}  controller.ErrorResponse
+// @Failure      500          {object}  controller.ErrorResponse
 // @Router       /ingredient/{id} [get]
-func GetIngredientHandler(c *gin.Context) {
-	var ingredient model.Ingredient
+func (h *Handler) GetIngredientHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	id := c.Param("id")
 
@@ -46,24 +48,25 @@ func GetIngredientHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	ingredient, err := h.app.Repos.Recipes.FindIngredient(validID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Common.NotFound)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Common.DBConnectionErr)})
 		return
 	}
 
-	err = db.First(&ingredient, validID).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Common.NotFound.String()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+	// Ingredients are only as visible as their recipe.
+	if recipe, err := h.app.Repos.Recipes.FindByID(ingredient.RecipeID); err != nil || !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Common.NotFound)})
 		return
 	}
+
 	c.JSON(http.StatusOK, IngredientResponse{
-		Message: messages.Common.Success.String(),
-		Data:    ingredient,
+		Message: loc.T(messages.Common.Success),
+		Data:    *ingredient,
 	})
 }
 
@@ -79,13 +82,14 @@ func GetIngredientHandler(c *gin.Context) {
 // @Failure      404         {object}  controller.ErrorResponse
 // @Failure      500         {object}  controller.ErrorResponse
 // @Router       /ingredient [post]
-func PostIngredientHandler(c *gin.Context) {
+func (h *Handler) PostIngredientHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var ingredient model.Ingredient
-	var recipe model.Recipe
 
 	err := c.BindJSON(&ingredient)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: messages.Common.BadRequest.String()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Common.BadRequest)})
 		return
 	}
 
@@ -103,30 +107,38 @@ func PostIngredientHandler(c *gin.Context) {
 		}
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+	recipes := h.app.Repos.Recipes
 
-	err = db.First(&recipe, ingredient.RecipeID).Error
+	recipe, err := recipes.FindByID(ingredient.RecipeID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Common.DBConnectionErr)})
 		return
 	}
 
-	err = db.Create(&ingredient).Error
+	if !h.authorize(c, policy.Ingredient, policy.Create, recipe.UserID) {
+		return
+	}
+
+	err = h.changeRecipe(c, recipe.ID, func(tx *repository.Repositories) error {
+		if err := tx.Recipes.CreateIngredient(&ingredient); err != nil {
+			return err
+		}
+		if err := tx.Recipes.RefreshDietaryLabels(recipe.ID); err != nil {
+			return err
+		}
+		return h.enqueueNutrition(tx, recipe.ID)
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.Failed.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Common.Failed)})
 		return
 	}
 
 	c.JSON(http.StatusCreated, IngredientCreateResponse{
-		Message: messages.Common.Success.String(),
+		Message: loc.T(messages.Common.Success),
 		ID:      ingredient.ID,
 	})
 }
@@ -141,8 +153,8 @@ func PostIngredientHandler(c *gin.Context) {
 // @Failure      404  {object}  controller.ErrorResponse
 // @Failure      500  {object}  controller.ErrorResponse
 // @Router       /ingredient/{id} [delete]
-func DeleteIngredientHandler(c *gin.Context) {
-	var ingredient model.Ingredient
+func (h *Handler) DeleteIngredientHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	id := c.Param("id")
 
@@ -152,23 +164,37 @@ func DeleteIngredientHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	recipes := h.app.Repos.Recipes
+
+	ingredient, err := recipes.FindIngredient(validID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Common.NotFound)})
 		return
 	}
 
-	err = db.First(&ingredient, validID).Error
+	recipe, err := recipes.FindByID(ingredient.RecipeID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Common.NotFound.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
+		return
+	}
+
+	if !h.authorize(c, policy.Ingredient, policy.Delete, recipe.UserID) {
 		return
 	}
 
-	err = db.Delete(&model.Ingredient{}, validID).Error
+	err = h.changeRecipe(c, recipe.ID, func(tx *repository.Repositories) error {
+		if err := tx.Recipes.DeleteIngredient(validID); err != nil {
+			return err
+		}
+		if err := tx.Recipes.RefreshDietaryLabels(recipe.ID); err != nil {
+			return err
+		}
+		return h.enqueueNutrition(tx, recipe.ID)
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.Failed.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Common.Failed)})
 		return
 	}
 
-	c.JSON(http.StatusOK, SuccessMessageResponse{Message: messages.Common.Success.String()})
+	c.JSON(http.StatusOK, SuccessMessageResponse{Message: loc.T(messages.Common.Success)})
 }
@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type JobListResponse struct {
+	Message string      `json:"message"`
+	Data    []model.Job `json:"data"`
+	Count   int64       `json:"count"`
+	// Stats is the number of jobs per status, regardless of the filters.
+	Stats map[model.JobStatus]int64 `json:"stats"`
+}
+
+type JobResponse struct {
+	Message string    `json:"message"`
+	Data    model.Job `json:"data"`
+}
+
+// GetJobsHandler godoc
+// @Summary      List background jobs
+// @Description  Lists the background jobs, newest first, with the number of jobs per status. Filter by status=dead to see the jobs that failed for good.
+// @Tags         jobs
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status  query  string  false  "Job status"  Enums(pending, running, done, dead)
+// @Param        type    query  string  false  "Job type, e.g. nutrition.estimate"
+// @Param        limit   query  int     false  "Limit number of jobs returned"
+// @Param        offset  query  int     false  "Page number"
+// @Success      200  {object}  controller.JobListResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /admin/jobs [get]
+func (h *Handler) GetJobsHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	var status model.JobStatus
+	if s := c.Query("status"); s != "" {
+		var ok bool
+		if status, ok = model.ParseJobStatus(s); !ok {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Job.JobStatusInvalid)})
+			return
+		}
+	}
+
+	limit, offset, _ := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
+	if limit == 0 {
+		limit = 20
+	}
+
+	jobs, total, err := h.app.Repos.Jobs.List(status, c.Query("type"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Job.JobFetchFailed)})
+		return
+	}
+	stats, err := h.app.Repos.Jobs.CountByStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Job.JobFetchFailed)})
+		return
+	}
+
+	c.JSON(http.StatusOK, JobListResponse{
+		Message: loc.T(messages.Job.JobsFetched),
+		Data:    jobs,
+		Count:   total,
+		Stats:   stats,
+	})
+}
+
+// GetJobHandler godoc
+// @Summary      Get a background job
+// @Description  Returns one background job with its payload, attempts and last error.
+// @Tags         jobs
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Job ID"
+// @Success      200  {object}  controller.JobResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /admin/jobs/{id} [get]
+func (h *Handler) GetJobHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	id, err := utils.ValidateEntityID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	job, err := h.app.Repos.Jobs.FindByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Job.JobNotFound)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Job.JobFetchFailed)})
+		return
+	}
+
+	c.JSON(http.StatusOK, JobResponse{Message: loc.T(messages.Common.Success), Data: *job})
+}
+
+// PostRetryJobHandler godoc
+// @Summary      Retry a dead background job
+// @Description  Puts a job that failed for good back in the queue with a fresh set of attempts. Only dead jobs can be retried.
+// @Tags         jobs
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Job ID"
+// @Success      200  {object}  controller.JobResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      409  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /admin/jobs/{id}/retry [post]
+func (h *Handler) PostRetryJobHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	id, err := utils.ValidateEntityID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	job, err := h.app.Repos.Jobs.FindByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Job.JobNotFound)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Job.JobFetchFailed)})
+		return
+	}
+	if job.Status != model.JobDead {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: loc.T(messages.Job.JobNotDead)})
+		return
+	}
+
+	if err := h.app.Jobs.Retry(id); err != nil {
+		// Retried by someone else in the meantime.
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: loc.T(messages.Job.JobNotDead)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Job.JobRetryFailed)})
+		return
+	}
+	if job, err = h.app.Repos.Jobs.FindByID(id); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Job.JobFetchFailed)})
+		return
+	}
+
+	c.JSON(http.StatusOK, JobResponse{Message: loc.T(messages.Job.JobRetried), Data: *job})
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/mailer"
+	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/token"
+)
+
+const verificationTTL = 24 * time.Hour
+
+// sendMail renders t in the language of loc and sends it.
+func (h *Handler) sendMail(loc messages.Localizer, to string, t mailer.Template, data mailer.LinkData) error {
+	return h.app.Mailer.Send(mailer.Render(loc, t, to, data))
+}
+
+// mailLink builds a frontend link carrying token as query parameter.
+func (h *Handler) mailLink(path, tok string) string {
+	base := strings.TrimRight(h.app.Config.Mail.LinkBaseURL, "/")
+	return base + path + "?token=" + url.QueryEscape(tok)
+}
+
+// sendVerificationEmail stores a fresh verification token for user and
+// emails the link to confirm the address.
+func (h *Handler) sendVerificationEmail(loc messages.Localizer, user *model.User) error {
+	verificationToken, err := token.GenerateResetToken()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := h.app.Clock.Now().Add(verificationTTL)
+	err = h.app.Repos.Users.Updates(user, map[string]any{
+		"verification_token":      verificationToken,
+		"verification_expires_at": expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.sendMail(loc, user.Email, mailer.AccountVerification, mailer.LinkData{
+		Name: user.Name,
+		Link: h.mailLink("/verify-email", verificationToken),
+	})
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Abb133Se/recepieshare/config"
+	"github.com/Abb133Se/recepieshare/dietary"
+	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/gin-gonic/gin"
+)
+
+// maxMinutes bounds prep, cook and total time: a week, enough for cured or
+// fermented dishes.
+const maxMinutes = 7 * 24 * 60
+
+// RecipeMetadataInput is the time, difficulty, cuisine and equipment of a
+// recipe as sent on create and update. Omitted fields keep their value; an
+// empty string or list clears it.
+type RecipeMetadataInput struct {
+	PrepMinutes *int `json:"prep_minutes"`
+	CookMinutes *int `json:"cook_minutes"`
+	// TotalMinutes defaults to prep plus cook time. Set it when resting or
+	// rising time adds to that.
+	TotalMinutes *int     `json:"total_minutes"`
+	Difficulty   *string  `json:"difficulty"`
+	Cuisine      *string  `json:"cuisine"`
+	Equipment    []string `json:"equipment"`
+}
+
+type RecipeOptionsResponse struct {
+	Message string `json:"message"`
+	// Difficulties are ordered from easiest to hardest.
+	Difficulties []string `json:"difficulties"`
+	Cuisines     []string `json:"cuisines"`
+	Equipment    []string `json:"equipment"`
+	Diets        []string `json:"diets"`
+	Allergens    []string `json:"allergens"`
+}
+
+// setRecipeMetadata applies in to recipe. It returns the message to report
+// when a time is out of range or a value is not one of the configured ones.
+func setRecipeMetadata(recipe *model.Recipe, in RecipeMetadataInput, cfg config.RecipesConfig) (messages.Message, messages.Args, bool) {
+	for _, t := range []struct {
+		field string
+		value *int
+		dst   *int
+	}{
+		{"prep_minutes", in.PrepMinutes, &recipe.PrepMinutes},
+		{"cook_minutes", in.CookMinutes, &recipe.CookMinutes},
+		{"total_minutes", in.TotalMinutes, &recipe.TotalMinutes},
+	} {
+		if t.value == nil {
+			continue
+		}
+		if *t.value < 0 || *t.value > maxMinutes {
+			return messages.Recipe.TimeInvalid, messages.Args{"field": t.field, "max": maxMinutes}, false
+		}
+		*t.dst = *t.value
+	}
+	if in.TotalMinutes == nil && (in.PrepMinutes != nil || in.CookMinutes != nil) {
+		recipe.TotalMinutes = recipe.PrepMinutes + recipe.CookMinutes
+	}
+	if recipe.TotalMinutes < recipe.PrepMinutes+recipe.CookMinutes {
+		return messages.Recipe.TotalTimeInvalid, nil, false
+	}
+
+	if in.Difficulty != nil {
+		recipe.Difficulty, recipe.DifficultyLevel = "", 0
+		if *in.Difficulty != "" {
+			i, ok := matchChoice(cfg.Difficulties, *in.Difficulty)
+			if !ok {
+				return messages.Recipe.DifficultyInvalid, choiceArgs(*in.Difficulty, cfg.Difficulties), false
+			}
+			recipe.Difficulty, recipe.DifficultyLevel = cfg.Difficulties[i], i+1
+		}
+	}
+
+	if in.Cuisine != nil {
+		recipe.Cuisine = ""
+		if *in.Cuisine != "" {
+			i, ok := matchChoice(cfg.Cuisines, *in.Cuisine)
+			if !ok {
+				return messages.Recipe.CuisineInvalid, choiceArgs(*in.Cuisine, cfg.Cuisines), false
+			}
+			recipe.Cuisine = cfg.Cuisines[i]
+		}
+	}
+
+	if in.Equipment != nil {
+		equipment := []string{}
+		seen := map[int]bool{}
+		for _, name := range in.Equipment {
+			i, ok := matchChoice(cfg.Equipment, name)
+			if !ok {
+				return messages.Recipe.EquipmentInvalid, choiceArgs(name, cfg.Equipment), false
+			}
+			if !seen[i] {
+				seen[i] = true
+				equipment = append(equipment, cfg.Equipment[i])
+			}
+		}
+		recipe.Equipment = equipment
+	}
+
+	return messages.Message{}, nil, true
+}
+
+// matchChoice finds value among choices, ignoring case and surrounding
+// space.
+func matchChoice(choices []string, value string) (int, bool) {
+	value = strings.TrimSpace(value)
+	for i, c := range choices {
+		if strings.EqualFold(c, value) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func choiceArgs[T ~string](value string, choices []T) messages.Args {
+	allowed := make([]string, len(choices))
+	for i, c := range choices {
+		allowed[i] = string(c)
+	}
+	return messages.Args{"value": value, "allowed": strings.Join(allowed, ", ")}
+}
+
+// GetRecipeOptionsHandler godoc
+// @Summary      List recipe metadata values
+// @Description  Returns the difficulties, cuisines and equipment recipes may be tagged with, as configured on the server, and the diets and allergens recipes are labelled with.
+// @Tags         recipes
+// @Produce      json
+// @Success      200  {object}  controller.RecipeOptionsResponse
+// @Router       /recipes/options [get]
+func (h *Handler) GetRecipeOptionsHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+	cfg := h.app.Config.Recipes
+
+	resp := RecipeOptionsResponse{
+		Message:      loc.T(messages.Common.Success),
+		Difficulties: cfg.Difficulties,
+		Cuisines:     cfg.Cuisines,
+		Equipment:    cfg.Equipment,
+	}
+	for _, d := range dietary.Diets {
+		resp.Diets = append(resp.Diets, string(d))
+	}
+	for _, a := range dietary.Allergens {
+		resp.Allergens = append(resp.Allergens, string(a))
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,291 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/pantry"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type PantryItemRequest struct {
+	Name string `json:"name" binding:"required,max=255"`
+}
+
+type PantryListResponse struct {
+	Message string             `json:"message"`
+	Data    []model.PantryItem `json:"data"`
+}
+
+type PantryItemResponse struct {
+	Message string           `json:"message"`
+	Data    model.PantryItem `json:"data"`
+}
+
+// PantryMatch is a recipe with how much of it the pantry covers.
+type PantryMatch struct {
+	Recipe RecipeWithImageIDs `json:"recipe"`
+	// Have and Total count the distinct ingredients of the recipe, leaving
+	// out staples when they are ignored.
+	Have     int     `json:"have"`
+	Total    int     `json:"total"`
+	Coverage float64 `json:"coverage"`
+	// Missing lists the ingredients not in the pantry, as the recipe names
+	// them.
+	Missing []string `json:"missing"`
+}
+
+type PantryMatchResponse struct {
+	Message string        `json:"message"`
+	Data    []PantryMatch `json:"data"`
+	Count   int64         `json:"count"`
+}
+
+// GetPantryHandler godoc
+// @Summary      List the pantry
+// @Description  Lists the ingredients the current user has at home, by name.
+// @Tags         pantry
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  controller.PantryListResponse
+// @Failure      401  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /pantry [get]
+func (h *Handler) GetPantryHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	items, err := h.app.Repos.Pantry.List(c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Pantry.PantryFetchFailed)})
+		return
+	}
+	if items == nil {
+		items = []model.PantryItem{}
+	}
+
+	c.JSON(http.StatusOK, PantryListResponse{Message: loc.T(messages.Pantry.PantryFetched), Data: items})
+}
+
+// PostPantryItemHandler godoc
+// @Summary      Add an ingredient to the pantry
+// @Description  Adds an ingredient the current user has at home. Names are compared ignoring case, plurals and words such as "fresh" or "chopped", so each ingredient can only be added once.
+// @Tags         pantry
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        item  body      PantryItemRequest  true  "Ingredient"
+// @Success      201   {object}  controller.PantryItemResponse
+// @Failure      400   {object}  controller.ErrorResponse
+// @Failure      401   {object}  controller.ErrorResponse
+// @Failure      409   {object}  controller.ErrorResponse
+// @Failure      500   {object}  controller.ErrorResponse
+// @Router       /pantry [post]
+func (h *Handler) PostPantryItemHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	var req PantryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	item := model.PantryItem{UserID: c.GetUint("userID"), Name: strings.TrimSpace(req.Name)}
+	if !h.checkPantryName(c, item.UserID, 0, item.Name) {
+		return
+	}
+	if err := h.app.Repos.Pantry.Create(&item); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Pantry.PantrySaveFailed)})
+		return
+	}
+
+	c.JSON(http.StatusCreated, PantryItemResponse{Message: loc.T(messages.Pantry.PantryItemAdded), Data: item})
+}
+
+// PutPantryItemHandler godoc
+// @Summary      Rename a pantry ingredient
+// @Description  Changes the name of an ingredient in the current user's pantry.
+// @Tags         pantry
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int                true  "Pantry item ID"
+// @Param        item  body      PantryItemRequest  true  "Ingredient"
+// @Success      200   {object}  controller.PantryItemResponse
+// @Failure      400   {object}  controller.ErrorResponse
+// @Failure      401   {object}  controller.ErrorResponse
+// @Failure      404   {object}  controller.ErrorResponse
+// @Failure      409   {object}  controller.ErrorResponse
+// @Failure      500   {object}  controller.ErrorResponse
+// @Router       /pantry/{id} [put]
+func (h *Handler) PutPantryItemHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	item, ok := h.findPantryItem(c)
+	if !ok {
+		return
+	}
+
+	var req PantryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	item.Name = strings.TrimSpace(req.Name)
+	if !h.checkPantryName(c, item.UserID, item.ID, item.Name) {
+		return
+	}
+	if err := h.app.Repos.Pantry.Save(item); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Pantry.PantrySaveFailed)})
+		return
+	}
+
+	c.JSON(http.StatusOK, PantryItemResponse{Message: loc.T(messages.Pantry.PantryItemUpdated), Data: *item})
+}
+
+// DeletePantryItemHandler godoc
+// @Summary      Remove an ingredient from the pantry
+// @Description  Removes an ingredient from the current user's pantry.
+// @Tags         pantry
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Pantry item ID"
+// @Success      200  {object}  controller.SimpleMessageResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      401  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /pantry/{id} [delete]
+func (h *Handler) DeletePantryItemHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	item, ok := h.findPantryItem(c)
+	if !ok {
+		return
+	}
+	if err := h.app.Repos.Pantry.Delete(item); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Pantry.PantryDeleteFailed)})
+		return
+	}
+
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.Pantry.PantryItemDeleted)})
+}
+
+// GetPantryRecipesHandler godoc
+// @Summary      Find recipes to cook from the pantry
+// @Description  Ranks recipes by the share of their ingredients in the current user's pantry, best covered first, and lists the missing ingredients of each. Only recipes with at least one ingredient in the pantry are returned. Ingredient names are compared ignoring case, plurals and words such as "fresh" or "chopped", and a pantry ingredient covers the recipe ingredients whose words it all has, so "chicken breast" covers "chicken" but "chicken" does not cover "chicken breast". With ignore_staples, ingredients most kitchens have, such as salt and water, are left out of the count, including more specific ones such as "sea salt". The filters of /recipes/search apply as well.
+// @Tags         pantry
+// @Produce      json
+// @Security     BearerAuth
+// @Param        max_missing        query     int     false  "Only recipes missing at most this many ingredients"
+// @Param        ignore_staples     query     bool    false  "Leave staples such as salt, pepper and water out of the count"
+// @Param        title              query     string  false  "Filter by recipe title (partial match)"
+// @Param        tag_ids            query     string  false  "Filter by tag IDs (comma-separated)"
+// @Param        category_ids       query     string  false  "Filter by category IDs (comma-separated)"
+// @Param        diet               query     string  false  "Only recipes fitting every listed diet (comma-separated)"
+// @Param        exclude_allergens  query     string  false  "Leave out recipes containing any listed allergen (comma-separated)"
+// @Param        max_total_minutes  query     int     false  "Only recipes ready in at most this many minutes"
+// @Param        limit              query     int     false  "Limit number of recipes returned, at most 100"
+// @Param        offset             query     int     false  "Page number"
+// @Success      200                {object}  controller.PantryMatchResponse
+// @Failure      400                {object}  controller.ErrorResponse
+// @Failure      401                {object}  controller.ErrorResponse
+// @Failure      500                {object}  controller.ErrorResponse
+// @Router       /pantry/recipes [get]
+func (h *Handler) GetPantryRecipesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	maxMissing := -1
+	if v := c.Query("max_missing"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Pantry.PantryMaxMissingInvalid)})
+			return
+		}
+		maxMissing = n
+	}
+
+	params, ok := h.recipeSearchParams(c)
+	if !ok {
+		return
+	}
+
+	limit, offset, _ := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
+	if limit == 0 {
+		limit = 10
+	}
+
+	matches, total, err := h.app.Pantry.Match(c.GetUint("userID"), params, maxMissing, c.Query("ignore_staples") == "true", limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Pantry.PantryMatchFailed)})
+		return
+	}
+
+	recipes := make([]model.Recipe, len(matches))
+	for i, m := range matches {
+		recipes[i] = m.Recipe
+	}
+	data := make([]PantryMatch, len(matches))
+	for i, r := range h.recipeListResponse(recipes) {
+		m := matches[i]
+		missing := m.Missing
+		if missing == nil {
+			missing = []string{}
+		}
+		data[i] = PantryMatch{Recipe: r, Have: m.Have, Total: m.Total, Coverage: m.Coverage(), Missing: missing}
+	}
+
+	c.JSON(http.StatusOK, PantryMatchResponse{Message: loc.T(messages.Common.Success), Data: data, Count: total})
+}
+
+// findPantryItem loads the :id pantry item of the current user and answers
+// the request when there is none.
+func (h *Handler) findPantryItem(c *gin.Context) (*model.PantryItem, bool) {
+	loc := middleware.Localizer(c)
+
+	id, err := utils.ValidateEntityID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+
+	item, err := h.app.Repos.Pantry.Find(c.GetUint("userID"), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Pantry.PantryItemNotFound)})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Pantry.PantryFetchFailed)})
+		return nil, false
+	}
+	return item, true
+}
+
+// checkPantryName answers the request and returns false when name is not an
+// ingredient or the user already has it under another item than id.
+func (h *Handler) checkPantryName(c *gin.Context, userID, id uint, name string) bool {
+	loc := middleware.Localizer(c)
+
+	key := pantry.Key(name)
+	if key == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Pantry.PantryItemInvalid)})
+		return false
+	}
+	existing, err := h.app.Repos.Pantry.FindByKey(userID, key)
+	switch {
+	case err == nil && existing.ID != id:
+		c.JSON(http.StatusConflict, ErrorResponse{Error: loc.T(messages.Pantry.PantryItemExists, messages.Args{"name": existing.Name})})
+		return false
+	case err != nil && !errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Pantry.PantrySaveFailed)})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/gin-gonic/gin"
+)
+
+type RecipeStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	// PublishAt is required for the scheduled status and ignored otherwise.
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+type RecipeStatusResponse struct {
+	Message     string             `json:"message"`
+	Status      model.RecipeStatus `json:"status"`
+	PublishAt   *time.Time         `json:"publish_at,omitempty"`
+	PublishedAt *time.Time         `json:"published_at,omitempty"`
+}
+
+// setRecipeStatus moves recipe to the named status. It returns the message to
+// report when the status is unknown or a scheduled recipe lacks a publish
+// time in the future.
+func setRecipeStatus(recipe *model.Recipe, name string, publishAt *time.Time, now time.Time) (messages.Message, bool) {
+	status, ok := model.ParseRecipeStatus(name)
+	if !ok {
+		return messages.Recipe.StatusInvalid, false
+	}
+
+	switch status {
+	case model.StatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return messages.Recipe.PublishAtInvalid, false
+		}
+		at := publishAt.UTC()
+		recipe.PublishAt = &at
+	case model.StatusPublished:
+		recipe.PublishAt = nil
+		if recipe.PublishedAt == nil {
+			recipe.PublishedAt = &now
+		}
+	default:
+		recipe.PublishAt = nil
+	}
+	recipe.Status = status
+	return messages.Message{}, true
+}
+
+// recipeStatusFilter returns the status listings are restricted to. Only
+// callers who may read every unpublished recipe can pick another one with
+// the status query parameter, or see all of them by leaving it empty.
+func recipeStatusFilter(c *gin.Context) string {
+	if policy.Check(middleware.Subject(c).Role, policy.Recipe, policy.ReadUnpublished) == policy.Any {
+		return c.Query("status")
+	}
+	return string(model.StatusPublished)
+}
+
+// recipeVisibilityFilter is the visibility counterpart of recipeStatusFilter:
+// listings only contain public recipes unless the caller may read every
+// private one.
+func recipeVisibilityFilter(c *gin.Context) string {
+	if policy.Check(middleware.Subject(c).Role, policy.Recipe, policy.ReadPrivate) == policy.Any {
+		return c.Query("visibility")
+	}
+	return string(model.VisibilityPublic)
+}
+
+// PutRecipeStatusHandler godoc
+// @Summary      Change the publication status of a recipe
+// @Description  Moves a recipe between draft, published, scheduled and archived. Only published recipes appear in public listings; drafts, scheduled and archived recipes are visible to their author and admins only. Scheduled recipes are published automatically once publish_at has passed.
+// @Tags         recipes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path  int                  true  "Recipe ID"
+// @Param        status  body  RecipeStatusRequest  true  "New status"
+// @Success      200  {object}  controller.RecipeStatusResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /recipe/{id}/status [put]
+func (h *Handler) PutRecipeStatusHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	var req RecipeStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	recipe, ok := h.ownRecipe(c)
+	if !ok {
+		return
+	}
+
+	if msg, ok := setRecipeStatus(recipe, req.Status, req.PublishAt, h.app.Clock.Now()); !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(msg)})
+		return
+	}
+
+	if err := h.app.Repos.Recipes.Save(recipe); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.StatusUpdateFail)})
+		return
+	}
+	h.app.Suggest.Invalidate()
+
+	c.JSON(http.StatusOK, RecipeStatusResponse{
+		Message:     loc.T(messages.Recipe.StatusUpdated),
+		Status:      recipe.Status,
+		PublishAt:   recipe.PublishAt,
+		PublishedAt: recipe.PublishedAt,
+	})
+}
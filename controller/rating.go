@@ -4,13 +4,13 @@ import (
 	"errors"
 	"net/http"
 
-	"github.com/Abb133Se/recepieshare/internal"
 	"github.com/Abb133Se/recepieshare/messages"
 	"github.com/Abb133Se/recepieshare/middleware"
 	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/Abb133Se/recepieshare/repository"
 	"github.com/Abb133Se/recepieshare/utils"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // Request/Response structs for Swagger documentation
@@ -44,7 +44,9 @@ type AverageRatingResponse struct {
 // @Failure      404     {object}  ErrorResponse "Recipe not found"
 // @Failure      500     {object}  ErrorResponse "Internal server error"
 // @Router       /rating [post]
-func PostRatingHandler(c *gin.Context) {
+func (h *Handler) PostRatingHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var req PostRatingRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
@@ -53,41 +55,34 @@ func PostRatingHandler(c *gin.Context) {
 
 	userID := c.GetUint("userID")
 	if userID == 0 {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: loc.T(messages.Common.Unauthorized)})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+	repos := h.app.Repos
 
-	var recipe model.Recipe
-	if err := db.First(&recipe, req.RecipeID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+	if recipe, err := repos.Recipes.FindByID(req.RecipeID); err != nil || !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		return
 	}
 
-	var existing model.Rating
-	if err := db.Where("user_id = ? AND recipe_id = ?", userID, req.RecipeID).
-		First(&existing).Error; err == nil {
+	if existing, err := repos.Ratings.FindByUserAndRecipe(userID, req.RecipeID); err == nil {
 		existing.Score = req.Score
-		if err := db.Save(&existing).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Rating.RatingUpdateFailed.String()})
+		if err := repos.Ratings.Save(existing); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Rating.RatingUpdateFailed)})
 			return
 		}
-		c.JSON(http.StatusOK, RatingResponse{Message: messages.Rating.RatingUpdated.String(), ID: existing.ID})
+		c.JSON(http.StatusOK, RatingResponse{Message: loc.T(messages.Rating.RatingUpdated), ID: existing.ID})
 		return
 	}
 
 	rating := model.Rating{UserID: userID, RecipeID: req.RecipeID, Score: req.Score}
-	if err := db.Create(&rating).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Rating.RatingAddFail.String()})
+	if err := repos.Ratings.Create(&rating); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Rating.RatingAddFail)})
 		return
 	}
 
-	c.JSON(http.StatusOK, RatingResponse{Message: messages.Rating.RatingAdded.String(), ID: rating.ID})
+	c.JSON(http.StatusOK, RatingResponse{Message: loc.T(messages.Rating.RatingAdded), ID: rating.ID})
 }
 
 // DeleteRatingHandler godoc
@@ -118,22 +113,8 @@ func PostRatingHandler(c *gin.Context) {
 // @Failure      404 {object} controller.ErrorResponse
 // @Failure      500 {object} controller.ErrorResponse
 // @Router       /admin/user/{userID}/rating/{id} [delete]
-func DeleteRatingHandler(c *gin.Context) {
-	var userID uint
-	var err error
-	if role := c.GetString("role"); role == "user" {
-		userID = c.GetUint("userID")
-		if userID == 0 {
-			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-			return
-		}
-	} else if role == "admin" {
-		userID, err = middleware.GetEffectiveUserID(c)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-			return
-		}
-	}
+func (h *Handler) DeleteRatingHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	ratingID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
@@ -141,28 +122,26 @@ func DeleteRatingHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	rating, err := h.app.Repos.Ratings.FindByID(ratingID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Rating.RatingNotFound)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Rating.RatingFetchFail)})
 		return
 	}
 
-	var rating model.Rating
-	if err := db.Where("id = ? AND user_id = ?", ratingID, userID).First(&rating).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusForbidden, ErrorResponse{Error: messages.Rating.RatingDeleteForbidden.String()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Rating.RatingFetchFail.String()})
+	if !h.authorize(c, policy.Rating, policy.Delete, rating.UserID) {
 		return
 	}
 
-	if err := db.Delete(&rating).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Rating.RatingDeleteFail.String()})
+	if err := h.app.Repos.Ratings.Delete(rating); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Rating.RatingDeleteFail)})
 		return
 	}
 
-	c.JSON(http.StatusOK, SuccessMessageResponse{Message: messages.Rating.RatingDeleted.String()})
+	c.JSON(http.StatusOK, SuccessMessageResponse{Message: loc.T(messages.Rating.RatingDeleted)})
 }
 
 // GetAverageRatingHandler godoc
@@ -175,8 +154,8 @@ func DeleteRatingHandler(c *gin.Context) {
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /recipe/{id}/rating [get]
-func GetAverageRatingHandler(c *gin.Context) {
-	var ratings []model.Rating
+func (h *Handler) GetAverageRatingHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	validID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
@@ -184,21 +163,20 @@ func GetAverageRatingHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+	if recipe, err := h.app.Repos.Recipes.FindByID(validID); err != nil || !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		return
 	}
 
-	err = db.Where("recipe_id = ?", validID).Find(&ratings).Error
+	ratings, err := h.app.Repos.Ratings.ListForRecipe(validID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Rating.RatingFetchFail.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Rating.RatingFetchFail)})
 		return
 	}
 
 	if len(ratings) == 0 {
 		c.JSON(http.StatusNotFound, ErrorResponse{
-			Error: messages.Rating.RatingNotFound.String(),
+			Error: loc.T(messages.Rating.RatingNotFound),
 		})
 		return
 	}
@@ -230,9 +208,10 @@ func GetAverageRatingHandler(c *gin.Context) {
 // @Failure      404     {object}  ErrorResponse
 // @Failure      500     {object}  ErrorResponse
 // @Router       /rating/{id} [put]
-func PutUpdateRatingHandler(c *gin.Context) {
+func (h *Handler) PutUpdateRatingHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var rating model.Rating
-	var existingRating model.Rating
 
 	validId, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
@@ -246,42 +225,40 @@ func PutUpdateRatingHandler(c *gin.Context) {
 	}
 
 	if rating.Score < 1 || rating.Score > 5 {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: messages.Rating.RatingInvalidScore.String()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Rating.RatingInvalidScore)})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	existingRating, err := h.app.Repos.Ratings.FindByID(validId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Rating.RatingNotFound)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Rating.RatingFetchFail)})
 		return
 	}
 
-	err = db.First(&existingRating, validId).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Rating.RatingNotFound.String()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Rating.RatingFetchFail.String()})
+	if !h.authorize(c, policy.Rating, policy.Update, existingRating.UserID) {
 		return
 	}
 
 	existingRating.Score = rating.Score
-	err = db.Save(&existingRating).Error
+	err = h.app.Repos.Ratings.Save(existingRating)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Rating.RatingUpdateFailed.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Rating.RatingUpdateFailed)})
 		return
 	}
 
 	c.JSON(http.StatusOK, RatingResponse{
-		Message: messages.Rating.RatingUpdated.String(),
+		Message: loc.T(messages.Rating.RatingUpdated),
 		ID:      existingRating.ID,
 	})
 }
 
 // GetAllRatings godoc
 // @Summary      Get all ratings
-// @Description  Retrieve a paginated list of all ratings with user and recipe details
+// @Description  Retrieve a paginated list of all ratings with user and recipe details. Callers who may not read every unpublished and private recipe only see ratings of published, public ones.
 // @Tags         ratings
 // @Security     BearerAuth
 // @Param        limit     query     int     false  "Number of items per page" default(10)
@@ -292,47 +269,14 @@ func PutUpdateRatingHandler(c *gin.Context) {
 // @Failure      401  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /ratings [get]
-func GetAllRatings(c *gin.Context) {
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "database connection failed"})
-		return
-	}
-
+func (h *Handler) GetAllRatings(c *gin.Context) {
 	limit, offset, _ := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
 	if limit == 0 {
 		limit = 10
 	}
 
-	// Build query with joins
-	query := db.Table("ratings").
-		Select(`ratings.id as rating_id,
-                ratings.score as score,
-                users.id as user_id,
-                users.name as user_name,
-                recipes.id as recipe_id,
-                recipes.title as recipe_title`).
-		Joins("JOIN users ON ratings.user_id = users.id").
-		Joins("JOIN recipes ON ratings.recipe_id = recipes.id")
-
-	// Count total ratings
-	total, err := utils.Count(query, "ratings")
+	ratings, total, err := h.app.Repos.Ratings.ListAll(recipeStatusFilter(c), recipeVisibilityFilter(c), limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count ratings"})
-		return
-	}
-
-	// Fetch paginated results
-	var ratings []struct {
-		RatingID    uint   `json:"rating_id"`
-		Score       uint   `json:"score"`
-		UserID      uint   `json:"user_id"`
-		UserName    string `json:"user_name"`
-		RecipeID    uint   `json:"recipe_id"`
-		RecipeTitle string `json:"recipe_title"`
-	}
-
-	if err := utils.Paginate(query, limit, offset, &ratings); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch ratings"})
 		return
 	}
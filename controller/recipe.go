@@ -4,32 +4,25 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/dietary"
 	"github.com/Abb133Se/recepieshare/messages"
 	"github.com/Abb133Se/recepieshare/middleware"
 	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/nutrition"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/Abb133Se/recepieshare/quantity"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/search"
 	"github.com/Abb133Se/recepieshare/service"
 	"github.com/Abb133Se/recepieshare/utils"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-type TopRatedRecipe struct {
-	RecipeID   uint    `json:"recipe_id"`
-	Title      string  `json:"title"`
-	Average    float64 `json:"average"`
-	TotalVotes int64   `json:"total_votes"`
-}
-
-type MostPopularRecipe struct {
-	RecipeID      uint   `json:"recipe_id"`
-	Title         string `json:"title"`
-	FavoriteCount int64  `json:"favorite_count"`
-}
-
 type TagNamesInput struct {
 	Tags []string `json:"tags" binding:"required"`
 }
@@ -42,6 +35,15 @@ type PostRecipeRequest struct {
 	TagNames    []string           `json:"tag_names"` // Optional: Create/find tags by name
 	CategoryIDs []uint             `json:"category_ids"`
 	Steps       []model.Step       `json:"steps"`
+	// Servings defaults to 1. Yield is free text such as "1 loaf".
+	Servings int    `json:"servings"`
+	Yield    string `json:"yield"`
+	// Status defaults to published. Scheduled recipes need PublishAt.
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at"`
+	// Visibility defaults to public.
+	Visibility string `json:"visibility"`
+	RecipeMetadataInput
 }
 
 type RecipeResponse struct {
@@ -58,9 +60,9 @@ type SimpleMessageResponse struct {
 	Message string `json:"message"`
 }
 
-type TopRatedRecipesResponse []TopRatedRecipe
+type TopRatedRecipesResponse []repository.TopRatedRecipe
 type MostPopularRecipesResponse struct {
-	Recipes []MostPopularRecipe `json:"recipes"`
+	Recipes []repository.MostPopularRecipe `json:"recipes"`
 }
 
 type IngredientsResponse struct {
@@ -69,14 +71,9 @@ type IngredientsResponse struct {
 }
 
 type CommentsResponse struct {
-	Message string                `json:"message"`
-	Data    []CommentWithUserName `json:"data"`
-	Count   int64                 `json:"count"`
-}
-
-type CommentWithUserName struct {
-	model.Comment
-	UserName string `json:"user_name"`
+	Message string                           `json:"message"`
+	Data    []repository.CommentWithUserName `json:"data"`
+	Count   int64                            `json:"count"`
 }
 
 type TagsResponse struct {
@@ -87,8 +84,28 @@ type RecipeCategoriesResponse struct {
 	Categories []model.Category `json:"categories"`
 }
 
+// IngredientNutrition is the stored nutrition of one ingredient. Source is
+// empty when no provider matched it.
+type IngredientNutrition struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	Amount string `json:"amount"`
+	nutrition.Facts
+	Source string `json:"source,omitempty"`
+}
+
 type NutritionResponse struct {
-	NutritionalValues interface{} `json:"nutritional_values"`
+	Message     string                  `json:"message"`
+	Ingredients []IngredientNutrition   `json:"ingredients"`
+	Total       nutrition.Facts         `json:"total"`
+	PerServing  nutrition.Facts         `json:"per_serving"`
+	Report      service.NutritionReport `json:"report"`
+}
+
+type NutritionLabelResponse struct {
+	Message string                  `json:"message"`
+	Label   nutrition.Label         `json:"label"`
+	Report  service.NutritionReport `json:"report"`
 }
 
 type RecipeWithImageIDs struct {
@@ -109,8 +126,37 @@ type RecipeWithImageIDs struct {
 	Carbs         float64            `json:"carbs"`
 	Fiber         float64            `json:"fiber"`
 	Sugar         float64            `json:"sugar"`
-	CreatedAt     time.Time          `json:"created_at"`
-	UpdatedAt     time.Time          `json:"updated_at"`
+	SaturatedFat  float64            `json:"saturated_fat"`
+	Cholesterol   float64            `json:"cholesterol"`
+	Sodium        float64            `json:"sodium"`
+	Potassium     float64            `json:"potassium"`
+	Servings      int                `json:"servings"`
+	Yield         string             `json:"yield,omitempty"`
+	PerServing    nutrition.Facts    `json:"per_serving"`
+	Allergens     []string           `json:"allergens"`
+	Diets         []string           `json:"diets"`
+	PrepMinutes   int                `json:"prep_minutes"`
+	CookMinutes   int                `json:"cook_minutes"`
+	TotalMinutes  int                `json:"total_minutes"`
+	Difficulty    string             `json:"difficulty"`
+	Cuisine       string             `json:"cuisine"`
+	Equipment     []string           `json:"equipment"`
+	// NutritionReport tells which ingredients the nutrition values cover.
+	// Only set on single recipe responses.
+	NutritionReport *service.NutritionReport `json:"nutrition_report,omitempty"`
+	// NutritionPending is set when the nutrition is still being estimated
+	// in the background.
+	NutritionPending bool `json:"nutrition_pending,omitempty"`
+	// ScaleFactor is set when the recipe was scaled with ?servings=N.
+	// UnscaledIngredients lists the ingredients whose amount has no number.
+	ScaleFactor         float64            `json:"scale_factor,omitempty"`
+	UnscaledIngredients []string           `json:"unscaled_ingredients,omitempty"`
+	Status              model.RecipeStatus `json:"status"`
+	PublishAt           *time.Time         `json:"publish_at,omitempty"`
+	PublishedAt         *time.Time         `json:"published_at,omitempty"`
+	Visibility          string             `json:"visibility"`
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
 }
 
 type RecipeListWithImagesResponse struct {
@@ -119,20 +165,104 @@ type RecipeListWithImagesResponse struct {
 	Count   int64                `json:"count"`
 }
 
-var API_KEY = "YTAMecQ6C06ClaR/HmS26g==OUlc0LkiJgLyFjhv"
+type RecipeSearchResponse struct {
+	Message string               `json:"message"`
+	Data    []RecipeWithImageIDs `json:"data"`
+	Count   int64                `json:"count"`
+	// Facets holds the facets asked for, by name.
+	Facets map[string]repository.Facet `json:"facets,omitempty"`
+	// FacetsTruncated is set when a full-text query matched more recipes
+	// than facets are counted over; the facets then cover the best matches
+	// only, while Count stays exact.
+	FacetsTruncated bool `json:"facets_truncated,omitempty"`
+}
+
+func newRecipeWithImageIDs(recipe model.Recipe, imageIDs []uint) RecipeWithImageIDs {
+	return RecipeWithImageIDs{
+		ID:           recipe.ID,
+		Title:        recipe.Title,
+		Text:         recipe.Text,
+		UserID:       recipe.UserID,
+		Ingredients:  recipe.Ingredients,
+		Tags:         recipe.Tags,
+		Categories:   recipe.Categories,
+		Steps:        recipe.Steps,
+		Images:       imageIDs,
+		Calories:     recipe.Calories,
+		Protein:      recipe.Protein,
+		Fat:          recipe.Fat,
+		Carbs:        recipe.Carbs,
+		Fiber:        recipe.Fiber,
+		Sugar:        recipe.Sugar,
+		SaturatedFat: recipe.SaturatedFat,
+		Cholesterol:  recipe.Cholesterol,
+		Sodium:       recipe.Sodium,
+		Potassium:    recipe.Potassium,
+		Servings:     recipe.Servings,
+		Yield:        recipe.Yield,
+		PerServing:   service.PerServing(&recipe),
+		Allergens:    recipe.Allergens,
+		Diets:        recipe.Diets,
+		PrepMinutes:  recipe.PrepMinutes,
+		CookMinutes:  recipe.CookMinutes,
+		TotalMinutes: recipe.TotalMinutes,
+		Difficulty:   recipe.Difficulty,
+		Cuisine:      recipe.Cuisine,
+		Equipment:    recipe.Equipment,
+		Status:       recipe.Status,
+		PublishAt:    recipe.PublishAt,
+		PublishedAt:  recipe.PublishedAt,
+		Visibility:   string(recipe.Visibility),
+		CreatedAt:    recipe.CreatedAt,
+		UpdatedAt:    recipe.UpdatedAt,
+	}
+}
+
+// recipeListResponse attaches image IDs to a page of recipes with a single
+// image query.
+func (h *Handler) recipeListResponse(recipes []model.Recipe) []RecipeWithImageIDs {
+	ids := make([]uint, len(recipes))
+	for i, r := range recipes {
+		ids[i] = r.ID
+	}
+	imageIDs, _ := h.app.Images.GetImageIDsForEntities("recipe", ids)
+
+	var data []RecipeWithImageIDs
+	for _, r := range recipes {
+		data = append(data, newRecipeWithImageIDs(r, imageIDs[r.ID]))
+	}
+	return data
+}
+
+// recipeChanged reindexes a recipe that was created, edited or deleted for
+// search and suggestions.
+func (h *Handler) recipeChanged(recipeID uint) {
+	h.app.Search.Refresh(recipeID)
+	h.app.Suggest.Invalidate()
+}
+
+// enqueueNutrition queues the nutrition estimate of the recipe, so slow
+// providers run in the background instead of holding up the request. Pass
+// the transaction that changes the ingredients; the job only exists once it
+// commits.
+func (h *Handler) enqueueNutrition(repos *repository.Repositories, recipeID uint) error {
+	return h.app.Jobs.Enqueue(repos, service.JobEstimateNutrition, service.EstimateNutritionPayload{RecipeID: recipeID})
+}
 
 // GetRecipeHandler godoc
 // @Summary      Get recipe by ID
-// @Description  Get detailed recipe info including ingredients, comments, tags, categories, steps, and image IDs
+// @Description  Get detailed recipe info including ingredients, comments, tags, categories, steps, and image IDs. With servings the ingredient amounts and nutrition are scaled to that many servings.
 // @Tags         recipes
-// @Param        id   path      int  true  "Recipe ID"
+// @Param        id        path      int     true   "Recipe ID"
+// @Param        servings  query     int     false  "Scale the recipe to this many servings"
+// @Param        units     query     string  false  "Measurement system: metric, imperial or original (defaults to the user's preference)"
 // @Success      200  {object}  RecipeWithImageIDs
 // @Failure      400  {object}  ErrorResponse
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /recipe/{id} [get]
-func GetRecipeHandler(c *gin.Context) {
-	var recipe model.Recipe
+func (h *Handler) GetRecipeHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	validID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
@@ -140,37 +270,82 @@ func GetRecipeHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+	repos := h.app.Repos
 
 	// Preload associations
-	err = db.Preload("Ingredients").
-		Preload("Comments").
-		Preload("User").
-		Preload("Tags").
-		Preload("Categories").
-		Preload("Steps").
-		First(&recipe, validID).Error
+	recipe, err := repos.Recipes.FindByID(validID, "Ingredients", "Comments", "User", "Tags", "Categories", "Steps")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeFetchFail.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
 		return
 	}
+	if !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
+		return
+	}
+
+	h.writeRecipeDetail(c, recipe)
+}
 
-	imageIDs, _ := service.GetImageIDsForEntity("recipe", recipe.ID)
+// maxServings bounds the servings a recipe can have or be scaled to.
+const maxServings = 1000
 
-	type FavResult struct {
-		Count       int64
-		UserFavored bool
+func validServings(n int) bool {
+	return n >= 1 && n <= maxServings
+}
+
+// unitSystem returns the measurement system ingredient amounts are shown in:
+// the units query parameter, else the caller's preference, else the units
+// the author wrote. It writes the error response for an unknown system.
+func (h *Handler) unitSystem(c *gin.Context) (quantity.System, bool) {
+	param, set := c.GetQuery("units")
+	if !set {
+		if userID := c.GetUint("userID"); userID != 0 {
+			if user, err := h.app.Repos.Users.FindByID(userID); err == nil {
+				param = user.Units
+			}
+		}
+	}
+
+	system, ok := quantity.ParseSystem(param)
+	if !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: middleware.Localizer(c).T(messages.Recipe.UnitsInvalid)})
+		return "", false
+	}
+	return system, true
+}
+
+// writeRecipeDetail responds with the recipe, its image IDs and favorite
+// stats, and counts the view. With ?servings=N the recipe is scaled first,
+// and amounts are converted to the system picked by unitSystem.
+func (h *Handler) writeRecipeDetail(c *gin.Context, recipe *model.Recipe) {
+	loc := middleware.Localizer(c)
+	repos := h.app.Repos
+
+	var (
+		factor   float64
+		unscaled []string
+	)
+	if param := c.Query("servings"); param != "" {
+		servings, err := strconv.Atoi(param)
+		if err != nil || !validServings(servings) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.ServingsInvalid, messages.Args{"max": maxServings})})
+			return
+		}
+		factor, unscaled = service.ScaleRecipe(recipe, servings)
 	}
 
-	var result FavResult
+	system, ok := h.unitSystem(c)
+	if !ok {
+		return
+	}
+	service.ConvertIngredients(recipe.Ingredients, system)
+
+	imageIDs, _ := h.app.Images.GetImageIDsForEntity("recipe", recipe.ID)
+
 	userID := uint(0)
 	if uidVal, exists := c.Get("userID"); exists {
 		if uid, ok := uidVal.(uint); ok {
@@ -178,31 +353,14 @@ func GetRecipeHandler(c *gin.Context) {
 		}
 	}
 
-	db.Model(&model.Favorite{}).
-		Select("COUNT(*) as count, SUM(CASE WHEN user_id = ? THEN 1 ELSE 0 END) > 0 as user_favored", userID).
-		Where("recipe_id = ?", recipe.ID).
-		Scan(&result)
-
-	resp := RecipeWithImageIDs{
-		ID:            recipe.ID,
-		Title:         recipe.Title,
-		Text:          recipe.Text,
-		UserID:        recipe.UserID,
-		Ingredients:   recipe.Ingredients,
-		Tags:          recipe.Tags,
-		Categories:    recipe.Categories,
-		Steps:         recipe.Steps,
-		Images:        imageIDs,
-		FavoriteCount: result.Count,
-		Calories:      recipe.Calories,
-		Protein:       recipe.Protein,
-		Fat:           recipe.Fat,
-		Carbs:         recipe.Carbs,
-		Fiber:         recipe.Fiber,
-		Sugar:         recipe.Sugar,
-		IsFavorited:   result.UserFavored,
-		CreatedAt:     recipe.CreatedAt,
-		UpdatedAt:     recipe.UpdatedAt,
+	resp := newRecipeWithImageIDs(*recipe, imageIDs)
+	resp.ScaleFactor = factor
+	resp.UnscaledIngredients = unscaled
+	report := service.NutritionReportFor(recipe)
+	resp.NutritionReport = &report
+	if stats, err := repos.Favorites.Stats(recipe.ID, userID); err == nil {
+		resp.FavoriteCount = stats.Count
+		resp.IsFavorited = stats.UserFavored
 	}
 
 	view := model.RecipeView{
@@ -212,8 +370,8 @@ func GetRecipeHandler(c *gin.Context) {
 	if userID := c.GetUint("userID"); userID != 0 {
 		view.UserID = &userID
 	}
-	if err := db.Create(&view).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeCreateFailed.String()})
+	if err := repos.Recipes.RecordView(&view); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeCreateFailed)})
 		return
 	}
 
@@ -231,7 +389,9 @@ func GetRecipeHandler(c *gin.Context) {
 // @Failure      400     {object}  ErrorResponse
 // @Failure      500     {object}  ErrorResponse
 // @Router       /recipe [post]
-func PostRecipeHandler(c *gin.Context) {
+func (h *Handler) PostRecipeHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var req PostRecipeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -244,37 +404,23 @@ func PostRecipeHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+	repos := h.app.Repos
 
-	var user model.User
-	if err := db.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: messages.User.UserNotFound.String()})
+	if _, err := repos.Users.FindByID(userID); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.User.UserNotFound)})
 		return
 	}
 
-	var tags []model.Tag
-	if len(req.TagIDs) > 0 {
-		db.Find(&tags, req.TagIDs)
-	}
+	tags, _ := repos.Tags.FindByIDs(req.TagIDs)
 	for _, name := range req.TagNames {
-		var tag model.Tag
-		if err := db.Where("name = ?", name).First(&tag).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				tag = model.Tag{Name: name}
-				db.Create(&tag)
-			}
+		tag, err := repos.Tags.FindOrCreateByName(name)
+		if err != nil {
+			continue
 		}
-		tags = append(tags, tag)
+		tags = append(tags, *tag)
 	}
 
-	var categories []model.Category
-	if len(req.CategoryIDs) > 0 {
-		db.Find(&categories, req.CategoryIDs)
-	}
+	categories, _ := repos.Categories.FindByIDs(req.CategoryIDs)
 
 	recipe := model.Recipe{
 		Title:       req.Title,
@@ -285,66 +431,57 @@ func PostRecipeHandler(c *gin.Context) {
 		Categories:  categories,
 		Steps:       req.Steps,
 	}
+	recipe.SetDietaryLabels(recipe.Ingredients)
 
-	if err := db.Create(&recipe).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeCreateFailed.String()})
+	if req.Servings == 0 {
+		req.Servings = 1
+	}
+	if !validServings(req.Servings) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.ServingsInvalid, messages.Args{"max": maxServings})})
 		return
 	}
+	recipe.Servings = req.Servings
+	recipe.Yield = req.Yield
 
-	var ingredientStrings []string
-	for _, ing := range recipe.Ingredients {
-		ingredientStrings = append(ingredientStrings, fmt.Sprintf("%s of %s", ing.Amount, ing.Name))
+	if req.Status == "" {
+		req.Status = string(model.StatusPublished)
+	}
+	if msg, ok := setRecipeStatus(&recipe, req.Status, req.PublishAt, h.app.Clock.Now()); !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(msg)})
+		return
 	}
 
-	nutritionItems, err := utils.EstimateNutrition(API_KEY, ingredientStrings)
-	if err == nil && len(nutritionItems) == len(recipe.Ingredients) {
-		var totalCalories, totalProtein, totalFat, totalCarbs, totalFiber, totalSugar float64
-
-		for i, item := range nutritionItems {
-			recipe.Ingredients[i].Calories = item.Calories
-			recipe.Ingredients[i].Protein = item.ProteinG
-			recipe.Ingredients[i].Fat = item.FatTotalG
-			recipe.Ingredients[i].Carbs = item.CarbohydratesTotalG
-			recipe.Ingredients[i].Fiber = item.FiberG
-			recipe.Ingredients[i].Sugar = item.SugarG
+	if req.Visibility == "" {
+		req.Visibility = string(model.VisibilityPublic)
+	}
+	if msg, ok := setRecipeVisibility(&recipe, req.Visibility); !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(msg)})
+		return
+	}
+	if msg, args, ok := setRecipeMetadata(&recipe, req.RecipeMetadataInput, h.app.Config.Recipes); !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(msg, args)})
+		return
+	}
 
-			totalCalories += item.Calories
-			totalProtein += item.ProteinG
-			totalFat += item.FatTotalG
-			totalCarbs += item.CarbohydratesTotalG
-			totalFiber += item.FiberG
-			totalSugar += item.SugarG
+	err := repos.Transaction(func(tx *repository.Repositories) error {
+		if err := tx.Recipes.Create(&recipe); err != nil {
+			return err
 		}
-
-		recipe.Calories = totalCalories
-		recipe.Protein = totalProtein
-		recipe.Fat = totalFat
-		recipe.Carbs = totalCarbs
-		recipe.Fiber = totalFiber
-		recipe.Sugar = totalSugar
-
-		db.Save(&recipe)
-		for i := range recipe.Ingredients {
-			db.Save(&recipe.Ingredients[i])
+		if _, err := h.app.Revisions.Record(tx, recipe.ID, userID, nil); err != nil {
+			return err
 		}
+		return h.enqueueNutrition(tx, recipe.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeCreateFailed)})
+		return
 	}
+	h.recipeChanged(recipe.ID)
 
-	imageIDs, _ := service.GetImageIDsForEntity("recipe", recipe.ID)
-
-	resp := RecipeWithImageIDs{
-		ID:          recipe.ID,
-		Title:       recipe.Title,
-		Text:        recipe.Text,
-		Ingredients: req.Ingredients,
-		UserID:      recipe.UserID,
-		Tags:        recipe.Tags,
-		Categories:  recipe.Categories,
-		Steps:       recipe.Steps,
-		Images:      imageIDs,
-		CreatedAt:   recipe.CreatedAt,
-		UpdatedAt:   recipe.UpdatedAt,
-	}
+	imageIDs, _ := h.app.Images.GetImageIDsForEntity("recipe", recipe.ID)
 
+	resp := newRecipeWithImageIDs(recipe, imageIDs)
+	resp.NutritionPending = true
 	c.JSON(http.StatusCreated, resp)
 }
 
@@ -376,22 +513,8 @@ func PostRecipeHandler(c *gin.Context) {
 // @Failure      404 {object} controller.ErrorResponse
 // @Failure      500 {object} controller.ErrorResponse
 // @Router       /admin/user/{userID}/recipe/{id} [delete]
-func DeleteRecipeHandler(c *gin.Context) {
-	var userID uint
-	var err error
-	if role := c.GetString("role"); role == "user" {
-		userID := c.GetUint("userID")
-		if userID == 0 {
-			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-			return
-		}
-	} else if role == "admin" {
-		userID, err = middleware.GetEffectiveUserID(c)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-			return
-		}
-	}
+func (h *Handler) DeleteRecipeHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	recipeID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
@@ -399,49 +522,44 @@ func DeleteRecipeHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+	recipes := h.app.Repos.Recipes
 
-	var recipe model.Recipe
-	if err := db.Where("id = ? AND user_id = ?", recipeID, userID).
-		First(&recipe).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusForbidden, ErrorResponse{Error: messages.Recipe.RecipeDeleteForbidden.String()})
+	recipe, err := recipes.FindByID(recipeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeFetchFail.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
 		return
 	}
 
-	// Clear M2M associations (join tables must be cleaned manually)
-	_ = db.Model(&recipe).Association("Tags").Clear()
-	_ = db.Model(&recipe).Association("Categories").Clear()
+	if !h.authorize(c, policy.Recipe, policy.Delete, recipe.UserID) {
+		return
+	}
 
-	// Delete recipe (cascade takes care of children)
-	if err := db.Delete(&recipe).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeDeleteFail.String()})
+	if err := recipes.Delete(recipe); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeDeleteFail)})
 		return
 	}
+	h.recipeChanged(recipe.ID)
 
-	c.JSON(http.StatusOK, SimpleMessageResponse{Message: messages.Recipe.RecipeDeleted.String()})
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.Recipe.RecipeDeleted)})
 }
 
 // GetAllRecipeIngredientHandler godoc
 // @Summary      Get all ingredients for a recipe
 // @Description  Retrieve all ingredients for a specific recipe by ID
 // @Tags         recipes
-// @Param        id   path      int  true  "Recipe ID"
+// @Param        id     path      int     true   "Recipe ID"
+// @Param        units  query     string  false  "Measurement system: metric, imperial or original (defaults to the user's preference)"
 // @Success      200  {object}  IngredientsResponse
 // @Failure      400  {object}  ErrorResponse
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /recipe/{id}/ingredients [get]
-func GetAllRecipeIngredientHandler(c *gin.Context) {
-
-	var Ingredient []model.Ingredient
+func (h *Handler) GetAllRecipeIngredientHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	validID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
@@ -449,31 +567,33 @@ func GetAllRecipeIngredientHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+	recipes := h.app.Repos.Recipes
+
+	recipe, err := recipes.FindByID(validID)
+	if err != nil || !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		return
 	}
 
-	err = db.First(&model.Recipe{}, validID).Error
+	Ingredient, err := recipes.ListIngredients(validID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeIngredientsFetchFail)})
 		return
 	}
 
-	err = db.Model(&model.Ingredient{}).Where("recipe_id = ?", c.Param("id")).Find(&Ingredient).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeIngredientsFetchFail.String()})
+	if len(Ingredient) == 0 {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeHasNoIngredient)})
 		return
 	}
 
-	if len(Ingredient) == 0 {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeHasNoIngredient.String()})
+	system, ok := h.unitSystem(c)
+	if !ok {
 		return
 	}
+	service.ConvertIngredients(Ingredient, system)
 
 	c.JSON(http.StatusOK, IngredientsResponse{
-		Message: messages.Common.Success.String(),
+		Message: loc.T(messages.Common.Success),
 		Data:    Ingredient,
 	})
 
@@ -493,38 +613,21 @@ func GetAllRecipeIngredientHandler(c *gin.Context) {
 // @Failure      404   {object}  controller.ErrorResponse
 // @Failure      500   {object}  controller.ErrorResponse
 // @Router       /recipe/{id}/comments [get]
-func GetAllRecipeCommentsHandler(c *gin.Context) {
+func (h *Handler) GetAllRecipeCommentsHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	validID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	var recipe model.Recipe
-	if err := db.First(&recipe, validID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+	if recipe, err := h.app.Repos.Recipes.FindByID(validID); err != nil || !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		return
 	}
 
 	sort := c.Query("sortOrder")
-	query := db.Model(&model.Comment{}).
-		Select("comments.*, users.name AS user_name").
-		Joins("JOIN users ON users.id = comments.user_id").
-		Where("comments.recipe_id = ?", validID)
-	query = utils.ApplyCommentSorting(query, sort)
-
-	// Count
-	totalCount, err := utils.Count(query, "comments")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Comment.CommnetFetchFail.String()})
-		return
-	}
 
 	// Paginate
 	limit, offset, _ := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
@@ -532,42 +635,25 @@ func GetAllRecipeCommentsHandler(c *gin.Context) {
 		limit = 10
 	}
 
-	var comments []CommentWithUserName
-	if err := utils.Paginate(query, limit, offset, &comments); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Comment.CommnetFetchFail.String()})
+	comments, totalCount, err := h.app.Repos.Comments.ListForRecipe(validID, sort, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Comment.CommnetFetchFail)})
 		return
 	}
 
 	c.JSON(http.StatusOK, CommentsResponse{
-		Message: messages.Common.Success.String(),
+		Message: loc.T(messages.Common.Success),
 		Data:    comments,
 		Count:   totalCount,
 	})
 }
 
-// GetAllRecipesHandler godoc
-// @Summary      Get all recipes with pagination, filtering, and sorting
-// @Description  Retrieve a paginated list of recipes with total count, optionally filtered by title, ingredient, tags, categories, user, and sorted by title, creation date, rating, or favorites
-// @Tags         recipes
-// @Produce      json
-// @Param        limit         query     int     false  "Limit number of recipes returned"
-// @Param        offset        query     int     false  "Number of recipes to skip"
-// @Param        sort          query     string  false  "Sort order: title_asc, title_desc, created_asc, created_desc, rating_desc, favorites_desc"
-// @Param        title         query     string  false  "Filter by recipe title (partial match)"
-// @Param        ingredient    query     string  false  "Filter by ingredient name (partial match)"
-// @Param        tag_ids       query     string  false  "Filter by tag IDs (comma-separated)"
-// @Param        category_ids  query     string  false  "Filter by category IDs (comma-separated)"
-// @Param        user_id       query     int     false  "Filter by user ID"
-// @Success      200           {object}  controller.RecipeListWithImagesResponse
-// @Failure      400           {object}  controller.ErrorResponse
-// @Failure      500           {object}  controller.ErrorResponse
-// @Router       /recipe/list [get]
-func GetAllRecipesHandler(c *gin.Context) {
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+// recipeSearchParams collects the recipe filters of the query string for
+// utils.ApplyRecipeFilters. It responds with 400 and returns false when a
+// filter names an unknown diet, allergen, difficulty, cuisine or equipment.
+func (h *Handler) recipeSearchParams(c *gin.Context) (map[string]string, bool) {
+	loc := middleware.Localizer(c)
+	cfg := h.app.Config.Recipes
 
 	params := map[string]string{
 		"title":        c.Query("title"),
@@ -575,63 +661,177 @@ func GetAllRecipesHandler(c *gin.Context) {
 		"tag_ids":      c.Query("tag_ids"),
 		"category_ids": c.Query("category_ids"),
 		"user_id":      c.Query("user_id"),
-		"rating":       c.Query("rating"),
-		"min_calories": c.Query("min_calories"),
-		"max_calories": c.Query("max_calories"),
-		"min_protein":  c.Query("min_protein"),
-		"max_protein":  c.Query("max_protein"),
-		"min_fat":      c.Query("min_fat"),
-		"max_fat":      c.Query("max_fat"),
-		"min_carbs":    c.Query("min_carbs"),
-		"max_carbs":    c.Query("max_carbs"),
-		"min_fiber":    c.Query("min_fiber"),
-		"max_fiber":    c.Query("max_fiber"),
-		"min_sugar":    c.Query("min_sugar"),
-		"max_sugar":    c.Query("max_sugar"),
-	}
-	query := db.Model(&model.Recipe{}).Preload("Ingredients").Preload("Tags").Preload("Categories").Preload("Steps")
-	query = utils.ApplyRecipeFilters(query, params)
-	sort := c.Query("sortOrder")
+		"rating":       c.Query("min_rating"),
+		"min_votes":    c.Query("min_votes"),
+		"per_serving":  c.Query("per_serving"),
+		"status":       recipeStatusFilter(c),
+		"visibility":   recipeVisibilityFilter(c),
+
+		"exclude_ingredients":  c.Query("exclude_ingredients"),
+		"exclude_tag_ids":      c.Query("exclude_tag_ids"),
+		"exclude_category_ids": c.Query("exclude_category_ids"),
+		"exclude_user_ids":     c.Query("exclude_user_ids"),
+	}
+	// rating is the older name of min_rating.
+	if params["rating"] == "" {
+		params["rating"] = c.Query("rating")
+	}
+	for _, column := range utils.NutrientColumns {
+		params["min_"+column] = c.Query("min_" + column)
+		params["max_"+column] = c.Query("max_" + column)
+	}
+	for _, column := range utils.TimeColumns {
+		params["min_"+column] = c.Query("min_" + column)
+		params["max_"+column] = c.Query("max_" + column)
+	}
+
+	for _, name := range utils.ParseList(c.Query("diet")) {
+		if _, ok := dietary.ParseDiet(name); !ok {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.DietInvalid, choiceArgs(name, dietary.Diets))})
+			return nil, false
+		}
+	}
+	params["diet"] = c.Query("diet")
+	for _, name := range utils.ParseList(c.Query("exclude_allergens")) {
+		if _, ok := dietary.ParseAllergen(name); !ok {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.AllergenInvalid, choiceArgs(name, dietary.Allergens))})
+			return nil, false
+		}
+	}
+	params["exclude_allergens"] = c.Query("exclude_allergens")
 
-	query = utils.ApplyRecipeSorting(query, sort)
+	for _, param := range []string{"tag_match", "category_match"} {
+		mode := c.DefaultQuery(param, utils.MatchAny)
+		if mode != utils.MatchAny && mode != utils.MatchAll {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.MatchModeInvalid, choiceArgs(mode, []string{utils.MatchAny, utils.MatchAll}))})
+			return nil, false
+		}
+		params[param] = mode
+	}
+	for _, param := range []string{"created_from", "created_to"} {
+		if v := c.Query(param); v != "" {
+			if _, _, ok := utils.ParseDate(v); !ok {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.DateInvalid, messages.Args{"value": v})})
+				return nil, false
+			}
+			params[param] = v
+		}
+	}
+	if expr := c.Query("filter"); expr != "" {
+		if _, err := utils.ParseRecipeFilter(expr); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: filterErrorMessage(loc, err)})
+			return nil, false
+		}
+		params["filter"] = expr
+	}
+
+	// Difficulties, cuisines and equipment are stored as configured, so
+	// the filters are rewritten to that spelling.
+	for _, f := range []struct {
+		param   string
+		choices []string
+		msg     messages.Message
+	}{
+		{"difficulty", cfg.Difficulties, messages.Recipe.DifficultyInvalid},
+		{"cuisine", cfg.Cuisines, messages.Recipe.CuisineInvalid},
+		{"equipment", cfg.Equipment, messages.Recipe.EquipmentInvalid},
+	} {
+		var values []string
+		for _, name := range utils.ParseList(c.Query(f.param)) {
+			i, ok := matchChoice(f.choices, name)
+			if !ok {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(f.msg, choiceArgs(name, f.choices))})
+				return nil, false
+			}
+			values = append(values, f.choices[i])
+		}
+		params[f.param] = strings.Join(values, ",")
+	}
 
-	var baseRecipes []model.Recipe
-	totalCount, err := utils.Count(query, "recipes")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeFetchFail.String()})
+	return params, true
+}
+
+// filterErrorMessage explains why utils.ParseRecipeFilter rejected a
+// filter expression.
+func filterErrorMessage(loc messages.Localizer, err error) string {
+	var fe *utils.FilterError
+	if !errors.As(err, &fe) {
+		return err.Error()
+	}
+	switch fe.Kind {
+	case utils.FilterUnknownField:
+		return loc.T(messages.Recipe.FilterFieldInvalid, choiceArgs(fe.Token, utils.FilterFields))
+	case utils.FilterBadValue:
+		return loc.T(messages.Recipe.FilterValueInvalid, messages.Args{"value": fe.Token, "field": fe.Field})
+	case utils.FilterTooComplex:
+		return loc.T(messages.Recipe.FilterTooComplex, messages.Args{"terms": utils.MaxFilterTerms, "length": utils.MaxFilterLength})
+	}
+	if fe.Token == "" {
+		return loc.T(messages.Recipe.FilterIncomplete)
+	}
+	return loc.T(messages.Recipe.FilterSyntaxInvalid, messages.Args{"token": fe.Token, "pos": fe.Pos})
+}
+
+// GetAllRecipesHandler godoc
+// @Summary      Get all recipes with pagination, filtering, and sorting
+// @Description  Retrieve a paginated list of recipes with total count, optionally filtered by title, ingredient, tags, categories, user, and sorted by title, creation date, rating, or favorites. Nutrient ranges are given as min_<nutrient> and max_<nutrient> for calories, protein, fat, saturated_fat, carbs, fiber, sugar, cholesterol, sodium and potassium, time ranges as min_<time> and max_<time> for prep_minutes, cook_minutes and total_minutes. A filter expression combines field:value terms with AND, OR, NOT (or - before a term) and parentheses; a space also means AND. Fields are tag and category (name or ID), ingredient and title (partial match), author (user ID), cuisine, difficulty, diet, allergen, equipment, and rating, votes, created (a date), the nutrients and the times, which take a comparison such as rating:>=4 or total_minutes:<30. Values with spaces go in double quotes, as in ingredient:"olive oil".
+// @Tags         recipes
+// @Produce      json
+// @Param        limit                 query     int     false  "Limit number of recipes returned"
+// @Param        offset                query     int     false  "Number of recipes to skip"
+// @Param        sortOrder             query     string  false  "Sort order: title_asc, title_desc, created_asc, created_desc, rating_desc, favorites_desc, or calories, protein, fat, carbs, fiber, sugar, prep_time, cook_time, total_time or difficulty followed by _asc or _desc"
+// @Param        title                 query     string  false  "Filter by recipe title (partial match)"
+// @Param        ingredient            query     string  false  "Filter by ingredient name (partial match)"
+// @Param        tag_ids               query     string  false  "Filter by tag IDs (comma-separated)"
+// @Param        category_ids          query     string  false  "Filter by category IDs (comma-separated)"
+// @Param        user_id               query     int     false  "Filter by user ID"
+// @Param        min_rating            query     number  false  "Only recipes rated at least this on average; rating is accepted as well"
+// @Param        status                query     string  false  "Publication status (admins only, everyone else gets published recipes)"
+// @Param        visibility            query     string  false  "Visibility (admins only, everyone else gets public recipes)"
+// @Param        per_serving           query     bool    false  "Apply the nutrient ranges to one serving instead of the whole recipe"
+// @Param        diet                  query     string  false  "Only recipes fitting every listed diet (comma-separated): vegan, vegetarian, pescatarian, gluten_free, dairy_free, nut_free"
+// @Param        exclude_allergens     query     string  false  "Leave out recipes containing any listed allergen (comma-separated): celery, gluten, crustacean, egg, fish, lupin, milk, mollusc, mustard, tree_nut, peanut, sesame, soy, sulphite"
+// @Param        min_votes             query     int     false  "Only recipes with at least this many ratings"
+// @Param        exclude_ingredients   query     string  false  "Leave out recipes with any listed ingredient (comma-separated, partial match)"
+// @Param        exclude_tag_ids       query     string  false  "Leave out recipes with any listed tag (comma-separated IDs)"
+// @Param        exclude_category_ids  query     string  false  "Leave out recipes in any listed category (comma-separated IDs)"
+// @Param        exclude_user_ids      query     string  false  "Leave out recipes by any listed author (comma-separated IDs)"
+// @Param        tag_match             query     string  false  "Whether recipes need any or all of tag_ids"  Enums(any, all)
+// @Param        category_match        query     string  false  "Whether recipes need any or all of category_ids"  Enums(any, all)
+// @Param        created_from          query     string  false  "Only recipes created at or after this date (YYYY-MM-DD, in the server's time zone) or time (RFC 3339)"
+// @Param        created_to            query     string  false  "Only recipes created at or before this date (the whole day) or time"
+// @Param        filter                query     string  false  "Filter expression, e.g. tag:vegan AND NOT ingredient:nuts; see the description"
+// @Param        difficulty            query     string  false  "Filter by difficulty (comma-separated), see /recipes/options"
+// @Param        cuisine               query     string  false  "Filter by cuisine (comma-separated), see /recipes/options"
+// @Param        equipment             query     string  false  "Only recipes using every listed piece of equipment (comma-separated), see /recipes/options"
+// @Param        max_total_minutes     query     int     false  "Only recipes ready in at most this many minutes; min_/max_ also work for prep_minutes and cook_minutes"
+// @Success      200                   {object}  controller.RecipeListWithImagesResponse
+// @Failure      400                   {object}  controller.ErrorResponse
+// @Failure      500                   {object}  controller.ErrorResponse
+// @Router       /recipe/list [get]
+func (h *Handler) GetAllRecipesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	params, ok := h.recipeSearchParams(c)
+	if !ok {
 		return
 	}
+	sort := c.Query("sortOrder")
 
 	limit, offset, _ := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
 	if limit == 0 {
 		limit = 10
 	}
-	if err := utils.Paginate(query, limit, offset, &baseRecipes); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Rating.RatingFetchFail.String()})
-		return
-	}
 
-	var responseData []RecipeWithImageIDs
-	for _, recipe := range baseRecipes {
-		imageIDs, _ := service.GetImageIDsForEntity("recipe", recipe.ID)
-		responseData = append(responseData, RecipeWithImageIDs{
-			ID:          recipe.ID,
-			Title:       recipe.Title,
-			Text:        recipe.Text,
-			UserID:      recipe.UserID,
-			Ingredients: recipe.Ingredients,
-			Tags:        recipe.Tags,
-			Categories:  recipe.Categories,
-			Steps:       recipe.Steps,
-			Images:      imageIDs,
-			CreatedAt:   recipe.CreatedAt,
-			UpdatedAt:   recipe.UpdatedAt,
-		})
+	baseRecipes, totalCount, err := h.app.Repos.Recipes.Search(params, sort, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
+		return
 	}
 
 	c.JSON(http.StatusOK, RecipeListWithImagesResponse{
-		Message: messages.Common.Success.String(),
-		Data:    responseData,
+		Message: loc.T(messages.Common.Success),
+		Data:    h.recipeListResponse(baseRecipes),
 		Count:   totalCount,
 	})
 }
@@ -649,7 +849,9 @@ func GetAllRecipesHandler(c *gin.Context) {
 // @Failure      404   {object}  controller.SimpleMessageResponse
 // @Failure      500   {object}  controller.SimpleMessageResponse
 // @Router       /recipe/{id} [put]
-func PutRecipeUpdateHandler(c *gin.Context) {
+func (h *Handler) PutRecipeUpdateHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var input struct {
 		Title       string             `json:"title"`
 		Text        string             `json:"text"`
@@ -658,6 +860,10 @@ func PutRecipeUpdateHandler(c *gin.Context) {
 		TagIDs      []uint             `json:"tag_ids"`
 		Tags        []model.Tag        `json:"tags"`
 		CategoryIDs []uint             `json:"category_ids"`
+		// Servings and Yield are kept when omitted.
+		Servings *int    `json:"servings"`
+		Yield    *string `json:"yield"`
+		RecipeMetadataInput
 	}
 
 	validID, err := utils.ValidateEntityID(c.Param("id"))
@@ -671,46 +877,53 @@ func PutRecipeUpdateHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	recipe, err := h.app.Repos.Recipes.FindByID(validID, "Ingredients", "Steps", "Tags", "Categories")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
 		return
 	}
 
-	var recipe model.Recipe
-	if err = db.Preload("Ingredients").Preload("Steps").
-		Preload("Tags").Preload("Categories").
-		First(&recipe, validID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeFetchFail.String()})
+	if !h.authorize(c, policy.Recipe, policy.Update, recipe.UserID) {
+		return
+	}
+
+	if input.Servings != nil && !validServings(*input.Servings) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.ServingsInvalid, messages.Args{"max": maxServings})})
 		return
 	}
 
 	recipe.Title = input.Title
 	recipe.Text = input.Text
+	if input.Servings != nil {
+		recipe.Servings = *input.Servings
+	}
+	if input.Yield != nil {
+		recipe.Yield = *input.Yield
+	}
+	if msg, args, ok := setRecipeMetadata(recipe, input.RecipeMetadataInput, h.app.Config.Recipes); !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(msg, args)})
+		return
+	}
 
-	err = db.Transaction(func(tx *gorm.DB) error {
+	recipe.SetDietaryLabels(input.Ingredients)
+
+	err = h.changeRecipe(c, recipe.ID, func(tx *repository.Repositories) error {
 		// Save recipe base fields
-		if err := tx.Save(&recipe).Error; err != nil {
+		if err := tx.Recipes.Save(recipe); err != nil {
 			return err
 		}
 
 		// Replace ingredients
-		for i := range input.Ingredients {
-			input.Ingredients[i].RecipeID = recipe.ID
-		}
-		if err := tx.Model(&recipe).Association("Ingredients").Replace(input.Ingredients); err != nil {
+		if err := tx.Recipes.ReplaceIngredients(recipe, input.Ingredients); err != nil {
 			return err
 		}
 
 		// Replace steps
-		for i := range input.Steps {
-			input.Steps[i].RecipeID = recipe.ID
-		}
-		if err := tx.Model(&recipe).Association("Steps").Replace(input.Steps); err != nil {
+		if err := tx.Recipes.ReplaceSteps(recipe, input.Steps); err != nil {
 			return err
 		}
 
@@ -718,15 +931,13 @@ func PutRecipeUpdateHandler(c *gin.Context) {
 		tagMap := make(map[uint]model.Tag)
 		tagNameMap := make(map[string]bool)
 
-		if len(input.TagIDs) > 0 {
-			var existingTags []model.Tag
-			if err := tx.Where("id IN ?", input.TagIDs).Find(&existingTags).Error; err != nil {
-				return err
-			}
-			for _, t := range existingTags {
-				tagMap[t.ID] = t
-				tagNameMap[strings.ToLower(t.Name)] = true
-			}
+		existingTags, err := tx.Tags.FindByIDs(input.TagIDs)
+		if err != nil {
+			return err
+		}
+		for _, t := range existingTags {
+			tagMap[t.ID] = t
+			tagNameMap[strings.ToLower(t.Name)] = true
 		}
 
 		for _, t := range input.Tags {
@@ -735,10 +946,10 @@ func PutRecipeUpdateHandler(c *gin.Context) {
 				continue
 			}
 
-			var existing model.Tag
-			if err := tx.Where("LOWER(name) = ?", tagName).First(&existing).Error; err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					if err := tx.Create(&t).Error; err != nil {
+			existing, err := tx.Tags.FindByNameFold(tagName)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					if err := tx.Tags.Create(&t); err != nil {
 						return err
 					}
 					tagMap[t.ID] = t
@@ -747,7 +958,7 @@ func PutRecipeUpdateHandler(c *gin.Context) {
 					return err
 				}
 			} else {
-				tagMap[existing.ID] = existing
+				tagMap[existing.ID] = *existing
 				tagNameMap[tagName] = true
 			}
 		}
@@ -757,80 +968,30 @@ func PutRecipeUpdateHandler(c *gin.Context) {
 			finalTags = append(finalTags, t)
 		}
 
-		if err := tx.Model(&recipe).Association("Tags").Replace(finalTags); err != nil {
+		if err := tx.Recipes.ReplaceTags(recipe, finalTags); err != nil {
 			return err
 		}
 
 		// Handle categories
-		var categories []model.Category
-		if len(input.CategoryIDs) > 0 {
-			if err := tx.Where("id IN ?", input.CategoryIDs).Find(&categories).Error; err != nil {
-				return err
-			}
-			if len(categories) != len(input.CategoryIDs) {
-				return fmt.Errorf("one or more category IDs are invalid")
-			}
-		}
-		if err := tx.Model(&recipe).Association("Categories").Replace(categories); err != nil {
-			return err
-		}
-
-		if err := tx.Preload("Ingredients").First(&recipe, recipe.ID).Error; err != nil {
+		categories, err := tx.Categories.FindByIDs(input.CategoryIDs)
+		if err != nil {
 			return err
 		}
-
-		var ingredientStrings []string
-		for _, ing := range recipe.Ingredients {
-			ingredientStrings = append(ingredientStrings, fmt.Sprintf("%s of %s", ing.Amount, ing.Name))
+		if len(categories) != len(input.CategoryIDs) {
+			return fmt.Errorf("one or more category IDs are invalid")
 		}
-
-		nutritionItems, err := utils.EstimateNutrition(API_KEY, ingredientStrings)
-		if err == nil && len(nutritionItems) == len(recipe.Ingredients) {
-			var totalCalories, totalProtein, totalFat, totalCarbs, totalFiber, totalSugar float64
-
-			for i := range recipe.Ingredients {
-				recipe.Ingredients[i].Calories = nutritionItems[i].Calories
-				recipe.Ingredients[i].Protein = nutritionItems[i].ProteinG
-				recipe.Ingredients[i].Fat = nutritionItems[i].FatTotalG
-				recipe.Ingredients[i].Carbs = nutritionItems[i].CarbohydratesTotalG
-				recipe.Ingredients[i].Fiber = nutritionItems[i].FiberG
-				recipe.Ingredients[i].Sugar = nutritionItems[i].SugarG
-
-				totalCalories += nutritionItems[i].Calories
-				totalProtein += nutritionItems[i].ProteinG
-				totalFat += nutritionItems[i].FatTotalG
-				totalCarbs += nutritionItems[i].CarbohydratesTotalG
-				totalFiber += nutritionItems[i].FiberG
-				totalSugar += nutritionItems[i].SugarG
-			}
-
-			recipe.Calories = totalCalories
-			recipe.Protein = totalProtein
-			recipe.Fat = totalFat
-			recipe.Carbs = totalCarbs
-			recipe.Fiber = totalFiber
-			recipe.Sugar = totalSugar
-
-			if err := tx.Save(&recipe).Error; err != nil {
-				return err
-			}
-
-			for i := range recipe.Ingredients {
-				if err := tx.Save(&recipe.Ingredients[i]).Error; err != nil {
-					return err
-				}
-			}
+		if err := tx.Recipes.ReplaceCategories(recipe, categories); err != nil {
+			return err
 		}
-
-		return nil
+		return h.enqueueNutrition(tx, recipe.ID)
 	})
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeUpdateFail.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeUpdateFail)})
 		return
 	}
 
-	c.JSON(http.StatusOK, SimpleMessageResponse{Message: messages.Recipe.RecipeUpdated.String()})
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.Recipe.RecipeUpdated)})
 }
 
 // GetTopRatedRecipesHandler godoc
@@ -839,12 +1000,13 @@ func PutRecipeUpdateHandler(c *gin.Context) {
 // @Tags         recipes
 // @Param        limit   query  int  false "Limit number of recipes"
 // @Param        offset  query  int  false "Offset for pagination"
-// @Success      200     {array}  TopRatedRecipe
+// @Success      200     {array}  repository.TopRatedRecipe
 // @Failure      400     {object} ErrorResponse
 // @Failure      500     {object} ErrorResponse
 // @Router       /recipes/top-rated [get]
-func GetTopRatedRecipesHandler(c *gin.Context) {
-	var results []TopRatedRecipe
+func (h *Handler) GetTopRatedRecipesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var limit, offset = 1, 0
 
 	validLimit, validOffset, err := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
@@ -855,22 +1017,9 @@ func GetTopRatedRecipesHandler(c *gin.Context) {
 	limit = validLimit
 	offset = validOffset
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	err = db.Table("ratings").
-		Select("recipes.id AS recipe_id, recipes.title, AVG(ratings.score) AS average, COUNT(ratings.id) AS total_votes").
-		Joins("JOIN recipes ON recipes.id = ratings.recipe_id").
-		Group("ratings.recipe_id").
-		Order("average DESC").
-		Limit(limit).
-		Offset(offset).
-		Scan(&results).Error
+	results, err := h.app.Repos.Recipes.TopRated(limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeFetchFail.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
 		return
 	}
 
@@ -888,8 +1037,9 @@ func GetTopRatedRecipesHandler(c *gin.Context) {
 // @Failure      400     {object} ErrorResponse
 // @Failure      500     {object} ErrorResponse
 // @Router       /recipes/most-popular [get]
-func GetMostPopularRecipesHandler(c *gin.Context) {
-	var results []MostPopularRecipe
+func (h *Handler) GetMostPopularRecipesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var limit, offset = 1, 0
 
 	validLimit, validOffset, err := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
@@ -900,22 +1050,9 @@ func GetMostPopularRecipesHandler(c *gin.Context) {
 	limit = validLimit
 	offset = validOffset
 
-	db, err := internal.GetGormInstance()
+	results, err := h.app.Repos.Recipes.MostPopular(limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	err = db.Table("recipes").
-		Select("recipes.id as recipe_id, recipes.title, COUNT(favorites.id) as favorite_count").
-		Joins("LEFT JOIN favorites ON recipes.id = favorites.recipe_id").
-		Group("recipes.id").
-		Order("favorite_count DESC").
-		Limit(limit).
-		Offset(offset).
-		Scan(&results).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeFetchFail.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
 		return
 	}
 
@@ -924,46 +1061,113 @@ func GetMostPopularRecipesHandler(c *gin.Context) {
 
 // GetRecipeNutritionHandler godoc
 // @Summary      Get nutritional values for a recipe
-// @Description  Estimate nutrition info based on ingredients via AI model
+// @Description  Returns the nutrition of every ingredient, the recipe totals and one serving, as estimated when the recipe was saved. The report lists the ingredients no nutrition provider could match; the totals leave them out.
 // @Tags         recipes
 // @Param        id   path      int  true  "Recipe ID"
 // @Success      200  {object}  NutritionResponse
 // @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /recipe/{id}/calories [get]
-func GetRecipeNutritionHandler(c *gin.Context) {
-	id := c.Param("id")
+func (h *Handler) GetRecipeNutritionHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
-	recipeID, err := utils.ValidateEntityID(id)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
+	recipe, ok := h.nutritionRecipe(c)
+	if !ok {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
+	ingredients := make([]IngredientNutrition, len(recipe.Ingredients))
+	for i := range recipe.Ingredients {
+		ing := &recipe.Ingredients[i]
+		ingredients[i] = IngredientNutrition{
+			ID:     ing.ID,
+			Name:   ing.Name,
+			Amount: ing.Amount,
+			Facts:  service.IngredientFacts(ing).Round(),
+			Source: ing.NutritionSource,
+		}
 	}
 
-	var ingredients []model.Ingredient
-	if err := db.Where("recipe_id = ?", recipeID).Find(&ingredients).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeIngredientsFetchFail.String()})
+	c.JSON(http.StatusOK, NutritionResponse{
+		Message:     loc.T(messages.Common.Success),
+		Ingredients: ingredients,
+		Total:       service.RecipeFacts(recipe).Round(),
+		PerServing:  service.PerServing(recipe),
+		Report:      service.NutritionReportFor(recipe),
+	})
+}
+
+// nutritionLabelNames are the label lines by nutrient key.
+var nutritionLabelNames = map[string]messages.Message{
+	"fat":           messages.Recipe.NutrientFat,
+	"saturated_fat": messages.Recipe.NutrientSaturatedFat,
+	"cholesterol":   messages.Recipe.NutrientCholesterol,
+	"sodium":        messages.Recipe.NutrientSodium,
+	"carbs":         messages.Recipe.NutrientCarbs,
+	"fiber":         messages.Recipe.NutrientFiber,
+	"sugar":         messages.Recipe.NutrientSugar,
+	"protein":       messages.Recipe.NutrientProtein,
+	"potassium":     messages.Recipe.NutrientPotassium,
+}
+
+// GetRecipeNutritionLabelHandler godoc
+// @Summary      Get the nutrition-facts label of a recipe
+// @Description  Returns the nutrition-facts label of one serving: calories and the nutrients in label order, rounded as labels print them, with the percentage of the daily value for a 2,000 kcal diet. The report tells whether every ingredient was matched; otherwise the label understates the recipe.
+// @Tags         recipes
+// @Produce      json
+// @Param        id   path      int  true  "Recipe ID"
+// @Success      200  {object}  NutritionLabelResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /recipe/{id}/nutrition-label [get]
+func (h *Handler) GetRecipeNutritionLabelHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	recipe, ok := h.nutritionRecipe(c)
+	if !ok {
 		return
 	}
 
-	var ingredientStrings []string
-	for _, ing := range ingredients {
-		ingredientStrings = append(ingredientStrings, fmt.Sprintf("%s of %s", ing.Amount, ing.Name))
+	label := nutrition.NewLabel(service.PerServing(recipe), max(recipe.Servings, 1), service.ServingGrams(recipe))
+	for i := range label.Nutrients {
+		line := &label.Nutrients[i]
+		line.Name = loc.T(nutritionLabelNames[line.Key])
 	}
 
-	nutritionData, err := utils.EstimateNutrition(API_KEY, ingredientStrings)
+	c.JSON(http.StatusOK, NutritionLabelResponse{
+		Message: loc.T(messages.Common.Success),
+		Label:   label,
+		Report:  service.NutritionReportFor(recipe),
+	})
+}
+
+// nutritionRecipe loads the recipe of a nutrition route with its
+// ingredients and writes the error response when the caller cannot see it.
+func (h *Handler) nutritionRecipe(c *gin.Context) (*model.Recipe, bool) {
+	loc := middleware.Localizer(c)
+
+	recipeID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeNutritionFail.String()})
-		return
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
+		return nil, false
 	}
 
-	c.JSON(http.StatusOK, NutritionResponse{NutritionalValues: nutritionData})
+	recipe, err := h.app.Repos.Recipes.FindByID(recipeID, "Ingredients")
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNutritionFail)})
+		return nil, false
+	}
+	if !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
+		return nil, false
+	}
+	return recipe, true
 }
 
 // GetRecipeTagsHandler godoc
@@ -976,29 +1180,28 @@ func GetRecipeNutritionHandler(c *gin.Context) {
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /recipe/{id}/tags [get]
-func GetRecipeTagsHandler(c *gin.Context) {
+func (h *Handler) GetRecipeTagsHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	recipeID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	recipe, err := h.app.Repos.Recipes.FindByID(recipeID, "Tags")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	var recipe model.Recipe
-	err = db.Preload("Tags").First(&recipe, recipeID).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		} else {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeTagFetchFail.String()})
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeTagFetchFail)})
 		}
 		return
 	}
+	if !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
+		return
+	}
 
 	c.JSON(http.StatusOK, TagsResponse{Tags: recipe.Tags})
 }
@@ -1019,24 +1222,24 @@ func GetRecipeTagsHandler(c *gin.Context) {
 // @Failure      404   {object}  ErrorResponse
 // @Failure      500   {object}  ErrorResponse
 // @Router       /recipe/{id}/tags [put]
-func PutRecipeTagsHandler(c *gin.Context) {
-	recipeID := c.Param("id")
+func (h *Handler) PutRecipeTagsHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
-	_, err := utils.ValidateEntityID(recipeID)
+	recipeID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	repos := h.app.Repos
+
+	recipe, err := repos.Recipes.FindByID(recipeID, "Tags")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
 		return
 	}
 
-	var recipe model.Recipe
-	if err := db.Preload("Tags").First(&recipe, recipeID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeFetchFail.String()})
+	if !h.authorize(c, policy.Recipe, policy.Update, recipe.UserID) {
 		return
 	}
 
@@ -1048,28 +1251,31 @@ func PutRecipeTagsHandler(c *gin.Context) {
 
 	var tags []model.Tag
 	for _, tagName := range input.Tags {
-		var tag model.Tag
-		if err := db.Where("name = ?", tagName).First(&tag).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				tag = model.Tag{Name: tagName}
-				if err := db.Create(&tag).Error; err != nil {
-					c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeTagCreateFail.String()})
+		tag, err := repos.Tags.FindByName(tagName)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				tag = &model.Tag{Name: tagName}
+				if err := repos.Tags.Create(tag); err != nil {
+					c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeTagCreateFail)})
 					return
 				}
 			} else {
-				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeTagQueryFail.String()})
+				c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeTagQueryFail)})
 				return
 			}
 		}
-		tags = append(tags, tag)
+		tags = append(tags, *tag)
 	}
 
-	if err := db.Model(&recipe).Association("Tags").Replace(tags); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeTagUpdateFail.String()})
+	err = h.changeRecipe(c, recipe.ID, func(tx *repository.Repositories) error {
+		return tx.Recipes.ReplaceTags(recipe, tags)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeTagUpdateFail)})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": messages.Recipe.RecipeTagUpdated.String(), "tags": tags})
+	c.JSON(http.StatusOK, gin.H{"message": loc.T(messages.Recipe.RecipeTagUpdated), "tags": tags})
 }
 
 // DeleteRecipeTagsHandler godoc
@@ -1083,31 +1289,36 @@ func PutRecipeTagsHandler(c *gin.Context) {
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /recipe/{id}/tags [delete]
-func DeleteRecipeTagsHandler(c *gin.Context) {
+func (h *Handler) DeleteRecipeTagsHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	recipeID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	recipes := h.app.Repos.Recipes
+
+	recipe, err := recipes.FindByID(recipeID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		return
 	}
 
-	var recipe model.Recipe
-	if err := db.Preload("Tags").First(&recipe, recipeID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+	if !h.authorize(c, policy.Recipe, policy.Update, recipe.UserID) {
 		return
 	}
 
-	if err := db.Model(&recipe).Association("Tags").Clear(); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeTagsDeleteFail.String()})
+	err = h.changeRecipe(c, recipe.ID, func(tx *repository.Repositories) error {
+		return tx.Recipes.ClearTags(recipe)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeTagsDeleteFail)})
 		return
 	}
 
-	c.JSON(http.StatusOK, SimpleMessageResponse{Message: messages.Recipe.ReciepTagsDeleted.String()})
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.Recipe.ReciepTagsDeleted)})
 }
 
 // GetRecipeCategoriesHandler godoc
@@ -1121,38 +1332,33 @@ func DeleteRecipeTagsHandler(c *gin.Context) {
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /recipe/{id}/categories [get]
-func GetRecipeCategoriesHandler(c *gin.Context) {
+func (h *Handler) GetRecipeCategoriesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	recipeID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
 		return
 	}
 
-	var limit, offset = 1, 0
-	validLimit, validOffset, err := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
-	if err != nil {
+	if _, _, err := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset")); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	limit = validLimit
-	offset = validOffset
-
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
 
-	var recipe model.Recipe
-	err = db.Preload("Categories").First(&recipe, recipeID).Limit(limit).Offset(offset).Error
+	recipe, err := h.app.Repos.Recipes.FindByID(recipeID, "Categories")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		} else {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeCatsFetcFail.String()})
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeCatsFetcFail)})
 		}
 		return
 	}
+	if !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
+		return
+	}
 
 	c.JSON(http.StatusOK, RecipeCategoriesResponse{Categories: recipe.Categories})
 }
@@ -1168,124 +1374,125 @@ func GetRecipeCategoriesHandler(c *gin.Context) {
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /recipe/{id}/categories [delete]
-func DeleteRecipeCategoriesHandler(c *gin.Context) {
+func (h *Handler) DeleteRecipeCategoriesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	recipeID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid recipe ID"})
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	recipes := h.app.Repos.Recipes
+
+	recipe, err := recipes.FindByID(recipeID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
 		return
 	}
 
-	var recipe model.Recipe
-	if err := db.Preload("Categories").First(&recipe, recipeID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Recipe.RecipeNotFound.String()})
+	if !h.authorize(c, policy.Recipe, policy.Update, recipe.UserID) {
 		return
 	}
 
-	if err := db.Model(&recipe).Association("Categories").Clear(); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeCatsDeleteFail.String()})
+	err = h.changeRecipe(c, recipe.ID, func(tx *repository.Repositories) error {
+		return tx.Recipes.ClearCategories(recipe)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeCatsDeleteFail)})
 		return
 	}
 
-	c.JSON(http.StatusOK, SimpleMessageResponse{Message: messages.Recipe.RecipeCatsDeleted.String()})
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.Recipe.RecipeCatsDeleted)})
 }
 
 // SearchRecipesHandler godoc
 // @Summary      Search recipes with pagination, filtering, and sorting
-// @Description  Search for recipes using various filters and retrieve a paginated list with total count, optionally sorted
+// @Description  Search for recipes using various filters and retrieve a paginated list with total count, optionally sorted. The full-text query q matches titles, descriptions, steps, ingredient names and tags in English and Persian, ignoring word endings and stop words; every word must match, "quoted words" must appear as a phrase and word* matches any word starting with it. Results of a full-text query are ranked by relevance unless another sort order is given. With facets the response also counts the matches per tag, category, author and average rating and buckets them by each nutrient and time; every facet ignores its own filter, including the terms on its field in the filter expression, so it shows what changing that filter would give. Nutrient ranges are given as min_<nutrient> and max_<nutrient> for calories, protein, fat, saturated_fat, carbs, fiber, sugar, cholesterol, sodium and potassium, time ranges as min_<time> and max_<time> for prep_minutes, cook_minutes and total_minutes. A filter expression combines field:value terms with AND, OR, NOT (or - before a term) and parentheses; a space also means AND. Fields are tag and category (name or ID), ingredient and title (partial match), author (user ID), cuisine, difficulty, diet, allergen, equipment, and rating, votes, created (a date), the nutrients and the times, which take a comparison such as rating:>=4 or total_minutes:<30. Values with spaces go in double quotes, as in ingredient:"olive oil".
 // @Tags         recipes
 // @Produce      json
-// @Param        title         query     string  false  "Filter by recipe title (partial match)"
-// @Param        ingredient    query     string  false  "Filter by ingredient name (partial match)"
-// @Param        tag_ids       query     string  false  "Filter by tag IDs (comma-separated)"
-// @Param        category_ids  query     string  false  "Filter by category IDs (comma-separated)"
-// @Param        user_id       query     string  false  "Filter by user ID"
-// @Param        sort          query     string  false  "Sort order: title_asc, title_desc, created_asc, created_desc, rating_desc, favorites_desc"
-// @Param        limit         query     int     false  "Limit number of recipes returned"
-// @Param        offset        query     int     false  "Number of recipes to skip"
-// @Success      200           {object}  controller.RecipeListWithImagesResponse
-// @Failure      400           {object}  controller.ErrorResponse
-// @Failure      500           {object}  controller.ErrorResponse
+// @Param        q                     query     string  false  "Full-text query"
+// @Param        title                 query     string  false  "Filter by recipe title (partial match)"
+// @Param        ingredient            query     string  false  "Filter by ingredient name (partial match)"
+// @Param        tag_ids               query     string  false  "Filter by tag IDs (comma-separated)"
+// @Param        category_ids          query     string  false  "Filter by category IDs (comma-separated)"
+// @Param        user_id               query     string  false  "Filter by user ID"
+// @Param        min_rating            query     number  false  "Only recipes rated at least this on average; rating is accepted as well"
+// @Param        status                query     string  false  "Publication status (admins only, everyone else gets published recipes)"
+// @Param        visibility            query     string  false  "Visibility (admins only, everyone else gets public recipes)"
+// @Param        per_serving           query     bool    false  "Apply the nutrient ranges to one serving instead of the whole recipe"
+// @Param        diet                  query     string  false  "Only recipes fitting every listed diet (comma-separated): vegan, vegetarian, pescatarian, gluten_free, dairy_free, nut_free"
+// @Param        exclude_allergens     query     string  false  "Leave out recipes containing any listed allergen (comma-separated): celery, gluten, crustacean, egg, fish, lupin, milk, mollusc, mustard, tree_nut, peanut, sesame, soy, sulphite"
+// @Param        min_votes             query     int     false  "Only recipes with at least this many ratings"
+// @Param        exclude_ingredients   query     string  false  "Leave out recipes with any listed ingredient (comma-separated, partial match)"
+// @Param        exclude_tag_ids       query     string  false  "Leave out recipes with any listed tag (comma-separated IDs)"
+// @Param        exclude_category_ids  query     string  false  "Leave out recipes in any listed category (comma-separated IDs)"
+// @Param        exclude_user_ids      query     string  false  "Leave out recipes by any listed author (comma-separated IDs)"
+// @Param        tag_match             query     string  false  "Whether recipes need any or all of tag_ids"  Enums(any, all)
+// @Param        category_match        query     string  false  "Whether recipes need any or all of category_ids"  Enums(any, all)
+// @Param        created_from          query     string  false  "Only recipes created at or after this date (YYYY-MM-DD, in the server's time zone) or time (RFC 3339)"
+// @Param        created_to            query     string  false  "Only recipes created at or before this date (the whole day) or time"
+// @Param        filter                query     string  false  "Filter expression, e.g. tag:vegan AND NOT ingredient:nuts; see the description"
+// @Param        difficulty            query     string  false  "Filter by difficulty (comma-separated), see /recipes/options"
+// @Param        cuisine               query     string  false  "Filter by cuisine (comma-separated), see /recipes/options"
+// @Param        equipment             query     string  false  "Only recipes using every listed piece of equipment (comma-separated), see /recipes/options"
+// @Param        max_total_minutes     query     int     false  "Only recipes ready in at most this many minutes; min_/max_ also work for prep_minutes and cook_minutes"
+// @Param        sortOrder             query     string  false  "Sort order: relevance (only with q), title_asc, title_desc, created_asc, created_desc, rating_desc, favorites_desc, or calories, protein, fat, carbs, fiber, sugar, prep_time, cook_time, total_time or difficulty followed by _asc or _desc"
+// @Param        facets                query     string  false  "Facets to compute (comma-separated): tags, categories, authors, rating, or a nutrient or time column such as calories or total_minutes"
+// @Param        limit                 query     int     false  "Limit number of recipes returned, at most 100 for a full-text query"
+// @Param        offset                query     int     false  "Page number"
+// @Success      200                   {object}  controller.RecipeSearchResponse
+// @Failure      400                   {object}  controller.ErrorResponse
+// @Failure      500                   {object}  controller.ErrorResponse
 // @Router       /recipes/search [get]
-func SearchRecipesHandler(c *gin.Context) {
-	fmt.Println("Full query string:", c.Request.URL.RawQuery)
-	params := map[string]string{
-		"title":        c.Query("title"),
-		"ingredient":   c.Query("ingredient"),
-		"tag_ids":      c.Query("tag_ids"),
-		"category_ids": c.Query("category_ids"),
-		"user_id":      c.Query("user_id"),
-		"rating":       c.Query("rating"),
-		"min_calories": c.Query("min_calories"),
-		"max_calories": c.Query("max_calories"),
-		"min_protein":  c.Query("min_protein"),
-		"max_protein":  c.Query("max_protein"),
-		"min_fat":      c.Query("min_fat"),
-		"max_fat":      c.Query("max_fat"),
-		"min_carbs":    c.Query("min_carbs"),
-		"max_carbs":    c.Query("max_carbs"),
-		"min_fiber":    c.Query("min_fiber"),
-		"max_fiber":    c.Query("max_fiber"),
-		"min_sugar":    c.Query("min_sugar"),
-		"max_sugar":    c.Query("max_sugar"),
-	}
-	fmt.Println(c.Query("max_calories"))
-
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+func (h *Handler) SearchRecipesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	params, ok := h.recipeSearchParams(c)
+	if !ok {
 		return
 	}
 
-	query := db.Model(&model.Recipe{}).
-		Preload("Tags").
-		Preload("Categories").
-		Preload("User").
-		Preload("Ratings").
-		Preload("Ingredients")
-	query = utils.ApplyRecipeFilters(query, params)
-	query = utils.ApplyRecipeSorting(query, c.Query("sortOrder"))
-
-	var recipes []model.Recipe
-	totalCount, err := utils.Count(query, "recipes")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeFetchFail.String()})
-		return
+	facetNames := utils.ParseList(c.Query("facets"))
+	for _, name := range facetNames {
+		if !slices.Contains(repository.RecipeFacets, name) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.FacetInvalid, choiceArgs(name, repository.RecipeFacets))})
+			return
+		}
 	}
+
 	limit, offset, _ := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
 	if limit == 0 {
 		limit = 10
 	}
-	if err := utils.Paginate(query, limit, offset, &recipes); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Rating.RatingFetchFail.String()})
-		return
-	}
 
-	var recipesWithImages []RecipeWithImageIDs
-	for _, r := range recipes {
-		imageIDs, _ := service.GetImageIDsForEntity("recipe", r.ID)
-		recipesWithImages = append(recipesWithImages, RecipeWithImageIDs{
-			ID:         r.ID,
-			Title:      r.Title,
-			Text:       r.Text,
-			UserID:     r.UserID,
-			Tags:       r.Tags,
-			Categories: r.Categories,
-			Steps:      r.Steps,
-			Images:     imageIDs,
-			CreatedAt:  r.CreatedAt,
-			UpdatedAt:  r.UpdatedAt,
-		})
+	var recipes []model.Recipe
+	var totalCount int64
+	var facets map[string]repository.Facet
+	var truncated bool
+	var err error
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		query := search.ParseQuery(q)
+		recipes, totalCount, err = h.app.Search.Find(query, params, c.Query("sortOrder"), limit, offset)
+		if err == nil && len(facetNames) > 0 {
+			facets, truncated, err = h.app.Search.Facets(query, params, facetNames)
+		}
+	} else {
+		recipes, totalCount, err = h.app.Repos.Recipes.Search(params, c.Query("sortOrder"), limit, offset)
+		if err == nil && len(facetNames) > 0 {
+			facets, err = h.app.Repos.Recipes.Facets(facetNames, params, nil)
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
+		return
 	}
 
-	c.JSON(http.StatusOK, RecipeListWithImagesResponse{
-		Message: messages.Common.Success.String(),
-		Data:    recipesWithImages,
-		Count:   totalCount,
+	c.JSON(http.StatusOK, RecipeSearchResponse{
+		Message:         loc.T(messages.Common.Success),
+		Data:            h.recipeListResponse(recipes),
+		Count:           totalCount,
+		Facets:          facets,
+		FacetsTruncated: truncated,
 	})
 }
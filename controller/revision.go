@@ -0,0 +1,258 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/service"
+	"github.com/Abb133Se/recepieshare/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type RevisionListResponse struct {
+	Message string                 `json:"message"`
+	Data    []model.RecipeRevision `json:"data"`
+	Count   int64                  `json:"count"`
+}
+
+type RevisionResponse struct {
+	Message string               `json:"message"`
+	Data    model.RecipeRevision `json:"data"`
+}
+
+type RevisionDiffResponse struct {
+	Message string                `json:"message"`
+	From    int                   `json:"from"`
+	To      int                   `json:"to"`
+	Changes []service.FieldChange `json:"changes"`
+}
+
+// changeRecipe runs fn in a transaction, records the resulting state of the
+// recipe as a new revision authored by the caller and reindexes it.
+func (h *Handler) changeRecipe(c *gin.Context, recipeID uint, fn func(tx *repository.Repositories) error) error {
+	err := h.app.Repos.Transaction(func(tx *repository.Repositories) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		_, err := h.app.Revisions.Record(tx, recipeID, c.GetUint("userID"), nil)
+		return err
+	})
+	if err == nil {
+		h.recipeChanged(recipeID)
+	}
+	return err
+}
+
+// revisionRecipe loads the recipe of a revision route and writes the error
+// response when it does not exist.
+func (h *Handler) revisionRecipe(c *gin.Context) (*model.Recipe, bool) {
+	loc := middleware.Localizer(c)
+
+	recipeID, err := utils.ValidateEntityID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+
+	recipe, err := h.app.Repos.Recipes.FindByID(recipeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
+		return nil, false
+	}
+	if !h.canSeeRecipe(c, recipe) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
+		return nil, false
+	}
+	return recipe, true
+}
+
+// revisionError writes the response for an error of the revision service.
+func revisionError(c *gin.Context, err error, fallback messages.Message) {
+	loc := middleware.Localizer(c)
+
+	if errors.Is(err, service.ErrRevisionNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RevisionNotFound)})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(fallback)})
+}
+
+// GetRecipeRevisionsHandler godoc
+// @Summary      List the revisions of a recipe
+// @Description  Returns the version history of a recipe, newest first. Every change to the recipe, its ingredients, steps, tags or categories creates a revision.
+// @Tags         revisions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path   int  true   "Recipe ID"
+// @Param        limit   query  int  false  "Limit number of revisions returned"
+// @Param        offset  query  int  false  "Page number"
+// @Success      200  {object}  controller.RevisionListResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /recipe/{id}/revisions [get]
+func (h *Handler) GetRecipeRevisionsHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	recipe, ok := h.revisionRecipe(c)
+	if !ok {
+		return
+	}
+
+	limit, offset, _ := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
+	if limit == 0 {
+		limit = 10
+	}
+
+	revisions, total, err := h.app.Repos.Revisions.ListByRecipe(recipe.ID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RevisionFetchFail)})
+		return
+	}
+
+	c.JSON(http.StatusOK, RevisionListResponse{
+		Message: loc.T(messages.Common.Success),
+		Data:    revisions,
+		Count:   total,
+	})
+}
+
+// GetRecipeRevisionHandler godoc
+// @Summary      Get one revision of a recipe
+// @Description  Returns the full snapshot of the recipe at the given version.
+// @Tags         revisions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  int  true  "Recipe ID"
+// @Param        version  path  int  true  "Revision version"
+// @Success      200  {object}  controller.RevisionResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /recipe/{id}/revisions/{version} [get]
+func (h *Handler) GetRecipeRevisionHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	recipe, ok := h.revisionRecipe(c)
+	if !ok {
+		return
+	}
+
+	version, err := utils.ValidateEntityID(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	rev, err := h.app.Revisions.Find(recipe.ID, int(version))
+	if err != nil {
+		revisionError(c, err, messages.Recipe.RevisionFetchFail)
+		return
+	}
+
+	c.JSON(http.StatusOK, RevisionResponse{Message: loc.T(messages.Common.Success), Data: *rev})
+}
+
+// GetRecipeRevisionDiffHandler godoc
+// @Summary      Compare two revisions of a recipe
+// @Description  Lists the fields that differ between two versions. Ingredients, steps, tags and categories also list the added and removed items.
+// @Tags         revisions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path   int  true  "Recipe ID"
+// @Param        from  query  int  true  "Older version"
+// @Param        to    query  int  true  "Newer version"
+// @Success      200  {object}  controller.RevisionDiffResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /recipe/{id}/revisions/diff [get]
+func (h *Handler) GetRecipeRevisionDiffHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	recipe, ok := h.revisionRecipe(c)
+	if !ok {
+		return
+	}
+
+	from, err := utils.ValidateEntityID(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	to, err := utils.ValidateEntityID(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	changes, err := h.app.Revisions.Diff(recipe.ID, int(from), int(to))
+	if err != nil {
+		revisionError(c, err, messages.Recipe.RevisionFetchFail)
+		return
+	}
+
+	c.JSON(http.StatusOK, RevisionDiffResponse{
+		Message: loc.T(messages.Common.Success),
+		From:    int(from),
+		To:      int(to),
+		Changes: changes,
+	})
+}
+
+// PostRestoreRecipeRevisionHandler godoc
+// @Summary      Restore an old revision of a recipe
+// @Description  Writes the content of the given version back to the recipe. The restore is recorded as a new revision, so it can be undone as well.
+// @Tags         revisions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path  int  true  "Recipe ID"
+// @Param        version  path  int  true  "Revision version to restore"
+// @Success      200  {object}  controller.RevisionResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /recipe/{id}/revisions/{version}/restore [post]
+func (h *Handler) PostRestoreRecipeRevisionHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	recipe, ok := h.revisionRecipe(c)
+	if !ok {
+		return
+	}
+
+	if !h.authorize(c, policy.Recipe, policy.Update, recipe.UserID) {
+		return
+	}
+
+	version, err := utils.ValidateEntityID(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	rev, err := h.app.Revisions.Restore(recipe.ID, int(version), c.GetUint("userID"))
+	if err != nil {
+		revisionError(c, err, messages.Recipe.RevisionRestoreFail)
+		return
+	}
+	h.recipeChanged(recipe.ID)
+
+	// Restored ingredients come without nutrition values.
+	if err := h.enqueueNutrition(h.app.Repos, recipe.ID); err != nil {
+		log.Printf("failed to queue nutrition estimate of recipe %d: %v", recipe.ID, err)
+	}
+
+	c.JSON(http.StatusOK, RevisionResponse{Message: loc.T(messages.Recipe.RevisionRestored), Data: *rev})
+}
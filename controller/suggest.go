@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
+	"github.com/Abb133Se/recepieshare/search"
+	"github.com/Abb133Se/recepieshare/service"
+	"github.com/Abb133Se/recepieshare/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSuggestions = 10
+	maxSuggestions     = 50
+)
+
+type SuggestResponse struct {
+	Message string              `json:"message"`
+	Data    []search.Suggestion `json:"data"`
+}
+
+// GetSuggestHandler godoc
+// @Summary      Autocomplete recipes, ingredients, tags and categories
+// @Description  Completes partial input to recipe titles, ingredient names, tags and categories of published public recipes. Entries starting with q rank above entries with a later word starting with it; when there are too few, entries starting with something within one typo (two for queries over five letters) of q are added. Popular entries, by favorites and views for recipes and by number of recipes otherwise, rank higher.
+// @Tags         search
+// @Produce      json
+// @Param        q      query     string  true   "Partial input"
+// @Param        types  query     string  false  "Types to suggest (comma-separated): recipe, ingredient, tag, category; all by default"
+// @Param        limit  query     int     false  "Maximum number of suggestions (default 10, at most 50)"
+// @Success      200    {object}  controller.SuggestResponse
+// @Failure      400    {object}  controller.ErrorResponse
+// @Router       /suggest [get]
+func (h *Handler) GetSuggestHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	types := utils.ParseList(c.Query("types"))
+	for _, t := range types {
+		if !slices.Contains(service.SuggestTypes, t) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.SuggestTypeInvalid, choiceArgs(t, service.SuggestTypes))})
+			return
+		}
+	}
+
+	limit, _, _ := utils.ValidateOffLimit(c.Query("limit"), "")
+	if limit == 0 {
+		limit = defaultSuggestions
+	}
+	limit = min(limit, maxSuggestions)
+
+	suggestions := h.app.Suggest.Suggest(c.Query("q"), types, limit)
+	if suggestions == nil {
+		suggestions = []search.Suggestion{}
+	}
+	c.JSON(http.StatusOK, SuggestResponse{Message: loc.T(messages.Common.Success), Data: suggestions})
+}
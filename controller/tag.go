@@ -2,14 +2,15 @@ package controller
 
 import (
 	"errors"
+	"log"
 	"net/http"
 
-	"github.com/Abb133Se/recepieshare/internal"
 	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
 	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
 	"github.com/Abb133Se/recepieshare/utils"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type TagResponse struct {
@@ -34,8 +35,8 @@ type TagsListResponse struct {
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /tag/{id} [get]
-func GetTagHandler(c *gin.Context) {
-	var tag model.Tag
+func (h *Handler) GetTagHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	validID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
@@ -43,22 +44,16 @@ func GetTagHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	tag, err := h.app.Repos.Tags.FindByID(validID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	err = db.First(&tag, validID).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Tag.TagNotFound.String()})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Tag.TagNotFound)})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Common.DBConnectionErr)})
 		return
 	}
-	c.JSON(http.StatusOK, TagResponse{Message: messages.Common.Success.String(), Data: tag})
+	c.JSON(http.StatusOK, TagResponse{Message: loc.T(messages.Common.Success), Data: *tag})
 }
 
 // PostTagHandler godoc
@@ -73,7 +68,9 @@ func GetTagHandler(c *gin.Context) {
 // @Failure      409  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /tag [post]
-func PostTagHandler(c *gin.Context) {
+func (h *Handler) PostTagHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var tag model.Tag
 
 	if err := c.ShouldBindJSON(&tag); err != nil {
@@ -81,33 +78,29 @@ func PostTagHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+	tags := h.app.Repos.Tags
+
+	if _, err := tags.FindByName(tag.Name); err == nil {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: loc.T(messages.Tag.TagAlreadyExists)})
 		return
 	}
 
-	var existingTag model.Tag
-	if err := db.Where("name = ?", tag.Name).First(&existingTag).Error; err == nil {
-		c.JSON(http.StatusConflict, ErrorResponse{Error: messages.Tag.TagAlreadyExists.String()})
-		return
+	// Recipes are linked after creation, and only if they exist.
+	var recipeIDs []uint
+	for _, r := range tag.Recipes {
+		recipeIDs = append(recipeIDs, r.ID)
 	}
+	tag.Recipes = nil
 
-	if err := db.Create(&tag).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Tag.TagCreationFailed.String()})
+	if err := tags.Create(&tag); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Tag.TagCreationFailed)})
 		return
 	}
 
-	if len(tag.Recipes) > 0 {
-		for _, r := range tag.Recipes {
-			var existingRecipe model.Recipe
-			if err := db.First(&existingRecipe, r.ID).Error; err == nil {
-				db.Model(&tag).Association("Recipes").Append(&existingRecipe)
-			}
-		}
-	}
+	_ = tags.AttachRecipes(&tag, recipeIDs)
+	h.tagsChanged()
 
-	c.JSON(http.StatusCreated, TagResponse{Message: messages.Tag.TagCreationOk.String(), Data: tag})
+	c.JSON(http.StatusCreated, TagResponse{Message: loc.T(messages.Tag.TagCreationOk), Data: tag})
 }
 
 // GetAllTagsHandler godoc
@@ -119,42 +112,19 @@ func PostTagHandler(c *gin.Context) {
 // @Success      200     {object}  controller.TagsResponse
 // @Failure      500     {object}  controller.ErrorResponse
 // @Router       /tags [get]
-func GetAllTagsHandler(c *gin.Context) {
-	var tags []model.Tag
+func (h *Handler) GetAllTagsHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	sort := c.DefaultQuery("sort", "")
-	var order string
-	switch sort {
-	case "name_desc":
-		order = "name DESC"
-	case "name_asc":
-		order = "name ASC"
-	case "created_desc":
-		order = "created_at DESC"
-	case "created_asc":
-		order = "created_at ASC"
-	default:
-		order = ""
-	}
 
-	db, err := internal.GetGormInstance()
+	tags, err := h.app.Repos.Tags.List(sort)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	query := db.Model(&model.Tag{})
-	if order != "" {
-		query = query.Order(order)
-	}
-
-	if err := query.Find(&tags).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Tag.TagFetchFailed.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Tag.TagFetchFailed)})
 		return
 	}
 
 	c.JSON(http.StatusOK, TagsListResponse{
-		Message: messages.Common.Success.String(),
+		Message: loc.T(messages.Common.Success),
 		Data:    tags,
 		Count:   int64(len(tags)),
 	})
@@ -173,7 +143,9 @@ func GetAllTagsHandler(c *gin.Context) {
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /tag/{id} [put]
-func PutTagHandler(c *gin.Context) {
+func (h *Handler) PutTagHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
 	var tag model.Tag
 	tagID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
@@ -186,25 +158,20 @@ func PutTagHandler(c *gin.Context) {
 		return
 	}
 
-	db, err := internal.GetGormInstance()
+	existing, err := h.app.Repos.Tags.FindByID(tagID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	var existing model.Tag
-	if err := db.First(&existing, tagID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Tag.TagNotFound.String()})
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Tag.TagNotFound)})
 		return
 	}
 
 	existing.Name = tag.Name
-	if err := db.Save(&existing).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Tag.TagUploadFailed.String()})
+	if err := h.app.Repos.Tags.Save(existing); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Tag.TagUploadFailed)})
 		return
 	}
+	h.tagsChanged()
 
-	c.JSON(http.StatusOK, TagResponse{Message: messages.Tag.TagUploadOK.String(), Data: existing})
+	c.JSON(http.StatusOK, TagResponse{Message: loc.T(messages.Tag.TagUploadOK), Data: *existing})
 }
 
 // DeleteTagHandler godoc
@@ -217,30 +184,44 @@ func PutTagHandler(c *gin.Context) {
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /tag/{id} [delete]
-func DeleteTagHandler(c *gin.Context) {
-	tagID := c.Param("id")
-	var tag model.Tag
+func (h *Handler) DeleteTagHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	tags := h.app.Repos.Tags
 
-	db, err := internal.GetGormInstance()
+	tagID, err := utils.ValidateEntityID(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Tag.TagNotFound)})
 		return
 	}
 
-	if err := db.Preload("Recipes").First(&tag, tagID).Error; err != nil {
-		c.JSON(http.StatusNotFound, ErrorResponse{Error: messages.Tag.TagNotFound.String()})
+	tag, err := tags.FindByID(tagID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Tag.TagNotFound)})
 		return
 	}
 
-	if err := db.Model(&tag).Association("Recipes").Clear(); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Tag.TagFailedAssocioationRemova.String()})
+	if err := tags.DetachRecipes(tag); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Tag.TagFailedAssocioationRemova)})
 		return
 	}
 
-	if err := db.Delete(&tag).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Tag.TagDeletionFaied.String()})
+	if err := tags.Delete(tag); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Tag.TagDeletionFaied)})
 		return
 	}
+	h.tagsChanged()
 
-	c.JSON(http.StatusOK, SimpleMessageResponse{Message: messages.Tag.TagDeletionOk.String()})
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.Tag.TagDeletionOk)})
+}
+
+// tagsChanged reindexes all recipes in the background after a tag was
+// created, renamed or deleted, as every recipe carrying it changed.
+func (h *Handler) tagsChanged() {
+	h.app.Suggest.Invalidate()
+	go func() {
+		if err := h.app.Search.Rebuild(); err != nil {
+			log.Printf("failed to rebuild search index: %v", err)
+		}
+	}()
 }
@@ -1,12 +1,16 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
-	"github.com/Abb133Se/recepieshare/internal"
 	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
 	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/Abb133Se/recepieshare/quantity"
+	"github.com/Abb133Se/recepieshare/repository"
 	"github.com/Abb133Se/recepieshare/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -18,14 +22,9 @@ type UserRecipesResponse struct {
 }
 
 type UserFavoritesResponse struct {
-	Message string              `json:"message"`
-	Data    []FavoriteWithTitle `json:"data"`
-	Count   int64               `json:"count"`
-}
-
-type FavoriteWithTitle struct {
-	model.Favorite
-	RecipeTitle string `json:"recipe_title"`
+	Message string                         `json:"message"`
+	Data    []repository.FavoriteWithTitle `json:"data"`
+	Count   int64                          `json:"count"`
 }
 
 type UserRatingsResponse struct {
@@ -34,53 +33,6 @@ type UserRatingsResponse struct {
 	Count   int64          `json:"count"`
 }
 
-// UserStats holds computed statistics for a user
-// @Description Aggregated statistics based on the user's activity in the system.
-type UserStats struct {
-	// Total number of recipes created by this user
-	TotalRecipes int64 `json:"total_recipes" example:"12"`
-
-	// Total number of comments posted by this user
-	TotalComments int64 `json:"total_comments" example:"34"`
-
-	// Total number of times this user's recipes were favorited by others
-	TotalFavorites int64 `json:"total_favorites" example:"87"`
-
-	// Average rating across all this user's recipes (scale 1–5)
-	AverageRating float64 `json:"average_rating" example:"4.5"`
-
-	// Sum of likes across all comments posted by this user
-	TotalLikesOnComments int64 `json:"total_likes_on_comments" example:"56"`
-
-	// Average number of likes per comment posted by this user
-	AverageLikesPerComment float64 `json:"average_likes_per_comment" example:"2.3"`
-
-	// Total number of ratings this user has given to other recipes
-	TotalRatingsGiven int64 `json:"total_ratings_given" example:"19"`
-
-	// Average rating value this user gives when rating other recipes (scale 1–5)
-	AverageRatingGiven float64 `json:"average_rating_given" example:"3.8"`
-
-	// Total number of recipes this user has favorited
-	TotalFavoritesGiven int64 `json:"total_favorites_given" example:"25"`
-}
-
-// RecipeSummary provides a summary of a recipe used in profile highlights
-// @Description Minimal recipe representation used to highlight "most popular" and "highest rated" recipes.
-type RecipeSummary struct {
-	// Unique identifier of the recipe
-	ID uint `json:"id" example:"7"`
-
-	// Title of the recipe
-	Title string `json:"title" example:"Classic Pancakes"`
-
-	// Rating score for highest-rated recipe (scale 1–5); only present if relevant
-	Score float64 `json:"score,omitempty" example:"4.8"`
-
-	// Favorite count for most-popular recipe; only present if relevant
-	Count int64 `json:"count,omitempty" example:"120"`
-}
-
 // UserProfileResponse represents the complete profile of a user
 // @Description Full user profile including base user data, profile image, aggregated statistics, and highlighted recipes.
 type UserProfileResponse struct {
@@ -92,13 +44,13 @@ type UserProfileResponse struct {
 	ProfileImage *model.Image `json:"profile_image,omitempty"`
 
 	// Aggregated statistics about user's activity (recipes, comments, favorites, ratings)
-	Stats UserStats `json:"stats"`
+	Stats repository.UserStats `json:"stats"`
 
 	// The user's recipe with the highest number of favorites (if exists)
-	MostPopular *RecipeSummary `json:"most_popular_recipe,omitempty"`
+	MostPopular *repository.RecipeSummary `json:"most_popular_recipe,omitempty"`
 
 	// The user's recipe with the highest average rating (if exists)
-	HighestRated *RecipeSummary `json:"highest_rated_recipe,omitempty"`
+	HighestRated *repository.RecipeSummary `json:"highest_rated_recipe,omitempty"`
 }
 
 type UserListResponse struct {
@@ -125,32 +77,19 @@ type UserListResponse struct {
 // @Failure      401 {object} ErrorResponse "Unauthorized: missing or invalid token"
 // @Failure      500 {object} ErrorResponse "Internal server error"
 // @Router       /user/profile [get]
-func GetUserProfile(c *gin.Context) {
-	userID := c.GetUint("userID")
-	if userID == 0 {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-		return
-	}
+func (h *Handler) GetUserProfile(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
+	userID, ok := h.targetUserID(c, policy.User, policy.Read)
+	if !ok {
 		return
 	}
 
+	repos := h.app.Repos
+
 	// --- 1. Load user + relationships ---
-	var user model.User
-	if err := db.Preload("Recipes.Ingredients").
-		Preload("Recipes.Comments").
-		Preload("Recipes.Favorites").
-		Preload("Recipes.Ratings").
-		Preload("Recipes.Tags").
-		Preload("Recipes.Categories").
-		Preload("Recipes.Steps").
-		Preload("Comments").
-		Preload("Favorites").
-		Preload("Ratings").
-		First(&user, userID).Error; err != nil {
+	user, err := repos.Users.FindProfile(userID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -162,94 +101,46 @@ func GetUserProfile(c *gin.Context) {
 	user.PasswordResetExpiresAt = nil
 
 	// --- 2. Fetch profile image ---
-	var profileImage model.Image
-	if err := db.Where("entity_type = ? AND entity_id = ?", "user", userID).
-		First(&profileImage).Error; err != nil {
-		profileImage = model.Image{}
-	}
+	profileImage, _ := repos.Images.FindFirst("user", userID)
 
 	// --- 3. Aggregated statistics in one query ---
-	var stats UserStats
-	err = db.Raw(`
-		SELECT
-			(SELECT COUNT(*) FROM recipes r WHERE r.user_id = ?) AS total_recipes,
-			(SELECT COUNT(*) FROM comments c WHERE c.user_id = ?) AS total_comments,
-			(SELECT COUNT(*) FROM favorites f JOIN recipes r2 ON f.recipe_id = r2.id WHERE r2.user_id = ?) AS total_favorites,
-			(SELECT COALESCE(AVG(rt.score),0) FROM ratings rt JOIN recipes r3 ON rt.recipe_id = r3.id WHERE r3.user_id = ?) AS average_rating,
-			(SELECT COALESCE(SUM(c2.likes),0) FROM comments c2 WHERE c2.user_id = ?) AS total_likes_on_comments,
-			(SELECT COALESCE(AVG(c3.likes),0) FROM comments c3 WHERE c3.user_id = ?) AS average_likes_per_comment,
-			(SELECT COUNT(*) FROM ratings r4 WHERE r4.user_id = ?) AS total_ratings_given,
-			(SELECT COALESCE(AVG(r5.score),0) FROM ratings r5 WHERE r5.user_id = ?) AS average_rating_given,
-			(SELECT COUNT(*) FROM favorites f2 WHERE f2.user_id = ?) AS total_favorites_given
-	`, userID, userID, userID, userID, userID, userID, userID, userID, userID).Scan(&stats).Error
-
+	stats, err := repos.Users.Stats(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.User.UserStatFetchFail.String()})
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.UserStatFetchFail)})
 		return
 	}
 
 	// --- 4. Most popular recipe (highest favorites count) ---
-	var mostPopular RecipeSummary
-	db.Raw(`
-		SELECT r.id, r.title, COUNT(f.id) AS count
-		FROM recipes r
-		LEFT JOIN favorites f ON f.recipe_id = r.id
-		WHERE r.user_id = ?
-		GROUP BY r.id
-		ORDER BY COUNT(f.id) DESC
-		LIMIT 1
-	`, userID).Scan(&mostPopular)
+	mostPopular, _ := repos.Users.MostPopularRecipe(userID)
 
 	// --- 5. Highest-rated recipe (best average rating) ---
-	var highestRated RecipeSummary
-	db.Raw(`
-		SELECT r.id, r.title, COALESCE(AVG(rt.score),0) AS score
-		FROM recipes r
-		LEFT JOIN ratings rt ON rt.recipe_id = r.id
-		WHERE r.user_id = ?
-		GROUP BY r.id
-		ORDER BY AVG(rt.score) DESC
-		LIMIT 1
-	`, userID).Scan(&highestRated)
+	highestRated, _ := repos.Users.HighestRatedRecipe(userID)
 
 	// --- 6. Build response ---
 	resp := UserProfileResponse{
-		User:         user,
-		Stats:        stats,
-		MostPopular:  nil,
-		HighestRated: nil,
-	}
-
-	if profileImage.ID != 0 {
-		resp.ProfileImage = &profileImage
-	}
-	if mostPopular.ID != 0 {
-		resp.MostPopular = &mostPopular
-	}
-	if highestRated.ID != 0 {
-		resp.HighestRated = &highestRated
+		User:         *user,
+		ProfileImage: profileImage,
+		Stats:        *stats,
+		MostPopular:  mostPopular,
+		HighestRated: highestRated,
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
 
-func GetAllUsersHandler(c *gin.Context) {
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
+func (h *Handler) GetAllUsersHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
 	// --- 1. Load all users without heavy preloads ---
-	var users []model.User
-	if err := db.Find(&users).Error; err != nil {
+	users, err := h.app.Repos.Users.List()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	// --- 2. Fetch profile images in one query ---
-	var images []model.Image
-	if err := db.Where("entity_type = ?", "user").Find(&images).Error; err != nil {
+	images, err := h.app.Repos.Images.ListByType("user")
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -278,7 +169,7 @@ func GetAllUsersHandler(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "users fetched successfully",
+		"message": loc.T(messages.User.UsersFetched, messages.Args{"count": len(response)}),
 		"count":   len(response),
 		"data":    response,
 	})
@@ -295,26 +186,12 @@ func GetAllUsersHandler(c *gin.Context) {
 // @Failure      400    {object}  controller.ErrorResponse
 // @Failure      500    {object}  controller.ErrorResponse
 // @Router       /user/recipes [get]
-func GetUserRecipesHandler(c *gin.Context) {
-	// ✅ Get userID from JWT middleware
-	userIDValue, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-		return
-	}
-	userID := userIDValue.(uint)
-
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	query := db.Model(&model.Recipe{}).Where("user_id = ?", userID)
+func (h *Handler) GetUserRecipesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
-	totalCount, err := utils.Count(query, "recipes")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeFetchFail.String()})
+	// The caller, or the :id of the admin route
+	userID, ok := h.targetUserID(c, policy.User, policy.Read)
+	if !ok {
 		return
 	}
 
@@ -324,14 +201,14 @@ func GetUserRecipesHandler(c *gin.Context) {
 		limit = 10
 	}
 
-	var recipes []model.Recipe
-	if err := utils.Paginate(query, limit, offset, &recipes); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Recipe.RecipeFetchFail.String()})
+	recipes, totalCount, err := h.app.Repos.Recipes.ListByUser(userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
 		return
 	}
 
 	c.JSON(http.StatusOK, UserRecipesResponse{
-		Message: messages.Common.Success.String(),
+		Message: loc.T(messages.Common.Success),
 		Data:    recipes,
 		Count:   totalCount,
 	})
@@ -348,28 +225,11 @@ func GetUserRecipesHandler(c *gin.Context) {
 // @Failure      400    {object}  controller.ErrorResponse
 // @Failure      500    {object}  controller.ErrorResponse
 // @Router       /user/favorites [get]
-func GetUserFavoritesHandler(c *gin.Context) {
-	userIDValue, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-		return
-	}
-	userID := userIDValue.(uint)
+func (h *Handler) GetUserFavoritesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	query := db.Table("favorites").
-		Select("favorites.id, favorites.recipe_id, recipes.title as recipe_title").
-		Joins("JOIN recipes ON favorites.recipe_id = recipes.id").
-		Where("favorites.user_id = ?", userID)
-
-	totalCount, err := utils.Count(query, "favorites")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Favorite.FavoriteFailed.String()})
+	userID, ok := h.targetUserID(c, policy.Favorite, policy.Read)
+	if !ok {
 		return
 	}
 
@@ -379,14 +239,14 @@ func GetUserFavoritesHandler(c *gin.Context) {
 		limit = 10
 	}
 
-	var favorites []FavoriteWithTitle
-	if err := utils.Paginate(query, limit, offset, &favorites); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Favorite.FavoriteFailed.String()})
+	favorites, totalCount, err := h.app.Repos.Favorites.ListByUser(userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Favorite.FavoriteFailed)})
 		return
 	}
 
 	c.JSON(http.StatusOK, UserFavoritesResponse{
-		Message: messages.Common.Success.String(),
+		Message: loc.T(messages.Common.Success),
 		Data:    favorites,
 		Count:   totalCount,
 	})
@@ -403,25 +263,11 @@ func GetUserFavoritesHandler(c *gin.Context) {
 // @Failure      400    {object}  controller.ErrorResponse
 // @Failure      500    {object}  controller.ErrorResponse
 // @Router       /user/ratings [get]
-func GetUserRatingsHandler(c *gin.Context) {
-	userIDValue, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: messages.Common.Unauthorized.String()})
-		return
-	}
-	userID := userIDValue.(uint)
+func (h *Handler) GetUserRatingsHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
 
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Common.DBConnectionErr.String()})
-		return
-	}
-
-	query := db.Model(&model.Rating{}).Where("user_id = ?", userID)
-
-	totalCount, err := utils.Count(query, "ratings")
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Rating.RatingFetchFail.String()})
+	userID, ok := h.targetUserID(c, policy.User, policy.Read)
+	if !ok {
 		return
 	}
 
@@ -431,15 +277,123 @@ func GetUserRatingsHandler(c *gin.Context) {
 		limit = 10
 	}
 
-	var ratings []model.Rating
-	if err := utils.Paginate(query, limit, offset, &ratings); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: messages.Rating.RatingFetchFail.String()})
+	ratings, totalCount, err := h.app.Repos.Ratings.ListByUser(userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Rating.RatingFetchFail)})
 		return
 	}
 
 	c.JSON(http.StatusOK, UserRatingsResponse{
-		Message: messages.Common.Success.String(),
+		Message: loc.T(messages.Common.Success),
 		Data:    ratings,
 		Count:   totalCount,
 	})
 }
+
+// PutUserRoleHandler godoc
+// @Summary      Change a user's role
+// @Description  Assigns one of the roles user, moderator or admin to a user. Admins cannot change their own role.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path  int                               true  "User ID"
+// @Param        body  body  controller.UpdateUserRoleRequest  true  "New role"
+// @Success      200  {object}  controller.SimpleMessageResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /admin/user/{id}/role [put]
+func (h *Handler) PutUserRoleHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	userID, err := utils.ValidateEntityID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Common.BadRequest)})
+		return
+	}
+
+	role, ok := policy.ParseRole(req.Role)
+	if !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.User.RoleInvalid)})
+		return
+	}
+
+	if userID == c.GetUint("userID") {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: loc.T(messages.User.RoleChangeSelf)})
+		return
+	}
+
+	users := h.app.Repos.Users
+
+	user, err := users.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.User.UserNotFound)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.UserFetchFail)})
+		return
+	}
+
+	if err := users.Updates(user, map[string]any{"role": string(role)}); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.RoleUpdateFail)})
+		return
+	}
+
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.User.RoleUpdated)})
+}
+
+// PutUserPreferencesHandler godoc
+// @Summary      Update the caller's preferences
+// @Description  Sets the measurement system recipes are shown in when a request does not pass the units parameter. Use original to show the units the author wrote.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body  controller.UserPreferencesRequest  true  "Preferences"
+// @Success      200  {object}  controller.SimpleMessageResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /user/preferences [put]
+func (h *Handler) PutUserPreferencesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	var req UserPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Common.BadRequest)})
+		return
+	}
+
+	system, ok := quantity.ParseSystem(req.Units)
+	if !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.UnitsInvalid)})
+		return
+	}
+	units := string(system)
+	if system == quantity.Original {
+		units = ""
+	}
+
+	users := h.app.Repos.Users
+
+	user, err := users.FindByID(c.GetUint("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.UserFetchFail)})
+		return
+	}
+
+	if err := users.Updates(user, map[string]any{"units": units}); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.User.PreferencesUpdateFail)})
+		return
+	}
+
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.User.PreferencesUpdated)})
+}
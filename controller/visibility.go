@@ -0,0 +1,399 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/middleware"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/token"
+	"github.com/Abb133Se/recepieshare/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type RecipeVisibilityRequest struct {
+	Visibility string `json:"visibility" binding:"required"`
+}
+
+type RecipeVisibilityResponse struct {
+	Message    string `json:"message"`
+	Visibility string `json:"visibility"`
+	// ShareToken is only set for unlisted recipes. Pass it as share_token to
+	// the recipe endpoints or open /shared/{token}.
+	ShareToken string `json:"share_token,omitempty"`
+}
+
+type RecipeInviteRequest struct {
+	UserID uint   `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+type RecipeInvitesResponse struct {
+	Message string                   `json:"message"`
+	Data    []repository.InvitedUser `json:"data"`
+}
+
+// setRecipeVisibility changes the visibility of recipe. Switching to unlisted
+// creates a share token, switching away drops it so old links stop working.
+func setRecipeVisibility(recipe *model.Recipe, name string) (messages.Message, bool) {
+	visibility, ok := model.ParseRecipeVisibility(name)
+	if !ok {
+		return messages.Recipe.VisibilityInvalid, false
+	}
+
+	if visibility != model.VisibilityUnlisted {
+		recipe.ShareToken = nil
+	} else if recipe.ShareToken == nil {
+		shareToken, err := token.GenerateShareToken()
+		if err != nil {
+			return messages.Recipe.VisibilityUpdateFail, false
+		}
+		recipe.ShareToken = &shareToken
+	}
+	recipe.Visibility = visibility
+	return messages.Message{}, true
+}
+
+func visibilityResponse(msg string, recipe *model.Recipe) RecipeVisibilityResponse {
+	resp := RecipeVisibilityResponse{Message: msg, Visibility: string(recipe.Visibility)}
+	if recipe.ShareToken != nil {
+		resp.ShareToken = *recipe.ShareToken
+	}
+	return resp
+}
+
+// ownRecipe loads the recipe of the :id route parameter and checks that the
+// caller may change it.
+func (h *Handler) ownRecipe(c *gin.Context) (*model.Recipe, bool) {
+	loc := middleware.Localizer(c)
+
+	recipeID, err := utils.ValidateEntityID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+
+	recipe, err := h.app.Repos.Recipes.FindByID(recipeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
+		return nil, false
+	}
+
+	if !h.authorize(c, policy.Recipe, policy.Update, recipe.UserID) {
+		return nil, false
+	}
+	return recipe, true
+}
+
+// PutRecipeVisibilityHandler godoc
+// @Summary      Change who can see a recipe
+// @Description  Public recipes are listed everywhere. Unlisted recipes are left out of listings and search but open for anyone with the share token returned here. Private recipes are visible to the owner and invited users only.
+// @Tags         recipes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path  int                      true  "Recipe ID"
+// @Param        visibility  body  RecipeVisibilityRequest  true  "New visibility"
+// @Success      200  {object}  controller.RecipeVisibilityResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /recipe/{id}/visibility [put]
+func (h *Handler) PutRecipeVisibilityHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	var req RecipeVisibilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	recipe, ok := h.ownRecipe(c)
+	if !ok {
+		return
+	}
+
+	if msg, ok := setRecipeVisibility(recipe, req.Visibility); !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(msg)})
+		return
+	}
+
+	if err := h.app.Repos.Recipes.Save(recipe); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.VisibilityUpdateFail)})
+		return
+	}
+	h.app.Suggest.Invalidate()
+
+	c.JSON(http.StatusOK, visibilityResponse(loc.T(messages.Recipe.VisibilityUpdated), recipe))
+}
+
+// GetRecipeShareLinkHandler godoc
+// @Summary      Get the share token of an unlisted recipe
+// @Tags         recipes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Recipe ID"
+// @Success      200  {object}  controller.RecipeVisibilityResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Router       /recipe/{id}/share-link [get]
+func (h *Handler) GetRecipeShareLinkHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	recipe, ok := h.ownRecipe(c)
+	if !ok {
+		return
+	}
+
+	if recipe.ShareToken == nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.ShareLinkUnavailable)})
+		return
+	}
+
+	c.JSON(http.StatusOK, visibilityResponse(loc.T(messages.Common.Success), recipe))
+}
+
+// PostRotateRecipeShareLinkHandler godoc
+// @Summary      Renew the share token of an unlisted recipe
+// @Description  Replaces the share token, so everyone holding the old link loses access.
+// @Tags         recipes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Recipe ID"
+// @Success      200  {object}  controller.RecipeVisibilityResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /recipe/{id}/share-link [post]
+func (h *Handler) PostRotateRecipeShareLinkHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	recipe, ok := h.ownRecipe(c)
+	if !ok {
+		return
+	}
+
+	if recipe.Visibility != model.VisibilityUnlisted {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.ShareLinkUnavailable)})
+		return
+	}
+
+	recipe.ShareToken = nil
+	if _, ok := setRecipeVisibility(recipe, string(model.VisibilityUnlisted)); !ok {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.VisibilityUpdateFail)})
+		return
+	}
+	if err := h.app.Repos.Recipes.Save(recipe); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.VisibilityUpdateFail)})
+		return
+	}
+
+	c.JSON(http.StatusOK, visibilityResponse(loc.T(messages.Recipe.ShareLinkRotated), recipe))
+}
+
+// GetSharedRecipeHandler godoc
+// @Summary      Open an unlisted recipe by its share token
+// @Description  Works without logging in. Ingredients, comments and images of the recipe are available by passing the same token as the share_token query parameter.
+// @Tags         recipes
+// @Produce      json
+// @Param        token     path   string  true   "Share token"
+// @Param        servings  query  int     false  "Scale the recipe to this many servings"
+// @Param        units     query  string  false  "Measurement system: metric, imperial or original"
+// @Success      200  {object}  controller.RecipeWithImageIDs
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /shared/{token} [get]
+func (h *Handler) GetSharedRecipeHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	recipe, err := h.app.Repos.Recipes.FindByShareToken(c.Param("token"), "Ingredients", "Comments", "User", "Tags", "Categories", "Steps")
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
+		return
+	}
+
+	if recipe.Status != model.StatusPublished &&
+		!policy.Can(middleware.Subject(c), policy.Recipe, policy.ReadUnpublished, recipe.UserID) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.RecipeNotFound)})
+		return
+	}
+
+	h.writeRecipeDetail(c, recipe)
+}
+
+// GetRecipeInvitesHandler godoc
+// @Summary      List the users invited to a recipe
+// @Tags         recipes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path  int  true  "Recipe ID"
+// @Success      200  {object}  controller.RecipeInvitesResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /recipe/{id}/invites [get]
+func (h *Handler) GetRecipeInvitesHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	recipe, ok := h.ownRecipe(c)
+	if !ok {
+		return
+	}
+
+	users, err := h.app.Repos.Invites.ListByRecipe(recipe.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.InvitesFetchFail)})
+		return
+	}
+
+	c.JSON(http.StatusOK, RecipeInvitesResponse{Message: loc.T(messages.Common.Success), Data: users})
+}
+
+// PostRecipeInviteHandler godoc
+// @Summary      Invite a user to a private recipe
+// @Description  The user is identified by user_id or email. Invited users can see the recipe while it is private.
+// @Tags         recipes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path  int                  true  "Recipe ID"
+// @Param        invite  body  RecipeInviteRequest  true  "User to invite"
+// @Success      200  {object}  controller.SimpleMessageResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /recipe/{id}/invites [post]
+func (h *Handler) PostRecipeInviteHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	var req RecipeInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if req.UserID == 0 && req.Email == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.InviteUserRequired)})
+		return
+	}
+
+	recipe, ok := h.ownRecipe(c)
+	if !ok {
+		return
+	}
+
+	var (
+		user *model.User
+		err  error
+	)
+	if req.UserID != 0 {
+		user, err = h.app.Repos.Users.FindByID(req.UserID)
+	} else {
+		user, err = h.app.Repos.Users.FindByEmail(req.Email)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.User.UserNotFound)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.InviteFail)})
+		return
+	}
+
+	if user.ID == recipe.UserID {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: loc.T(messages.Recipe.InviteSelf)})
+		return
+	}
+
+	if err := h.app.Repos.Invites.Create(&model.RecipeInvite{RecipeID: recipe.ID, UserID: user.ID}); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.InviteFail)})
+		return
+	}
+
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.Recipe.InviteAdded)})
+}
+
+// DeleteRecipeInviteHandler godoc
+// @Summary      Withdraw the invitation of a user
+// @Tags         recipes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path  int  true  "Recipe ID"
+// @Param        userId  path  int  true  "Invited user ID"
+// @Success      200  {object}  controller.SimpleMessageResponse
+// @Failure      400  {object}  controller.ErrorResponse
+// @Failure      403  {object}  controller.ErrorResponse
+// @Failure      404  {object}  controller.ErrorResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /recipe/{id}/invites/{userId} [delete]
+func (h *Handler) DeleteRecipeInviteHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	userID, err := utils.ValidateEntityID(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	recipe, ok := h.ownRecipe(c)
+	if !ok {
+		return
+	}
+
+	if err := h.app.Repos.Invites.Delete(recipe.ID, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: loc.T(messages.Recipe.InviteNotFound)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.InviteFail)})
+		return
+	}
+
+	c.JSON(http.StatusOK, SimpleMessageResponse{Message: loc.T(messages.Recipe.InviteRemoved)})
+}
+
+// GetSharedWithMeHandler godoc
+// @Summary      List private recipes shared with the caller
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        limit   query  int  false  "Limit number of recipes returned"
+// @Param        offset  query  int  false  "Page number"
+// @Success      200  {object}  controller.RecipeListWithImagesResponse
+// @Failure      500  {object}  controller.ErrorResponse
+// @Router       /user/shared-recipes [get]
+func (h *Handler) GetSharedWithMeHandler(c *gin.Context) {
+	loc := middleware.Localizer(c)
+
+	limit, offset, _ := utils.ValidateOffLimit(c.Query("limit"), c.Query("offset"))
+	if limit == 0 {
+		limit = 10
+	}
+
+	recipes, total, err := h.app.Repos.Recipes.ListSharedWith(c.GetUint("userID"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: loc.T(messages.Recipe.RecipeFetchFail)})
+		return
+	}
+
+	c.JSON(http.StatusOK, RecipeListWithImagesResponse{
+		Message: loc.T(messages.Common.Success),
+		Data:    h.recipeListResponse(recipes),
+		Count:   total,
+	})
+}
@@ -0,0 +1,194 @@
+// Package dietary derives allergens and diets from ingredient names. A
+// built-in knowledge base maps foods and their synonyms to the 14 allergens
+// of EU regulation 1169/2011 and to the animal products that rule out a
+// diet. Ingredients it does not know are assumed to be free of all of them.
+package dietary
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Allergen is one of the 14 allergens that must be declared in the EU.
+type Allergen string
+
+const (
+	Celery     Allergen = "celery"
+	Gluten     Allergen = "gluten"
+	Crustacean Allergen = "crustacean"
+	Egg        Allergen = "egg"
+	Fish       Allergen = "fish"
+	Lupin      Allergen = "lupin"
+	Milk       Allergen = "milk"
+	Mollusc    Allergen = "mollusc"
+	Mustard    Allergen = "mustard"
+	TreeNut    Allergen = "tree_nut"
+	Peanut     Allergen = "peanut"
+	Sesame     Allergen = "sesame"
+	Soy        Allergen = "soy"
+	Sulphite   Allergen = "sulphite"
+)
+
+// Allergens lists every allergen.
+var Allergens = []Allergen{
+	Celery, Gluten, Crustacean, Egg, Fish, Lupin, Milk,
+	Mollusc, Mustard, TreeNut, Peanut, Sesame, Soy, Sulphite,
+}
+
+// Diet is a dietary label a recipe may carry.
+type Diet string
+
+const (
+	Vegan       Diet = "vegan"
+	Vegetarian  Diet = "vegetarian"
+	Pescatarian Diet = "pescatarian"
+	GlutenFree  Diet = "gluten_free"
+	DairyFree   Diet = "dairy_free"
+	NutFree     Diet = "nut_free"
+)
+
+// Diets lists every diet.
+var Diets = []Diet{Vegan, Vegetarian, Pescatarian, GlutenFree, DairyFree, NutFree}
+
+// ParseAllergen validates an allergen name from a request.
+func ParseAllergen(s string) (Allergen, bool) {
+	for _, a := range Allergens {
+		if string(a) == s {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+// ParseDiet validates a diet name from a request.
+func ParseDiet(s string) (Diet, bool) {
+	for _, d := range Diets {
+		if string(d) == s {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+// trait is what an ingredient contains: an allergen or one of the animal
+// products below.
+type trait string
+
+const (
+	meat    trait = "meat"
+	honey   trait = "honey"
+	gelatin trait = "gelatin"
+)
+
+// excludes lists the traits that rule out each diet.
+var excludes = map[Diet][]trait{
+	Vegan:       {meat, trait(Fish), trait(Crustacean), trait(Mollusc), gelatin, trait(Milk), trait(Egg), honey},
+	Vegetarian:  {meat, trait(Fish), trait(Crustacean), trait(Mollusc), gelatin},
+	Pescatarian: {meat, gelatin},
+	GlutenFree:  {trait(Gluten)},
+	DairyFree:   {trait(Milk)},
+	NutFree:     {trait(TreeNut), trait(Peanut)},
+}
+
+// Labels are the allergens a recipe contains and the diets it fits.
+type Labels struct {
+	Allergens []string
+	Diets     []string
+}
+
+// Analyze derives the labels of a recipe from its ingredient names.
+func Analyze(ingredients []string) Labels {
+	found := map[trait]bool{}
+	for _, name := range ingredients {
+		for t := range traitsOf(name) {
+			found[t] = true
+		}
+	}
+
+	labels := Labels{Allergens: []string{}, Diets: []string{}}
+	for _, a := range Allergens {
+		if found[trait(a)] {
+			labels.Allergens = append(labels.Allergens, string(a))
+		}
+	}
+	for _, d := range Diets {
+		fits := true
+		for _, t := range excludes[d] {
+			if found[t] {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			labels.Diets = append(labels.Diets, string(d))
+		}
+	}
+	return labels
+}
+
+// traitsOf looks the words of an ingredient name up in the knowledge base.
+// Longer phrases win, so "peanut butter" is a peanut and not milk, and
+// modifiers such as "gluten-free" or "vegan" cancel what they deny.
+func traitsOf(ingredient string) map[trait]bool {
+	words := nameWords(ingredient)
+
+	var cancelled []trait
+	var rest []string
+	for i := 0; i < len(words); i++ {
+		if i+1 < len(words) && words[i+1] == "free" {
+			if ts, ok := freeFrom[words[i]]; ok {
+				cancelled = append(cancelled, ts...)
+				i++
+				continue
+			}
+		}
+		if ts, ok := plantBased[words[i]]; ok {
+			cancelled = append(cancelled, ts...)
+			continue
+		}
+		rest = append(rest, words[i])
+	}
+
+	traits := map[trait]bool{}
+	for i := 0; i < len(rest); {
+		n := min(maxPhraseWords, len(rest)-i)
+		for ; n > 0; n-- {
+			if ts, ok := knowledge[strings.Join(rest[i:i+n], " ")]; ok {
+				for _, t := range ts {
+					traits[t] = true
+				}
+				break
+			}
+		}
+		i += max(n, 1)
+	}
+	for _, t := range cancelled {
+		delete(traits, t)
+	}
+	return traits
+}
+
+// nameWords splits a name into lower-case singular words.
+func nameWords(name string) []string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for i, w := range words {
+		words[i] = singular(w)
+	}
+	return words
+}
+
+func singular(w string) string {
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "oes") && len(w) > 4:
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && len(w) > 3:
+		return w[:len(w)-1]
+	}
+	return w
+}
@@ -0,0 +1,146 @@
+package dietary
+
+import "strings"
+
+// foods is the knowledge base: ingredient names and their synonyms, in the
+// singular and lower case, grouped by what they contain. Names that contain
+// another name, such as "peanut butter" and "butter", override it.
+var foods = []struct {
+	names  []string
+	traits []trait
+}{
+	// Plant foods whose names would otherwise match an entry below.
+	{names: []string{
+		"coconut milk", "coconut cream", "coconut butter", "cocoa butter", "shea butter",
+		"cream of tartar", "rice flour", "corn flour", "cornflour", "coconut flour",
+		"chickpea flour", "gram flour", "buckwheat flour", "potato flour", "tapioca flour",
+		"rice noodle", "rice paper", "corn tortilla", "rice vinegar", "apple cider vinegar",
+		"nutmeg", "butternut squash", "eggplant", "butter bean", "water chestnut",
+		"pine nut", "tiger nut", "ginger ale", "root beer",
+	}},
+
+	{names: []string{
+		"wheat", "flour", "bread", "breadcrumb", "panko", "crouton", "pasta", "spaghetti",
+		"macaroni", "penne", "fusilli", "linguine", "fettuccine", "lasagna", "lasagne",
+		"tagliatelle", "ravioli", "tortellini", "gnocchi", "noodle", "udon", "ramen",
+		"couscous", "bulgur", "semolina", "durum", "spelt", "farro", "barley", "rye", "oat",
+		"oatmeal", "malt", "seitan", "tortilla", "pita", "naan", "bagel", "croissant",
+		"baguette", "brioche", "pastry", "puff pastry", "phyllo", "filo", "pie crust",
+		"cracker", "biscuit", "cookie", "cake", "muffin", "pancake mix", "beer", "ale",
+		"bran", "wheat germ", "graham cracker", "self raising flour", "self rising flour",
+	}, traits: []trait{trait(Gluten)}},
+
+	{names: []string{
+		"milk", "whole milk", "skim milk", "butter", "buttermilk", "cream", "heavy cream",
+		"whipping cream", "double cream", "single cream", "sour cream", "creme fraiche",
+		"ice cream", "condensed milk", "evaporated milk", "milk powder", "cheese",
+		"cream cheese", "cottage cheese", "goat cheese", "parmesan", "parmigiano",
+		"pecorino", "mozzarella", "cheddar", "feta", "ricotta", "mascarpone", "brie",
+		"camembert", "gouda", "gruyere", "emmental", "halloumi", "paneer", "burrata",
+		"provolone", "yogurt", "yoghurt", "greek yogurt", "kefir", "ghee", "whey", "casein",
+		"lactose", "custard", "milk chocolate", "white chocolate", "bechamel",
+	}, traits: []trait{trait(Milk)}},
+	{names: []string{"pesto"}, traits: []trait{trait(Milk), trait(TreeNut)}},
+	{names: []string{"alfredo sauce"}, traits: []trait{trait(Milk)}},
+
+	{names: []string{
+		"egg", "egg white", "egg yolk", "yolk", "mayonnaise", "mayo", "aioli", "meringue",
+		"egg noodle", "hollandaise",
+	}, traits: []trait{trait(Egg)}},
+	{names: []string{"egg pasta", "fresh pasta"}, traits: []trait{trait(Egg), trait(Gluten)}},
+
+	{names: []string{
+		"fish", "salmon", "tuna", "cod", "trout", "sardine", "mackerel", "haddock",
+		"tilapia", "halibut", "sea bass", "seabass", "bass", "anchovy", "herring", "pollock",
+		"swordfish", "snapper", "catfish", "carp", "sole", "fish sauce", "fish stock",
+		"worcestershire sauce", "caviar", "roe", "bonito", "dashi",
+	}, traits: []trait{trait(Fish)}},
+	{names: []string{
+		"shrimp", "prawn", "crab", "lobster", "crayfish", "langoustine", "krill",
+		"shrimp paste",
+	}, traits: []trait{trait(Crustacean)}},
+	{names: []string{
+		"mussel", "clam", "oyster", "scallop", "squid", "calamari", "octopus", "snail",
+		"escargot", "cuttlefish", "oyster sauce", "abalone",
+	}, traits: []trait{trait(Mollusc)}},
+
+	{names: []string{
+		"almond", "hazelnut", "walnut", "cashew", "pecan", "pistachio", "macadamia",
+		"brazil nut", "nut", "mixed nut", "almond milk", "almond flour", "ground almond",
+		"almond butter", "cashew butter", "marzipan", "praline", "nougat", "frangipane",
+		"amaretto", "gianduja", "hazelnut spread",
+	}, traits: []trait{trait(TreeNut)}},
+	{names: []string{"nutella"}, traits: []trait{trait(TreeNut), trait(Milk)}},
+	{names: []string{
+		"peanut", "peanut butter", "groundnut", "peanut oil", "satay sauce",
+	}, traits: []trait{trait(Peanut)}},
+
+	{names: []string{
+		"soy", "soya", "soybean", "soy milk", "soya milk", "tofu", "tempeh", "edamame",
+		"miso", "tamari", "natto", "soy lecithin", "textured vegetable protein",
+	}, traits: []trait{trait(Soy)}},
+	{names: []string{"soy sauce", "soya sauce", "teriyaki sauce", "hoisin sauce"},
+		traits: []trait{trait(Soy), trait(Gluten)}},
+
+	{names: []string{"sesame", "sesame seed", "sesame oil", "tahini", "hummus", "halva", "halvah"},
+		traits: []trait{trait(Sesame)}},
+	{names: []string{"mustard", "dijon", "mustard seed", "mustard powder"},
+		traits: []trait{trait(Mustard)}},
+	{names: []string{"celery", "celeriac", "celery salt", "celery seed"},
+		traits: []trait{trait(Celery)}},
+	{names: []string{"lupin", "lupine", "lupin flour"}, traits: []trait{trait(Lupin)}},
+	{names: []string{
+		"wine", "red wine", "white wine", "sherry", "port", "marsala", "vermouth",
+		"wine vinegar", "red wine vinegar", "white wine vinegar", "balsamic vinegar",
+		"dried apricot", "dried fruit", "raisin", "sultana", "sulphite", "sulfite",
+	}, traits: []trait{trait(Sulphite)}},
+
+	{names: []string{
+		"meat", "beef", "ground beef", "minced beef", "steak", "veal", "pork", "ground pork",
+		"bacon", "pancetta", "ham", "prosciutto", "chorizo", "salami", "pepperoni",
+		"sausage", "hot dog", "chicken", "turkey", "duck", "goose", "lamb", "mutton",
+		"goat", "venison", "rabbit", "mince", "meatball", "lard", "suet", "tallow",
+		"chicken stock", "beef stock", "chicken broth", "beef broth", "bone broth",
+		"liver", "oxtail", "brisket",
+	}, traits: []trait{meat}},
+	{names: []string{"gelatin", "gelatine", "marshmallow"}, traits: []trait{gelatin}},
+	{names: []string{"honey"}, traits: []trait{honey}},
+}
+
+// freeFrom maps the word before "free" to what "<word>-free" rules out.
+var freeFrom = map[string][]trait{
+	"gluten":   {trait(Gluten)},
+	"wheat":    {trait(Gluten)},
+	"dairy":    {trait(Milk)},
+	"lactose":  {trait(Milk)},
+	"milk":     {trait(Milk)},
+	"egg":      {trait(Egg)},
+	"nut":      {trait(TreeNut), trait(Peanut)},
+	"peanut":   {trait(Peanut)},
+	"soy":      {trait(Soy)},
+	"sulphite": {trait(Sulphite)},
+	"sulfite":  {trait(Sulphite)},
+}
+
+// plantBased maps words such as "vegan" to the animal products they rule
+// out, so "vegan butter" is not milk.
+var plantBased = map[string][]trait{
+	"vegan":      {meat, gelatin, honey, trait(Milk), trait(Egg), trait(Fish), trait(Crustacean), trait(Mollusc)},
+	"vegetarian": {meat, gelatin, trait(Fish), trait(Crustacean), trait(Mollusc)},
+	"plant":      {meat, gelatin, honey, trait(Milk), trait(Egg), trait(Fish), trait(Crustacean), trait(Mollusc)},
+	"meatless":   {meat},
+}
+
+var (
+	knowledge      = map[string][]trait{}
+	maxPhraseWords int
+)
+
+func init() {
+	for _, f := range foods {
+		for _, name := range f.names {
+			knowledge[name] = f.traits
+			maxPhraseWords = max(maxPhraseWords, len(strings.Fields(name)))
+		}
+	}
+}
@@ -59,7 +59,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/controller.TimeSeriesData"
+                                "$ref": "#/definitions/repository.TimeSeriesData"
                             }
                         }
                     },
@@ -84,6 +84,273 @@ const docTemplate = `{
                 }
             }
         },
+        "/admin/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the background jobs, newest first, with the number of jobs per status. Filter by status=dead to see the jobs that failed for good.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "List background jobs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "running",
+                            "done",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "Job status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Job type, e.g. nutrition.estimate",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit number of jobs returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.JobListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns one background job with its payload, attempts and last error.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get a background job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Puts a job that failed for good back in the queue with a fresh set of attempts. Only dead jobs can be retried.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Retry a dead background job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/user/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Assigns one of the roles user, moderator or admin to a user. Admins cannot change their own role.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change a user's role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UpdateUserRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/admin/user/{userID}/comment/{id}": {
             "delete": {
                 "security": [
@@ -1041,7 +1308,7 @@ const docTemplate = `{
         },
         "/ingredient/{id}": {
             "get": {
-                "description": "Retrieve an ingredient by its ID",
+                "description": "Retrieve an ingredient by its ID. Ingredients of recipes the caller may not see are not found; pass share_token for unlisted recipes.",
                 "tags": [
                     "ingredients"
                 ],
@@ -1053,6 +1320,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Share token of an unlisted recipe",
+                        "name": "share_token",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1177,14 +1450,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/rating": {
+        "/logout": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Adds a new rating for the recipe by the current user (from JWT) or updates the score if one already exists. Score must be between 1 and 5.",
+                "description": "Revokes the current access token and, if provided, the refresh token family",
                 "consumes": [
                     "application/json"
                 ],
@@ -1192,17 +1465,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "auth"
                 ],
-                "summary": "Add or update a rating for a recipe",
+                "summary": "Logout",
                 "parameters": [
                     {
-                        "description": "Rating data",
-                        "name": "rating",
+                        "description": "Refresh token to revoke",
+                        "name": "data",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.PostRatingRequest"
+                            "$ref": "#/definitions/controller.LogoutRequest"
                         }
                     }
                 ],
@@ -1210,11 +1482,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.RatingResponse"
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request or score",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
@@ -1225,73 +1497,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Recipe not found",
-                        "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/rating/{id}": {
-            "put": {
-                "description": "Update the score of an existing rating by its ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "ratings"
-                ],
-                "summary": "Update a rating by ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Rating ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated rating data",
-                        "name": "rating",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Rating"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/controller.RatingResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
@@ -1299,53 +1506,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/ratings": {
+        "/pantry": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve a paginated list of all ratings with user and recipe details",
-                "tags": [
-                    "ratings"
+                "description": "Lists the ingredients the current user has at home, by name.",
+                "produces": [
+                    "application/json"
                 ],
-                "summary": "Get all ratings",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Number of items per page",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Pagination offset",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "default": "date_desc",
-                        "description": "Sort order: score_asc, score_desc, date_asc, date_desc",
-                        "name": "sortOrder",
-                        "in": "query"
-                    }
+                "tags": [
+                    "pantry"
                 ],
+                "summary": "List the pantry",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
+                            "$ref": "#/definitions/controller.PantryListResponse"
                         }
                     },
                     "401": {
@@ -1361,11 +1541,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/recipe": {
+            },
             "post": {
-                "description": "Create a new recipe with ingredients, tags (by IDs or names), categories, steps, and returns the new recipe including image IDs",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds an ingredient the current user has at home. Names are compared ignoring case, plurals and words such as \"fresh\" or \"chopped\", so each ingredient can only be added once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1373,17 +1556,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "recipes"
+                    "pantry"
                 ],
-                "summary": "Create a new recipe",
+                "summary": "Add an ingredient to the pantry",
                 "parameters": [
                     {
-                        "description": "Recipe data",
-                        "name": "recipe",
+                        "description": "Ingredient",
+                        "name": "item",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.PostRecipeRequest"
+                            "$ref": "#/definitions/controller.PantryItemRequest"
                         }
                     }
                 ],
@@ -1391,7 +1574,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/controller.RecipeWithImageIDs"
+                            "$ref": "#/definitions/controller.PantryItemResponse"
                         }
                     },
                     "400": {
@@ -1400,6 +1583,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1409,63 +1604,80 @@ const docTemplate = `{
                 }
             }
         },
-        "/recipe/list": {
+        "/pantry/recipes": {
             "get": {
-                "description": "Retrieve a paginated list of recipes with total count, optionally filtered by title, ingredient, tags, categories, user, and sorted by title, creation date, rating, or favorites",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ranks recipes by the share of their ingredients in the current user's pantry, best covered first, and lists the missing ingredients of each. Only recipes with at least one ingredient in the pantry are returned. Ingredient names are compared ignoring case, plurals and words such as \"fresh\" or \"chopped\", and a pantry ingredient covers the recipe ingredients whose words it all has, so \"chicken breast\" covers \"chicken\" but \"chicken\" does not cover \"chicken breast\". With ignore_staples, ingredients most kitchens have, such as salt and water, are left out of the count, including more specific ones such as \"sea salt\". The filters of /recipes/search apply as well.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recipes"
+                    "pantry"
                 ],
-                "summary": "Get all recipes with pagination, filtering, and sorting",
+                "summary": "Find recipes to cook from the pantry",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Limit number of recipes returned",
-                        "name": "limit",
+                        "description": "Only recipes missing at most this many ingredients",
+                        "name": "max_missing",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Number of recipes to skip",
-                        "name": "offset",
+                        "type": "boolean",
+                        "description": "Leave staples such as salt, pepper and water out of the count",
+                        "name": "ignore_staples",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Sort order: title_asc, title_desc, created_asc, created_desc, rating_desc, favorites_desc",
-                        "name": "sort",
+                        "description": "Filter by recipe title (partial match)",
+                        "name": "title",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by recipe title (partial match)",
-                        "name": "title",
+                        "description": "Filter by tag IDs (comma-separated)",
+                        "name": "tag_ids",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by ingredient name (partial match)",
-                        "name": "ingredient",
+                        "description": "Filter by category IDs (comma-separated)",
+                        "name": "category_ids",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by tag IDs (comma-separated)",
-                        "name": "tag_ids",
+                        "description": "Only recipes fitting every listed diet (comma-separated)",
+                        "name": "diet",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by category IDs (comma-separated)",
-                        "name": "category_ids",
+                        "description": "Leave out recipes containing any listed allergen (comma-separated)",
+                        "name": "exclude_allergens",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Filter by user ID",
-                        "name": "user_id",
+                        "description": "Only recipes ready in at most this many minutes",
+                        "name": "max_total_minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit number of recipes returned, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "offset",
                         "in": "query"
                     }
                 ],
@@ -1473,7 +1685,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.RecipeListWithImagesResponse"
+                            "$ref": "#/definitions/controller.PantryMatchResponse"
                         }
                     },
                     "400": {
@@ -1482,6 +1694,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1491,27 +1709,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/recipe/{id}": {
-            "get": {
-                "description": "Get detailed recipe info including ingredients, comments, tags, categories, steps, and image IDs",
+        "/pantry/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes the name of an ingredient in the current user's pantry.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "recipes"
+                    "pantry"
                 ],
-                "summary": "Get recipe by ID",
+                "summary": "Rename a pantry ingredient",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Recipe ID",
+                        "description": "Pantry item ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Ingredient",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.PantryItemRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.RecipeWithImageIDs"
+                            "$ref": "#/definitions/controller.PantryItemResponse"
                         }
                     },
                     "400": {
@@ -1520,12 +1758,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1534,34 +1784,27 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
-                "description": "Updates a recipe by ID. Replaces title, text, ingredients, steps, tags, and categories.",
-                "consumes": [
-                    "application/json"
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Removes an ingredient from the current user's pantry.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recipes"
+                    "pantry"
                 ],
-                "summary": "Update an existing recipe",
+                "summary": "Remove an ingredient from the pantry",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Recipe ID",
+                        "description": "Pantry item ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated recipe data",
-                        "name": "recipe",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object"
-                        }
                     }
                 ],
                 "responses": {
@@ -1574,131 +1817,129 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/recipe/{id}/calories": {
-            "get": {
-                "description": "Estimate nutrition info based on ingredients via AI model",
-                "tags": [
-                    "recipes"
-                ],
-                "summary": "Get nutritional values for a recipe",
-                "parameters": [
+        "/rating": {
+            "post": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "Recipe ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "BearerAuth": []
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/controller.NutritionResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/recipe/{id}/categories": {
-            "get": {
-                "description": "Retrieves all categories associated with the specified recipe.",
+                "description": "Adds a new rating for the recipe by the current user (from JWT) or updates the score if one already exists. Score must be between 1 and 5.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recipes"
+                    "ratings"
                 ],
-                "summary": "Get categories for a recipe",
+                "summary": "Add or update a rating for a recipe",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Recipe ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Rating data",
+                        "name": "rating",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.PostRatingRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.CategoriesResponse"
+                            "$ref": "#/definitions/controller.RatingResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid request or score",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Not Found",
+                        "description": "Recipe not found",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Clears all categories associated with the specified recipe.",
+            }
+        },
+        "/rating/{id}": {
+            "put": {
+                "description": "Update the score of an existing rating by its ID",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recipes"
+                    "ratings"
                 ],
-                "summary": "Remove all categories from a recipe",
+                "summary": "Update a rating by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Recipe ID",
+                        "description": "Rating ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Updated rating data",
+                        "name": "rating",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Rating"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                            "$ref": "#/definitions/controller.RatingResponse"
                         }
                     },
                     "400": {
@@ -1722,40 +1963,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/recipe/{id}/comments": {
+        "/ratings": {
             "get": {
-                "description": "Retrieve a paginated list of comments for a specific recipe with total count, optionally sorted by likes or date",
-                "produces": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Retrieve a paginated list of all ratings with user and recipe details. Callers who may not read every unpublished and private recipe only see ratings of published, public ones.",
                 "tags": [
-                    "comments"
+                    "ratings"
                 ],
-                "summary": "Get all comments for a recipe with pagination and sorting",
+                "summary": "Get all ratings",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Recipe ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit number of comments returned",
+                        "default": 10,
+                        "description": "Number of items per page",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Number of comments to skip",
+                        "default": 0,
+                        "description": "Pagination offset",
                         "name": "offset",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Sort order: likes_desc, likes_asc, date_asc, date_desc",
-                        "name": "sort",
+                        "default": "date_desc",
+                        "description": "Sort order: score_asc, score_desc, date_asc, date_desc",
+                        "name": "sortOrder",
                         "in": "query"
                     }
                 ],
@@ -1763,7 +2002,8 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.CommentsResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -1772,8 +2012,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
@@ -1787,202 +2027,269 @@ const docTemplate = `{
                 }
             }
         },
-        "/recipe/{id}/image": {
+        "/recipe": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Uploads an image file and associates it with a recipe (must belong to the authenticated user)",
+                "description": "Create a new recipe with ingredients, tags (by IDs or names), categories, steps, and returns the new recipe including image IDs",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "images"
+                    "recipes"
                 ],
-                "summary": "Upload image for a recipe",
+                "summary": "Create a new recipe",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Recipe ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "Image file (jpeg, png, webp)",
-                        "name": "image",
-                        "in": "formData",
-                        "required": true
+                        "description": "Recipe data",
+                        "name": "recipe",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.PostRecipeRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Image uploaded successfully",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controller.RecipeWithImageIDs"
                         }
                     },
                     "400": {
-                        "description": "Invalid input or file error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "403": {
-                        "description": "Not authorized to upload for this recipe",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/recipe/{id}/image/{imageId}": {
+        "/recipe/list": {
             "get": {
-                "description": "Retrieves and serves an image associated with a recipe",
+                "description": "Retrieve a paginated list of recipes with total count, optionally filtered by title, ingredient, tags, categories, user, and sorted by title, creation date, rating, or favorites. Nutrient ranges are given as min_\u003cnutrient\u003e and max_\u003cnutrient\u003e for calories, protein, fat, saturated_fat, carbs, fiber, sugar, cholesterol, sodium and potassium, time ranges as min_\u003ctime\u003e and max_\u003ctime\u003e for prep_minutes, cook_minutes and total_minutes. A filter expression combines field:value terms with AND, OR, NOT (or - before a term) and parentheses; a space also means AND. Fields are tag and category (name or ID), ingredient and title (partial match), author (user ID), cuisine, difficulty, diet, allergen, equipment, and rating, votes, created (a date), the nutrients and the times, which take a comparison such as rating:\u003e=4 or total_minutes:\u003c30. Values with spaces go in double quotes, as in ingredient:\"olive oil\".",
                 "produces": [
-                    "image/*"
+                    "application/json"
                 ],
                 "tags": [
-                    "images"
+                    "recipes"
                 ],
-                "summary": "Serve recipe image by ID",
+                "summary": "Get all recipes with pagination, filtering, and sorting",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Recipe ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Limit number of recipes returned",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Image ID",
-                        "name": "imageId",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Image file served"
+                        "description": "Number of recipes to skip",
+                        "name": "offset",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Invalid ID parameters",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                    {
+                        "type": "string",
+                        "description": "Sort order: title_asc, title_desc, created_asc, created_desc, rating_desc, favorites_desc, or calories, protein, fat, carbs, fiber, sugar, prep_time, cook_time, total_time or difficulty followed by _asc or _desc",
+                        "name": "sortOrder",
+                        "in": "query"
                     },
-                    "404": {
-                        "description": "Image not found",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "security": [
                     {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Deletes an image associated with a recipe (must belong to the authenticated user)",
-                "tags": [
-                    "images"
-                ],
-                "summary": "Delete a recipe image by ID",
-                "parameters": [
+                        "type": "string",
+                        "description": "Filter by recipe title (partial match)",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by ingredient name (partial match)",
+                        "name": "ingredient",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tag IDs (comma-separated)",
+                        "name": "tag_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by category IDs (comma-separated)",
+                        "name": "category_ids",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Recipe ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by user ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Only recipes rated at least this on average; rating is accepted as well",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Publication status (admins only, everyone else gets published recipes)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Visibility (admins only, everyone else gets public recipes)",
+                        "name": "visibility",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apply the nutrient ranges to one serving instead of the whole recipe",
+                        "name": "per_serving",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only recipes fitting every listed diet (comma-separated): vegan, vegetarian, pescatarian, gluten_free, dairy_free, nut_free",
+                        "name": "diet",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Leave out recipes containing any listed allergen (comma-separated): celery, gluten, crustacean, egg, fish, lupin, milk, mollusc, mustard, tree_nut, peanut, sesame, soy, sulphite",
+                        "name": "exclude_allergens",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Image ID",
-                        "name": "imageId",
-                        "in": "path",
-                        "required": true
+                        "description": "Only recipes with at least this many ratings",
+                        "name": "min_votes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Leave out recipes with any listed ingredient (comma-separated, partial match)",
+                        "name": "exclude_ingredients",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Leave out recipes with any listed tag (comma-separated IDs)",
+                        "name": "exclude_tag_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Leave out recipes in any listed category (comma-separated IDs)",
+                        "name": "exclude_category_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Leave out recipes by any listed author (comma-separated IDs)",
+                        "name": "exclude_user_ids",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Whether recipes need any or all of tag_ids",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Whether recipes need any or all of category_ids",
+                        "name": "category_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only recipes created at or after this date (YYYY-MM-DD, in the server's time zone) or time (RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only recipes created at or before this date (the whole day) or time",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, e.g. tag:vegan AND NOT ingredient:nuts; see the description",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by difficulty (comma-separated), see /recipes/options",
+                        "name": "difficulty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by cuisine (comma-separated), see /recipes/options",
+                        "name": "cuisine",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only recipes using every listed piece of equipment (comma-separated), see /recipes/options",
+                        "name": "equipment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only recipes ready in at most this many minutes; min_/max_ also work for prep_minutes and cook_minutes",
+                        "name": "max_total_minutes",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Image deleted successfully",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.RecipeListWithImagesResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID parameters",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "403": {
-                        "description": "Not authorized to delete this recipe image",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/recipe/{id}/ingredients": {
+        "/recipe/{id}": {
             "get": {
-                "description": "Retrieve all ingredients for a specific recipe by ID",
+                "description": "Get detailed recipe info including ingredients, comments, tags, categories, steps, and image IDs. With servings the ingredient amounts and nutrition are scaled to that many servings.",
                 "tags": [
                     "recipes"
                 ],
-                "summary": "Get all ingredients for a recipe",
+                "summary": "Get recipe by ID",
                 "parameters": [
                     {
                         "type": "integer",
@@ -1990,13 +2297,25 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Scale the recipe to this many servings",
+                        "name": "servings",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Measurement system: metric, imperial or original (defaults to the user's preference)",
+                        "name": "units",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.IngredientsResponse"
+                            "$ref": "#/definitions/controller.RecipeWithImageIDs"
                         }
                     },
                     "400": {
@@ -2018,15 +2337,19 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/recipe/{id}/rating": {
-            "get": {
-                "description": "Retrieve the average rating and count of ratings for a given recipe ID",
+            },
+            "put": {
+                "description": "Updates a recipe by ID. Replaces title, text, ingredients, steps, tags, and categories.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "ratings"
+                    "recipes"
                 ],
-                "summary": "Get average rating for a recipe",
+                "summary": "Update an existing recipe",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2034,43 +2357,52 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Updated recipe data",
+                        "name": "recipe",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.AverageRatingResponse"
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
                         }
                     }
                 }
             }
         },
-        "/recipe/{id}/tags": {
+        "/recipe/{id}/calories": {
             "get": {
-                "description": "Retrieves all tags associated with the specified recipe",
+                "description": "Returns the nutrition of every ingredient, the recipe totals and one serving, as estimated when the recipe was saved. The report lists the ingredients no nutrition provider could match; the totals leave them out.",
                 "tags": [
                     "recipes"
                 ],
-                "summary": "Get tags for a recipe",
+                "summary": "Get nutritional values for a recipe",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2084,7 +2416,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.TagsResponse"
+                            "$ref": "#/definitions/controller.NutritionResponse"
                         }
                     },
                     "400": {
@@ -2106,19 +2438,18 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Replaces the tags associated with the specified recipe.",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/recipe/{id}/categories": {
+            "get": {
+                "description": "Retrieves all categories associated with the specified recipe.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "recipes"
                 ],
-                "summary": "Update tags for a recipe",
+                "summary": "Get categories for a recipe",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2126,22 +2457,13 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "List of tag names",
-                        "name": "tags",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller.TagNamesInput"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.TagsResponse"
+                            "$ref": "#/definitions/controller.CategoriesResponse"
                         }
                     },
                     "400": {
@@ -2165,14 +2487,14 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Clears all tags associated with the specified recipe.",
+                "description": "Clears all categories associated with the specified recipe.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "recipes"
                 ],
-                "summary": "Remove all tags from a recipe",
+                "summary": "Remove all categories from a recipe",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2210,32 +2532,48 @@ const docTemplate = `{
                 }
             }
         },
-        "/recipes/most-popular": {
+        "/recipe/{id}/comments": {
             "get": {
-                "description": "Get recipes sorted by number of favorites",
+                "description": "Retrieve a paginated list of comments for a specific recipe with total count, optionally sorted by likes or date",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "recipes"
+                    "comments"
                 ],
-                "summary": "Get most popular recipes by favorites",
+                "summary": "Get all comments for a recipe with pagination and sorting",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Limit number of recipes",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit number of comments returned",
                         "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Offset for pagination",
+                        "description": "Number of comments to skip",
                         "name": "offset",
                         "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order: likes_desc, likes_asc, date_asc, date_desc",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.MostPopularRecipesResponse"
+                            "$ref": "#/definitions/controller.CommentsResponse"
                         }
                     },
                     "400": {
@@ -2244,6 +2582,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2253,106 +2597,214 @@ const docTemplate = `{
                 }
             }
         },
-        "/recipes/search": {
-            "get": {
-                "description": "Search for recipes using various filters and retrieve a paginated list with total count, optionally sorted",
+        "/recipe/{id}/image": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Uploads an image file and associates it with a recipe (must belong to the authenticated user)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "recipes"
+                    "images"
                 ],
-                "summary": "Search recipes with pagination, filtering, and sorting",
+                "summary": "Upload image for a recipe",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Filter by recipe title (partial match)",
-                        "name": "title",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Filter by ingredient name (partial match)",
-                        "name": "ingredient",
-                        "in": "query"
+                        "type": "file",
+                        "description": "Image file (jpeg, png, webp)",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Image uploaded successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Filter by tag IDs (comma-separated)",
-                        "name": "tag_ids",
-                        "in": "query"
+                    "400": {
+                        "description": "Invalid input or file error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Filter by category IDs (comma-separated)",
-                        "name": "category_ids",
-                        "in": "query"
+                    "403": {
+                        "description": "Not authorized to upload for this recipe",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/recipe/{id}/image/{imageId}": {
+            "get": {
+                "description": "Retrieves and serves an image associated with a recipe",
+                "produces": [
+                    "image/*"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Serve recipe image by ID",
+                "parameters": [
                     {
-                        "type": "string",
-                        "description": "Filter by user ID",
-                        "name": "user_id",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Sort order: title_asc, title_desc, created_asc, created_desc, rating_desc, favorites_desc",
-                        "name": "sort",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Image ID",
+                        "name": "imageId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Image file served"
+                    },
+                    "400": {
+                        "description": "Invalid ID parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
+                    "404": {
+                        "description": "Image not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes an image associated with a recipe (must belong to the authenticated user)",
+                "tags": [
+                    "images"
+                ],
+                "summary": "Delete a recipe image by ID",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Limit number of recipes returned",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Number of recipes to skip",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Image ID",
+                        "name": "imageId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Image deleted successfully",
                         "schema": {
-                            "$ref": "#/definitions/controller.RecipeListWithImagesResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid ID parameters",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Not authorized to delete this recipe image",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/recipes/top-rated": {
+        "/recipe/{id}/ingredients": {
             "get": {
-                "description": "Get recipes sorted by average rating, with total votes count",
+                "description": "Retrieve all ingredients for a specific recipe by ID",
                 "tags": [
                     "recipes"
                 ],
-                "summary": "Get top rated recipes",
+                "summary": "Get all ingredients for a recipe",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Limit number of recipes",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Offset for pagination",
-                        "name": "offset",
+                        "type": "string",
+                        "description": "Measurement system: metric, imperial or original (defaults to the user's preference)",
+                        "name": "units",
                         "in": "query"
                     }
                 ],
@@ -2360,10 +2812,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/controller.TopRatedRecipe"
-                            }
+                            "$ref": "#/definitions/controller.IngredientsResponse"
                         }
                     },
                     "400": {
@@ -2372,6 +2821,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2381,35 +2836,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/reset-password": {
-            "post": {
-                "description": "Resets password using reset token",
-                "consumes": [
-                    "application/json"
+        "/recipe/{id}/invites": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "recipes"
                 ],
-                "summary": "Reset user password",
+                "summary": "List the users invited to a recipe",
                 "parameters": [
                     {
-                        "description": "Reset password data",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller.ResetPasswordRequest"
-                        }
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.ResetPasswordResponse"
+                            "$ref": "#/definitions/controller.RecipeInvitesResponse"
                         }
                     },
                     "400": {
@@ -2418,6 +2872,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2425,11 +2891,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/signup": {
+            },
             "post": {
-                "description": "Creates a new user account",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The user is identified by user_id or email. Invited users can see the recipe while it is private.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2437,17 +2906,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "recipes"
                 ],
-                "summary": "Register a new user",
+                "summary": "Invite a user to a private recipe",
                 "parameters": [
                     {
-                        "description": "User signup info",
-                        "name": "user",
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User to invite",
+                        "name": "invite",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.UserSignupRequest"
+                            "$ref": "#/definitions/controller.RecipeInviteRequest"
                         }
                     }
                 ],
@@ -2464,8 +2940,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
@@ -2479,35 +2961,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/tag": {
-            "post": {
-                "description": "Creates a new tag. Optionally associates it with recipes by IDs.",
-                "consumes": [
-                    "application/json"
+        "/recipe/{id}/invites/{userId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "recipes"
                 ],
-                "summary": "Create a new tag",
+                "summary": "Withdraw the invitation of a user",
                 "parameters": [
                     {
-                        "description": "Tag data",
-                        "name": "tag",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.Tag"
-                        }
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Invited user ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.TagResponse"
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
                         }
                     },
                     "400": {
@@ -2516,8 +3004,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
@@ -2531,20 +3025,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/tag/{id}": {
+        "/recipe/{id}/nutrition-label": {
             "get": {
-                "description": "Retrieves a tag by its ID",
+                "description": "Returns the nutrition-facts label of one serving: calories and the nutrients in label order, rounded as labels print them, with the percentage of the daily value for a 2,000 kcal diet. The report tells whether every ingredient was matched; otherwise the label understates the recipe.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "recipes"
                 ],
-                "summary": "Get a tag by ID",
+                "summary": "Get the nutrition-facts label of a recipe",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag ID",
+                        "description": "Recipe ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2554,7 +3048,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.TagResponse"
+                            "$ref": "#/definitions/controller.NutritionLabelResponse"
                         }
                     },
                     "400": {
@@ -2576,42 +3070,29 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Updates the name of a tag specified by its ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+            }
+        },
+        "/recipe/{id}/rating": {
+            "get": {
+                "description": "Retrieve the average rating and count of ratings for a given recipe ID",
                 "tags": [
-                    "tags"
+                    "ratings"
                 ],
-                "summary": "Update a tag by ID",
+                "summary": "Get average rating for a recipe",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag ID",
+                        "description": "Recipe ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated tag data",
-                        "name": "tag",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller.TagsResponse"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.TagResponse"
+                            "$ref": "#/definitions/controller.AverageRatingResponse"
                         }
                     },
                     "400": {
@@ -2633,30 +3114,55 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes a tag and removes all its associations with recipes",
+            }
+        },
+        "/recipe/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the version history of a recipe, newest first. Every change to the recipe, its ingredients, steps, tags or categories creates a revision.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "revisions"
                 ],
-                "summary": "Delete a tag by ID",
+                "summary": "List the revisions of a recipe",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Tag ID",
+                        "description": "Recipe ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit number of revisions returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                            "$ref": "#/definitions/controller.RevisionListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
                     "404": {
@@ -2674,29 +3180,61 @@ const docTemplate = `{
                 }
             }
         },
-        "/tags": {
+        "/recipe/{id}/revisions/diff": {
             "get": {
-                "description": "Retrieve all tags, optionally sorted by name or creation date",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the fields that differ between two versions. Ingredients, steps, tags and categories also list the added and removed items.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tags"
+                    "revisions"
                 ],
-                "summary": "Get all tags",
+                "summary": "Compare two revisions of a recipe",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Sort order: name_asc, name_desc, created_asc, created_desc",
-                        "name": "sort",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Older version",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Newer version",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.TagsResponse"
+                            "$ref": "#/definitions/controller.RevisionDiffResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
                     "500": {
@@ -2708,35 +3246,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/favorites": {
+        "/recipe/{id}/revisions/{version}": {
             "get": {
-                "description": "Retrieve a paginated list of favorite recipes for the logged-in user",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the full snapshot of the recipe at the given version.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "revisions"
                 ],
-                "summary": "Get user's favorites with pagination",
+                "summary": "Get one revision of a recipe",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Limit number of favorites returned",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Number of favorites to skip",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Revision version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.UserFavoritesResponse"
+                            "$ref": "#/definitions/controller.RevisionResponse"
                         }
                     },
                     "400": {
@@ -2745,6 +3290,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2754,31 +3305,64 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/profile": {
-            "get": {
-                "description": "Fetches the complete profile of the authenticated user.\nThe response includes:\n- **Base user info** (ID, name, email, timestamps)\n- **Profile image** (optional, if uploaded)\n- **User statistics** (totals, averages, likes, ratings, favorites)\n- **Highlights** (most popular and highest-rated recipes created by the user)",
+        "/recipe/{id}/revisions/{version}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Writes the content of the given version back to the recipe. The restore is recorded as a new revision, so it can be undone as well.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "revisions"
                 ],
-                "summary": "Get user profile",
-                "responses": {
-                    "200": {
-                        "description": "User profile with details, stats, and highlights",
-                        "schema": {
-                            "$ref": "#/definitions/controller.UserProfileResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized: missing or invalid token",
+                "summary": "Restore an old revision of a recipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision version to restore",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.RevisionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
@@ -2786,190 +3370,204 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/profile-image": {
-            "post": {
+        "/recipe/{id}/share-link": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Uploads a single profile image for the authenticated user",
-                "consumes": [
-                    "multipart/form-data"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "images"
+                    "recipes"
                 ],
-                "summary": "Upload user profile image",
+                "summary": "Get the share token of an unlisted recipe",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Image file (jpeg, png, webp)",
-                        "name": "image",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Image uploaded successfully",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/controller.RecipeVisibilityResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input or file error",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/user/profile-image/{imageId}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves and serves a user's profile image",
+                "description": "Replaces the share token, so everyone holding the old link loses access.",
                 "produces": [
-                    "image/*"
+                    "application/json"
                 ],
                 "tags": [
-                    "images"
+                    "recipes"
                 ],
-                "summary": "Serve authenticated user's profile image",
+                "summary": "Renew the share token of an unlisted recipe",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Image ID",
-                        "name": "imageId",
+                        "description": "Recipe ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Image file served"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.RecipeVisibilityResponse"
+                        }
                     },
                     "400": {
-                        "description": "Invalid ID parameters",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Image not found",
+                        "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/recipe/{id}/status": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Deletes a user's profile image by ID",
+                "description": "Moves a recipe between draft, published, scheduled and archived. Only published recipes appear in public listings; drafts, scheduled and archived recipes are visible to their author and admins only. Scheduled recipes are published automatically once publish_at has passed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "images"
+                    "recipes"
                 ],
-                "summary": "Delete authenticated user's profile image",
+                "summary": "Change the publication status of a recipe",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Image ID",
-                        "name": "imageId",
+                        "description": "Recipe ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.RecipeStatusRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Profile image deleted successfully",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.RecipeStatusResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID parameters",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/user/ratings": {
+        "/recipe/{id}/tags": {
             "get": {
-                "description": "Retrieve a paginated list of ratings for the logged-in user",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Retrieves all tags associated with the specified recipe",
                 "tags": [
-                    "users"
+                    "recipes"
                 ],
-                "summary": "Get user's ratings with pagination",
+                "summary": "Get tags for a recipe",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Limit number of ratings returned",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of ratings to skip",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.UserRatingsResponse"
+                            "$ref": "#/definitions/controller.TagsResponse"
                         }
                     },
                     "400": {
@@ -2978,6 +3576,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2985,37 +3589,42 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/user/recipes": {
-            "get": {
-                "description": "Retrieve a paginated list of recipes for the logged-in user with total count",
+            },
+            "put": {
+                "description": "Replaces the tags associated with the specified recipe.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "recipes"
                 ],
-                "summary": "Get user's recipes with pagination",
+                "summary": "Update tags for a recipe",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Limit number of recipes returned",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Number of recipes to skip",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "List of tag names",
+                        "name": "tags",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.TagNamesInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.UserRecipesResponse"
+                            "$ref": "#/definitions/controller.TagsResponse"
                         }
                     },
                     "400": {
@@ -3024,32 +3633,2342 @@ const docTemplate = `{
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/controller.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Clears all tags associated with the specified recipe.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Remove all tags from a recipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipe/{id}/visibility": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Public recipes are listed everywhere. Unlisted recipes are left out of listings and search but open for anyone with the share token returned here. Private recipes are visible to the owner and invited users only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Change who can see a recipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New visibility",
+                        "name": "visibility",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.RecipeVisibilityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.RecipeVisibilityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/most-popular": {
+            "get": {
+                "description": "Get recipes sorted by number of favorites",
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Get most popular recipes by favorites",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit number of recipes",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.MostPopularRecipesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/options": {
+            "get": {
+                "description": "Returns the difficulties, cuisines and equipment recipes may be tagged with, as configured on the server, and the diets and allergens recipes are labelled with.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "List recipe metadata values",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.RecipeOptionsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/search": {
+            "get": {
+                "description": "Search for recipes using various filters and retrieve a paginated list with total count, optionally sorted. The full-text query q matches titles, descriptions, steps, ingredient names and tags in English and Persian, ignoring word endings and stop words; every word must match, \"quoted words\" must appear as a phrase and word* matches any word starting with it. Results of a full-text query are ranked by relevance unless another sort order is given. With facets the response also counts the matches per tag, category, author and average rating and buckets them by each nutrient and time; every facet ignores its own filter, including the terms on its field in the filter expression, so it shows what changing that filter would give. Nutrient ranges are given as min_\u003cnutrient\u003e and max_\u003cnutrient\u003e for calories, protein, fat, saturated_fat, carbs, fiber, sugar, cholesterol, sodium and potassium, time ranges as min_\u003ctime\u003e and max_\u003ctime\u003e for prep_minutes, cook_minutes and total_minutes. A filter expression combines field:value terms with AND, OR, NOT (or - before a term) and parentheses; a space also means AND. Fields are tag and category (name or ID), ingredient and title (partial match), author (user ID), cuisine, difficulty, diet, allergen, equipment, and rating, votes, created (a date), the nutrients and the times, which take a comparison such as rating:\u003e=4 or total_minutes:\u003c30. Values with spaces go in double quotes, as in ingredient:\"olive oil\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Search recipes with pagination, filtering, and sorting",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Full-text query",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by recipe title (partial match)",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by ingredient name (partial match)",
+                        "name": "ingredient",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by tag IDs (comma-separated)",
+                        "name": "tag_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by category IDs (comma-separated)",
+                        "name": "category_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by user ID",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Only recipes rated at least this on average; rating is accepted as well",
+                        "name": "min_rating",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Publication status (admins only, everyone else gets published recipes)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Visibility (admins only, everyone else gets public recipes)",
+                        "name": "visibility",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Apply the nutrient ranges to one serving instead of the whole recipe",
+                        "name": "per_serving",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only recipes fitting every listed diet (comma-separated): vegan, vegetarian, pescatarian, gluten_free, dairy_free, nut_free",
+                        "name": "diet",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Leave out recipes containing any listed allergen (comma-separated): celery, gluten, crustacean, egg, fish, lupin, milk, mollusc, mustard, tree_nut, peanut, sesame, soy, sulphite",
+                        "name": "exclude_allergens",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only recipes with at least this many ratings",
+                        "name": "min_votes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Leave out recipes with any listed ingredient (comma-separated, partial match)",
+                        "name": "exclude_ingredients",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Leave out recipes with any listed tag (comma-separated IDs)",
+                        "name": "exclude_tag_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Leave out recipes in any listed category (comma-separated IDs)",
+                        "name": "exclude_category_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Leave out recipes by any listed author (comma-separated IDs)",
+                        "name": "exclude_user_ids",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Whether recipes need any or all of tag_ids",
+                        "name": "tag_match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Whether recipes need any or all of category_ids",
+                        "name": "category_match",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only recipes created at or after this date (YYYY-MM-DD, in the server's time zone) or time (RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only recipes created at or before this date (the whole day) or time",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter expression, e.g. tag:vegan AND NOT ingredient:nuts; see the description",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by difficulty (comma-separated), see /recipes/options",
+                        "name": "difficulty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by cuisine (comma-separated), see /recipes/options",
+                        "name": "cuisine",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only recipes using every listed piece of equipment (comma-separated), see /recipes/options",
+                        "name": "equipment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only recipes ready in at most this many minutes; min_/max_ also work for prep_minutes and cook_minutes",
+                        "name": "max_total_minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order: relevance (only with q), title_asc, title_desc, created_asc, created_desc, rating_desc, favorites_desc, or calories, protein, fat, carbs, fiber, sugar, prep_time, cook_time, total_time or difficulty followed by _asc or _desc",
+                        "name": "sortOrder",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Facets to compute (comma-separated): tags, categories, authors, rating, or a nutrient or time column such as calories or total_minutes",
+                        "name": "facets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit number of recipes returned, at most 100 for a full-text query",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.RecipeSearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/top-rated": {
+            "get": {
+                "description": "Get recipes sorted by average rating, with total votes count",
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Get top rated recipes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit number of recipes",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/repository.TopRatedRecipe"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reset-password": {
+            "post": {
+                "description": "Resets password using reset token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset user password",
+                "parameters": [
+                    {
+                        "description": "Reset password data",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ResetPasswordResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/shared/{token}": {
+            "get": {
+                "description": "Works without logging in. Ingredients, comments and images of the recipe are available by passing the same token as the share_token query parameter.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recipes"
+                ],
+                "summary": "Open an unlisted recipe by its share token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Scale the recipe to this many servings",
+                        "name": "servings",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Measurement system: metric, imperial or original",
+                        "name": "units",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.RecipeWithImageIDs"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/signup": {
+            "post": {
+                "description": "Creates a new user account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User signup info",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserSignupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/suggest": {
+            "get": {
+                "description": "Completes partial input to recipe titles, ingredient names, tags and categories of published public recipes. Entries starting with q rank above entries with a later word starting with it; when there are too few, entries starting with something within one typo (two for queries over five letters) of q are added. Popular entries, by favorites and views for recipes and by number of recipes otherwise, rank higher.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search"
+                ],
+                "summary": "Autocomplete recipes, ingredients, tags and categories",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Partial input",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Types to suggest (comma-separated): recipe, ingredient, tag, category; all by default",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of suggestions (default 10, at most 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SuggestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tag": {
+            "post": {
+                "description": "Creates a new tag. Optionally associates it with recipes by IDs.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Create a new tag",
+                "parameters": [
+                    {
+                        "description": "Tag data",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Tag"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controller.TagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tag/{id}": {
+            "get": {
+                "description": "Retrieves a tag by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get a tag by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.TagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Updates the name of a tag specified by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Update a tag by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated tag data",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.TagsResponse"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.TagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes a tag and removes all its associations with recipes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Delete a tag by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Retrieve all tags, optionally sorted by name or creation date",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get all tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sort order: name_asc, name_desc, created_asc, created_desc",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.TagsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token for a new access/refresh token pair. The presented refresh token is invalidated; reusing it revokes every token issued from the same login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.TokenRefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/favorites": {
+            "get": {
+                "description": "Retrieve a paginated list of favorite recipes for the logged-in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user's favorites with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit number of favorites returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of favorites to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserFavoritesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/preferences": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets the measurement system recipes are shown in when a request does not pass the units parameter. Use original to show the units the author wrote.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update the caller's preferences",
+                "parameters": [
+                    {
+                        "description": "Preferences",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/profile": {
+            "get": {
+                "description": "Fetches the complete profile of the authenticated user.\nThe response includes:\n- **Base user info** (ID, name, email, timestamps)\n- **Profile image** (optional, if uploaded)\n- **User statistics** (totals, averages, likes, ratings, favorites)\n- **Highlights** (most popular and highest-rated recipes created by the user)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user profile",
+                "responses": {
+                    "200": {
+                        "description": "User profile with details, stats, and highlights",
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserProfileResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized: missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/profile-image": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Uploads a single profile image for the authenticated user",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Upload user profile image",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image file (jpeg, png, webp)",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Image uploaded successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or file error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/profile-image/{imageId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves and serves a user's profile image",
+                "produces": [
+                    "image/*"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Serve authenticated user's profile image",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Image ID",
+                        "name": "imageId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Image file served"
+                    },
+                    "400": {
+                        "description": "Invalid ID parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Image not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a user's profile image by ID",
+                "tags": [
+                    "images"
+                ],
+                "summary": "Delete authenticated user's profile image",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Image ID",
+                        "name": "imageId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile image deleted successfully",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID parameters",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/user/ratings": {
+            "get": {
+                "description": "Retrieve a paginated list of ratings for the logged-in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user's ratings with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit number of ratings returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of ratings to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserRatingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/recipes": {
+            "get": {
+                "description": "Retrieve a paginated list of recipes for the logged-in user with total count",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user's recipes with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit number of recipes returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of recipes to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserRecipesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/shared-recipes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List private recipes shared with the caller",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit number of recipes returned",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.RecipeListWithImagesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email": {
+            "post": {
+                "description": "Confirms the email address using the token sent on signup",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify email address",
+                "parameters": [
+                    {
+                        "description": "Verification token",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sends a new verification link to the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resend verification email",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SimpleMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller.AverageRatingResponse": {
+            "type": "object",
+            "properties": {
+                "average": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "recipe_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controller.CategoriesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Category"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.CategoryResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.Category"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.CommentResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.CommentsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.CommentWithUserName"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.FavoriteResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.ForgotPasswordResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.IngredientCreateResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.IngredientNutrition": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "calories": {
+                    "type": "number"
+                },
+                "carbs": {
+                    "type": "number"
+                },
+                "cholesterol": {
+                    "type": "number"
+                },
+                "fat": {
+                    "type": "number"
+                },
+                "fiber": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "potassium": {
+                    "type": "number"
+                },
+                "protein": {
+                    "type": "number"
+                },
+                "saturated_fat": {
+                    "type": "number"
+                },
+                "sodium": {
+                    "type": "number"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "sugar": {
+                    "type": "number"
+                }
+            }
+        },
+        "controller.IngredientResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.Ingredient"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.IngredientsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Ingredient"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.JobListResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Job"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "stats": {
+                    "description": "Stats is the number of jobs per status, regardless of the filters.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                }
+            }
+        },
+        "controller.JobResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.Job"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.LogoutRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "description": "Optional; when given the whole refresh-token family is revoked as well.",
+                    "type": "string"
+                }
+            }
+        },
+        "controller.MostPopularRecipesResponse": {
+            "type": "object",
+            "properties": {
+                "recipes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.MostPopularRecipe"
+                    }
+                }
+            }
+        },
+        "controller.NutritionLabelResponse": {
+            "type": "object",
+            "properties": {
+                "label": {
+                    "$ref": "#/definitions/nutrition.Label"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "report": {
+                    "$ref": "#/definitions/service.NutritionReport"
+                }
+            }
+        },
+        "controller.NutritionResponse": {
+            "type": "object",
+            "properties": {
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.IngredientNutrition"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "per_serving": {
+                    "$ref": "#/definitions/nutrition.Facts"
+                },
+                "report": {
+                    "$ref": "#/definitions/service.NutritionReport"
+                },
+                "total": {
+                    "$ref": "#/definitions/nutrition.Facts"
+                }
+            }
+        },
+        "controller.PantryItemRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "controller.PantryItemResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/model.PantryItem"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.PantryListResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PantryItem"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.PantryMatch": {
+            "type": "object",
+            "properties": {
+                "coverage": {
+                    "type": "number"
+                },
+                "have": {
+                    "description": "Have and Total count the distinct ingredients of the recipe, leaving\nout staples when they are ignored.",
+                    "type": "integer"
+                },
+                "missing": {
+                    "description": "Missing lists the ingredients not in the pantry, as the recipe names\nthem.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "recipe": {
+                    "$ref": "#/definitions/controller.RecipeWithImageIDs"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controller.PantryMatchResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.PantryMatch"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.PostCommentRequest": {
+            "type": "object",
+            "required": [
+                "description",
+                "recipe_id",
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "recipe_id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.PostFavoriteRequest": {
+            "type": "object",
+            "required": [
+                "recipe_id"
+            ],
+            "properties": {
+                "recipe_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controller.PostRatingRequest": {
+            "type": "object",
+            "required": [
+                "recipe_id",
+                "score"
+            ],
+            "properties": {
+                "recipe_id": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1
+                }
+            }
+        },
+        "controller.PostRecipeRequest": {
+            "type": "object",
+            "required": [
+                "ingredients",
+                "text",
+                "title"
+            ],
+            "properties": {
+                "category_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "cook_minutes": {
+                    "type": "integer"
+                },
+                "cuisine": {
+                    "type": "string"
+                },
+                "difficulty": {
+                    "type": "string"
+                },
+                "equipment": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Ingredient"
+                    }
+                },
+                "prep_minutes": {
+                    "type": "integer"
+                },
+                "publish_at": {
+                    "type": "string"
+                },
+                "servings": {
+                    "description": "Servings defaults to 1. Yield is free text such as \"1 loaf\".",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status defaults to published. Scheduled recipes need PublishAt.",
+                    "type": "string"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Step"
+                    }
+                },
+                "tag_ids": {
+                    "description": "Optional: Use existing tag IDs",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "tag_names": {
+                    "description": "Optional: Create/find tags by name",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "text": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "total_minutes": {
+                    "description": "TotalMinutes defaults to prep plus cook time. Set it when resting or\nrising time adds to that.",
+                    "type": "integer"
+                },
+                "visibility": {
+                    "description": "Visibility defaults to public.",
+                    "type": "string"
+                },
+                "yield": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.RatingResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.RecipeInviteRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controller.RecipeInvitesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.InvitedUser"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.RecipeListWithImagesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.RecipeWithImageIDs"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.RecipeOptionsResponse": {
+            "type": "object",
+            "properties": {
+                "allergens": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "cuisines": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "diets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "difficulties": {
+                    "description": "Difficulties are ordered from easiest to hardest.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "equipment": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.RecipeSearchResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controller.RecipeWithImageIDs"
+                    }
+                },
+                "facets": {
+                    "description": "Facets holds the facets asked for, by name.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/repository.Facet"
+                    }
+                },
+                "facets_truncated": {
+                    "description": "FacetsTruncated is set when a full-text query matched more recipes\nthan facets are counted over; the facets then cover the best matches\nonly, while Count stays exact.",
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.RecipeStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "publish_at": {
+                    "description": "PublishAt is required for the scheduled status and ignored otherwise.",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.RecipeStatusResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "publish_at": {
+                    "type": "string"
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.RecipeStatus"
+                }
+            }
+        },
+        "controller.RecipeVisibilityRequest": {
+            "type": "object",
+            "required": [
+                "visibility"
+            ],
+            "properties": {
+                "visibility": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.RecipeVisibilityResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "share_token": {
+                    "description": "ShareToken is only set for unlisted recipes. Pass it as share_token to\nthe recipe endpoints or open /shared/{token}.",
+                    "type": "string"
+                },
+                "visibility": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.RecipeWithImageIDs": {
+            "type": "object",
+            "properties": {
+                "allergens": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "calories": {
+                    "type": "number"
+                },
+                "carbs": {
+                    "type": "number"
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Category"
+                    }
+                },
+                "cholesterol": {
+                    "type": "number"
+                },
+                "cook_minutes": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "cuisine": {
+                    "type": "string"
+                },
+                "diets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "difficulty": {
+                    "type": "string"
+                },
+                "equipment": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "fat": {
+                    "type": "number"
+                },
+                "favorite_count": {
+                    "type": "integer"
+                },
+                "fiber": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Ingredient"
+                    }
+                },
+                "is_favorited": {
+                    "type": "boolean"
+                },
+                "nutrition_pending": {
+                    "description": "NutritionPending is set when the nutrition is still being estimated\nin the background.",
+                    "type": "boolean"
+                },
+                "nutrition_report": {
+                    "description": "NutritionReport tells which ingredients the nutrition values cover.\nOnly set on single recipe responses.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.NutritionReport"
+                        }
+                    ]
+                },
+                "per_serving": {
+                    "$ref": "#/definitions/nutrition.Facts"
+                },
+                "potassium": {
+                    "type": "number"
+                },
+                "prep_minutes": {
+                    "type": "integer"
+                },
+                "protein": {
+                    "type": "number"
+                },
+                "publish_at": {
+                    "type": "string"
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "saturated_fat": {
+                    "type": "number"
+                },
+                "scale_factor": {
+                    "description": "ScaleFactor is set when the recipe was scaled with ?servings=N.\nUnscaledIngredients lists the ingredients whose amount has no number.",
+                    "type": "number"
+                },
+                "servings": {
+                    "type": "integer"
+                },
+                "sodium": {
+                    "type": "number"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.RecipeStatus"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Step"
+                    }
+                },
+                "sugar": {
+                    "type": "number"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Tag"
                     }
+                },
+                "text": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "total_minutes": {
+                    "type": "integer"
+                },
+                "unscaled_ingredients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "visibility": {
+                    "type": "string"
+                },
+                "yield": {
+                    "type": "string"
                 }
             }
-        }
-    },
-    "definitions": {
-        "controller.AverageRatingResponse": {
+        },
+        "controller.ResetPasswordRequest": {
             "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
             "properties": {
-                "average": {
-                    "type": "number"
+                "new_password": {
+                    "type": "string"
                 },
-                "count": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.ResetPasswordResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.RevisionDiffResponse": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.FieldChange"
+                    }
+                },
+                "from": {
                     "type": "integer"
                 },
-                "recipe_id": {
+                "message": {
+                    "type": "string"
+                },
+                "to": {
                     "type": "integer"
                 }
             }
         },
-        "controller.CategoriesResponse": {
+        "controller.RevisionListResponse": {
             "type": "object",
             "properties": {
                 "count": {
@@ -3058,7 +5977,7 @@ const docTemplate = `{
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Category"
+                        "$ref": "#/definitions/model.RecipeRevision"
                     }
                 },
                 "message": {
@@ -3066,74 +5985,40 @@ const docTemplate = `{
                 }
             }
         },
-        "controller.CategoryResponse": {
+        "controller.RevisionResponse": {
             "type": "object",
             "properties": {
                 "data": {
-                    "$ref": "#/definitions/model.Category"
+                    "$ref": "#/definitions/model.RecipeRevision"
                 },
                 "message": {
                     "type": "string"
                 }
             }
         },
-        "controller.CommentResponse": {
+        "controller.SimpleMessageResponse": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer"
-                },
                 "message": {
                     "type": "string"
                 }
             }
         },
-        "controller.CommentWithUserName": {
+        "controller.SuccessMessageResponse": {
             "type": "object",
-            "required": [
-                "description",
-                "title"
-            ],
             "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "likes": {
-                    "type": "integer"
-                },
-                "recipe_id": {
-                    "type": "integer"
-                },
-                "title": {
-                    "type": "string"
-                },
-                "updatedAt": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "integer"
-                },
-                "user_name": {
+                "message": {
                     "type": "string"
                 }
             }
         },
-        "controller.CommentsResponse": {
+        "controller.SuggestResponse": {
             "type": "object",
             "properties": {
-                "count": {
-                    "type": "integer"
-                },
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/controller.CommentWithUserName"
+                        "$ref": "#/definitions/search.Suggestion"
                     }
                 },
                 "message": {
@@ -3141,103 +6026,196 @@ const docTemplate = `{
                 }
             }
         },
-        "controller.ErrorResponse": {
+        "controller.TagNamesInput": {
             "type": "object",
+            "required": [
+                "tags"
+            ],
             "properties": {
-                "error": {
-                    "type": "string"
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "controller.FavoriteResponse": {
+        "controller.TagResponse": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer"
+                "data": {
+                    "$ref": "#/definitions/model.Tag"
                 },
                 "message": {
                     "type": "string"
                 }
             }
         },
-        "controller.FavoriteWithTitle": {
+        "controller.TagsResponse": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Tag"
+                    }
+                }
+            }
+        },
+        "controller.TokenRefreshRequest": {
             "type": "object",
             "required": [
-                "recipe_id",
-                "user_id"
+                "refresh_token"
             ],
             "properties": {
-                "createdAt": {
+                "refresh_token": {
                     "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "recipe_id": {
+                }
+            }
+        },
+        "controller.UpdateUserRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "description": "user|moderator|admin",
+                    "type": "string"
+                }
+            }
+        },
+        "controller.UserFavoritesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
                     "type": "integer"
                 },
-                "recipe_title": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.FavoriteWithTitle"
+                    }
                 },
-                "updatedAt": {
+                "message": {
                     "type": "string"
-                },
-                "user_id": {
-                    "type": "integer"
                 }
             }
         },
-        "controller.ForgotPasswordRequest": {
+        "controller.UserLoginRequest": {
             "type": "object",
             "required": [
-                "email"
+                "email",
+                "password"
             ],
             "properties": {
                 "email": {
                     "type": "string"
+                },
+                "password": {
+                    "type": "string"
                 }
             }
         },
-        "controller.ForgotPasswordResponse": {
+        "controller.UserLoginResponse": {
             "type": "object",
             "properties": {
-                "message": {
+                "expires_at": {
                     "type": "string"
                 },
-                "reset_token": {
+                "refresh_token": {
                     "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controller.IngredientCreateResponse": {
+        "controller.UserPreferencesRequest": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "message": {
+                "units": {
+                    "description": "metric|imperial|original",
                     "type": "string"
                 }
             }
         },
-        "controller.IngredientResponse": {
+        "controller.UserProfileResponse": {
+            "description": "Full user profile including base user data, profile image, aggregated statistics, and highlighted recipes.",
+            "type": "object",
+            "properties": {
+                "highest_rated_recipe": {
+                    "description": "The user's recipe with the highest average rating (if exists)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/repository.RecipeSummary"
+                        }
+                    ]
+                },
+                "most_popular_recipe": {
+                    "description": "The user's recipe with the highest number of favorites (if exists)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/repository.RecipeSummary"
+                        }
+                    ]
+                },
+                "profile_image": {
+                    "description": "Profile image associated with the user (if uploaded), including file path and metadata",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Image"
+                        }
+                    ]
+                },
+                "stats": {
+                    "description": "Aggregated statistics about user's activity (recipes, comments, favorites, ratings)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/repository.UserStats"
+                        }
+                    ]
+                },
+                "user": {
+                    "description": "Base user information (name, email, etc.)\nWarning: password and sensitive fields are omitted from this response",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    ]
+                }
+            }
+        },
+        "controller.UserRatingsResponse": {
             "type": "object",
             "properties": {
+                "count": {
+                    "type": "integer"
+                },
                 "data": {
-                    "$ref": "#/definitions/model.Ingredient"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Rating"
+                    }
                 },
                 "message": {
                     "type": "string"
                 }
             }
         },
-        "controller.IngredientsResponse": {
+        "controller.UserRecipesResponse": {
             "type": "object",
             "properties": {
+                "count": {
+                    "type": "integer"
+                },
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Ingredient"
+                        "$ref": "#/definitions/model.Recipe"
                     }
                 },
                 "message": {
@@ -3245,189 +6223,337 @@ const docTemplate = `{
                 }
             }
         },
-        "controller.MostPopularRecipe": {
+        "controller.UserSignupRequest": {
             "type": "object",
+            "required": [
+                "email",
+                "last_name",
+                "name",
+                "password"
+            ],
             "properties": {
-                "favorite_count": {
-                    "type": "integer"
+                "email": {
+                    "type": "string"
                 },
-                "recipe_id": {
-                    "type": "integer"
+                "last_name": {
+                    "type": "string"
                 },
-                "title": {
+                "name": {
+                    "type": "string"
+                },
+                "password": {
                     "type": "string"
                 }
             }
         },
-        "controller.MostPopularRecipesResponse": {
+        "controller.VerifyEmailRequest": {
             "type": "object",
+            "required": [
+                "token"
+            ],
             "properties": {
-                "recipes": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/controller.MostPopularRecipe"
-                    }
+                "token": {
+                    "type": "string"
                 }
             }
         },
-        "controller.NutritionResponse": {
+        "model.Category": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "nutritional_values": {}
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
             }
         },
-        "controller.PostCommentRequest": {
+        "model.Comment": {
             "type": "object",
             "required": [
                 "description",
-                "recipe_id",
                 "title"
             ],
             "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
                 "description": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "integer"
+                },
+                "likes": {
+                    "type": "integer"
+                },
                 "recipe_id": {
                     "type": "integer"
                 },
                 "title": {
                     "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controller.PostFavoriteRequest": {
+        "model.Favorite": {
             "type": "object",
             "required": [
-                "recipe_id"
+                "recipe_id",
+                "user_id"
             ],
             "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
                 "recipe_id": {
                     "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controller.PostRatingRequest": {
+        "model.Image": {
             "type": "object",
-            "required": [
-                "recipe_id",
-                "score"
-            ],
             "properties": {
-                "recipe_id": {
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_id": {
                     "type": "integer"
                 },
-                "score": {
-                    "type": "integer",
-                    "maximum": 5,
-                    "minimum": 1
+                "entity_type": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
                 }
             }
         },
-        "controller.PostRecipeRequest": {
+        "model.Ingredient": {
             "type": "object",
             "required": [
-                "ingredients",
-                "text",
-                "title"
+                "amount",
+                "name"
             ],
             "properties": {
-                "category_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "amount": {
+                    "type": "string"
                 },
-                "ingredients": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Ingredient"
-                    }
+                "calories": {
+                    "type": "number"
                 },
-                "steps": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Step"
-                    }
+                "carbs": {
+                    "type": "number"
                 },
-                "tag_ids": {
-                    "description": "Optional: Use existing tag IDs",
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "cholesterol": {
+                    "type": "number"
                 },
-                "tag_names": {
-                    "description": "Optional: Create/find tags by name",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "createdAt": {
+                    "type": "string"
                 },
-                "text": {
+                "fat": {
+                    "type": "number"
+                },
+                "fiber": {
+                    "type": "number"
+                },
+                "grams": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
-                "title": {
+                "note": {
+                    "type": "string"
+                },
+                "nutrition_source": {
+                    "description": "NutritionSource is the provider the nutrients came from, empty when\nno provider matched the ingredient.",
+                    "type": "string"
+                },
+                "potassium": {
+                    "type": "number"
+                },
+                "protein": {
+                    "type": "number"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "quantity_max": {
+                    "type": "number"
+                },
+                "recipe_id": {
+                    "type": "integer"
+                },
+                "saturated_fat": {
+                    "description": "Cholesterol, Sodium and Potassium are in milligrams, the other\nnutrients in grams.",
+                    "type": "number"
+                },
+                "sodium": {
+                    "type": "number"
+                },
+                "sugar": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "controller.RatingResponse": {
+        "model.Job": {
             "type": "object",
             "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "message": {
+                "last_error": {
+                    "type": "string"
+                },
+                "locked_at": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.JobStatus"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "controller.RecipeListWithImagesResponse": {
+        "model.JobStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "done",
+                "dead"
+            ],
+            "x-enum-varnames": [
+                "JobPending",
+                "JobRunning",
+                "JobDone",
+                "JobDead"
+            ]
+        },
+        "model.PantryItem": {
             "type": "object",
             "properties": {
-                "count": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/controller.RecipeWithImageIDs"
-                    }
+                "name": {
+                    "type": "string"
                 },
-                "message": {
+                "updated_at": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controller.RecipeSummary": {
-            "description": "Minimal recipe representation used to highlight \"most popular\" and \"highest rated\" recipes.",
+        "model.Rating": {
             "type": "object",
+            "required": [
+                "score"
+            ],
             "properties": {
-                "count": {
-                    "description": "Favorite count for most-popular recipe; only present if relevant",
-                    "type": "integer",
-                    "example": 120
+                "createdAt": {
+                    "type": "string"
                 },
                 "id": {
-                    "description": "Unique identifier of the recipe",
-                    "type": "integer",
-                    "example": 7
+                    "type": "integer"
+                },
+                "recipe_id": {
+                    "type": "integer"
                 },
                 "score": {
-                    "description": "Rating score for highest-rated recipe (scale 1–5); only present if relevant",
-                    "type": "number",
-                    "example": 4.8
+                    "type": "integer"
                 },
-                "title": {
-                    "description": "Title of the recipe",
-                    "type": "string",
-                    "example": "Classic Pancakes"
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "controller.RecipeWithImageIDs": {
+        "model.Recipe": {
             "type": "object",
+            "required": [
+                "text",
+                "title",
+                "user_id"
+            ],
             "properties": {
+                "allergens": {
+                    "description": "Allergens and Diets are derived from the ingredient names whenever\nthe ingredients change, see SetDietaryLabels.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "calories": {
                     "type": "number"
                 },
@@ -3440,14 +6566,50 @@ const docTemplate = `{
                         "$ref": "#/definitions/model.Category"
                     }
                 },
-                "created_at": {
+                "cholesterol": {
+                    "type": "number"
+                },
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Comment"
+                    }
+                },
+                "cook_minutes": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "cuisine": {
+                    "type": "string"
+                },
+                "diets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "difficulty": {
                     "type": "string"
                 },
+                "difficulty_level": {
+                    "type": "integer"
+                },
+                "equipment": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "fat": {
                     "type": "number"
                 },
-                "favorite_count": {
-                    "type": "integer"
+                "favorites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Favorite"
+                    }
                 },
                 "fiber": {
                     "type": "number"
@@ -3455,24 +6617,47 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
-                "images": {
+                "ingredients": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "$ref": "#/definitions/model.Ingredient"
                     }
                 },
-                "ingredients": {
+                "potassium": {
+                    "type": "number"
+                },
+                "prep_minutes": {
+                    "description": "Times are in minutes, zero when unknown. Difficulty, Cuisine and\nEquipment take the values configured under recipes; DifficultyLevel\nis the position of Difficulty in that list, from 1 for the easiest.",
+                    "type": "integer"
+                },
+                "protein": {
+                    "type": "number"
+                },
+                "publish_at": {
+                    "type": "string"
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "ratings": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Ingredient"
+                        "$ref": "#/definitions/model.Rating"
                     }
                 },
-                "is_favorited": {
-                    "type": "boolean"
+                "saturated_fat": {
+                    "description": "Cholesterol, Sodium and Potassium are in milligrams, the other\nnutrients in grams.",
+                    "type": "number"
                 },
-                "protein": {
+                "servings": {
+                    "type": "integer"
+                },
+                "sodium": {
                     "type": "number"
                 },
+                "status": {
+                    "$ref": "#/definitions/model.RecipeStatus"
+                },
                 "steps": {
                     "type": "array",
                     "items": {
@@ -3494,252 +6679,264 @@ const docTemplate = `{
                 "title": {
                     "type": "string"
                 },
-                "updated_at": {
+                "total_minutes": {
+                    "type": "integer"
+                },
+                "updatedAt": {
                     "type": "string"
                 },
                 "user_id": {
                     "type": "integer"
-                }
-            }
-        },
-        "controller.ResetPasswordRequest": {
-            "type": "object",
-            "required": [
-                "new_password",
-                "token"
-            ],
-            "properties": {
-                "new_password": {
-                    "type": "string"
                 },
-                "token": {
-                    "type": "string"
-                }
-            }
-        },
-        "controller.ResetPasswordResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "controller.SimpleMessageResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
+                "visibility": {
+                    "$ref": "#/definitions/model.RecipeVisibility"
+                },
+                "yield": {
                     "type": "string"
                 }
             }
         },
-        "controller.SuccessMessageResponse": {
+        "model.RecipeRevision": {
             "type": "object",
             "properties": {
-                "message": {
+                "created_at": {
                     "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "recipe_id": {
+                    "type": "integer"
+                },
+                "restored_from": {
+                    "description": "RestoredFrom is set when the revision restored an older version.",
+                    "type": "integer"
+                },
+                "snapshot": {
+                    "$ref": "#/definitions/model.RecipeSnapshot"
+                },
+                "user_id": {
+                    "description": "UserID is the author of the change, not necessarily the recipe owner.",
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "controller.TagNamesInput": {
+        "model.RecipeSnapshot": {
             "type": "object",
-            "required": [
-                "tags"
-            ],
             "properties": {
-                "tags": {
+                "categories": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/model.SnapshotRef"
+                    }
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotIngredient"
+                    }
+                },
+                "metadata": {
+                    "description": "Metadata is nil in snapshots taken before recipes had it.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SnapshotMetadata"
+                        }
+                    ]
+                },
+                "servings": {
+                    "type": "integer"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SnapshotStep"
                     }
-                }
-            }
-        },
-        "controller.TagResponse": {
-            "type": "object",
-            "properties": {
-                "data": {
-                    "$ref": "#/definitions/model.Tag"
                 },
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "controller.TagsResponse": {
-            "type": "object",
-            "properties": {
                 "tags": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Tag"
+                        "$ref": "#/definitions/model.SnapshotRef"
                     }
+                },
+                "text": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "yield": {
+                    "type": "string"
                 }
             }
         },
-        "controller.TimeSeriesData": {
+        "model.RecipeStatus": {
+            "type": "string",
+            "enum": [
+                "draft",
+                "published",
+                "scheduled",
+                "archived"
+            ],
+            "x-enum-varnames": [
+                "StatusDraft",
+                "StatusPublished",
+                "StatusScheduled",
+                "StatusArchived"
+            ]
+        },
+        "model.RecipeVisibility": {
+            "type": "string",
+            "enum": [
+                "public",
+                "unlisted",
+                "private"
+            ],
+            "x-enum-varnames": [
+                "VisibilityPublic",
+                "VisibilityUnlisted",
+                "VisibilityPrivate"
+            ]
+        },
+        "model.SnapshotIngredient": {
             "type": "object",
             "properties": {
-                "count": {
-                    "type": "integer"
+                "amount": {
+                    "type": "string"
                 },
-                "label": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "controller.TopRatedRecipe": {
+        "model.SnapshotMetadata": {
             "type": "object",
             "properties": {
-                "average": {
-                    "type": "number"
-                },
-                "recipe_id": {
+                "cook_minutes": {
                     "type": "integer"
                 },
-                "title": {
+                "cuisine": {
                     "type": "string"
                 },
-                "total_votes": {
-                    "type": "integer"
-                }
-            }
-        },
-        "controller.UserFavoritesResponse": {
-            "type": "object",
-            "properties": {
-                "count": {
+                "difficulty": {
+                    "type": "string"
+                },
+                "difficulty_level": {
                     "type": "integer"
                 },
-                "data": {
+                "equipment": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/controller.FavoriteWithTitle"
+                        "type": "string"
                     }
                 },
-                "message": {
-                    "type": "string"
+                "prep_minutes": {
+                    "type": "integer"
+                },
+                "total_minutes": {
+                    "type": "integer"
                 }
             }
         },
-        "controller.UserLoginRequest": {
+        "model.SnapshotRef": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
-                    "type": "string"
+                "id": {
+                    "type": "integer"
                 },
-                "password": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "controller.UserLoginResponse": {
+        "model.SnapshotStep": {
             "type": "object",
             "properties": {
-                "token": {
-                    "type": "string"
-                },
-                "user_id": {
+                "order": {
                     "type": "integer"
+                },
+                "text": {
+                    "type": "string"
                 }
             }
         },
-        "controller.UserProfileResponse": {
-            "description": "Full user profile including base user data, profile image, aggregated statistics, and highlighted recipes.",
+        "model.Step": {
             "type": "object",
+            "required": [
+                "order",
+                "text"
+            ],
             "properties": {
-                "highest_rated_recipe": {
-                    "description": "The user's recipe with the highest average rating (if exists)",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/controller.RecipeSummary"
-                        }
-                    ]
+                "createdAt": {
+                    "type": "string"
                 },
-                "most_popular_recipe": {
-                    "description": "The user's recipe with the highest number of favorites (if exists)",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/controller.RecipeSummary"
-                        }
-                    ]
+                "id": {
+                    "type": "integer"
                 },
-                "profile_image": {
-                    "description": "Profile image associated with the user (if uploaded), including file path and metadata",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.Image"
-                        }
-                    ]
+                "order": {
+                    "type": "integer"
                 },
-                "stats": {
-                    "description": "Aggregated statistics about user's activity (recipes, comments, favorites, ratings)",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/controller.UserStats"
-                        }
-                    ]
+                "recipe_id": {
+                    "type": "integer"
                 },
-                "user": {
-                    "description": "Base user information (name, email, etc.)\nWarning: password and sensitive fields are omitted from this response",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.User"
-                        }
-                    ]
+                "text": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
-        "controller.UserRatingsResponse": {
+        "model.Tag": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "count": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Rating"
-                    }
+                "name": {
+                    "type": "string"
                 },
-                "message": {
+                "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "controller.UserRecipesResponse": {
+        "model.User": {
             "type": "object",
             "properties": {
-                "count": {
-                    "type": "integer"
-                },
-                "data": {
+                "comments": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Recipe"
+                        "$ref": "#/definitions/model.Comment"
                     }
                 },
-                "message": {
+                "createdAt": {
                     "type": "string"
-                }
-            }
-        },
-        "controller.UserSignupRequest": {
-            "type": "object",
-            "required": [
-                "email",
-                "last_name",
-                "name",
-                "password"
-            ],
-            "properties": {
+                },
                 "email": {
                     "type": "string"
                 },
+                "email_verified_at": {
+                    "type": "string"
+                },
+                "favorites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Favorite"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
                 "last_name": {
                     "type": "string"
                 },
@@ -3748,81 +6945,115 @@ const docTemplate = `{
                 },
                 "password": {
                     "type": "string"
+                },
+                "ratings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Rating"
+                    }
+                },
+                "recipes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Recipe"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
+                "salt": {
+                    "description": "only set for legacy SHA-256 hashes",
+                    "type": "string"
+                },
+                "units": {
+                    "description": "preferred measurement system, empty for the original units",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
-        "controller.UserStats": {
-            "description": "Aggregated statistics based on the user's activity in the system.",
+        "nutrition.Facts": {
             "type": "object",
             "properties": {
-                "average_likes_per_comment": {
-                    "description": "Average number of likes per comment posted by this user",
-                    "type": "number",
-                    "example": 2.3
+                "calories": {
+                    "type": "number"
                 },
-                "average_rating": {
-                    "description": "Average rating across all this user's recipes (scale 1–5)",
-                    "type": "number",
-                    "example": 4.5
+                "carbs": {
+                    "type": "number"
                 },
-                "average_rating_given": {
-                    "description": "Average rating value this user gives when rating other recipes (scale 1–5)",
-                    "type": "number",
-                    "example": 3.8
+                "cholesterol": {
+                    "type": "number"
                 },
-                "total_comments": {
-                    "description": "Total number of comments posted by this user",
-                    "type": "integer",
-                    "example": 34
+                "fat": {
+                    "type": "number"
                 },
-                "total_favorites": {
-                    "description": "Total number of times this user's recipes were favorited by others",
-                    "type": "integer",
-                    "example": 87
+                "fiber": {
+                    "type": "number"
                 },
-                "total_favorites_given": {
-                    "description": "Total number of recipes this user has favorited",
-                    "type": "integer",
-                    "example": 25
+                "potassium": {
+                    "type": "number"
                 },
-                "total_likes_on_comments": {
-                    "description": "Sum of likes across all comments posted by this user",
-                    "type": "integer",
-                    "example": 56
+                "protein": {
+                    "type": "number"
                 },
-                "total_ratings_given": {
-                    "description": "Total number of ratings this user has given to other recipes",
-                    "type": "integer",
-                    "example": 19
+                "saturated_fat": {
+                    "type": "number"
                 },
-                "total_recipes": {
-                    "description": "Total number of recipes created by this user",
-                    "type": "integer",
-                    "example": 12
+                "sodium": {
+                    "type": "number"
+                },
+                "sugar": {
+                    "type": "number"
                 }
             }
         },
-        "model.Category": {
+        "nutrition.Label": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "createdAt": {
-                    "type": "string"
+                "calories": {
+                    "type": "number"
                 },
-                "id": {
+                "nutrients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/nutrition.LabelLine"
+                    }
+                },
+                "serving_grams": {
+                    "description": "ServingGrams is the weight of one serving, zero when the weight of\nsome ingredient is unknown.",
+                    "type": "number"
+                },
+                "servings": {
+                    "type": "integer"
+                }
+            }
+        },
+        "nutrition.LabelLine": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "daily_value": {
                     "type": "integer"
                 },
+                "key": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
-                "updatedAt": {
+                "parent": {
+                    "type": "string"
+                },
+                "unit": {
                     "type": "string"
                 }
             }
         },
-        "model.Comment": {
+        "repository.CommentWithUserName": {
             "type": "object",
             "required": [
                 "description",
@@ -3852,108 +7083,64 @@ const docTemplate = `{
                 },
                 "user_id": {
                     "type": "integer"
-                }
-            }
-        },
-        "model.Favorite": {
-            "type": "object",
-            "required": [
-                "recipe_id",
-                "user_id"
-            ],
-            "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "recipe_id": {
-                    "type": "integer"
                 },
-                "updatedAt": {
+                "user_name": {
                     "type": "string"
-                },
-                "user_id": {
-                    "type": "integer"
                 }
             }
         },
-        "model.Image": {
+        "repository.Facet": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "entity_id": {
-                    "type": "integer"
-                },
-                "entity_type": {
-                    "type": "string"
-                },
-                "format": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "path": {
-                    "type": "string"
+                "buckets": {
+                    "description": "Buckets is set for the rating and the histogram facets. Rating buckets\nare cumulative and closed: each counts the recipes averaging from From\nup to and including To, 5 stars.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.FacetBucket"
+                    }
                 },
-                "size": {
-                    "type": "integer"
+                "values": {
+                    "description": "Values is set for tags, categories and authors, most common first.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.FacetValue"
+                    }
                 }
             }
         },
-        "model.Ingredient": {
+        "repository.FacetBucket": {
             "type": "object",
-            "required": [
-                "amount",
-                "name"
-            ],
             "properties": {
-                "amount": {
-                    "type": "string"
-                },
-                "calories": {
-                    "type": "number"
-                },
-                "carbs": {
-                    "type": "number"
-                },
-                "createdAt": {
-                    "type": "string"
-                },
-                "fat": {
-                    "type": "number"
-                },
-                "fiber": {
-                    "type": "number"
-                },
-                "id": {
+                "count": {
                     "type": "integer"
                 },
-                "name": {
-                    "type": "string"
-                },
-                "protein": {
+                "from": {
                     "type": "number"
                 },
-                "recipe_id": {
+                "to": {
+                    "type": "number"
+                }
+            }
+        },
+        "repository.FacetValue": {
+            "type": "object",
+            "properties": {
+                "count": {
                     "type": "integer"
                 },
-                "sugar": {
-                    "type": "number"
+                "id": {
+                    "type": "integer"
                 },
-                "updatedAt": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "model.Rating": {
+        "repository.FavoriteWithTitle": {
             "type": "object",
             "required": [
-                "score"
+                "recipe_id",
+                "user_id"
             ],
             "properties": {
                 "createdAt": {
@@ -3965,8 +7152,8 @@ const docTemplate = `{
                 "recipe_id": {
                     "type": "integer"
                 },
-                "score": {
-                    "type": "integer"
+                "recipe_title": {
+                    "type": "string"
                 },
                 "updatedAt": {
                     "type": "string"
@@ -3976,194 +7163,201 @@ const docTemplate = `{
                 }
             }
         },
-        "model.Recipe": {
+        "repository.InvitedUser": {
             "type": "object",
-            "required": [
-                "text",
-                "title",
-                "user_id"
-            ],
             "properties": {
-                "calories": {
-                    "type": "number"
-                },
-                "carbs": {
-                    "type": "number"
-                },
-                "categories": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Category"
-                    }
-                },
-                "comments": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Comment"
-                    }
+                "created_at": {
+                    "type": "string"
                 },
-                "createdAt": {
+                "email": {
                     "type": "string"
                 },
-                "fat": {
-                    "type": "number"
+                "last_name": {
+                    "type": "string"
                 },
-                "favorites": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Favorite"
-                    }
+                "name": {
+                    "type": "string"
                 },
-                "fiber": {
-                    "type": "number"
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "repository.MostPopularRecipe": {
+            "type": "object",
+            "properties": {
+                "favorite_count": {
+                    "type": "integer"
                 },
-                "id": {
+                "recipe_id": {
                     "type": "integer"
                 },
-                "ingredients": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Ingredient"
-                    }
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.RecipeSummary": {
+            "description": "Minimal recipe representation used to highlight \"most popular\" and \"highest rated\" recipes.",
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Favorite count for most-popular recipe; only present if relevant",
+                    "type": "integer",
+                    "example": 120
                 },
-                "protein": {
-                    "type": "number"
+                "id": {
+                    "description": "Unique identifier of the recipe",
+                    "type": "integer",
+                    "example": 7
                 },
-                "ratings": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Rating"
-                    }
+                "score": {
+                    "description": "Rating score for highest-rated recipe (scale 1–5); only present if relevant",
+                    "type": "number",
+                    "example": 4.8
                 },
-                "steps": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Step"
-                    }
+                "title": {
+                    "description": "Title of the recipe",
+                    "type": "string",
+                    "example": "Classic Pancakes"
+                }
+            }
+        },
+        "repository.TimeSeriesData": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
                 },
-                "sugar": {
+                "label": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.TopRatedRecipe": {
+            "type": "object",
+            "properties": {
+                "average": {
                     "type": "number"
                 },
-                "tags": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Tag"
-                    }
-                },
-                "text": {
-                    "type": "string"
+                "recipe_id": {
+                    "type": "integer"
                 },
                 "title": {
                     "type": "string"
                 },
-                "updatedAt": {
-                    "type": "string"
-                },
-                "user_id": {
+                "total_votes": {
                     "type": "integer"
                 }
             }
         },
-        "model.Step": {
+        "repository.UserStats": {
+            "description": "Aggregated statistics based on the user's activity in the system.",
             "type": "object",
-            "required": [
-                "order",
-                "text"
-            ],
             "properties": {
-                "createdAt": {
-                    "type": "string"
+                "average_likes_per_comment": {
+                    "description": "Average number of likes per comment posted by this user",
+                    "type": "number",
+                    "example": 2.3
                 },
-                "id": {
-                    "type": "integer"
+                "average_rating": {
+                    "description": "Average rating across all this user's recipes (scale 1–5)",
+                    "type": "number",
+                    "example": 4.5
                 },
-                "order": {
-                    "type": "integer"
+                "average_rating_given": {
+                    "description": "Average rating value this user gives when rating other recipes (scale 1–5)",
+                    "type": "number",
+                    "example": 3.8
                 },
-                "recipe_id": {
-                    "type": "integer"
+                "total_comments": {
+                    "description": "Total number of comments posted by this user",
+                    "type": "integer",
+                    "example": 34
                 },
-                "text": {
-                    "type": "string"
+                "total_favorites": {
+                    "description": "Total number of times this user's recipes were favorited by others",
+                    "type": "integer",
+                    "example": 87
                 },
-                "updatedAt": {
-                    "type": "string"
+                "total_favorites_given": {
+                    "description": "Total number of recipes this user has favorited",
+                    "type": "integer",
+                    "example": 25
+                },
+                "total_likes_on_comments": {
+                    "description": "Sum of likes across all comments posted by this user",
+                    "type": "integer",
+                    "example": 56
+                },
+                "total_ratings_given": {
+                    "description": "Total number of ratings this user has given to other recipes",
+                    "type": "integer",
+                    "example": 19
+                },
+                "total_recipes": {
+                    "description": "Total number of recipes created by this user",
+                    "type": "integer",
+                    "example": 12
                 }
             }
         },
-        "model.Tag": {
+        "search.Suggestion": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "score": {
+                    "type": "number"
+                },
+                "text": {
                     "type": "string"
                 },
-                "updatedAt": {
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "model.User": {
+        "service.FieldChange": {
             "type": "object",
             "properties": {
-                "comments": {
+                "added": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Comment"
+                        "type": "string"
                     }
                 },
-                "createdAt": {
-                    "type": "string"
-                },
-                "email": {
+                "field": {
                     "type": "string"
                 },
-                "favorites": {
+                "from": {},
+                "removed": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Favorite"
+                        "type": "string"
                     }
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "last_name": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
+                "to": {}
+            }
+        },
+        "service.NutritionReport": {
+            "type": "object",
+            "properties": {
+                "complete": {
+                    "type": "boolean"
                 },
-                "password": {
-                    "type": "string"
+                "matched": {
+                    "type": "integer"
                 },
-                "ratings": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Rating"
-                    }
+                "total": {
+                    "type": "integer"
                 },
-                "recipes": {
+                "unmatched": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Recipe"
+                        "type": "string"
                     }
-                },
-                "role": {
-                    "type": "string"
-                },
-                "salt": {
-                    "type": "string"
-                },
-                "updatedAt": {
-                    "type": "string"
                 }
             }
         }
@@ -0,0 +1,13 @@
+package internal
+
+import "time"
+
+// Clock abstracts time.Now so time-dependent code can be driven from tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the wall clock.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	mysqlDriver "gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// DateBucket is the granularity used when grouping rows by date.
+type DateBucket int
+
+const (
+	DayBucket DateBucket = iota
+	MonthBucket
+)
+
+// Dialect hides the SQL differences between the supported databases so the
+// rest of the code base can stay driver-agnostic.
+type Dialect interface {
+	// Name returns the driver name as used in the configuration.
+	Name() string
+
+	// Open returns the GORM dialector for the given DSN.
+	Open(dsn string) gorm.Dialector
+
+	// DateLabel returns an SQL expression formatting column as a
+	// "YYYY-MM-DD" (DayBucket) or "YYYY-MM" (MonthBucket) label.
+	DateLabel(column string, bucket DateBucket) string
+
+	// IsUniqueViolation reports whether err was caused by a unique constraint.
+	IsUniqueViolation(err error) bool
+}
+
+// NewDialect returns the dialect registered under name.
+func NewDialect(name string) (Dialect, error) {
+	switch strings.ToLower(name) {
+	case "", "mysql":
+		return mysqlDialect{}, nil
+	case "sqlite", "sqlite3":
+		return sqliteDialect{}, nil
+	case "postgres", "postgresql":
+		return postgresDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", name)
+	}
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) Open(dsn string) gorm.Dialector { return mysqlDriver.Open(dsn) }
+
+func (mysqlDialect) DateLabel(column string, bucket DateBucket) string {
+	if bucket == MonthBucket {
+		return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m')", column)
+	}
+	return fmt.Sprintf("DATE_FORMAT(%s, '%%Y-%%m-%%d')", column)
+}
+
+func (mysqlDialect) IsUniqueViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+// Open enables foreign keys unless the DSN already decides it, otherwise the
+// ON DELETE CASCADE constraints declared on the models are ignored.
+func (sqliteDialect) Open(dsn string) gorm.Dialector {
+	if !strings.Contains(dsn, "_foreign_keys") && !strings.Contains(dsn, "_fk") {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn += sep + "_foreign_keys=on"
+	}
+	return sqlite.Open(dsn)
+}
+
+func (sqliteDialect) DateLabel(column string, bucket DateBucket) string {
+	if bucket == MonthBucket {
+		return fmt.Sprintf("strftime('%%Y-%%m', %s)", column)
+	}
+	return fmt.Sprintf("strftime('%%Y-%%m-%%d', %s)", column)
+}
+
+func (sqliteDialect) IsUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) Open(dsn string) gorm.Dialector { return postgres.Open(dsn) }
+
+func (postgresDialect) DateLabel(column string, bucket DateBucket) string {
+	if bucket == MonthBucket {
+		return fmt.Sprintf("to_char(%s, 'YYYY-MM')", column)
+	}
+	return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD')", column)
+}
+
+func (postgresDialect) IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
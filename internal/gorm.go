@@ -1,32 +1,32 @@
 package internal
 
 import (
-	"log"
-	"sync"
+	"fmt"
 
-	"gorm.io/driver/mysql"
+	"github.com/Abb133Se/recepieshare/config"
 	"gorm.io/gorm"
 )
 
-var (
-	db   *gorm.DB
-	once sync.Once
-)
+// OpenDatabase connects to the configured database and applies the pool
+// settings. The returned dialect matches cfg.Driver.
+func OpenDatabase(cfg config.DatabaseConfig) (*gorm.DB, Dialect, error) {
+	dialect, err := NewDialect(cfg.Driver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := gorm.Open(dialect.Open(cfg.DSN), &gorm.Config{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect database: %w", err)
+	}
 
-func GetGormInstance() (*gorm.DB, error) {
-	var err error
-	once.Do(func() {
-		dsn := "root:i1a3a7c7e@Se@tcp(127.0.0.1:3306)/recipes_db?charset=utf8mb4&parseTime=True&loc=Local"
-		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
-		if err != nil {
-			log.Println("failed to connect database:", err)
-			return
-		}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)                // limit open connections
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)                // keep some idle
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime.Duration) // 0 means no forced recycling
 
-		sqlDB, _ := db.DB()
-		sqlDB.SetMaxOpenConns(20)   // limit open connections
-		sqlDB.SetMaxIdleConns(10)   // keep some idle
-		sqlDB.SetConnMaxLifetime(0) // no forced recycling
-	})
-	return db, err
+	return db, dialect, nil
 }
@@ -23,12 +23,6 @@ type LocalStorage struct {
 	BasePath string // e.g. "uploads"
 }
 
-var Backend StorageBackend
-
-func InitLocalStorage(basePath string) {
-	Backend = NewLocalStorage(basePath)
-}
-
 func NewLocalStorage(basePath string) *LocalStorage {
 	return &LocalStorage{BasePath: basePath}
 }
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileMailer writes every message as an .eml file into a directory, which is
+// handy for local development and for inspecting links in emails.
+type FileMailer struct {
+	from string
+	dir  string
+}
+
+func NewFileMailer(from, dir string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &FileMailer{from: from, dir: dir}, nil
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	now := time.Now()
+	name := fmt.Sprintf("%d.eml", now.UnixNano())
+	return os.WriteFile(filepath.Join(m.dir, name), encode(m.from, msg, now), 0o644)
+}
+
+// WriterMailer prints messages to a writer, by default stdout.
+type WriterMailer struct {
+	from string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+func NewStdoutMailer(from string) *WriterMailer {
+	return &WriterMailer{from: from, w: os.Stdout}
+}
+
+func (m *WriterMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := fmt.Fprintf(m.w, "----- mail -----\r\n%s----- end mail -----\r\n", encode(m.from, msg, time.Now()))
+	return err
+}
@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/config"
+)
+
+// SMTPMailer sends through an SMTP relay. net/smtp upgrades to STARTTLS when
+// the server offers it.
+type SMTPMailer struct {
+	from string
+	addr string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(from string, cfg config.SMTPConfig) *SMTPMailer {
+	m := &SMTPMailer{
+		from: from,
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+	}
+	if cfg.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return m
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, encode(m.from, msg, time.Now())); err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", msg.To, err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package mailer
+
+import (
+	"github.com/Abb133Se/recepieshare/messages"
+)
+
+// Template is a localized email. Subject and body are translation keys whose
+// texts may use the placeholders {name} and {link}.
+type Template struct {
+	Subject messages.Message
+	Body    messages.Message
+}
+
+var (
+	PasswordReset = Template{
+		Subject: messages.Email.PasswordResetSubject,
+		Body:    messages.Email.PasswordResetBody,
+	}
+	AccountVerification = Template{
+		Subject: messages.Email.VerificationSubject,
+		Body:    messages.Email.VerificationBody,
+	}
+)
+
+// LinkData is the template data of emails that carry a single link.
+type LinkData struct {
+	Name string
+	Link string
+}
+
+func (d LinkData) args() messages.Args {
+	return messages.Args{"name": d.Name, "link": d.Link}
+}
+
+// Render builds the message for to in the language of l.
+func Render(l messages.Localizer, t Template, to string, data LinkData) Message {
+	args := data.args()
+	return Message{To: to, Subject: l.T(t.Subject, args), Body: l.T(t.Body, args)}
+}
@@ -1,49 +1,265 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/Abb133Se/recepieshare/app"
+	"github.com/Abb133Se/recepieshare/config"
 	_ "github.com/Abb133Se/recepieshare/docs"
 	"github.com/Abb133Se/recepieshare/internal"
-	"github.com/Abb133Se/recepieshare/middleware"
+	"github.com/Abb133Se/recepieshare/messages"
 	"github.com/Abb133Se/recepieshare/migrate"
+	"github.com/Abb133Se/recepieshare/nutrition"
+	"github.com/Abb133Se/recepieshare/repository"
 	"github.com/Abb133Se/recepieshare/routes"
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
+	"github.com/Abb133Se/recepieshare/service"
+	"github.com/Abb133Se/recepieshare/token"
+)
+
+const (
+	migrateUsage   = "usage: migrate up | down [N] | status | create <name>"
+	messagesUsage  = "usage: messages check"
+	nutritionUsage = "usage: nutrition import <file.csv> | prune | refresh"
 )
 
 func main() {
-	r := gin.Default()
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("failed to load configuration: %v", err)
+	}
+	// migrate create only writes a file, so it runs on any configuration.
+	if len(args) < 2 || args[0] != "migrate" || args[1] != "create" {
+		if err := cfg.Validate(); err != nil {
+			log.Fatalf("failed to load configuration: %v", err)
+		}
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			if err := runMigrate(cfg, args[1:]); err != nil {
+				log.Fatalf("migrate: %v", err)
+			}
+			return
+		case "messages":
+			if err := runMessages(cfg, args[1:]); err != nil {
+				log.Fatalf("messages: %v", err)
+			}
+			return
+		case "nutrition":
+			if err := runNutrition(cfg, args[1:]); err != nil {
+				log.Fatalf("nutrition: %v", err)
+			}
+			return
+		default:
+			log.Fatalf("unknown command %q", args[0])
+		}
+	}
+
+	a, err := app.New(cfg)
+	if err != nil {
+		log.Fatalf("failed to initialize application: %v", err)
+	}
+	token.Init(cfg.JWT)
+
+	pending, err := migrate.New(a.DB).Pending()
+	if err != nil {
+		log.Fatalf("failed to check migrations: %v", err)
+	}
+	if len(pending) > 0 {
+		log.Fatalf("%d migration(s) pending, run `migrate up` first", len(pending))
+	}
+
+	if err := a.Search.Rebuild(); err != nil {
+		log.Fatalf("failed to build search index: %v", err)
+	}
+	if err := a.Suggest.Rebuild(); err != nil {
+		log.Fatalf("failed to build suggestions: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a.Scheduler.Start(ctx)
+	a.Jobs.Start(ctx)
+	a.Search.Start(ctx)
+	a.Suggest.Start(ctx)
+
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler: routes.NewRouter(a),
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("impossible to start server: %s", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Printf("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to shut down server: %v", err)
+	}
+	// Running jobs see ctx cancelled and are retried on the next start.
+	a.Jobs.Wait()
+}
 
-	r.Use(middleware.SiteVisitMiddleware())
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	// create only writes a file and must work without a database.
+	if args[0] == "create" {
+		if len(args) < 2 {
+			return errors.New("usage: migrate create <name>")
+		}
+		path, err := migrate.Create("migrate", strings.Join(args[1:], "_"))
+		if err != nil {
+			return err
+		}
+		fmt.Println("created", path)
+		return nil
+	}
+
+	db, _, err := internal.OpenDatabase(cfg.Database)
+	if err != nil {
+		return err
+	}
+	m := migrate.New(db)
 
-	// for future use the specific methods and config needed
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:4200"}, // your frontend URL
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+	switch args[0] {
+	case "up":
+		n, err := m.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migration(s)\n", n)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of migrations: %s", args[1])
+			}
+		}
+		n, err := m.Down(steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reverted %d migration(s)\n", n)
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Missing {
+				state += " (missing)"
+			}
+			fmt.Printf("%04d  %-30s  %s\n", s.Version, s.Name, state)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
 
-	internal.InitLocalStorage("uploads")
+// runMessages checks the translation catalogs, those in i18n.dir when set,
+// against the declared messages.
+func runMessages(cfg *config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "check" {
+		return errors.New(messagesUsage)
+	}
 
-	routes.AddRoutes(r)
+	var set map[string]messages.Catalog
+	if cfg.I18n.Dir != "" {
+		var err error
+		set, err = messages.LoadFS(os.DirFS(cfg.I18n.Dir))
+		if err != nil {
+			return err
+		}
+	}
 
-	db, err1 := internal.GetGormInstance()
-	if err1 != nil {
-		fmt.Println("not connected")
+	problems := messages.Check(set)
+	for _, p := range problems {
+		fmt.Println(p)
 	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(problems))
+	}
+	fmt.Println("all catalogs complete")
+	return nil
+}
 
-	if err := migrate.AutoMigration(db); err != nil {
-		log.Fatalf("Migration failed: %v", err)
+// runNutrition imports a food-composition CSV into the local food table or
+// prunes expired entries from the nutrition cache.
+func runNutrition(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(nutritionUsage)
 	}
 
-	err := r.Run(":3000")
+	db, dialect, err := internal.OpenDatabase(cfg.Database)
 	if err != nil {
-		log.Fatalf("impossible to start server: %s", err)
+		return err
+	}
+	repos := repository.New(db, dialect)
+
+	switch args[0] {
+	case "import":
+		if len(args) != 2 {
+			return errors.New("usage: nutrition import <file.csv>")
+		}
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		n, err := nutrition.ImportCSV(f, repos.Foods)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("imported %d food(s)\n", n)
+	case "prune":
+		n, err := repos.Nutrition.DeleteBefore(time.Now().Add(-cfg.Nutrition.CacheTTL.Duration))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("removed %d cache entries\n", n)
+	case "refresh":
+		// The running server picks the jobs up.
+		ids, err := repos.Recipes.IDs()
+		if err != nil {
+			return err
+		}
+		jobs := service.NewJobQueue(repos, internal.SystemClock{}, cfg.Jobs)
+		for _, id := range ids {
+			if err := jobs.Enqueue(repos, service.JobEstimateNutrition, service.EstimateNutritionPayload{RecipeID: id}); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("queued %d nutrition estimate(s)\n", len(ids))
+	default:
+		return errors.New(nutritionUsage)
 	}
+	return nil
 }
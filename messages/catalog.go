@@ -0,0 +1,192 @@
+package messages
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Entry is one translated message. Plural messages have Forms keyed by CLDR
+// plural category instead of Text.
+type Entry struct {
+	Text  string
+	Forms map[string]string
+}
+
+// Catalog holds the messages of one locale.
+type Catalog map[string]Entry
+
+//go:embed locales
+var embedded embed.FS
+
+// catalogs maps locale to catalog. It is swapped as a whole on reload, so
+// readers never see a half loaded set.
+var catalogs atomic.Pointer[map[string]Catalog]
+
+func init() {
+	sub, err := fs.Sub(embedded, "locales")
+	if err != nil {
+		panic(err)
+	}
+	loaded, err := LoadFS(sub)
+	if err != nil {
+		panic(fmt.Sprintf("messages: embedded catalogs: %v", err))
+	}
+	catalogs.Store(&loaded)
+}
+
+func current() map[string]Catalog {
+	return *catalogs.Load()
+}
+
+// Locales returns the locales that have a catalog, sorted.
+func Locales() []string {
+	var locales []string
+	for locale := range current() {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// LoadFS reads every <locale>.json and <locale>.toml file in the root of fsys.
+// The file name, lower-cased, is the locale (en.toml, fa-ir.json, ...).
+func LoadFS(fsys fs.FS) (map[string]Catalog, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := map[string]Catalog{}
+	for _, e := range entries {
+		ext := path.Ext(e.Name())
+		if e.IsDir() || (ext != ".json" && ext != ".toml") {
+			continue
+		}
+		locale := normalizeTag(strings.TrimSuffix(e.Name(), ext))
+		if _, ok := loaded[locale]; ok {
+			return nil, fmt.Errorf("%s: duplicate catalog for locale %q", e.Name(), locale)
+		}
+
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		catalog, err := parseCatalog(ext, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		loaded[locale] = catalog
+	}
+
+	if _, ok := loaded[defaultLang]; !ok {
+		return nil, fmt.Errorf("no catalog for the default locale %q", defaultLang)
+	}
+	return loaded, nil
+}
+
+func parseCatalog(ext string, data []byte) (Catalog, error) {
+	raw := map[string]any{}
+	var err error
+	if ext == ".toml" {
+		err = toml.Unmarshal(data, &raw)
+	} else {
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := Catalog{}
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			catalog[key] = Entry{Text: v}
+		case map[string]any:
+			forms := map[string]string{}
+			for category, text := range v {
+				s, ok := text.(string)
+				if !ok || !isPluralCategory(category) {
+					return nil, fmt.Errorf("%s: invalid plural form %q", key, category)
+				}
+				forms[category] = s
+			}
+			if _, ok := forms["other"]; !ok {
+				return nil, fmt.Errorf("%s: plural message without an \"other\" form", key)
+			}
+			catalog[key] = Entry{Forms: forms}
+		default:
+			return nil, fmt.Errorf("%s: value must be a string or a table of plural forms", key)
+		}
+	}
+	return catalog, nil
+}
+
+// LoadDir replaces the embedded catalogs with the ones found in dir.
+func LoadDir(dir string) error {
+	loaded, err := LoadFS(os.DirFS(dir))
+	if err != nil {
+		return fmt.Errorf("failed to load catalogs from %s: %w", dir, err)
+	}
+	catalogs.Store(&loaded)
+	return nil
+}
+
+// Watch reloads the catalogs in dir whenever one of its files changes,
+// checking every interval. It is meant for development; a broken catalog is
+// logged and the previous one kept. Call the returned function to stop.
+func Watch(dir string, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	last := dirStamp(dir)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				stamp := dirStamp(dir)
+				if stamp == last {
+					continue
+				}
+				last = stamp
+				if err := LoadDir(dir); err != nil {
+					log.Printf("messages: %v", err)
+					continue
+				}
+				log.Printf("messages: reloaded catalogs from %s", dir)
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// dirStamp summarises names, sizes and modification times of the files in dir.
+func dirStamp(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", e.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String()
+}
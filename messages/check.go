@@ -0,0 +1,121 @@
+package messages
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Problem is an inconsistency found by Check.
+type Problem struct {
+	Locale string
+	Key    string
+	Issue  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Locale, p.Key, p.Issue)
+}
+
+// Keys returns the keys of all declared messages, sorted.
+func Keys() []string {
+	var keys []string
+	for _, group := range groups {
+		v := reflect.ValueOf(group)
+		for i := 0; i < v.NumField(); i++ {
+			if m, ok := v.Field(i).Interface().(Message); ok {
+				keys = append(keys, m.Key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Check compares the given catalogs (the loaded ones when nil) with the
+// declared messages. It reports keys missing in a locale, keys no message
+// uses, plural messages lacking a form the locale needs and placeholders
+// that differ from the default locale.
+func Check(set map[string]Catalog) []Problem {
+	if set == nil {
+		set = current()
+	}
+
+	var problems []Problem
+	declared := map[string]bool{}
+	for _, key := range Keys() {
+		declared[key] = true
+	}
+
+	locales := make([]string, 0, len(set))
+	for locale := range set {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	for _, locale := range locales {
+		catalog := set[locale]
+		rule := pluralRuleFor(locale)
+
+		for _, key := range Keys() {
+			entry, ok := catalog[key]
+			if !ok {
+				problems = append(problems, Problem{locale, key, "missing"})
+				continue
+			}
+
+			if entry.Forms != nil {
+				for _, category := range rule.categories {
+					if _, ok := entry.Forms[category]; !ok {
+						problems = append(problems, Problem{locale, key, fmt.Sprintf("missing plural form %q", category)})
+					}
+				}
+			}
+
+			if locale == defaultLang {
+				continue
+			}
+			if base, ok := set[defaultLang][key]; ok {
+				want, got := entryPlaceholders(base), entryPlaceholders(entry)
+				if want != got {
+					problems = append(problems, Problem{locale, key, fmt.Sprintf("placeholders {%s} differ from %s {%s}", got, defaultLang, want)})
+				}
+			}
+		}
+
+		var unused []string
+		for key := range catalog {
+			if !declared[key] {
+				unused = append(unused, key)
+			}
+		}
+		sort.Strings(unused)
+		for _, key := range unused {
+			problems = append(problems, Problem{locale, key, "not declared in messages.go"})
+		}
+	}
+
+	return problems
+}
+
+// entryPlaceholders returns the sorted, distinct placeholders of all forms.
+func entryPlaceholders(e Entry) string {
+	seen := map[string]bool{}
+	collect := func(text string) {
+		for _, name := range placeholders(text) {
+			seen[name] = true
+		}
+	}
+	collect(e.Text)
+	for _, text := range e.Forms {
+		collect(text)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
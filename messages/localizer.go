@@ -0,0 +1,224 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Localizer translates messages for one negotiated locale. Keys missing in
+// the preferred language fall back along its chain, e.g. fa-ir → fa → en.
+type Localizer struct {
+	chain []string
+}
+
+// NewLocalizer builds a localizer for the given language tags in order of
+// preference. Every tag is followed by its parent languages and the chain
+// always ends with the default language.
+func NewLocalizer(tags ...string) Localizer {
+	var chain []string
+	seen := map[string]bool{}
+	add := func(tag string) {
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			chain = append(chain, tag)
+		}
+	}
+
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		for tag != "" {
+			add(tag)
+			i := strings.LastIndex(tag, "-")
+			if i < 0 {
+				break
+			}
+			tag = tag[:i]
+		}
+	}
+	add(defaultLang)
+
+	return Localizer{chain: chain}
+}
+
+// Negotiate picks a localizer from an Accept-Language header value.
+func Negotiate(acceptLanguage string) Localizer {
+	return NewLocalizer(ParseAcceptLanguage(acceptLanguage)...)
+}
+
+// Lang returns the most preferred language that has translations.
+func (l Localizer) Lang() string {
+	all := current()
+	for _, lang := range l.chain {
+		if _, ok := all[lang]; ok {
+			return lang
+		}
+	}
+	return defaultLang
+}
+
+// Args are the values of named placeholders such as {count}. A "count"
+// argument also selects the plural form of plural messages.
+type Args map[string]any
+
+// T returns m translated along the fallback chain, or its key when no
+// language has it. Placeholders are filled from args.
+func (l Localizer) T(m Message, args ...Args) string {
+	chain := l.chain
+	if len(chain) == 0 {
+		chain = []string{defaultLang}
+	}
+
+	merged := Args{}
+	for _, a := range args {
+		for k, v := range a {
+			merged[k] = v
+		}
+	}
+
+	all := current()
+	for _, lang := range chain {
+		entry, ok := all[lang][m.Key]
+		if !ok {
+			continue
+		}
+		text := entry.Text
+		if entry.Forms != nil {
+			text = entry.Forms[PluralCategory(lang, merged["count"])]
+			if text == "" {
+				text = entry.Forms["other"]
+			}
+		}
+		return interpolate(text, merged)
+	}
+	return m.Key
+}
+
+// interpolate replaces {name} with args["name"]. Unknown placeholders and
+// braces that do not enclose an identifier are left alone.
+func interpolate(text string, args Args) string {
+	if len(args) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+
+	var b strings.Builder
+	for {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(text[open:], '}')
+		if end < 0 {
+			break
+		}
+		name := text[open+1 : open+end]
+		value, ok := args[name]
+		if !ok || !isIdentifier(name) {
+			b.WriteString(text[:open+1])
+			text = text[open+1:]
+			continue
+		}
+		b.WriteString(text[:open])
+		fmt.Fprint(&b, value)
+		text = text[open+end+1:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// placeholders returns the {name} placeholders used in text.
+func placeholders(text string) []string {
+	var names []string
+	for {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			return names
+		}
+		end := strings.IndexByte(text[open:], '}')
+		if end < 0 {
+			return names
+		}
+		if name := text[open+1 : open+end]; isIdentifier(name) {
+			names = append(names, name)
+			text = text[open+end+1:]
+		} else {
+			text = text[open+1:]
+		}
+	}
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header
+// ordered by their q-value. Tags with q=0 and the wildcard are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := normalizeTag(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			q = parsed
+		}
+		if q == 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, q})
+	}
+
+	// Equal weights keep the order the client sent them in.
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+type localizerKey struct{}
+
+// WithLocalizer returns a copy of ctx carrying l.
+func WithLocalizer(ctx context.Context, l Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, l)
+}
+
+// FromContext returns the localizer stored by WithLocalizer.
+func FromContext(ctx context.Context) (Localizer, bool) {
+	l, ok := ctx.Value(localizerKey{}).(Localizer)
+	return l, ok
+}
@@ -0,0 +1,64 @@
+package messages
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"fa", []string{"fa"}},
+		{"en-US,en;q=0.9,fa;q=0.8", []string{"en-us", "en", "fa"}},
+		{"fa;q=0.5, de_DE;q=0.7", []string{"de-de", "fa"}},
+		{"en;q=0.5,fr,de", []string{"fr", "de", "en"}},
+		{"*, fa;q=0", []string{}},
+		{"fa;q=abc, en", []string{"en"}},
+		{"fa;q=2", []string{}},
+		{" , en ;q=0.3", []string{"en"}},
+	}
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNewLocalizerChain(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{nil, []string{"en"}},
+		{[]string{"fa-IR"}, []string{"fa-ir", "fa", "en"}},
+		{[]string{"zh-Hant-TW", "fa"}, []string{"zh-hant-tw", "zh-hant", "zh", "fa", "en"}},
+		{[]string{"en-GB", "en"}, []string{"en-gb", "en"}},
+	}
+	for _, tt := range tests {
+		if got := NewLocalizer(tt.tags...).chain; !slices.Equal(got, tt.want) {
+			t.Errorf("NewLocalizer(%q) chain = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	tests := []struct {
+		text string
+		args Args
+		want string
+	}{
+		{"Hello {name}", Args{"name": "Sara"}, "Hello Sara"},
+		{"{count} recipes", Args{"count": 3}, "3 recipes"},
+		{"Hello {name}", nil, "Hello {name}"},
+		{"Keep {unknown} and {not an id}", Args{"name": "x"}, "Keep {unknown} and {not an id}"},
+		{"Unclosed {name", Args{"name": "x"}, "Unclosed {name"},
+	}
+	for _, tt := range tests {
+		if got := interpolate(tt.text, tt.args); got != tt.want {
+			t.Errorf("interpolate(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
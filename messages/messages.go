@@ -1,278 +1,5 @@
 package messages
 
-import (
-	"fmt"
-	"strings"
-)
-
-var translations = map[string]map[string]string{
-	"en": {
-		// Common
-		"SUCCESS":           "Operation completed successfully",
-		"Failed":            "Operation failed",
-		"Unauthorized":      "Unauthorized",
-		"Forbidden":         "Forbidden",
-		"BadRequest":        "Bad Request",
-		"InternalServerErr": "Internal server error",
-		"NotFound":          "Not found",
-		"DBConnectionErr":   "Dtabase connection error",
-
-		// Recipe
-		"RecipeNotFound":             "Recipe not found",
-		"RecipeCreated":              "Recipe created successfully",
-		"RecipeCreateFailed":         "Failed to create recipe",
-		"RecipeUpdated":              "Recipe updated successfully",
-		"RecipeDeleted":              "Recipe deleted successfully",
-		"RecipeDeleteFail":           "Failed to delete recipe",
-		"RecipeDeleteForbidden":      "Recipe delete forbidden",
-		"RecipeHasNoIngredient":      "Recipe has no igrediens",
-		"RecipeIngredientsOk":        "Ingredients fetched succussfully",
-		"RecipeFetchFail":            "Failed to fetch recipe",
-		"RecipeIngredientsFetchFail": "Faled to fetch recipe ingredients",
-		"RecipeUpdateFail":           "Failed to update recipe",
-		"RecipeNutritionFail":        "Failed to fetch recipe nutritional valuse",
-		"RecipeTagFetchFail":         "Failed to fetch recipe tags",
-		"RecipeTagCreateFail":        "Failed to create tag(s)",
-		"RecipeTagQueryFail":         "Failed to query tags(s)",
-		"RecipeTagUpdateFail":        "Failed to update recipe tag(s)",
-		"RecipeTagUpdated":           "Recipe tags updated",
-		"RecipeTagDeleteFail":        "Failed to delete recipe tag(s)",
-		"RecipeTagDeleted":           "recipe tag(s) deleted succussfully",
-		"RecipeCatsFetcFail":         "Failed to fetch recipe categories",
-		"RecipeCatsDeleteFail":       "Faild to delete recipe categories",
-		"RecipeCatsDeleted":          "Recipe categories deleted successfully",
-
-		// User
-		"LoginInvalidEmailPass":     "Invalid email or password",
-		"UserAlreadyExists":         "User already exists",
-		"UserCreatedSuccess":        "User created successfully",
-		"UserCreateFailed":          "Failed to create user",
-		"UserNotFound":              "User not found",
-		"PasswordResetSent":         "Passwor reset link sent to email",
-		"PasswordResetFailed":       "failed to send reset password link",
-		"PasswordResetSuccess":      "Password reset successfully",
-		"TokenExpired":              "Token is expired",
-		"TokenInvalid":              "Token is invalid",
-		"EmptyInfoErr":              "User information shouldn't be empty",
-		"EmailCheckErr":             "Failed to check email",
-		"EmailExistsErr":            "Email ALready exists",
-		"UserFetchFail":             "Failed to trtrieve user",
-		"GeneratTokenFail":          "Failed to generae token",
-		"GenerateSaltFail":          "Failed to generate Salt",
-		"PasswordResetCreateFailed": "Failed to store reset token",
-		"UserStatFetchFail":         "Failed to fetch user stats",
-
-		// DB
-		"DB_ERROR": "Database error",
-		"DB_SAVE":  "Failed to save record",
-		"DB_CONN":  "Database connection failed",
-
-		// Comment
-		"CommentNotFound":        "Comment not found",
-		"CommentPosted":          "Comment posted successfully",
-		"CommentDeleted":         "Comment deleted successfully",
-		"CommentAlreadyExists":   "Comment already exists",
-		"CommentDeleteForbidden": "Comment deletion forbidden",
-		"CommentLikeSuccess":     "Comment liked successfully",
-		"CommentDislikeSuccess":  "Comment diliked successfully",
-		"CommnetFetchFail":       "Failed to fetch comment",
-		"CommentPostFail":        "Failed to post comment",
-		"CommentPost":            "Posted comment successfully",
-		"CommentDeleteFail":      "Failed to delete comment",
-		"CommentDislikeFail":     "Failed to dislike comment",
-		"CommentLikeFail":        "Failed to like comment",
-
-		// Favorite
-		"FavoriteAdded":           "Added to favorites",
-		"FavoriteRemoved":         "Removed from favorites",
-		"FavoriteNotFound":        "Removed not found",
-		"FavoriteExists":          "Favorite already exists",
-		"FavoriteFailed":          "Fialed to do operation on favorite",
-		"FavoriteAddFail":         "Failed to add favorite",
-		"FavoriteRemoveQueryFail": "Failed to query favorites",
-		"FavoriteRemoveFail":      "Failed to remove favorite",
-
-		// Rating
-		"RatingNotFound":        "Rating not found",
-		"RatingAdded":           "Rating submitted",
-		"RatingUpdated":         "Rating updated",
-		"RatingDeleted":         "Rating deleted",
-		"RatingInvalidScore":    "Invalid rating score",
-		"RatingUpdateFailed":    "Failed to update rating",
-		"RatingDeleteForbidden": "Rating deletion forbidden",
-		"RatingAddFail":         "Failed to add rating",
-		"RatingDeleteFail":      "Failed to delete rating",
-		"RatingFetchFail":       "Failed to fetch rating",
-
-		// Image
-		"ImageUploaded":        "Image uploaded successfully",
-		"ImageDeleted":         "Image deleted successfully",
-		"ImageNotFound":        "Image not found",
-		"ImageUploadForbidden": "Image uploaded forbidden",
-		"ImageDeleteForbidden": "Image delete forbidden",
-		"ImageServeFailed":     "Failed to load the image",
-
-		// Category
-		"CatCreationOk":               "Category created succussful",
-		"CatCreationFailed":           "Failed to create catifry",
-		"CatAlreadyExists":            "Category Already exists",
-		"CatUploadOK":                 "Category uploaded successfully",
-		"CatUploadFailed":             "Failed to update category",
-		"CatDeletionOk":               "Category deleted successfully",
-		"CatDeletionFaied":            "Failed to delete category",
-		"CatFetchFailed":              "Failed to fetch category",
-		"CatFailedAssocioationRemova": "Failed to delete category associations",
-		"CatNotFound":                 "Category Not Found",
-		"CatUpdateFail":               "Failed to update category",
-		"CatUpdateOk":                 "Category updated successfully",
-
-		// Tag
-		"TagCreationOk":               "Tag created succussful",
-		"TagCreationFailed":           "Failed to create catifry",
-		"TagAlreadyExists":            "Tag Already exists",
-		"TagUploadOK":                 "Tag uploaded successfully",
-		"TagUploadFailed":             "Failed to update tag",
-		"TagDeletionOk":               "Tagegory deleted successfully",
-		"TagDeletionFaied":            "failed to delete tag",
-		"TagFetchFailed":              "Failed to fetch tag",
-		"TagFailedAssocioationRemova": "Failed to delete tag associations",
-		"TagNotFound":                 "Tag Not Found",
-	},
-
-	"fa": {
-		// Common
-		"SUCCESS":           "عملیات با موفقیت انجام شد",
-		"Failed":            "عملیات ناموفق بود",
-		"Unauthorized":      "دسترسی غیرمجاز",
-		"Forbidden":         "اجازه دسترسی ندارید",
-		"BadRequest":        "درخواست نامعتبر",
-		"InternalServerErr": "خطای داخلی سرور",
-		"NotFound":          "یافت نشد",
-		"DBConnectionErr":   "خطا در اتصال به پایگاه داده",
-
-		// Recipe
-		"RecipeNotFound":             "دستور پخت یافت نشد",
-		"RecipeCreated":              "دستور پخت با موفقیت ساخته شد",
-		"RecipeCreateFailed":         "ساخت دستور پخت با خطا مواجه شد",
-		"RecipeUpdated":              "دستور پخت با موفقیت بروزرسانی شد",
-		"RecipeDeleted":              "دستوز پخت با موفقیت حذف شد",
-		"RecipeDeleteFail":           "خطا در حذف دستور پخت",
-		"RecipeDeleteForbidden":      "اجازه حذف این دستور را ندارید",
-		"RecipeHasNoIngredient":      "دستور پخت هیچ ماده اولیه ای ندارد",
-		"RecipeIngredientsOk":        "مواد اولیه با موفقیت دریافت شد",
-		"RecipeFetchFail":            "خطا در درسافت دستور پخت",
-		"RecipeIngredientsFetchFail": "خطا در باگذاری مواد اولیه دستور پخت",
-		"RecipeUpdateFail":           "خطا در بروزرسانی دستورپخت",
-		"RecipeNutritionFail":        "خطا در دریاف اطلاعات ارزش غذایی",
-		"RecipeTagFetchFail":         "خطا در دریافت برچسب های دستور پخت",
-		"RecipeTagCreateFail":        "خطا در ساخت برچسب ها",
-		"RecipeTagQueryFail":         "خطا در ارسال برچسب ها",
-		"RecipeTagUpdateFail":        "خطا در بروزرسانی برچسب ها",
-		"RecipeTagUpdated":           "با موفقیت بروزرسانی شد",
-		"RecipeTagDeleteFail":        "خطا در حذف برچسب",
-		"RecipeTagDeleted":           "حذف برچسب با موفقیت انجام شد",
-		"RecipeCatsFetcFail":         "خطا در دریافت دسته بندی ها",
-		"RecipeCatsDeleteFail":       "خطا در حذف دسته بندی ها",
-		"RecipeCatsDeleted":          "دسته بندی ها با موفقیت حذف شد",
-
-		// User
-		"LoginInvalidEmailPass":     "ایمیل یا رمز عبور نامعتبر است",
-		"UserAlreadyExists":         "کاربر با این ایمیل قبلا ثبت شده",
-		"UserCreatedSuccess":        "کاربر با موفقیت ثبت شد",
-		"UserCreateFailed":          "خطا در ایجاد کاربر",
-		"UserNotFound":              "کاربر یافت نشد",
-		"PasswordResetSent":         "درصورت وجود ایمیل لینک بازیابی ارسال شد",
-		"PasswordResetFailed":       "خطا در ارسال لینک بازیابی",
-		"PasswordResetSuccess":      "رمز عبور با موفقیت تغییر کرد",
-		"TokenExpired":              "توکن منقضی شده است",
-		"TokenInvalid":              "توکن نامعتبر است",
-		"EmptyInfoErr":              "اطلاعات کاربری نباید خالی باشند",
-		"EmailCheckErr":             "خطا در بررسی ایمیل",
-		"EmailExistsErr":            "این ایمیل قبلا در سایت ثبت شده است",
-		"UserFetchFail":             "خطا در دریافت اطلاعات کاربر",
-		"GeneratTokenFail":          "خطا در ساخت توکن",
-		"GenerateSaltFail":          "خطا در ساخت سالت",
-		"PasswordResetCreateFailed": "خطا در ذخیره سازی توکن بازیابی",
-		"UserStatFetchFail":         "خطا در بارگذاری اطلاعات کاربر",
-
-		// DB
-		"DB_ERROR": "خطای پایگاه داده",
-		"DB_SAVE":  "ذخیره رکورد با شکست مواجه شد",
-		"DB_CONN":  "اتصال به پایگاه داده برقرار نشد",
-
-		// Comment
-		"CommentNotFound":        "نظر یافت نشد",
-		"CommentPosted":          "نظر با موفقیت اضافه شد",
-		"CommentDeleted":         "نظر با موفقیت حذف شد",
-		"CommentAlreadyExists":   "شما قبلا به این دستور نظر داده اید",
-		"CommentDeleteForbidden": "شا مجاز به حذف این تسور نیستید",
-		"CommentLikeSuccess":     "نظر با موفقیت لایک شد",
-		"CommentDislikeSuccess":  "نظر با موفقیت دیسلایک شد",
-		"CommnetFetchFail":       "خطا در بارگذاری نظر",
-		"CommentPostFail":        "خطا در پست کردن نظر",
-		"CommentPost":            "نظر با موفقیت پست شد",
-		"CommentDeleteFail":      "نظر با موفقیت حذف شد",
-		"CommentDislikeFail":     "خطا در دیسلایک کردن نظر",
-		"CommentLikeFail":        "خطا در لایک کردن نظر",
-
-		// Favorite
-		"FavoriteAdded":           "به علاقه مندی ها اضافه شد",
-		"FavoriteRemoved":         "از علاقه مندی ها حذف شد",
-		"FavoriteNotFound":        "عالقه مندی یافت نشد",
-		"FavoriteExists":          "علاقه مندیقبلا اضافه شده است",
-		"FavoriteFailed":          "خطا در انجاام دستورات علاقه مندی ها",
-		"FavoriteAddFail":         "خطا در اضافه کردن علاقه مندی",
-		"FavoriteRemoveQueryFail": "خطا در ارسال دستور حذف",
-		"FavoriteRemoveFail":      "خطا در حذف علاقه مندی",
-
-		// Rating
-		"RatingNotFound":        "امتیاز یافت نشد",
-		"RatingAdded":           "امتیاز با موفقیت ثبت شد",
-		"RatingUpdated":         "امتیاز بروز رسانی شد",
-		"RatingDeleted":         "امتیاز با موفقیت حذف شد",
-		"RatingInvalidScore":    "امتیاز باید بین 1 تا 5 باشد",
-		"RatingUpdateFailed":    "خطا در بروزرسانی امتیاز",
-		"RatingDeleteForbidden": "اجازه حذف این امتیاز را ندارید",
-		"RatingAddFail":         "خطا در اضافه کردن امتیاز",
-		"RatingDeleteFail":      "خطا در حذف امتیاز",
-		"RatingFetchFail":       "خطا در دریافت امتیاز",
-
-		// Image
-		"ImageUploaded":        "تصویر با موفقیت بارگذاری شد",
-		"ImageDeleted":         "تصویر با موفقیت حذف شد",
-		"ImageNotFound":        "تصویر یافت نشد",
-		"ImageUploadForbidden": "اجازه بارگذاری تصویر برای این مورد را ندارید",
-		"ImageDeleteForbidden": "اجازه حذف این تصویر را ندارید",
-		"ImageServeFailed":     "خطا در بارگذاری تصویر",
-
-		// Category
-		"CatCreationOk":               "دسته بندی با موفقیت ساخته شد",
-		"CatCreationFailed":           "خطا در ساخت دسته بندی",
-		"CatAlreadyExists":            "این دسته بندی موجود میباشد",
-		"CatUploadOK":                 "دسته بندی با موفقیت برورسانی شد",
-		"CatUploadFailed":             "خطا در بروزرسانی دسته بندی",
-		"CatDeletionOk":               "دسته بندی با موفقیت حذف شد",
-		"CatDeletionFaied":            "خطا در حذف دسته بندی",
-		"CatFetchFailed":              "خطا در دریافت دسته بندی",
-		"CatFailedAssocioationRemova": "خطا در حذف ارتباطات دسته بندی",
-		"CatNotFound":                 "دسته بندی یافت نشد",
-		"CatUpdateFail":               "خطا در بروز رسانی دسته بندی",
-		"CatUpdateOk":                 "دسته بندی با موفقیت بروز رسانی شد",
-
-		// Tag
-		"TagCreationOk":               "برچسب با موفقیت ایجاد شد",
-		"TagCreationFailed":           "خطا در ایجا برچسب",
-		"TagAlreadyExists":            "برچسب موجود است",
-		"TagUploadOK":                 "برچسب با موفیت بروز رسانی شد",
-		"TagUploadFailed":             "خطا در بروز رسانی برچسب",
-		"TagDeletionOk":               "برچسب با موفقیت حذف شد",
-		"TagDeletionFaied":            "خطا در حذف برچسب",
-		"TagFetchFailed":              "خطا در دریافت برچسب",
-		"TagFailedAssocioationRemova": "خطا در حذف ارتباطات برچسب",
-		"TagNotFound":                 "برچسب یافت نشد",
-	},
-}
-
 var Common = struct {
 	Success           Message
 	Failed            Message
@@ -317,6 +44,53 @@ var Recipe = struct {
 	RecipeCatsFetcFail         Message
 	RecipeCatsDeleteFail       Message
 	RecipeCatsDeleted          Message
+	RevisionNotFound           Message
+	RevisionFetchFail          Message
+	RevisionRestored           Message
+	RevisionRestoreFail        Message
+	StatusInvalid              Message
+	PublishAtInvalid           Message
+	StatusUpdated              Message
+	StatusUpdateFail           Message
+	VisibilityInvalid          Message
+	VisibilityUpdated          Message
+	VisibilityUpdateFail       Message
+	ShareLinkRotated           Message
+	ShareLinkUnavailable       Message
+	InviteAdded                Message
+	InviteRemoved              Message
+	InviteFail                 Message
+	InviteNotFound             Message
+	InviteUserRequired         Message
+	InviteSelf                 Message
+	InvitesFetchFail           Message
+	ServingsInvalid            Message
+	UnitsInvalid               Message
+	NutrientFat                Message
+	NutrientSaturatedFat       Message
+	NutrientCholesterol        Message
+	NutrientSodium             Message
+	NutrientCarbs              Message
+	NutrientFiber              Message
+	NutrientSugar              Message
+	NutrientProtein            Message
+	NutrientPotassium          Message
+	DietInvalid                Message
+	AllergenInvalid            Message
+	TimeInvalid                Message
+	TotalTimeInvalid           Message
+	DifficultyInvalid          Message
+	CuisineInvalid             Message
+	EquipmentInvalid           Message
+	FacetInvalid               Message
+	SuggestTypeInvalid         Message
+	MatchModeInvalid           Message
+	DateInvalid                Message
+	FilterSyntaxInvalid        Message
+	FilterIncomplete           Message
+	FilterFieldInvalid         Message
+	FilterValueInvalid         Message
+	FilterTooComplex           Message
 }{
 	RecipeNotFound:             Message{"RecipeNotFound"},
 	RecipeCreated:              Message{"RecipeCreated"},
@@ -335,12 +109,59 @@ var Recipe = struct {
 	RecipeTagCreateFail:        Message{"RecipeTagCreateFail"},
 	RecipeTagQueryFail:         Message{"RecipeTagQueryFail"},
 	RecipeTagUpdateFail:        Message{"RecipeTagUpdateFail"},
-	RecipeTagUpdated:           Message{"RecipeTagupdated"},
+	RecipeTagUpdated:           Message{"RecipeTagUpdated"},
 	RecipeTagsDeleteFail:       Message{"RecipeTagDeleteFail"},
 	ReciepTagsDeleted:          Message{"RecipeTagDeleted"},
 	RecipeCatsFetcFail:         Message{"RecipeCatsFetcFail"},
 	RecipeCatsDeleteFail:       Message{"RecipeCatsDeleteFail"},
 	RecipeCatsDeleted:          Message{"RecipeCatsDeleted"},
+	RevisionNotFound:           Message{"RevisionNotFound"},
+	RevisionFetchFail:          Message{"RevisionFetchFail"},
+	RevisionRestored:           Message{"RevisionRestored"},
+	RevisionRestoreFail:        Message{"RevisionRestoreFail"},
+	StatusInvalid:              Message{"StatusInvalid"},
+	PublishAtInvalid:           Message{"PublishAtInvalid"},
+	StatusUpdated:              Message{"StatusUpdated"},
+	StatusUpdateFail:           Message{"StatusUpdateFail"},
+	VisibilityInvalid:          Message{"VisibilityInvalid"},
+	VisibilityUpdated:          Message{"VisibilityUpdated"},
+	VisibilityUpdateFail:       Message{"VisibilityUpdateFail"},
+	ShareLinkRotated:           Message{"ShareLinkRotated"},
+	ShareLinkUnavailable:       Message{"ShareLinkUnavailable"},
+	InviteAdded:                Message{"InviteAdded"},
+	InviteRemoved:              Message{"InviteRemoved"},
+	InviteFail:                 Message{"InviteFail"},
+	InviteNotFound:             Message{"InviteNotFound"},
+	InviteUserRequired:         Message{"InviteUserRequired"},
+	InviteSelf:                 Message{"InviteSelf"},
+	InvitesFetchFail:           Message{"InvitesFetchFail"},
+	ServingsInvalid:            Message{"ServingsInvalid"},
+	UnitsInvalid:               Message{"UnitsInvalid"},
+	NutrientFat:                Message{"NutrientFat"},
+	NutrientSaturatedFat:       Message{"NutrientSaturatedFat"},
+	NutrientCholesterol:        Message{"NutrientCholesterol"},
+	NutrientSodium:             Message{"NutrientSodium"},
+	NutrientCarbs:              Message{"NutrientCarbs"},
+	NutrientFiber:              Message{"NutrientFiber"},
+	NutrientSugar:              Message{"NutrientSugar"},
+	NutrientProtein:            Message{"NutrientProtein"},
+	NutrientPotassium:          Message{"NutrientPotassium"},
+	DietInvalid:                Message{"DietInvalid"},
+	AllergenInvalid:            Message{"AllergenInvalid"},
+	TimeInvalid:                Message{"TimeInvalid"},
+	TotalTimeInvalid:           Message{"TotalTimeInvalid"},
+	DifficultyInvalid:          Message{"DifficultyInvalid"},
+	CuisineInvalid:             Message{"CuisineInvalid"},
+	EquipmentInvalid:           Message{"EquipmentInvalid"},
+	FacetInvalid:               Message{"FacetInvalid"},
+	SuggestTypeInvalid:         Message{"SuggestTypeInvalid"},
+	MatchModeInvalid:           Message{"MatchModeInvalid"},
+	DateInvalid:                Message{"DateInvalid"},
+	FilterSyntaxInvalid:        Message{"FilterSyntaxInvalid"},
+	FilterIncomplete:           Message{"FilterIncomplete"},
+	FilterFieldInvalid:         Message{"FilterFieldInvalid"},
+	FilterValueInvalid:         Message{"FilterValueInvalid"},
+	FilterTooComplex:           Message{"FilterTooComplex"},
 }
 
 var User = struct {
@@ -351,7 +172,7 @@ var User = struct {
 	UserFetchFail             Message
 	UserNotFound              Message
 	GeneratTokenFail          Message
-	GenerateSaltFail          Message
+	HashPasswordFail          Message
 	PasswordResetSent         Message
 	PasswordResetFailed       Message
 	PasswordResetCreateFailed Message
@@ -362,6 +183,21 @@ var User = struct {
 	EmailCheckErr             Message
 	EmailExistsErr            Message
 	UserStatFetchFail         Message
+	RefreshTokenReused        Message
+	LogoutSuccess             Message
+	LogoutFail                Message
+	EmailNotVerified          Message
+	EmailVerified             Message
+	EmailAlreadyVerified      Message
+	VerificationSent          Message
+	VerificationSendFail      Message
+	RoleInvalid               Message
+	RoleUpdated               Message
+	RoleUpdateFail            Message
+	RoleChangeSelf            Message
+	UsersFetched              Message
+	PreferencesUpdated        Message
+	PreferencesUpdateFail     Message
 }{
 	LoginInvalidEmailPass:     Message{"LoginInvalidEmailPass"},
 	UserAlreadyExists:         Message{"UserAlreadyExists"},
@@ -378,9 +214,36 @@ var User = struct {
 	EmailExistsErr:            Message{"EmailExistsErr"},
 	UserFetchFail:             Message{"UserFetchFail"},
 	GeneratTokenFail:          Message{"GeneratTokenFail"},
-	GenerateSaltFail:          Message{"GenerateSaltFail"},
+	HashPasswordFail:          Message{"HashPasswordFail"},
 	PasswordResetCreateFailed: Message{"PasswordResetCreateFailed"},
 	UserStatFetchFail:         Message{"UserStatFetchFail"},
+	RefreshTokenReused:        Message{"RefreshTokenReused"},
+	LogoutSuccess:             Message{"LogoutSuccess"},
+	LogoutFail:                Message{"LogoutFail"},
+	EmailNotVerified:          Message{"EmailNotVerified"},
+	EmailVerified:             Message{"EmailVerified"},
+	EmailAlreadyVerified:      Message{"EmailAlreadyVerified"},
+	VerificationSent:          Message{"VerificationSent"},
+	VerificationSendFail:      Message{"VerificationSendFail"},
+	RoleInvalid:               Message{"RoleInvalid"},
+	RoleUpdated:               Message{"RoleUpdated"},
+	RoleUpdateFail:            Message{"RoleUpdateFail"},
+	RoleChangeSelf:            Message{"RoleChangeSelf"},
+	UsersFetched:              Message{"UsersFetched"},
+	PreferencesUpdated:        Message{"PreferencesUpdated"},
+	PreferencesUpdateFail:     Message{"PreferencesUpdateFail"},
+}
+
+var Email = struct {
+	PasswordResetSubject Message
+	PasswordResetBody    Message
+	VerificationSubject  Message
+	VerificationBody     Message
+}{
+	PasswordResetSubject: Message{"EmailPasswordResetSubject"},
+	PasswordResetBody:    Message{"EmailPasswordResetBody"},
+	VerificationSubject:  Message{"EmailVerificationSubject"},
+	VerificationBody:     Message{"EmailVerificationBody"},
 }
 
 var Comment = struct {
@@ -525,32 +388,70 @@ var Tag = struct {
 	TagFailedAssocioationRemova: Message{"TagFailedAssocioationRemova"},
 }
 
-var defaultLang = "en"
-var CurrentLang = defaultLang
+var Job = struct {
+	JobsFetched      Message
+	JobFetchFailed   Message
+	JobNotFound      Message
+	JobStatusInvalid Message
+	JobRetried       Message
+	JobNotDead       Message
+	JobRetryFailed   Message
+}{
+	JobsFetched:      Message{"JobsFetched"},
+	JobFetchFailed:   Message{"JobFetchFailed"},
+	JobNotFound:      Message{"JobNotFound"},
+	JobStatusInvalid: Message{"JobStatusInvalid"},
+	JobRetried:       Message{"JobRetried"},
+	JobNotDead:       Message{"JobNotDead"},
+	JobRetryFailed:   Message{"JobRetryFailed"},
+}
 
-func SetLang(lang string) {
-	lang = strings.ToLower(lang)
-	if _, ok := translations[lang]; ok {
-		CurrentLang = lang
-	} else {
-		CurrentLang = defaultLang
-	}
+var Pantry = struct {
+	PantryFetched           Message
+	PantryFetchFailed       Message
+	PantryItemNotFound      Message
+	PantryItemAdded         Message
+	PantryItemUpdated       Message
+	PantryItemDeleted       Message
+	PantryItemExists        Message
+	PantryItemInvalid       Message
+	PantrySaveFailed        Message
+	PantryDeleteFailed      Message
+	PantryMaxMissingInvalid Message
+	PantryMatchFailed       Message
+}{
+	PantryFetched:           Message{"PantryFetched"},
+	PantryFetchFailed:       Message{"PantryFetchFailed"},
+	PantryItemNotFound:      Message{"PantryItemNotFound"},
+	PantryItemAdded:         Message{"PantryItemAdded"},
+	PantryItemUpdated:       Message{"PantryItemUpdated"},
+	PantryItemDeleted:       Message{"PantryItemDeleted"},
+	PantryItemExists:        Message{"PantryItemExists"},
+	PantryItemInvalid:       Message{"PantryItemInvalid"},
+	PantrySaveFailed:        Message{"PantrySaveFailed"},
+	PantryDeleteFailed:      Message{"PantryDeleteFailed"},
+	PantryMaxMissingInvalid: Message{"PantryMaxMissingInvalid"},
+	PantryMatchFailed:       Message{"PantryMatchFailed"},
 }
 
-func T(lang, key string) string {
-	if vals, ok := translations[lang]; ok {
-		if msg, exists := vals[key]; exists {
-			return msg
-		}
-	}
-	return key
+// groups lists every message group so the declared keys can be checked
+// against the catalogs.
+var groups = []any{Common, Recipe, User, Email, Comment, Favorite, Rating, Image, Category, Tag, Job, Pantry}
+
+// defaultLang ends every fallback chain.
+var defaultLang = "en"
+
+// T returns key translated into lang, falling back to the default language.
+func T(lang, key string, args ...Args) string {
+	return NewLocalizer(lang).T(Message{key}, args...)
 }
 
 type Message struct {
 	Key string
 }
 
+// String returns the message in the default language. Request handlers use
+// the request's Localizer instead.
 func (m Message) String() string {
-	fmt.Println(CurrentLang, m.Key)
-	return T(CurrentLang, m.Key)
+	return NewLocalizer().T(m)
 }
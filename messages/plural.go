@@ -0,0 +1,207 @@
+package messages
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// operands are the CLDR plural operands of a number: n is the absolute
+// value, i its integer digits, v the number of visible fraction digits and f
+// the visible fraction digits as an integer.
+type operands struct {
+	n    float64
+	i, f int64
+	v    int
+}
+
+func newOperands(value any) (operands, bool) {
+	var s string
+	switch v := value.(type) {
+	case int:
+		s = strconv.Itoa(v)
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		s = fmt.Sprint(v)
+	case float32:
+		s = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		s = v
+	default:
+		return operands{}, false
+	}
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "-")
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+		return operands{}, false
+	}
+
+	o := operands{n: n}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	o.i, _ = strconv.ParseInt(intPart, 10, 64)
+	o.v = len(fracPart)
+	if fracPart != "" {
+		o.f, _ = strconv.ParseInt(fracPart, 10, 64)
+	}
+	return o, true
+}
+
+// pluralRule picks the CLDR cardinal plural category of a number.
+type pluralRule struct {
+	categories []string
+	category   func(o operands) string
+}
+
+var (
+	otherOnly = pluralRule{
+		categories: []string{"other"},
+		category:   func(o operands) string { return "other" },
+	}
+	// one: i = 1 and v = 0
+	oneIntegerOne = pluralRule{
+		categories: []string{"one", "other"},
+		category: func(o operands) string {
+			if o.i == 1 && o.v == 0 {
+				return "one"
+			}
+			return "other"
+		},
+	}
+	// one: n = 1
+	oneExactlyOne = pluralRule{
+		categories: []string{"one", "other"},
+		category: func(o operands) string {
+			if o.n == 1 {
+				return "one"
+			}
+			return "other"
+		},
+	}
+	// one: i = 0 or n = 1
+	oneZeroOrOne = pluralRule{
+		categories: []string{"one", "other"},
+		category: func(o operands) string {
+			if o.i == 0 || o.n == 1 {
+				return "one"
+			}
+			return "other"
+		},
+	}
+	// one: i = 0,1
+	oneIntegerZeroOrOne = pluralRule{
+		categories: []string{"one", "other"},
+		category: func(o operands) string {
+			if o.i == 0 || o.i == 1 {
+				return "one"
+			}
+			return "other"
+		},
+	}
+	eastSlavic = pluralRule{
+		categories: []string{"one", "few", "many", "other"},
+		category: func(o operands) string {
+			if o.v != 0 {
+				return "other"
+			}
+			mod10, mod100 := o.i%10, o.i%100
+			switch {
+			case mod10 == 1 && mod100 != 11:
+				return "one"
+			case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+				return "few"
+			default:
+				return "many"
+			}
+		},
+	}
+	polish = pluralRule{
+		categories: []string{"one", "few", "many", "other"},
+		category: func(o operands) string {
+			if o.v != 0 {
+				return "other"
+			}
+			mod10, mod100 := o.i%10, o.i%100
+			switch {
+			case o.i == 1:
+				return "one"
+			case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+				return "few"
+			default:
+				return "many"
+			}
+		},
+	}
+	arabic = pluralRule{
+		categories: []string{"zero", "one", "two", "few", "many", "other"},
+		category: func(o operands) string {
+			if o.v != 0 {
+				return "other"
+			}
+			mod100 := o.i % 100
+			switch {
+			case o.n == 0:
+				return "zero"
+			case o.n == 1:
+				return "one"
+			case o.n == 2:
+				return "two"
+			case mod100 >= 3 && mod100 <= 10:
+				return "few"
+			case mod100 >= 11 && mod100 <= 99:
+				return "many"
+			default:
+				return "other"
+			}
+		},
+	}
+)
+
+// pluralRules maps base languages to their CLDR cardinal rule. Languages not
+// listed use the English rule.
+var pluralRules = map[string]pluralRule{
+	"en": oneIntegerOne, "de": oneIntegerOne, "nl": oneIntegerOne, "sv": oneIntegerOne,
+	"it": oneIntegerOne, "fi": oneIntegerOne, "et": oneIntegerOne, "ca": oneIntegerOne,
+
+	"es": oneExactlyOne, "tr": oneExactlyOne, "el": oneExactlyOne, "hu": oneExactlyOne,
+	"bg": oneExactlyOne, "az": oneExactlyOne, "ur": oneExactlyOne,
+
+	"fa": oneZeroOrOne, "hi": oneZeroOrOne, "bn": oneZeroOrOne, "am": oneZeroOrOne,
+
+	"fr": oneIntegerZeroOrOne, "pt": oneIntegerZeroOrOne,
+
+	"ru": eastSlavic, "uk": eastSlavic, "be": eastSlavic,
+	"pl": polish,
+	"ar": arabic,
+
+	"ja": otherOnly, "zh": otherOnly, "ko": otherOnly, "th": otherOnly,
+	"vi": otherOnly, "id": otherOnly, "ms": otherOnly,
+}
+
+func pluralRuleFor(lang string) pluralRule {
+	base, _, _ := strings.Cut(lang, "-")
+	if rule, ok := pluralRules[base]; ok {
+		return rule
+	}
+	return oneIntegerOne
+}
+
+// PluralCategory returns the CLDR plural category of count in lang, or
+// "other" when count is not a number.
+func PluralCategory(lang string, count any) string {
+	o, ok := newOperands(count)
+	if !ok {
+		return "other"
+	}
+	return pluralRuleFor(lang).category(o)
+}
+
+func isPluralCategory(s string) bool {
+	switch s {
+	case "zero", "one", "two", "few", "many", "other":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package messages
+
+import "testing"
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		lang  string
+		count any
+		want  string
+	}{
+		{"en", 1, "one"},
+		{"en", 0, "other"},
+		{"en", 2, "other"},
+		{"en", "1.0", "other"},
+		{"en", -1, "one"},
+		{"en-US", 1, "one"},
+		{"fa", 0, "one"},
+		{"fa", 1, "one"},
+		{"fa", 0.5, "one"},
+		{"fa", 2, "other"},
+		{"fr", 1.5, "one"},
+		{"fr", 2, "other"},
+		{"es", 1, "one"},
+		{"es", "1.0", "one"},
+		{"ru", 1, "one"},
+		{"ru", 11, "many"},
+		{"ru", 22, "few"},
+		{"ru", 25, "many"},
+		{"ru", 1.5, "other"},
+		{"pl", 1, "one"},
+		{"pl", 21, "many"},
+		{"pl", 23, "few"},
+		{"ar", 0, "zero"},
+		{"ar", 2, "two"},
+		{"ar", 105, "few"},
+		{"ar", 111, "many"},
+		{"ar", 100, "other"},
+		{"ja", 1, "other"},
+		// Unknown languages use the English rule.
+		{"xx", 1, "one"},
+		{"en", "many", "other"},
+		{"en", nil, "other"},
+		{"en", uint(1), "one"},
+	}
+	for _, tt := range tests {
+		if got := PluralCategory(tt.lang, tt.count); got != tt.want {
+			t.Errorf("PluralCategory(%q, %v) = %q, want %q", tt.lang, tt.count, got, tt.want)
+		}
+	}
+}
@@ -1,16 +1,17 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/Abb133Se/recepieshare/repository"
 	"github.com/Abb133Se/recepieshare/token"
-	"github.com/Abb133Se/recepieshare/utils"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthenticatJWT() gin.HandlerFunc {
+// AuthenticatJWT validates the bearer token and rejects tokens that were
+// revoked through logout.
+func AuthenticatJWT(tokens repository.TokenRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -35,6 +36,18 @@ func AuthenticatJWT() gin.HandlerFunc {
 			return
 		}
 
+		revoked, err := tokens.IsAccessTokenRevoked(claims["jti"].(string))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+			c.Abort()
+			return
+		}
+
 		c.Set("claims", claims)
 
 		if idFloat, ok := claims["sub"].(float64); ok {
@@ -54,7 +67,7 @@ func AuthenticatJWT() gin.HandlerFunc {
 	}
 }
 
-func ExtractUserFromToken() gin.HandlerFunc {
+func ExtractUserFromToken(tokens repository.TokenRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader != "" {
@@ -63,44 +76,19 @@ func ExtractUserFromToken() gin.HandlerFunc {
 				tokenStr := parts[1]
 				claims, err := token.VerifyToken(tokenStr)
 				if err == nil {
+					if revoked, err := tokens.IsAccessTokenRevoked(claims["jti"].(string)); err != nil || revoked {
+						c.Next()
+						return
+					}
 					if idFloat, ok := claims["sub"].(float64); ok {
 						c.Set("userID", uint(idFloat))
 					}
+					if role, ok := claims["role"]; ok {
+						c.Set("role", role)
+					}
 				}
 			}
 		}
 		c.Next()
 	}
 }
-
-func AdminOnly() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		role := c.GetString("role")
-		if role != "admin" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
-			return
-		}
-		c.Next()
-	}
-}
-
-// utils.go
-func GetEffectiveUserID(c *gin.Context) (uint, error) {
-	adminRole := c.GetString("role")
-	paramUserID := c.Param("userID") // optional query parameter
-	currentUserID := c.GetUint("userID")
-
-	if adminRole == "admin" && paramUserID != "" {
-		uid, err := utils.ValidateEntityID(paramUserID)
-		if err != nil {
-			return 0, fmt.Errorf("invalid user_id parameter")
-		}
-		return uint(uid), nil
-	}
-
-	// Non-admin or no param: only own userID
-	if currentUserID == 0 {
-		return 0, fmt.Errorf("unauthorized")
-	}
-	return currentUserID, nil
-}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/gin-gonic/gin"
+)
+
+// Subject returns the authenticated caller as set by AuthenticatJWT.
+func Subject(c *gin.Context) policy.Subject {
+	return policy.Subject{
+		UserID: c.GetUint("userID"),
+		Role:   policy.Role(c.GetString("role")),
+	}
+}
+
+// RequireRole only lets through callers whose role is at least role.
+func RequireRole(role policy.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Subject(c).Role.AtLeast(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": Localizer(c).T(messages.Common.Forbidden)})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Authorize rejects callers whose role grants less than scope for action on
+// resource. With scope Own the handler still has to check the owner of the
+// loaded resource.
+func Authorize(resource policy.Resource, action policy.Action, scope policy.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if policy.Check(Subject(c).Role, resource, action) < scope {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": Localizer(c).T(messages.Common.Forbidden)})
+			return
+		}
+		c.Next()
+	}
+}
@@ -5,14 +5,33 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// defaultRequestLang is used when a request has no Accept-Language header.
+const defaultRequestLang = "fa"
+
+// SetLanguage negotiates the locale from the Accept-Language header and
+// stores its localizer on the request context.
 func SetLanguage() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		lang := c.GetHeader("Accept-Language")
-		// fmt.Println(lang)
-		if lang == "" {
-			lang = "fa"
-		}
-		messages.SetLang(lang)
+		loc := negotiate(c)
+		c.Request = c.Request.WithContext(messages.WithLocalizer(c.Request.Context(), loc))
+		c.Header("Content-Language", loc.Lang())
 		c.Next()
 	}
 }
+
+// Localizer returns the localizer of the request. Outside of SetLanguage it
+// is negotiated from the headers on the fly.
+func Localizer(c *gin.Context) messages.Localizer {
+	if loc, ok := messages.FromContext(c.Request.Context()); ok {
+		return loc
+	}
+	return negotiate(c)
+}
+
+func negotiate(c *gin.Context) messages.Localizer {
+	header := c.GetHeader("Accept-Language")
+	if header == "" {
+		header = defaultRequestLang
+	}
+	return messages.Negotiate(header)
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Abb133Se/recepieshare/messages"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail blocks users that have not confirmed their email
+// address yet. It must run after AuthenticatJWT.
+func RequireVerifiedEmail(users repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := users.FindByID(c.GetUint("userID"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": Localizer(c).T(messages.User.UserNotFound)})
+			return
+		}
+		if user.EmailVerifiedAt == nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": Localizer(c).T(messages.User.EmailNotVerified)})
+			return
+		}
+		c.Next()
+	}
+}
@@ -2,14 +2,13 @@ package middleware
 
 import (
 	"fmt"
-	"net/http"
 
-	"github.com/Abb133Se/recepieshare/internal"
 	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
 	"github.com/gin-gonic/gin"
 )
 
-func SiteVisitMiddleware() gin.HandlerFunc {
+func SiteVisitMiddleware(analytics repository.AnalyticsRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		visit := model.SiteVisit{
 			IPAddress: c.ClientIP(),
@@ -19,12 +18,7 @@ func SiteVisitMiddleware() gin.HandlerFunc {
 				visit.UserID = &uid
 			}
 		}
-		db, err := internal.GetGormInstance()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-			return
-		}
-		if err := db.Create(&visit).Error; err != nil {
+		if err := analytics.RecordSiteVisit(&visit); err != nil {
 			fmt.Println(err)
 		}
 		c.Next()
@@ -0,0 +1,62 @@
+package migrate
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/Abb133Se/recepieshare/migrate/internal/baseline"
+)
+
+// The initial schema is what AutoMigration used to create on startup, so
+// databases created before versioned migrations adopt it without changes.
+// It migrates frozen copies of the models of that time; databases created
+// by earlier builds, which migrated the current models instead, may already
+// have later columns, so later migrations still tolerate them.
+func init() {
+	register(Migration{
+		Version: 1,
+		Name:    "initial_schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(
+				&baseline.User{},
+				&baseline.Recipe{},
+				&baseline.Ingredient{},
+				&baseline.Comment{},
+				&baseline.Favorite{},
+				&baseline.Rating{},
+				&baseline.Tag{},
+				&baseline.Category{},
+				&baseline.Step{},
+				&baseline.Image{},
+				&baseline.RecipeView{},
+				&baseline.SiteVisit{},
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			// Tables are dropped one by one, children first: DropTable
+			// reorders its arguments itself and SQLite cannot disable
+			// foreign keys inside the migration transaction.
+			tables := []interface{}{
+				"recipe_tags",
+				"recipe_categories",
+				&baseline.SiteVisit{},
+				&baseline.RecipeView{},
+				&baseline.Image{},
+				&baseline.Step{},
+				&baseline.Rating{},
+				&baseline.Favorite{},
+				&baseline.Comment{},
+				&baseline.Ingredient{},
+				&baseline.Category{},
+				&baseline.Tag{},
+				&baseline.Recipe{},
+				&baseline.User{},
+			}
+			for _, table := range tables {
+				if err := tx.Migrator().DropTable(table); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Abb133Se/recepieshare/migrate/internal/baseline"
+)
+
+type refreshToken struct {
+	ID         uint          `gorm:"primaryKey"`
+	UserID     uint          `gorm:"index"`
+	User       baseline.User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	FamilyID   string        `gorm:"index;size:64"`
+	TokenHash  string        `gorm:"uniqueIndex;size:64"`
+	ExpiresAt  time.Time     `gorm:"index"`
+	RevokedAt  *time.Time
+	ReplacedBy *uint
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+func (refreshToken) TableName() string { return "refresh_tokens" }
+
+type revokedToken struct {
+	JTI       string    `gorm:"primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (revokedToken) TableName() string { return "revoked_tokens" }
+
+func init() {
+	register(Migration{
+		Version: 2,
+		Name:    "refresh_tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&refreshToken{}, &revokedToken{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&revokedToken{}); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(&refreshToken{})
+		},
+	})
+}
@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userVerification struct {
+	EmailVerifiedAt       *time.Time
+	VerificationToken     string `gorm:"size:255;index"`
+	VerificationExpiresAt *time.Time
+}
+
+func (userVerification) TableName() string { return "users" }
+
+var verificationColumns = []string{"EmailVerifiedAt", "VerificationToken", "VerificationExpiresAt"}
+
+func init() {
+	register(Migration{
+		Version: 3,
+		Name:    "email_verification",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &userVerification{}, verificationColumns...); err != nil {
+				return err
+			}
+			if !tx.Migrator().HasIndex(&userVerification{}, "VerificationToken") {
+				if err := tx.Migrator().CreateIndex(&userVerification{}, "VerificationToken"); err != nil {
+					return err
+				}
+			}
+			// Accounts that existed before verification was introduced are
+			// trusted, otherwise every existing user would lose posting rights.
+			return tx.Model(&userVerification{}).
+				Where("email_verified_at IS NULL").
+				UpdateColumn("email_verified_at", gorm.Expr("COALESCE(created_at, CURRENT_TIMESTAMP)")).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&userVerification{}, "VerificationToken") {
+				if err := tx.Migrator().DropIndex(&userVerification{}, "VerificationToken"); err != nil {
+					return err
+				}
+			}
+			return dropColumns(tx, &userVerification{}, verificationColumns...)
+		},
+	})
+}
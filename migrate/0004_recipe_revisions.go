@@ -0,0 +1,110 @@
+package migrate
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Abb133Se/recepieshare/migrate/internal/baseline"
+)
+
+type recipeRevision struct {
+	ID           uint            `gorm:"primaryKey"`
+	RecipeID     uint            `gorm:"uniqueIndex:idx_recipe_revision_version;not null"`
+	Recipe       baseline.Recipe `gorm:"foreignKey:RecipeID;constraint:OnDelete:CASCADE"`
+	Version      int             `gorm:"uniqueIndex:idx_recipe_revision_version;not null"`
+	UserID       uint            `gorm:"index"`
+	RestoredFrom *int
+	Snapshot     revisionSnapshot `gorm:"serializer:json;type:text"`
+	CreatedAt    time.Time        `gorm:"autoCreateTime"`
+}
+
+func (recipeRevision) TableName() string { return "recipe_revisions" }
+
+// revisionSnapshot is the snapshot format of the first revisions. Later
+// formats only add fields, so they still read it.
+type revisionSnapshot struct {
+	Title       string               `json:"title"`
+	Text        string               `json:"text"`
+	Ingredients []revisionIngredient `json:"ingredients"`
+	Steps       []revisionStep       `json:"steps"`
+	Tags        []revisionRef        `json:"tags"`
+	Categories  []revisionRef        `json:"categories"`
+}
+
+type revisionIngredient struct {
+	Name   string `json:"name"`
+	Amount string `json:"amount"`
+}
+
+type revisionStep struct {
+	Order int    `json:"order"`
+	Text  string `json:"text"`
+}
+
+type revisionRef struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+func snapshotOf(r baseline.Recipe) revisionSnapshot {
+	s := revisionSnapshot{
+		Title:       r.Title,
+		Text:        r.Text,
+		Ingredients: []revisionIngredient{},
+		Steps:       []revisionStep{},
+		Tags:        []revisionRef{},
+		Categories:  []revisionRef{},
+	}
+	for _, i := range r.Ingredients {
+		s.Ingredients = append(s.Ingredients, revisionIngredient{Name: i.Name, Amount: i.Amount})
+	}
+	for _, st := range r.Steps {
+		s.Steps = append(s.Steps, revisionStep{Order: st.Order, Text: st.Text})
+	}
+	sort.Slice(s.Steps, func(i, j int) bool { return s.Steps[i].Order < s.Steps[j].Order })
+	for _, t := range r.Tags {
+		s.Tags = append(s.Tags, revisionRef{ID: t.ID, Name: t.Name})
+	}
+	for _, c := range r.Categories {
+		s.Categories = append(s.Categories, revisionRef{ID: c.ID, Name: c.Name})
+	}
+	sort.Slice(s.Tags, func(i, j int) bool { return s.Tags[i].ID < s.Tags[j].ID })
+	sort.Slice(s.Categories, func(i, j int) bool { return s.Categories[i].ID < s.Categories[j].ID })
+	return s
+}
+
+func init() {
+	register(Migration{
+		Version: 4,
+		Name:    "recipe_revisions",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&recipeRevision{}); err != nil {
+				return err
+			}
+
+			// Existing recipes start their history with their current state.
+			var recipes []baseline.Recipe
+			return tx.Preload("Ingredients").Preload("Steps").Preload("Tags").Preload("Categories").
+				Where("id NOT IN (?)", tx.Model(&recipeRevision{}).Select("recipe_id")).
+				FindInBatches(&recipes, 100, func(batch *gorm.DB, _ int) error {
+					for i := range recipes {
+						rev := recipeRevision{
+							RecipeID: recipes[i].ID,
+							Version:  1,
+							UserID:   recipes[i].UserID,
+							Snapshot: snapshotOf(recipes[i]),
+						}
+						if err := tx.Create(&rev).Error; err != nil {
+							return err
+						}
+					}
+					return nil
+				}).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&recipeRevision{})
+		},
+	})
+}
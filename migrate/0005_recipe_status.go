@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type recipeStatus struct {
+	Status      string `gorm:"size:16;not null;default:published;index"`
+	PublishAt   *time.Time
+	PublishedAt *time.Time
+}
+
+func (recipeStatus) TableName() string { return "recipes" }
+
+var recipeStatusColumns = []string{"Status", "PublishAt", "PublishedAt"}
+
+func init() {
+	register(Migration{
+		Version: 5,
+		Name:    "recipe_status",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &recipeStatus{}, recipeStatusColumns...); err != nil {
+				return err
+			}
+			if !tx.Migrator().HasIndex(&recipeStatus{}, "Status") {
+				if err := tx.Migrator().CreateIndex(&recipeStatus{}, "Status"); err != nil {
+					return err
+				}
+			}
+			// Recipes created before publication states existed were public
+			// from the moment they were posted.
+			return tx.Model(&recipeStatus{}).
+				Where("published_at IS NULL AND status = ?", "published").
+				UpdateColumn("published_at", gorm.Expr("created_at")).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&recipeStatus{}, "Status") {
+				if err := tx.Migrator().DropIndex(&recipeStatus{}, "Status"); err != nil {
+					return err
+				}
+			}
+			return dropColumns(tx, &recipeStatus{}, recipeStatusColumns...)
+		},
+	})
+}
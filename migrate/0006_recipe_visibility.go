@@ -0,0 +1,62 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Abb133Se/recepieshare/migrate/internal/baseline"
+)
+
+type recipeVisibility struct {
+	Visibility string  `gorm:"size:16;not null;default:public;index"`
+	ShareToken *string `gorm:"size:64;uniqueIndex"`
+}
+
+func (recipeVisibility) TableName() string { return "recipes" }
+
+type recipeInvite struct {
+	ID        uint            `gorm:"primaryKey"`
+	RecipeID  uint            `gorm:"uniqueIndex:idx_recipe_invite_user;not null"`
+	Recipe    baseline.Recipe `gorm:"foreignKey:RecipeID;constraint:OnDelete:CASCADE"`
+	UserID    uint            `gorm:"uniqueIndex:idx_recipe_invite_user;index;not null"`
+	User      baseline.User   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time       `gorm:"autoCreateTime"`
+}
+
+func (recipeInvite) TableName() string { return "recipe_invites" }
+
+var recipeVisibilityColumns = []string{"Visibility", "ShareToken"}
+
+func init() {
+	register(Migration{
+		Version: 6,
+		Name:    "recipe_visibility",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &recipeVisibility{}, recipeVisibilityColumns...); err != nil {
+				return err
+			}
+			for _, field := range recipeVisibilityColumns {
+				if !tx.Migrator().HasIndex(&recipeVisibility{}, field) {
+					if err := tx.Migrator().CreateIndex(&recipeVisibility{}, field); err != nil {
+						return err
+					}
+				}
+			}
+			return tx.AutoMigrate(&recipeInvite{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&recipeInvite{}); err != nil {
+				return err
+			}
+			for _, field := range recipeVisibilityColumns {
+				if tx.Migrator().HasIndex(&recipeVisibility{}, field) {
+					if err := tx.Migrator().DropIndex(&recipeVisibility{}, field); err != nil {
+						return err
+					}
+				}
+			}
+			return dropColumns(tx, &recipeVisibility{}, recipeVisibilityColumns...)
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package migrate
+
+import "gorm.io/gorm"
+
+type recipeServings struct {
+	Servings int    `gorm:"not null;default:1"`
+	Yield    string `gorm:"size:100"`
+}
+
+func (recipeServings) TableName() string { return "recipes" }
+
+var recipeServingsColumns = []string{"Servings", "Yield"}
+
+func init() {
+	register(Migration{
+		Version: 7,
+		Name:    "recipe_servings",
+		Up: func(tx *gorm.DB) error {
+			// Servings defaults to 1, so existing recipes keep their
+			// per-serving nutrition equal to the totals.
+			return addColumns(tx, &recipeServings{}, recipeServingsColumns...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &recipeServings{}, recipeServingsColumns...)
+		},
+	})
+}
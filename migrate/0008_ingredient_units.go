@@ -0,0 +1,380 @@
+package migrate
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+type ingredientUnits struct {
+	Quantity    float64
+	QuantityMax float64
+	Unit        string `gorm:"size:16"`
+	Note        string `gorm:"size:255"`
+	Grams       float64
+}
+
+func (ingredientUnits) TableName() string { return "ingredients" }
+
+type userUnits struct {
+	Units string `gorm:"size:16"`
+}
+
+func (userUnits) TableName() string { return "users" }
+
+// amountIngredient is the part of an ingredient the backfill parses.
+type amountIngredient struct {
+	ID     uint `gorm:"primaryKey"`
+	Name   string
+	Amount string
+}
+
+func (amountIngredient) TableName() string { return "ingredients" }
+
+var ingredientUnitColumns = []string{"Quantity", "QuantityMax", "Unit", "Note", "Grams"}
+
+// parseAmount returns the structured quantity columns of an ingredient,
+// all zero when its amount has no number.
+func parseAmount(name, amount string) ingredientUnits {
+	q, ok := parseQuantity(amount)
+	if !ok {
+		return ingredientUnits{}
+	}
+	// Three decimals keep thirds precise enough to scale again.
+	cols := ingredientUnits{
+		Quantity:    math.Round(q.Value*1000) / 1000,
+		QuantityMax: math.Round(q.Max*1000) / 1000,
+		Unit:        q.Unit,
+		Note:        q.Note,
+	}
+	if grams, ok := q.grams(densityOf(name)); ok {
+		cols.Grams = math.Round(grams*10) / 10
+	}
+	return cols
+}
+
+// parseQuantity and the functions and tables below are quantity.Parse,
+// Quantity.Grams and quantity.DensityOf as of this migration, cut down to
+// what parseAmount needs.
+
+// parsedQuantity is a parsed amount: "2-3 large" has Value 2, Max 3 and
+// Note "large".
+type parsedQuantity struct {
+	Value float64
+	Max   float64
+	Unit  string
+	Note  string
+}
+
+// amountUnit is a unit of the registry. Base is its size in grams, or in
+// milliliters for volumes; counts have none.
+type amountUnit struct {
+	name   string
+	base   float64
+	volume bool
+}
+
+// amountUnits maps every spelling of a unit to it.
+var amountUnits = func() map[string]amountUnit {
+	mass := func(name string, base float64) amountUnit { return amountUnit{name, base, false} }
+	volume := func(name string, base float64) amountUnit { return amountUnit{name, base, true} }
+	count := func(name string) amountUnit { return amountUnit{name, 0, false} }
+	units := map[string]amountUnit{}
+	for _, u := range []struct {
+		unit    amountUnit
+		aliases []string
+	}{
+		{mass("mg", 0.001), []string{"mg", "milligram", "milligrams"}},
+		{mass("g", 1), []string{"g", "gr", "gram", "grams", "gramme", "grammes"}},
+		{mass("kg", 1000), []string{"kg", "kgs", "kilo", "kilos", "kilogram", "kilograms"}},
+		{mass("oz", 28.349523125), []string{"oz", "ounce", "ounces"}},
+		{mass("lb", 453.59237), []string{"lb", "lbs", "pound", "pounds"}},
+
+		{volume("ml", 1), []string{"ml", "milliliter", "milliliters", "millilitre", "millilitres"}},
+		{volume("cl", 10), []string{"cl", "centiliter", "centiliters", "centilitre", "centilitres"}},
+		{volume("dl", 100), []string{"dl", "deciliter", "deciliters", "decilitre", "decilitres"}},
+		{volume("l", 1000), []string{"l", "liter", "liters", "litre", "litres"}},
+		{volume("tsp", 4.92892159375), []string{"tsp", "tsps", "teaspoon", "teaspoons"}},
+		{volume("tbsp", 14.78676478125), []string{"tbsp", "tbsps", "tbs", "tbl", "tablespoon", "tablespoons"}},
+		{volume("fl oz", 29.5735295625), []string{"fl oz", "fluid ounce", "fluid ounces"}},
+		{volume("cup", 236.5882365), []string{"cup", "cups"}},
+		{volume("pint", 473.176473), []string{"pint", "pints", "pt"}},
+		{volume("quart", 946.352946), []string{"quart", "quarts", "qt"}},
+		{volume("gallon", 3785.411784), []string{"gallon", "gallons", "gal"}},
+
+		{count("piece"), []string{"piece", "pieces", "pc", "pcs"}},
+		{count("clove"), []string{"clove", "cloves"}},
+		{count("slice"), []string{"slice", "slices"}},
+		{count("can"), []string{"can", "cans", "tin", "tins"}},
+		{count("package"), []string{"package", "packages", "pkg", "pack", "packs"}},
+		{count("bunch"), []string{"bunch", "bunches"}},
+		{count("sprig"), []string{"sprig", "sprigs"}},
+		{count("stick"), []string{"stick", "sticks"}},
+		{count("handful"), []string{"handful", "handfuls"}},
+		{count("pinch"), []string{"pinch", "pinches"}},
+		{count("dash"), []string{"dash", "dashes"}},
+	} {
+		for _, alias := range u.aliases {
+			units[alias] = u.unit
+		}
+	}
+	return units
+}()
+
+// lookupAmountUnit finds a unit by any of its spellings, ignoring case and a
+// trailing dot as in "Tbsp.".
+func lookupAmountUnit(name string) (amountUnit, bool) {
+	u, ok := amountUnits[strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))]
+	return u, ok
+}
+
+var vulgarFractions = map[rune]float64{
+	'½': 1.0 / 2, '⅓': 1.0 / 3, '⅔': 2.0 / 3, '¼': 1.0 / 4, '¾': 3.0 / 4,
+	'⅕': 1.0 / 5, '⅖': 2.0 / 5, '⅗': 3.0 / 5, '⅘': 4.0 / 5, '⅙': 1.0 / 6,
+	'⅚': 5.0 / 6, '⅛': 1.0 / 8, '⅜': 3.0 / 8, '⅝': 5.0 / 8, '⅞': 7.0 / 8,
+}
+
+// parseQuantity reads an amount that starts with a number. It reports false
+// for amounts without one, such as "a pinch".
+func parseQuantity(s string) (parsedQuantity, bool) {
+	s = strings.TrimSpace(s)
+
+	value, rest, ok := parseAmountNumber(s)
+	if !ok {
+		return parsedQuantity{}, false
+	}
+	q := parsedQuantity{Value: value}
+
+	// Ranges: "2-3", "2 – 3", "2 to 3".
+	trimmed := strings.TrimLeftFunc(rest, unicode.IsSpace)
+	for _, sep := range []string{"-", "–", "to "} {
+		if !strings.HasPrefix(trimmed, sep) {
+			continue
+		}
+		if upper, after, ok := parseAmountNumber(strings.TrimSpace(trimmed[len(sep):])); ok && upper > value {
+			q.Max = upper
+			rest = after
+		}
+		break
+	}
+
+	if u, after, ok := splitAmountUnit(rest); ok {
+		q.Unit = u.name
+		rest = after
+	}
+
+	// Trim the separators between the unit and the note after it.
+	note := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(rest), ",;:"))
+	if note == "of" {
+		note = ""
+	}
+	q.Note = strings.TrimSpace(strings.TrimPrefix(note, "of "))
+	return q, true
+}
+
+// parseAmountNumber reads a leading integer, decimal, fraction, mixed number
+// or unicode fraction and returns the text after it.
+func parseAmountNumber(s string) (float64, string, bool) {
+	whole, rest, ok := parseSimpleNumber(s)
+	if !ok {
+		return 0, s, false
+	}
+
+	// A whole number may be followed by a fraction: "1 1/2", "1½", "1 ½".
+	if whole == math.Trunc(whole) && !strings.ContainsAny(s[:len(s)-len(rest)], "/.,") {
+		after := strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if frac, tail, ok := parseSimpleNumber(after); ok && frac < 1 {
+			r, _ := utf8.DecodeRuneInString(after)
+			if _, vulgar := vulgarFractions[r]; vulgar || strings.Contains(after[:len(after)-len(tail)], "/") {
+				return whole + frac, tail, true
+			}
+		}
+	}
+	return whole, rest, true
+}
+
+// parseSimpleNumber reads one of: a unicode fraction, "a/b", or a decimal
+// number with a dot or comma separator.
+func parseSimpleNumber(s string) (float64, string, bool) {
+	if r, size := utf8.DecodeRuneInString(s); size > 0 {
+		if v, ok := vulgarFractions[r]; ok {
+			return v, s[size:], true
+		}
+	}
+
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.' || s[end] == ',') {
+		end++
+	}
+	// A trailing separator belongs to the text, as in "2, chopped".
+	for end > 0 && (s[end-1] == '.' || s[end-1] == ',') {
+		end--
+	}
+	if end == 0 {
+		return 0, s, false
+	}
+
+	num, err := strconv.ParseFloat(strings.Replace(s[:end], ",", ".", 1), 64)
+	if err != nil {
+		return 0, s, false
+	}
+	rest := s[end:]
+
+	if strings.HasPrefix(rest, "/") && !strings.ContainsAny(s[:end], ".,") {
+		denEnd := 1
+		for denEnd < len(rest) && rest[denEnd] >= '0' && rest[denEnd] <= '9' {
+			denEnd++
+		}
+		if den, err := strconv.Atoi(rest[1:denEnd]); err == nil && den > 0 {
+			return num / float64(den), rest[denEnd:], true
+		}
+	}
+	return num, rest, true
+}
+
+// splitAmountUnit reads a unit from the start of s, trying two-word units
+// such as "fl oz" first, and returns the text after it.
+func splitAmountUnit(s string) (amountUnit, string, bool) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	first, rest := nextAmountWord(s)
+	if first == "" {
+		return amountUnit{}, s, false
+	}
+	if second, after := nextAmountWord(strings.TrimLeftFunc(rest, unicode.IsSpace)); second != "" {
+		if u, ok := lookupAmountUnit(first + " " + second); ok {
+			return u, after, true
+		}
+	}
+	if u, ok := lookupAmountUnit(first); ok {
+		return u, rest, true
+	}
+	return amountUnit{}, s, false
+}
+
+// nextAmountWord splits a leading run of letters, optionally ending in a dot.
+func nextAmountWord(s string) (string, string) {
+	end := 0
+	for end < len(s) {
+		r := rune(s[end])
+		if r >= 0x80 || !unicode.IsLetter(r) {
+			break
+		}
+		end++
+	}
+	if end > 0 && end < len(s) && s[end] == '.' {
+		end++
+	}
+	return s[:end], s[end:]
+}
+
+// grams estimates the weight of the quantity, taking the middle of ranges.
+// Volumes need the density of the food in grams per milliliter; counts
+// cannot be weighed.
+func (q parsedQuantity) grams(density float64) (float64, bool) {
+	u, ok := lookupAmountUnit(q.Unit)
+	if !ok || u.base == 0 {
+		return 0, false
+	}
+	value := q.Value
+	if q.Max > 0 {
+		value = (q.Value + q.Max) / 2
+	}
+	grams := value * u.base
+	if u.volume {
+		if density <= 0 {
+			return 0, false
+		}
+		grams *= density
+	}
+	return grams, true
+}
+
+// densities holds approximate densities of common foods in grams per
+// milliliter, keyed by name.
+var densities = map[string]float64{
+	"water": 1, "milk": 1.03, "buttermilk": 1.03, "cream": 1.0, "yogurt": 1.05,
+	"yoghurt": 1.05, "oil": 0.92, "olive oil": 0.91, "vinegar": 1.01, "soy sauce": 1.15,
+	"honey": 1.42, "maple syrup": 1.32, "syrup": 1.33, "juice": 1.04, "stock": 1.0,
+	"broth": 1.0, "wine": 0.99, "butter": 0.96, "flour": 0.53, "whole wheat": 0.51,
+	"cornstarch": 0.54, "sugar": 0.85, "brown sugar": 0.93, "powdered sugar": 0.51,
+	"icing sugar": 0.51, "salt": 1.22, "baking powder": 0.81, "baking soda": 0.93,
+	"cocoa": 0.42, "cocoa powder": 0.42, "rice": 0.85, "oats": 0.38, "rolled oats": 0.38,
+	"breadcrumbs": 0.45, "lentils": 0.81, "chickpeas": 0.72, "almonds": 0.6, "walnuts": 0.42,
+	"peanut butter": 1.09, "grated cheese": 0.45, "parmesan": 0.42, "chocolate chips": 0.72,
+	"raisins": 0.65, "ground coffee": 0.38,
+}
+
+// densityKeys are the density names, longest first, so "brown sugar" wins
+// over "sugar".
+var densityKeys = func() []string {
+	keys := slices.Collect(maps.Keys(densities))
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}()
+
+// densityOf looks up the density of an ingredient by the food names its
+// name contains, e.g. "all-purpose flour" is flour, and 0 for unknown foods.
+func densityOf(ingredient string) float64 {
+	words := strings.FieldsFunc(strings.ToLower(ingredient), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	name := " " + strings.Join(words, " ") + " "
+	for _, key := range densityKeys {
+		if strings.Contains(name, " "+key+" ") {
+			return densities[key]
+		}
+	}
+	return 0
+}
+
+func init() {
+	register(Migration{
+		Version: 8,
+		Name:    "ingredient_units",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &ingredientUnits{}, ingredientUnitColumns...); err != nil {
+				return err
+			}
+			if err := addColumns(tx, &userUnits{}, "Units"); err != nil {
+				return err
+			}
+
+			// Parse the amounts of existing ingredients.
+			var batch []amountIngredient
+			return tx.Model(&amountIngredient{}).FindInBatches(&batch, 200, func(batchTx *gorm.DB, _ int) error {
+				for _, ing := range batch {
+					cols := parseAmount(ing.Name, ing.Amount)
+					err := tx.Model(&amountIngredient{}).Where("id = ?", ing.ID).UpdateColumns(map[string]any{
+						"quantity":     cols.Quantity,
+						"quantity_max": cols.QuantityMax,
+						"unit":         cols.Unit,
+						"note":         cols.Note,
+						"grams":        cols.Grams,
+					}).Error
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := dropColumns(tx, &userUnits{}, "Units"); err != nil {
+				return err
+			}
+			return dropColumns(tx, &ingredientUnits{}, ingredientUnitColumns...)
+		},
+	})
+}
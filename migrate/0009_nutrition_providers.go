@@ -0,0 +1,72 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ingredientNutritionSource struct {
+	NutritionSource string `gorm:"size:32"`
+}
+
+func (ingredientNutritionSource) TableName() string { return "ingredients" }
+
+type food struct {
+	ID           uint   `gorm:"primaryKey"`
+	Name         string `gorm:"size:255;uniqueIndex;not null"`
+	Calories     float64
+	Protein      float64
+	Fat          float64
+	Carbs        float64
+	Fiber        float64
+	Sugar        float64
+	PortionGrams float64
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+}
+
+func (food) TableName() string { return "foods" }
+
+type nutritionCacheEntry struct {
+	ID       uint   `gorm:"primaryKey"`
+	CacheKey string `gorm:"size:255;uniqueIndex;not null"`
+	Source   string `gorm:"size:32"`
+	Food     string `gorm:"size:255"`
+	Calories float64
+	Protein  float64
+	Fat      float64
+	Carbs    float64
+	Fiber    float64
+	Sugar    float64
+	CachedAt time.Time `gorm:"index"`
+}
+
+func (nutritionCacheEntry) TableName() string { return "nutrition_cache_entries" }
+
+func init() {
+	register(Migration{
+		Version: 9,
+		Name:    "nutrition_providers",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &ingredientNutritionSource{}, "NutritionSource"); err != nil {
+				return err
+			}
+			if err := tx.AutoMigrate(&food{}, &nutritionCacheEntry{}); err != nil {
+				return err
+			}
+			// Nutrition stored so far came from CalorieNinjas, which only
+			// saved it when every ingredient matched.
+			return tx.Model(&ingredientNutritionSource{}).
+				Where("nutrition_source = '' OR nutrition_source IS NULL").
+				Where("calories > 0 OR protein > 0 OR fat > 0 OR carbs > 0").
+				UpdateColumn("nutrition_source", "calorieninjas").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&nutritionCacheEntry{}, &food{}); err != nil {
+				return err
+			}
+			return dropColumns(tx, &ingredientNutritionSource{}, "NutritionSource")
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package migrate
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type job struct {
+	ID          uint      `gorm:"primaryKey"`
+	Type        string    `gorm:"size:64;not null;index"`
+	Payload     string    `gorm:"type:text"`
+	Status      string    `gorm:"size:16;not null;default:pending;index:idx_job_status_run_at"`
+	Attempts    int       `gorm:"not null;default:0"`
+	MaxAttempts int       `gorm:"not null"`
+	RunAt       time.Time `gorm:"index:idx_job_status_run_at"`
+	LockedAt    *time.Time
+	LastError   string `gorm:"type:text"`
+	FinishedAt  *time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+func (job) TableName() string { return "jobs" }
+
+func init() {
+	register(Migration{
+		Version: 10,
+		Name:    "jobs",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&job{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&job{})
+		},
+	})
+}
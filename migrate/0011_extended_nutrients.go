@@ -0,0 +1,67 @@
+package migrate
+
+import "gorm.io/gorm"
+
+// The nutrient columns this migration adds, one type per table.
+type recipeNutrients struct {
+	SaturatedFat float64
+	Cholesterol  float64
+	Sodium       float64
+	Potassium    float64
+}
+
+func (recipeNutrients) TableName() string { return "recipes" }
+
+type ingredientNutrients struct {
+	SaturatedFat float64
+	Cholesterol  float64
+	Sodium       float64
+	Potassium    float64
+}
+
+func (ingredientNutrients) TableName() string { return "ingredients" }
+
+type foodNutrients struct {
+	SaturatedFat float64
+	Cholesterol  float64
+	Sodium       float64
+	Potassium    float64
+}
+
+func (foodNutrients) TableName() string { return "foods" }
+
+type cachedNutrients struct {
+	SaturatedFat float64
+	Cholesterol  float64
+	Sodium       float64
+	Potassium    float64
+}
+
+func (cachedNutrients) TableName() string { return "nutrition_cache_entries" }
+
+var extendedNutrientColumns = []string{"SaturatedFat", "Cholesterol", "Sodium", "Potassium"}
+
+func init() {
+	register(Migration{
+		Version: 11,
+		Name:    "extended_nutrients",
+		Up: func(tx *gorm.DB) error {
+			for _, m := range []any{&recipeNutrients{}, &ingredientNutrients{}, &foodNutrients{}, &cachedNutrients{}} {
+				if err := addColumns(tx, m, extendedNutrientColumns...); err != nil {
+					return err
+				}
+			}
+			// Cached lookups lack the new nutrients; looking them up again
+			// is cheaper than carrying zeros around.
+			return tx.Where("1 = 1").Delete(&nutritionCacheEntry{}).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, m := range []any{&recipeNutrients{}, &ingredientNutrients{}, &foodNutrients{}, &cachedNutrients{}} {
+				if err := dropColumns(tx, m, extendedNutrientColumns...); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,306 @@
+package migrate
+
+import (
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/Abb133Se/recepieshare/migrate/internal/baseline"
+)
+
+type recipeDietaryLabels struct {
+	ID        uint     `gorm:"primaryKey"`
+	Allergens []string `gorm:"serializer:json;type:text"`
+	Diets     []string `gorm:"serializer:json;type:text"`
+}
+
+func (recipeDietaryLabels) TableName() string { return "recipes" }
+
+// dietaryLabels and the functions and tables below are dietary.Analyze as of
+// this migration. What an ingredient contains is an allergen or one of
+// "meat", "honey" and "gelatin".
+
+// dietaryAllergens and dietaryDiets list the labels in the order they are
+// stored.
+var (
+	dietaryAllergens = []string{
+		"celery", "gluten", "crustacean", "egg", "fish", "lupin", "milk",
+		"mollusc", "mustard", "tree_nut", "peanut", "sesame", "soy", "sulphite",
+	}
+	dietaryDiets = []string{"vegan", "vegetarian", "pescatarian", "gluten_free", "dairy_free", "nut_free"}
+)
+
+// dietaryExcludes lists what rules out each diet.
+var dietaryExcludes = map[string][]string{
+	"vegan":       {"meat", "fish", "crustacean", "mollusc", "gelatin", "milk", "egg", "honey"},
+	"vegetarian":  {"meat", "fish", "crustacean", "mollusc", "gelatin"},
+	"pescatarian": {"meat", "gelatin"},
+	"gluten_free": {"gluten"},
+	"dairy_free":  {"milk"},
+	"nut_free":    {"tree_nut", "peanut"},
+}
+
+var dietaryFoods = []struct {
+	names  []string
+	traits []string
+}{
+	// Plant foods whose names would otherwise match an entry below.
+	{names: []string{
+		"coconut milk", "coconut cream", "coconut butter", "cocoa butter", "shea butter",
+		"cream of tartar", "rice flour", "corn flour", "cornflour", "coconut flour",
+		"chickpea flour", "gram flour", "buckwheat flour", "potato flour", "tapioca flour",
+		"rice noodle", "rice paper", "corn tortilla", "rice vinegar", "apple cider vinegar",
+		"nutmeg", "butternut squash", "eggplant", "butter bean", "water chestnut",
+		"pine nut", "tiger nut", "ginger ale", "root beer",
+	}},
+
+	{names: []string{
+		"wheat", "flour", "bread", "breadcrumb", "panko", "crouton", "pasta", "spaghetti",
+		"macaroni", "penne", "fusilli", "linguine", "fettuccine", "lasagna", "lasagne",
+		"tagliatelle", "ravioli", "tortellini", "gnocchi", "noodle", "udon", "ramen",
+		"couscous", "bulgur", "semolina", "durum", "spelt", "farro", "barley", "rye", "oat",
+		"oatmeal", "malt", "seitan", "tortilla", "pita", "naan", "bagel", "croissant",
+		"baguette", "brioche", "pastry", "puff pastry", "phyllo", "filo", "pie crust",
+		"cracker", "biscuit", "cookie", "cake", "muffin", "pancake mix", "beer", "ale",
+		"bran", "wheat germ", "graham cracker", "self raising flour", "self rising flour",
+	}, traits: []string{"gluten"}},
+
+	{names: []string{
+		"milk", "whole milk", "skim milk", "butter", "buttermilk", "cream", "heavy cream",
+		"whipping cream", "double cream", "single cream", "sour cream", "creme fraiche",
+		"ice cream", "condensed milk", "evaporated milk", "milk powder", "cheese",
+		"cream cheese", "cottage cheese", "goat cheese", "parmesan", "parmigiano",
+		"pecorino", "mozzarella", "cheddar", "feta", "ricotta", "mascarpone", "brie",
+		"camembert", "gouda", "gruyere", "emmental", "halloumi", "paneer", "burrata",
+		"provolone", "yogurt", "yoghurt", "greek yogurt", "kefir", "ghee", "whey", "casein",
+		"lactose", "custard", "milk chocolate", "white chocolate", "bechamel",
+	}, traits: []string{"milk"}},
+	{names: []string{"pesto"}, traits: []string{"milk", "tree_nut"}},
+	{names: []string{"alfredo sauce"}, traits: []string{"milk"}},
+
+	{names: []string{
+		"egg", "egg white", "egg yolk", "yolk", "mayonnaise", "mayo", "aioli", "meringue",
+		"egg noodle", "hollandaise",
+	}, traits: []string{"egg"}},
+	{names: []string{"egg pasta", "fresh pasta"}, traits: []string{"egg", "gluten"}},
+
+	{names: []string{
+		"fish", "salmon", "tuna", "cod", "trout", "sardine", "mackerel", "haddock",
+		"tilapia", "halibut", "sea bass", "seabass", "bass", "anchovy", "herring", "pollock",
+		"swordfish", "snapper", "catfish", "carp", "sole", "fish sauce", "fish stock",
+		"worcestershire sauce", "caviar", "roe", "bonito", "dashi",
+	}, traits: []string{"fish"}},
+	{names: []string{
+		"shrimp", "prawn", "crab", "lobster", "crayfish", "langoustine", "krill",
+		"shrimp paste",
+	}, traits: []string{"crustacean"}},
+	{names: []string{
+		"mussel", "clam", "oyster", "scallop", "squid", "calamari", "octopus", "snail",
+		"escargot", "cuttlefish", "oyster sauce", "abalone",
+	}, traits: []string{"mollusc"}},
+
+	{names: []string{
+		"almond", "hazelnut", "walnut", "cashew", "pecan", "pistachio", "macadamia",
+		"brazil nut", "nut", "mixed nut", "almond milk", "almond flour", "ground almond",
+		"almond butter", "cashew butter", "marzipan", "praline", "nougat", "frangipane",
+		"amaretto", "gianduja", "hazelnut spread",
+	}, traits: []string{"tree_nut"}},
+	{names: []string{"nutella"}, traits: []string{"tree_nut", "milk"}},
+	{names: []string{
+		"peanut", "peanut butter", "groundnut", "peanut oil", "satay sauce",
+	}, traits: []string{"peanut"}},
+
+	{names: []string{
+		"soy", "soya", "soybean", "soy milk", "soya milk", "tofu", "tempeh", "edamame",
+		"miso", "tamari", "natto", "soy lecithin", "textured vegetable protein",
+	}, traits: []string{"soy"}},
+	{names: []string{"soy sauce", "soya sauce", "teriyaki sauce", "hoisin sauce"},
+		traits: []string{"soy", "gluten"}},
+
+	{names: []string{"sesame", "sesame seed", "sesame oil", "tahini", "hummus", "halva", "halvah"},
+		traits: []string{"sesame"}},
+	{names: []string{"mustard", "dijon", "mustard seed", "mustard powder"},
+		traits: []string{"mustard"}},
+	{names: []string{"celery", "celeriac", "celery salt", "celery seed"},
+		traits: []string{"celery"}},
+	{names: []string{"lupin", "lupine", "lupin flour"}, traits: []string{"lupin"}},
+	{names: []string{
+		"wine", "red wine", "white wine", "sherry", "port", "marsala", "vermouth",
+		"wine vinegar", "red wine vinegar", "white wine vinegar", "balsamic vinegar",
+		"dried apricot", "dried fruit", "raisin", "sultana", "sulphite", "sulfite",
+	}, traits: []string{"sulphite"}},
+
+	{names: []string{
+		"meat", "beef", "ground beef", "minced beef", "steak", "veal", "pork", "ground pork",
+		"bacon", "pancetta", "ham", "prosciutto", "chorizo", "salami", "pepperoni",
+		"sausage", "hot dog", "chicken", "turkey", "duck", "goose", "lamb", "mutton",
+		"goat", "venison", "rabbit", "mince", "meatball", "lard", "suet", "tallow",
+		"chicken stock", "beef stock", "chicken broth", "beef broth", "bone broth",
+		"liver", "oxtail", "brisket",
+	}, traits: []string{"meat"}},
+	{names: []string{"gelatin", "gelatine", "marshmallow"}, traits: []string{"gelatin"}},
+	{names: []string{"honey"}, traits: []string{"honey"}},
+}
+
+// dietaryFreeFrom maps the word before "free" to what "<word>-free" rules out.
+var dietaryFreeFrom = map[string][]string{
+	"gluten":   {"gluten"},
+	"wheat":    {"gluten"},
+	"dairy":    {"milk"},
+	"lactose":  {"milk"},
+	"milk":     {"milk"},
+	"egg":      {"egg"},
+	"nut":      {"tree_nut", "peanut"},
+	"peanut":   {"peanut"},
+	"soy":      {"soy"},
+	"sulphite": {"sulphite"},
+	"sulfite":  {"sulphite"},
+}
+
+// dietaryPlantBased maps words such as "vegan" to the animal products they rule
+// out, so "vegan butter" is not milk.
+var dietaryPlantBased = map[string][]string{
+	"vegan":      {"meat", "gelatin", "honey", "milk", "egg", "fish", "crustacean", "mollusc"},
+	"vegetarian": {"meat", "gelatin", "fish", "crustacean", "mollusc"},
+	"plant":      {"meat", "gelatin", "honey", "milk", "egg", "fish", "crustacean", "mollusc"},
+	"meatless":   {"meat"},
+}
+
+var (
+	dietaryKnowledge      = map[string][]string{}
+	dietaryMaxPhraseWords int
+)
+
+func init() {
+	for _, f := range dietaryFoods {
+		for _, name := range f.names {
+			dietaryKnowledge[name] = f.traits
+			dietaryMaxPhraseWords = max(dietaryMaxPhraseWords, len(strings.Fields(name)))
+		}
+	}
+}
+
+// dietaryLabels derives the allergens a recipe contains and the diets it
+// fits from its ingredient names.
+func dietaryLabels(ingredients []string) (allergens, diets []string) {
+	found := map[string]bool{}
+	for _, name := range ingredients {
+		for t := range dietaryTraits(name) {
+			found[t] = true
+		}
+	}
+
+	allergens, diets = []string{}, []string{}
+	for _, a := range dietaryAllergens {
+		if found[a] {
+			allergens = append(allergens, a)
+		}
+	}
+	for _, d := range dietaryDiets {
+		fits := true
+		for _, t := range dietaryExcludes[d] {
+			if found[t] {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			diets = append(diets, d)
+		}
+	}
+	return allergens, diets
+}
+
+// dietaryTraits looks the words of an ingredient name up in the knowledge
+// base. Longer phrases win, and modifiers such as "gluten-free" or "vegan"
+// cancel what they deny.
+func dietaryTraits(ingredient string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(ingredient), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for i, w := range words {
+		words[i] = dietarySingular(w)
+	}
+
+	var cancelled []string
+	var rest []string
+	for i := 0; i < len(words); i++ {
+		if i+1 < len(words) && words[i+1] == "free" {
+			if ts, ok := dietaryFreeFrom[words[i]]; ok {
+				cancelled = append(cancelled, ts...)
+				i++
+				continue
+			}
+		}
+		if ts, ok := dietaryPlantBased[words[i]]; ok {
+			cancelled = append(cancelled, ts...)
+			continue
+		}
+		rest = append(rest, words[i])
+	}
+
+	traits := map[string]bool{}
+	for i := 0; i < len(rest); {
+		n := min(dietaryMaxPhraseWords, len(rest)-i)
+		for ; n > 0; n-- {
+			if ts, ok := dietaryKnowledge[strings.Join(rest[i:i+n], " ")]; ok {
+				for _, t := range ts {
+					traits[t] = true
+				}
+				break
+			}
+		}
+		i += max(n, 1)
+	}
+	for _, t := range cancelled {
+		delete(traits, t)
+	}
+	return traits
+}
+
+func dietarySingular(w string) string {
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "oes") && len(w) > 4:
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && len(w) > 3:
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func init() {
+	register(Migration{
+		Version: 12,
+		Name:    "dietary_labels",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &recipeDietaryLabels{}, "Allergens", "Diets"); err != nil {
+				return err
+			}
+
+			// Label existing recipes.
+			var batch []baseline.Recipe
+			return tx.Model(&baseline.Recipe{}).Preload("Ingredients").FindInBatches(&batch, 200, func(batchTx *gorm.DB, _ int) error {
+				for _, recipe := range batch {
+					names := make([]string, len(recipe.Ingredients))
+					for i, ing := range recipe.Ingredients {
+						names[i] = ing.Name
+					}
+					row := recipeDietaryLabels{ID: recipe.ID}
+					row.Allergens, row.Diets = dietaryLabels(names)
+					if err := tx.Model(&row).Select("Allergens", "Diets").UpdateColumns(&row).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumns(tx, &recipeDietaryLabels{}, "Allergens", "Diets")
+		},
+	})
+}
@@ -0,0 +1,52 @@
+package migrate
+
+import "gorm.io/gorm"
+
+type recipeMetadata struct {
+	PrepMinutes     int      `gorm:"not null;default:0"`
+	CookMinutes     int      `gorm:"not null;default:0"`
+	TotalMinutes    int      `gorm:"not null;default:0;index"`
+	Difficulty      string   `gorm:"size:32;index"`
+	DifficultyLevel int      `gorm:"not null;default:0"`
+	Cuisine         string   `gorm:"size:64;index"`
+	Equipment       []string `gorm:"serializer:json;type:text"`
+}
+
+func (recipeMetadata) TableName() string { return "recipes" }
+
+var (
+	recipeMetadataColumns = []string{
+		"PrepMinutes", "CookMinutes", "TotalMinutes", "Difficulty", "DifficultyLevel", "Cuisine", "Equipment",
+	}
+	recipeMetadataIndexes = []string{"TotalMinutes", "Difficulty", "Cuisine"}
+)
+
+func init() {
+	register(Migration{
+		Version: 13,
+		Name:    "recipe_metadata",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &recipeMetadata{}, recipeMetadataColumns...); err != nil {
+				return err
+			}
+			for _, field := range recipeMetadataIndexes {
+				if !tx.Migrator().HasIndex(&recipeMetadata{}, field) {
+					if err := tx.Migrator().CreateIndex(&recipeMetadata{}, field); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range recipeMetadataIndexes {
+				if tx.Migrator().HasIndex(&recipeMetadata{}, field) {
+					if err := tx.Migrator().DropIndex(&recipeMetadata{}, field); err != nil {
+						return err
+					}
+				}
+			}
+			return dropColumns(tx, &recipeMetadata{}, recipeMetadataColumns...)
+		},
+	})
+}
@@ -0,0 +1,116 @@
+package migrate
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/Abb133Se/recepieshare/migrate/internal/baseline"
+)
+
+type ingredientNameKey struct {
+	NameKey string `gorm:"size:255;index"`
+}
+
+func (ingredientNameKey) TableName() string { return "ingredients" }
+
+type pantryItem struct {
+	ID        uint          `gorm:"primaryKey"`
+	UserID    uint          `gorm:"uniqueIndex:idx_pantry_user_key;not null"`
+	User      baseline.User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Name      string        `gorm:"size:255;not null"`
+	NameKey   string        `gorm:"size:255;uniqueIndex:idx_pantry_user_key;not null"`
+	CreatedAt time.Time     `gorm:"autoCreateTime"`
+	UpdatedAt time.Time     `gorm:"autoUpdateTime"`
+}
+
+func (pantryItem) TableName() string { return "pantry_items" }
+
+// pantryFillerWords, pantryKey and pantrySingular are pantry.Key as of this
+// migration.
+var pantryFillerWords = map[string]bool{
+	"a": true, "and": true, "or": true, "of": true, "to": true, "the": true,
+	"fresh": true, "freshly": true, "chopped": true, "diced": true, "sliced": true,
+	"minced": true, "large": true, "medium": true, "small": true, "finely": true,
+	"roughly": true, "optional": true, "taste": true, "peeled": true, "grated": true,
+	"ripe": true, "whole": true, "cold": true, "warm": true, "hot": true,
+}
+
+func pantryKey(name string) string {
+	seen := map[string]bool{}
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if pantryFillerWords[w] {
+			continue
+		}
+		if w = pantrySingular(w); !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+func pantrySingular(w string) string {
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "oes") && len(w) > 4:
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && len(w) > 3:
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func init() {
+	register(Migration{
+		Version: 14,
+		Name:    "pantry",
+		Up: func(tx *gorm.DB) error {
+			if err := addColumns(tx, &ingredientNameKey{}, "NameKey"); err != nil {
+				return err
+			}
+			if !tx.Migrator().HasIndex(&ingredientNameKey{}, "NameKey") {
+				if err := tx.Migrator().CreateIndex(&ingredientNameKey{}, "NameKey"); err != nil {
+					return err
+				}
+			}
+
+			// Key the names of existing ingredients.
+			var batch []amountIngredient
+			err := tx.Model(&amountIngredient{}).FindInBatches(&batch, 500, func(batchTx *gorm.DB, _ int) error {
+				for _, ing := range batch {
+					if err := tx.Model(&amountIngredient{}).Where("id = ?", ing.ID).UpdateColumn("name_key", pantryKey(ing.Name)).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+			if err != nil {
+				return err
+			}
+
+			return tx.AutoMigrate(&pantryItem{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&pantryItem{}); err != nil {
+				return err
+			}
+			if tx.Migrator().HasIndex(&ingredientNameKey{}, "NameKey") {
+				if err := tx.Migrator().DropIndex(&ingredientNameKey{}, "NameKey"); err != nil {
+					return err
+				}
+			}
+			return dropColumns(tx, &ingredientNameKey{}, "NameKey")
+		},
+	})
+}
@@ -0,0 +1,30 @@
+package migrate
+
+import "gorm.io/gorm"
+
+// addColumns adds the given model fields unless they already exist. Earlier
+// builds created every current column in the initial schema, so this keeps
+// later migrations idempotent on their databases.
+func addColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().AddColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dropColumns(tx *gorm.DB, model interface{}, fields ...string) error {
+	for _, field := range fields {
+		if !tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		if err := tx.Migrator().DropColumn(model, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,27 +1,245 @@
 package migrate
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
-
-	"github.com/Abb133Se/recepieshare/model"
 )
 
-func AutoMigration(db *gorm.DB) error {
-	log.Println("Running auto migration...")
-	return db.AutoMigrate(
-		&model.User{},
-		&model.Recipe{},
-		&model.Ingredient{},
-		&model.Comment{},
-		&model.Favorite{},
-		&model.Rating{},
-		&model.Tag{},
-		&model.Category{},
-		&model.Step{},
-		&model.Image{},
-		&model.RecipeView{},
-		&model.SiteVisit{},
-	)
+// Migration is one numbered, reversible schema change. Up and Down run inside
+// a transaction together with the bookkeeping in schema_migrations.
+//
+// Migrations never use package model or other code that keeps changing:
+// they declare the tables and columns they touch as their own structs and
+// copy the functions and tables their backfills call into the migration
+// file, so they do the same thing no matter when they run.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration.
+type SchemaMigration struct {
+	Version   uint   `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255"`
+	AppliedAt time.Time
+}
+
+// Status describes a migration as seen by `migrate status`.
+type Status struct {
+	Version   uint
+	Name      string
+	AppliedAt *time.Time
+	// Missing is set for versions recorded in the database that this binary
+	// does not know about, e.g. after checking out an older revision.
+	Missing bool
+}
+
+var registry []Migration
+
+// register adds a migration to the registry. Each migration file calls it
+// from an init function.
+func register(m Migration) {
+	for _, existing := range registry {
+		if existing.Version == m.Version {
+			panic(fmt.Sprintf("migrate: duplicate migration version %04d", m.Version))
+		}
+	}
+	registry = append(registry, m)
+	sort.Slice(registry, func(i, j int) bool { return registry[i].Version < registry[j].Version })
+}
+
+// Migrations returns all registered migrations ordered by version.
+func Migrations() []Migration {
+	return append([]Migration(nil), registry...)
+}
+
+type Migrator struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Migrator {
+	return &Migrator{db: db}
+}
+
+func (m *Migrator) ensureTable() error {
+	return m.db.AutoMigrate(&SchemaMigration{})
+}
+
+func (m *Migrator) applied() (map[uint]SchemaMigration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	var rows []SchemaMigration
+	if err := m.db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[uint]SchemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Pending returns the registered migrations that have not been applied yet.
+func (m *Migrator) Pending() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, mig := range registry {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration in order and returns how many ran.
+func (m *Migrator) Up() (int, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return 0, err
+	}
+	for i, mig := range pending {
+		log.Printf("Applying migration %04d_%s...", mig.Version, mig.Name)
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := mig.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return i, fmt.Errorf("migration %04d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+	}
+	return len(pending), nil
+}
+
+// Down rolls back the n most recently applied migrations and returns how many
+// were reverted.
+func (m *Migrator) Down(n int) (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	byVersion := make(map[uint]Migration, len(registry))
+	for _, mig := range registry {
+		byVersion[mig.Version] = mig
+	}
+
+	versions := make([]uint, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	done := 0
+	for _, v := range versions {
+		if done == n {
+			break
+		}
+		mig, ok := byVersion[v]
+		if !ok {
+			return done, fmt.Errorf("migration %04d is applied but unknown to this binary", v)
+		}
+		log.Printf("Reverting migration %04d_%s...", mig.Version, mig.Name)
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := mig.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, mig.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("rollback of %04d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+		done++
+	}
+	return done, nil
+}
+
+// Status lists every registered migration, plus applied versions that are no
+// longer registered.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	known := make(map[uint]bool, len(registry))
+	for _, mig := range registry {
+		known[mig.Version] = true
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if row, ok := applied[mig.Version]; ok {
+			at := row.AppliedAt
+			s.AppliedAt = &at
+		}
+		statuses = append(statuses, s)
+	}
+	for v, row := range applied {
+		if !known[v] {
+			at := row.AppliedAt
+			statuses = append(statuses, Status{Version: v, Name: row.Name, AppliedAt: &at, Missing: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+var nameSanitizer = regexp.MustCompile(`[^a-z0-9]+`)
+
+const migrationTemplate = `package migrate
+
+import "gorm.io/gorm"
+
+func init() {
+	register(Migration{
+		Version: %d,
+		Name:    %q,
+		Up: func(tx *gorm.DB) error {
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return nil
+		},
+	})
+}
+`
+
+// Create writes an empty migration file into dir, numbered after the highest
+// registered version, and returns its path.
+func Create(dir, name string) (string, error) {
+	name = strings.Trim(nameSanitizer.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", fmt.Errorf("migration name is required")
+	}
+
+	var version uint = 1
+	if len(registry) > 0 {
+		version = registry[len(registry)-1].Version + 1
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%04d_%s.go", version, name))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, migrationTemplate, version, name); err != nil {
+		return "", err
+	}
+	return path, nil
 }
@@ -0,0 +1,115 @@
+package migrate
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+
+	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/model"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dialect, err := internal.NewDialect("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(dialect.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// The migrations declare their own copies of the models, so nothing but
+// this test notices when a model gains a column without a migration.
+func TestMigrationsCreateEveryModelColumn(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := New(db).Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	models := []any{
+		&model.User{}, &model.Recipe{}, &model.Ingredient{}, &model.Comment{}, &model.Favorite{},
+		&model.Rating{}, &model.Tag{}, &model.Category{}, &model.Step{}, &model.Image{},
+		&model.RecipeView{}, &model.SiteVisit{}, &model.RefreshToken{}, &model.RevokedToken{},
+		&model.RecipeRevision{}, &model.RecipeInvite{}, &model.Food{}, &model.NutritionCacheEntry{},
+		&model.Job{}, &model.PantryItem{},
+	}
+	for _, m := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			t.Fatal(err)
+		}
+		if !db.Migrator().HasTable(m) {
+			t.Errorf("table %s is missing", stmt.Schema.Table)
+			continue
+		}
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName == "" {
+				continue
+			}
+			if !db.Migrator().HasColumn(m, f.DBName) {
+				t.Errorf("column %s.%s is missing", stmt.Schema.Table, f.DBName)
+			}
+			if f.TagSettings["INDEX"] != "" || f.TagSettings["UNIQUEINDEX"] != "" {
+				for _, idx := range stmt.Schema.ParseIndexes() {
+					if hasField(idx, f) && !db.Migrator().HasIndex(m, idx.Name) {
+						t.Errorf("index %s on %s is missing", idx.Name, stmt.Schema.Table)
+					}
+				}
+			}
+		}
+	}
+}
+
+func hasField(idx schema.Index, f *schema.Field) bool {
+	for _, opt := range idx.Fields {
+		if opt.Field == f {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMigrationsRoundTrip(t *testing.T) {
+	db := newTestDB(t)
+	m := New(db)
+	if _, err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Down(len(Migrations())); err != nil {
+		t.Fatal(err)
+	}
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range tables {
+		if table != "schema_migrations" && table != "sqlite_sequence" {
+			t.Errorf("table %s is left after reverting every migration", table)
+		}
+	}
+	if _, err := m.Up(); err != nil {
+		t.Errorf("migrating up again: %v", err)
+	}
+}
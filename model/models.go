@@ -1,6 +1,15 @@
 package model
 
-import "time"
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/dietary"
+	"github.com/Abb133Se/recepieshare/pantry"
+	"github.com/Abb133Se/recepieshare/quantity"
+	"gorm.io/gorm"
+)
 
 type Recipe struct {
 	ID          uint         `gorm:"primaryKey"`
@@ -21,23 +30,166 @@ type Recipe struct {
 	Carbs       float64      `json:"carbs"`
 	Fiber       float64      `json:"fiber"`
 	Sugar       float64      `json:"sugar"`
-	CreatedAt   time.Time    `gorm:"autoCreateTime"`
-	UpdatedAt   time.Time    `gorm:"autoUpdateTime"`
+	// Cholesterol, Sodium and Potassium are in milligrams, the other
+	// nutrients in grams.
+	SaturatedFat float64          `json:"saturated_fat"`
+	Cholesterol  float64          `json:"cholesterol"`
+	Sodium       float64          `json:"sodium"`
+	Potassium    float64          `json:"potassium"`
+	Servings     int              `gorm:"not null;default:1" json:"servings"`
+	Yield        string           `gorm:"size:100" json:"yield"`
+	Status       RecipeStatus     `gorm:"size:16;not null;default:published;index" json:"status"`
+	PublishAt    *time.Time       `json:"publish_at,omitempty"`
+	PublishedAt  *time.Time       `json:"published_at,omitempty"`
+	Visibility   RecipeVisibility `gorm:"size:16;not null;default:public;index" json:"visibility"`
+	ShareToken   *string          `gorm:"size:64;uniqueIndex" json:"-"`
+	CreatedAt    time.Time        `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time        `gorm:"autoUpdateTime"`
+	// Allergens and Diets are derived from the ingredient names whenever
+	// the ingredients change, see SetDietaryLabels.
+	Allergens []string `gorm:"serializer:json;type:text" json:"allergens"`
+	Diets     []string `gorm:"serializer:json;type:text" json:"diets"`
+	// Times are in minutes, zero when unknown. Difficulty, Cuisine and
+	// Equipment take the values configured under recipes; DifficultyLevel
+	// is the position of Difficulty in that list, from 1 for the easiest.
+	PrepMinutes     int      `gorm:"not null;default:0" json:"prep_minutes"`
+	CookMinutes     int      `gorm:"not null;default:0" json:"cook_minutes"`
+	TotalMinutes    int      `gorm:"not null;default:0;index" json:"total_minutes"`
+	Difficulty      string   `gorm:"size:32;index" json:"difficulty"`
+	DifficultyLevel int      `gorm:"not null;default:0" json:"difficulty_level"`
+	Cuisine         string   `gorm:"size:64;index" json:"cuisine"`
+	Equipment       []string `gorm:"serializer:json;type:text" json:"equipment"`
+}
+
+// SetDietaryLabels derives the allergens and diets of the recipe from
+// ingredients.
+func (r *Recipe) SetDietaryLabels(ingredients []Ingredient) {
+	names := make([]string, len(ingredients))
+	for i, ing := range ingredients {
+		names[i] = ing.Name
+	}
+	labels := dietary.Analyze(names)
+	r.Allergens, r.Diets = labels.Allergens, labels.Diets
 }
 
+// RecipeStatus is the publication state of a recipe. Only published recipes
+// show up in public listings; scheduled ones are published by the scheduler
+// once their PublishAt has passed.
+type RecipeStatus string
+
+const (
+	StatusDraft     RecipeStatus = "draft"
+	StatusPublished RecipeStatus = "published"
+	StatusScheduled RecipeStatus = "scheduled"
+	StatusArchived  RecipeStatus = "archived"
+)
+
+// RecipeVisibility decides who may see a published recipe. Public recipes are
+// listed everywhere, unlisted ones only open with their ShareToken (never
+// serialized, the owner fetches it separately) and private ones only for the
+// owner and invited users.
+type RecipeVisibility string
+
+const (
+	VisibilityPublic   RecipeVisibility = "public"
+	VisibilityUnlisted RecipeVisibility = "unlisted"
+	VisibilityPrivate  RecipeVisibility = "private"
+)
+
+// ParseRecipeVisibility validates a visibility name from a request.
+func ParseRecipeVisibility(s string) (RecipeVisibility, bool) {
+	switch v := RecipeVisibility(s); v {
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+		return v, true
+	}
+	return "", false
+}
+
+// ParseRecipeStatus validates a status name from a request.
+func ParseRecipeStatus(s string) (RecipeStatus, bool) {
+	switch st := RecipeStatus(s); st {
+	case StatusDraft, StatusPublished, StatusScheduled, StatusArchived:
+		return st, true
+	}
+	return "", false
+}
+
+// Ingredient is one line of a recipe's ingredient list. Quantity,
+// QuantityMax, Unit and Note are parsed from Amount when the ingredient is
+// saved and stay empty for amounts without a number. Grams is the estimated
+// weight, from the unit or the food's density.
 type Ingredient struct {
-	ID        uint      `gorm:"primaryKey"`
-	Name      string    `json:"name" binding:"required"`
-	Amount    string    `json:"amount" binding:"required"`
-	RecipeID  uint      `json:"recipe_id" gorm:"index"`
-	Calories  float64   `json:"calories"`
-	Protein   float64   `json:"protein"`
-	Fat       float64   `json:"fat"`
-	Carbs     float64   `json:"carbs"`
-	Fiber     float64   `json:"fiber"`
-	Sugar     float64   `json:"sugar"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	ID          uint    `gorm:"primaryKey"`
+	Name        string  `json:"name" binding:"required"`
+	NameKey     string  `json:"-" gorm:"size:255;index"`
+	Amount      string  `json:"amount" binding:"required"`
+	Quantity    float64 `json:"quantity,omitempty"`
+	QuantityMax float64 `json:"quantity_max,omitempty"`
+	Unit        string  `json:"unit,omitempty" gorm:"size:16"`
+	Note        string  `json:"note,omitempty" gorm:"size:255"`
+	Grams       float64 `json:"grams,omitempty"`
+	RecipeID    uint    `json:"recipe_id" gorm:"index"`
+	Calories    float64 `json:"calories"`
+	Protein     float64 `json:"protein"`
+	Fat         float64 `json:"fat"`
+	Carbs       float64 `json:"carbs"`
+	Fiber       float64 `json:"fiber"`
+	Sugar       float64 `json:"sugar"`
+	// Cholesterol, Sodium and Potassium are in milligrams, the other
+	// nutrients in grams.
+	SaturatedFat float64 `json:"saturated_fat"`
+	Cholesterol  float64 `json:"cholesterol"`
+	Sodium       float64 `json:"sodium"`
+	Potassium    float64 `json:"potassium"`
+	// NutritionSource is the provider the nutrients came from, empty when
+	// no provider matched the ingredient.
+	NutritionSource string    `json:"nutrition_source,omitempty" gorm:"size:32"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+}
+
+// BeforeSave keeps the structured quantity in sync with Amount and the
+// name key, used for pantry matching, in sync with Name.
+func (i *Ingredient) BeforeSave(*gorm.DB) error {
+	i.Normalize()
+	i.NameKey = pantry.Key(i.Name)
+	return nil
+}
+
+// Normalize parses Amount into the structured quantity fields.
+func (i *Ingredient) Normalize() {
+	q, ok := quantity.Parse(i.Amount)
+	if !ok {
+		i.Quantity, i.QuantityMax, i.Unit, i.Note, i.Grams = 0, 0, "", "", 0
+		return
+	}
+	amount := i.Amount
+	i.SetQuantity(q)
+	i.Amount = amount
+}
+
+// ParsedQuantity returns the structured quantity, false when Amount has no
+// number.
+func (i *Ingredient) ParsedQuantity() (quantity.Quantity, bool) {
+	if i.Quantity <= 0 {
+		return quantity.Quantity{}, false
+	}
+	return quantity.Quantity{Value: i.Quantity, Max: i.QuantityMax, Unit: i.Unit, Note: i.Note}, true
+}
+
+// SetQuantity replaces the amount with q, e.g. after scaling or converting
+// it, and rewrites Amount to match.
+func (i *Ingredient) SetQuantity(q quantity.Quantity) {
+	i.Amount = q.String()
+	// Three decimals keep thirds precise enough to scale again.
+	i.Quantity = math.Round(q.Value*1000) / 1000
+	i.QuantityMax = math.Round(q.Max*1000) / 1000
+	i.Unit = q.Unit
+	i.Note = q.Note
+	i.Grams = 0
+	if grams, ok := q.Grams(quantity.DensityOf(i.Name)); ok {
+		i.Grams = math.Round(grams*10) / 10
+	}
 }
 
 type Step struct {
@@ -53,12 +205,16 @@ type User struct {
 	ID                     uint       `gorm:"primaryKey"`
 	Name                   string     `json:"name"`
 	LastName               string     `json:"last_name"`
-	Salt                   string     `json:"salt"`
+	Salt                   string     `json:"salt"` // only set for legacy SHA-256 hashes
 	Password               string     `json:"password"`
 	Email                  string     `json:"email"`
 	Role                   string     `gorm:"deafault:user" json:"role"`
 	PasswordResetToken     string     `json:"-" gorm:"size:255"`
 	PasswordResetExpiresAt *time.Time `json:"-"`
+	EmailVerifiedAt        *time.Time `json:"email_verified_at"`
+	VerificationToken      string     `json:"-" gorm:"size:255;index"`
+	VerificationExpiresAt  *time.Time `json:"-"`
+	Units                  string     `json:"units" gorm:"size:16"` // preferred measurement system, empty for the original units
 	Comments               []Comment  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"comments"`
 	Recipes                []Recipe   `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"recipes"`
 	Favorites              []Favorite `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"favorites"`
@@ -135,3 +291,232 @@ type SiteVisit struct {
 	IPAddress string    `json:"ip_address"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 }
+
+// RefreshToken is one link of a rotating refresh-token chain. All tokens
+// issued from the same login share a FamilyID so reuse of a rotated token can
+// revoke the whole chain.
+type RefreshToken struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"index"`
+	User       User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	FamilyID   string     `gorm:"index;size:64"`
+	TokenHash  string     `gorm:"uniqueIndex;size:64"`
+	ExpiresAt  time.Time  `gorm:"index"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy *uint      `json:"replaced_by,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+}
+
+// RevokedToken is the deny-list of access tokens (by jti) that were revoked
+// before they expired, e.g. on logout.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// RecipeInvite lets a user see a private recipe of someone else.
+type RecipeInvite struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	RecipeID  uint      `gorm:"uniqueIndex:idx_recipe_invite_user;not null" json:"recipe_id"`
+	Recipe    Recipe    `gorm:"foreignKey:RecipeID;constraint:OnDelete:CASCADE" json:"-"`
+	UserID    uint      `gorm:"uniqueIndex:idx_recipe_invite_user;index;not null" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// PantryItem is an ingredient a user has at home. NameKey is the normalized
+// name that recipe ingredients are matched against; a user has each
+// ingredient once.
+type PantryItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_pantry_user_key;not null" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Name      string    `gorm:"size:255;not null" json:"name"`
+	NameKey   string    `gorm:"size:255;uniqueIndex:idx_pantry_user_key;not null" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BeforeSave keeps NameKey in sync with Name.
+func (p *PantryItem) BeforeSave(*gorm.DB) error {
+	p.NameKey = pantry.Key(p.Name)
+	return nil
+}
+
+// Food is an entry of the local food-composition table imported from CSV.
+// Nutrients are per 100 g, in milligrams for cholesterol, sodium and
+// potassium; PortionGrams is the weight of one piece and lets
+// counted ingredients such as "2 eggs" be weighed.
+type Food struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"size:255;uniqueIndex;not null" json:"name"`
+	Calories     float64   `json:"calories"`
+	Protein      float64   `json:"protein"`
+	Fat          float64   `json:"fat"`
+	Carbs        float64   `json:"carbs"`
+	Fiber        float64   `json:"fiber"`
+	Sugar        float64   `json:"sugar"`
+	SaturatedFat float64   `json:"saturated_fat"`
+	Cholesterol  float64   `json:"cholesterol"`
+	Sodium       float64   `json:"sodium"`
+	Potassium    float64   `json:"potassium"`
+	PortionGrams float64   `json:"portion_grams"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// NutritionCacheEntry is the looked up nutrition of one ingredient, keyed by
+// its normalized name and quantity.
+type NutritionCacheEntry struct {
+	ID           uint   `gorm:"primaryKey"`
+	CacheKey     string `gorm:"size:255;uniqueIndex;not null"`
+	Source       string `gorm:"size:32"`
+	Food         string `gorm:"size:255"`
+	Calories     float64
+	Protein      float64
+	Fat          float64
+	SaturatedFat float64
+	Carbs        float64
+	Fiber        float64
+	Sugar        float64
+	Cholesterol  float64
+	Sodium       float64
+	Potassium    float64
+	CachedAt     time.Time `gorm:"index"`
+}
+
+// Job is a unit of background work such as estimating the nutrition of a
+// recipe. Payload is the JSON the handler registered for Type receives.
+// Failed jobs are retried at RunAt until MaxAttempts is reached, after which
+// they are dead and wait for an admin to retry them.
+type Job struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Type        string     `gorm:"size:64;not null;index" json:"type"`
+	Payload     string     `gorm:"type:text" json:"payload"`
+	Status      JobStatus  `gorm:"size:16;not null;default:pending;index:idx_job_status_run_at" json:"status"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int        `gorm:"not null" json:"max_attempts"`
+	RunAt       time.Time  `gorm:"index:idx_job_status_run_at" json:"run_at"`
+	LockedAt    *time.Time `json:"locked_at,omitempty"`
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// JobStatus is where a job is in its life cycle.
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobDead    JobStatus = "dead"
+)
+
+// ParseJobStatus validates a job status name from a request.
+func ParseJobStatus(s string) (JobStatus, bool) {
+	switch st := JobStatus(s); st {
+	case JobPending, JobRunning, JobDone, JobDead:
+		return st, true
+	}
+	return "", false
+}
+
+// RecipeRevision is a snapshot of the whole recipe aggregate, taken after
+// every change. Versions count up from 1 per recipe.
+type RecipeRevision struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	RecipeID uint   `gorm:"uniqueIndex:idx_recipe_revision_version;not null" json:"recipe_id"`
+	Recipe   Recipe `gorm:"foreignKey:RecipeID;constraint:OnDelete:CASCADE" json:"-"`
+	Version  int    `gorm:"uniqueIndex:idx_recipe_revision_version;not null" json:"version"`
+	// UserID is the author of the change, not necessarily the recipe owner.
+	UserID uint `gorm:"index" json:"user_id"`
+	// RestoredFrom is set when the revision restored an older version.
+	RestoredFrom *int           `json:"restored_from,omitempty"`
+	Snapshot     RecipeSnapshot `gorm:"serializer:json;type:text" json:"snapshot"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// RecipeSnapshot is the content of a recipe as stored in a revision.
+// Derived values such as nutrition are left out.
+type RecipeSnapshot struct {
+	Title       string               `json:"title"`
+	Text        string               `json:"text"`
+	Servings    int                  `json:"servings,omitempty"`
+	Yield       string               `json:"yield,omitempty"`
+	Ingredients []SnapshotIngredient `json:"ingredients"`
+	Steps       []SnapshotStep       `json:"steps"`
+	Tags        []SnapshotRef        `json:"tags"`
+	Categories  []SnapshotRef        `json:"categories"`
+	// Metadata is nil in snapshots taken before recipes had it.
+	Metadata *SnapshotMetadata `json:"metadata,omitempty"`
+}
+
+type SnapshotMetadata struct {
+	PrepMinutes     int      `json:"prep_minutes"`
+	CookMinutes     int      `json:"cook_minutes"`
+	TotalMinutes    int      `json:"total_minutes"`
+	Difficulty      string   `json:"difficulty"`
+	DifficultyLevel int      `json:"difficulty_level"`
+	Cuisine         string   `json:"cuisine"`
+	Equipment       []string `json:"equipment"`
+}
+
+type SnapshotIngredient struct {
+	Name   string `json:"name"`
+	Amount string `json:"amount"`
+}
+
+type SnapshotStep struct {
+	Order int    `json:"order"`
+	Text  string `json:"text"`
+}
+
+// SnapshotRef keeps the name next to the ID so a tag or category deleted in
+// the meantime can still be shown (and tags recreated on restore).
+type SnapshotRef struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// Snapshot captures the recipe with its Ingredients, Steps, Tags and
+// Categories, which must be loaded.
+func (r *Recipe) Snapshot() RecipeSnapshot {
+	s := RecipeSnapshot{
+		Title:       r.Title,
+		Text:        r.Text,
+		Servings:    r.Servings,
+		Yield:       r.Yield,
+		Ingredients: []SnapshotIngredient{},
+		Steps:       []SnapshotStep{},
+		Tags:        []SnapshotRef{},
+		Categories:  []SnapshotRef{},
+		Metadata: &SnapshotMetadata{
+			PrepMinutes:     r.PrepMinutes,
+			CookMinutes:     r.CookMinutes,
+			TotalMinutes:    r.TotalMinutes,
+			Difficulty:      r.Difficulty,
+			DifficultyLevel: r.DifficultyLevel,
+			Cuisine:         r.Cuisine,
+			Equipment:       r.Equipment,
+		},
+	}
+	for _, i := range r.Ingredients {
+		s.Ingredients = append(s.Ingredients, SnapshotIngredient{Name: i.Name, Amount: i.Amount})
+	}
+	for _, st := range r.Steps {
+		s.Steps = append(s.Steps, SnapshotStep{Order: st.Order, Text: st.Text})
+	}
+	sort.Slice(s.Steps, func(i, j int) bool { return s.Steps[i].Order < s.Steps[j].Order })
+	for _, t := range r.Tags {
+		s.Tags = append(s.Tags, SnapshotRef{ID: t.ID, Name: t.Name})
+	}
+	for _, c := range r.Categories {
+		s.Categories = append(s.Categories, SnapshotRef{ID: c.ID, Name: c.Name})
+	}
+	sort.Slice(s.Tags, func(i, j int) bool { return s.Tags[i].ID < s.Tags[j].ID })
+	sort.Slice(s.Categories, func(i, j int) bool { return s.Categories[i].ID < s.Categories[j].ID })
+	return s
+}
@@ -0,0 +1,112 @@
+package nutrition
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
+)
+
+// Cached remembers matched ingredients for ttl, so saving a recipe again or
+// another recipe with the same ingredient does not ask the providers again.
+// Unmatched ingredients are not cached, they may match once more foods are
+// imported.
+type Cached struct {
+	next  Provider
+	store repository.NutritionCacheRepository
+	clock internal.Clock
+	ttl   time.Duration
+}
+
+func NewCached(next Provider, store repository.NutritionCacheRepository, clock internal.Clock, ttl time.Duration) *Cached {
+	return &Cached{next: next, store: store, clock: clock, ttl: ttl}
+}
+
+func (c *Cached) Name() string { return c.next.Name() }
+
+func (c *Cached) Lookup(ctx context.Context, queries []Query) ([]Result, error) {
+	now := c.clock.Now()
+
+	keys := make([]string, len(queries))
+	for i, q := range queries {
+		keys[i] = q.Key()
+	}
+	cached, err := c.store.Find(keys, now.Add(-c.ttl))
+	if err != nil {
+		// A broken cache must not break nutrition.
+		log.Printf("failed to read nutrition cache: %v", err)
+		cached = nil
+	}
+
+	results := unmatched(len(queries))
+	var missing []int
+	for i, key := range keys {
+		if e, ok := cached[key]; ok {
+			results[i] = Result{
+				Facts: Facts{
+					Calories:     e.Calories,
+					Protein:      e.Protein,
+					Fat:          e.Fat,
+					SaturatedFat: e.SaturatedFat,
+					Carbs:        e.Carbs,
+					Fiber:        e.Fiber,
+					Sugar:        e.Sugar,
+					Cholesterol:  e.Cholesterol,
+					Sodium:       e.Sodium,
+					Potassium:    e.Potassium,
+				},
+				Matched: true,
+				Food:    e.Food,
+				Source:  e.Source,
+			}
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+
+	batch := make([]Query, len(missing))
+	for j, i := range missing {
+		batch[j] = queries[i]
+	}
+	found, err := c.next.Lookup(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []model.NutritionCacheEntry
+	stored := map[string]bool{}
+	for j, i := range missing {
+		results[i] = found[j]
+		if !found[j].Matched || stored[keys[i]] {
+			continue
+		}
+		stored[keys[i]] = true
+		f := found[j].Facts
+		entries = append(entries, model.NutritionCacheEntry{
+			CacheKey:     keys[i],
+			Source:       found[j].Source,
+			Food:         found[j].Food,
+			Calories:     f.Calories,
+			Protein:      f.Protein,
+			Fat:          f.Fat,
+			SaturatedFat: f.SaturatedFat,
+			Carbs:        f.Carbs,
+			Fiber:        f.Fiber,
+			Sugar:        f.Sugar,
+			Cholesterol:  f.Cholesterol,
+			Sodium:       f.Sodium,
+			Potassium:    f.Potassium,
+			CachedAt:     now,
+		})
+	}
+	if err := c.store.Put(entries); err != nil {
+		log.Printf("failed to write nutrition cache: %v", err)
+	}
+	return results, nil
+}
@@ -0,0 +1,104 @@
+package nutrition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const calorieNinjasURL = "https://api.calorieninjas.com/v1/nutrition"
+
+// CalorieNinjas looks ingredients up in the CalorieNinjas API, one request
+// per ingredient so every result can be told apart.
+type CalorieNinjas struct {
+	apiKey string
+	client *http.Client
+}
+
+func NewCalorieNinjas(apiKey string, timeout time.Duration) *CalorieNinjas {
+	return &CalorieNinjas{apiKey: apiKey, client: &http.Client{Timeout: timeout}}
+}
+
+func (p *CalorieNinjas) Name() string { return "calorieninjas" }
+
+type calorieNinjasItem struct {
+	Name                string  `json:"name"`
+	Calories            float64 `json:"calories"`
+	FatTotalG           float64 `json:"fat_total_g"`
+	ProteinG            float64 `json:"protein_g"`
+	CarbohydratesTotalG float64 `json:"carbohydrates_total_g"`
+	FiberG              float64 `json:"fiber_g"`
+	SugarG              float64 `json:"sugar_g"`
+	FatSaturatedG       float64 `json:"fat_saturated_g"`
+	CholesterolMg       float64 `json:"cholesterol_mg"`
+	SodiumMg            float64 `json:"sodium_mg"`
+	PotassiumMg         float64 `json:"potassium_mg"`
+}
+
+func (p *CalorieNinjas) Lookup(ctx context.Context, queries []Query) ([]Result, error) {
+	results := unmatched(len(queries))
+	for i, q := range queries {
+		items, err := p.fetch(ctx, q.Text())
+		if err != nil {
+			return nil, err
+		}
+		// The API splits "salt and pepper" into two items.
+		for _, item := range items {
+			results[i].Facts = results[i].Add(Facts{
+				Calories:     item.Calories,
+				Protein:      item.ProteinG,
+				Fat:          item.FatTotalG,
+				SaturatedFat: item.FatSaturatedG,
+				Carbs:        item.CarbohydratesTotalG,
+				Fiber:        item.FiberG,
+				Sugar:        item.SugarG,
+				Cholesterol:  item.CholesterolMg,
+				Sodium:       item.SodiumMg,
+				Potassium:    item.PotassiumMg,
+			})
+			if results[i].Food != "" {
+				results[i].Food += ", "
+			}
+			results[i].Food += item.Name
+		}
+		if len(items) > 0 {
+			results[i].Matched = true
+			results[i].Source = p.Name()
+		}
+	}
+	return results, nil
+}
+
+func (p *CalorieNinjas) fetch(ctx context.Context, query string) ([]calorieNinjasItem, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, calorieNinjasURL+"?query="+url.QueryEscape(query), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Api-Key", p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %s", string(body))
+	}
+
+	var result struct {
+		Items []calorieNinjasItem `json:"items"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+	return result.Items, nil
+}
@@ -0,0 +1,64 @@
+package nutrition
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// Chain asks each provider in turn for the ingredients the previous ones
+// did not match. A failing provider is skipped; Lookup only fails when every
+// provider did.
+type Chain []Provider
+
+func (c Chain) Name() string {
+	names := make([]string, len(c))
+	for i, p := range c {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func (c Chain) Lookup(ctx context.Context, queries []Query) ([]Result, error) {
+	results := unmatched(len(queries))
+
+	pending := make([]int, len(queries))
+	for i := range queries {
+		pending[i] = i
+	}
+
+	var lastErr error
+	failed := 0
+	for _, p := range c {
+		if len(pending) == 0 {
+			break
+		}
+
+		batch := make([]Query, len(pending))
+		for j, i := range pending {
+			batch[j] = queries[i]
+		}
+		found, err := p.Lookup(ctx, batch)
+		if err != nil {
+			log.Printf("nutrition provider %s failed: %v", p.Name(), err)
+			lastErr = err
+			failed++
+			continue
+		}
+
+		var still []int
+		for j, i := range pending {
+			if found[j].Matched {
+				results[i] = found[j]
+			} else {
+				still = append(still, i)
+			}
+		}
+		pending = still
+	}
+
+	if failed > 0 && failed == len(c) {
+		return nil, lastErr
+	}
+	return results, nil
+}
@@ -0,0 +1,150 @@
+package nutrition
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
+)
+
+// csvColumns maps the header names found in food-composition exports, such
+// as USDA FoodData Central, to the Food fields. Headers are compared in
+// lower case with everything but letters and digits turned into "_".
+var csvColumns = map[string][]string{
+	"name":     {"name", "description", "food", "food_name", "food_description"},
+	"calories": {"calories", "kcal", "energy", "energy_kcal", "calories_kcal"},
+	"protein":  {"protein", "protein_g"},
+	"fat":      {"fat", "fat_g", "total_fat", "fat_total_g", "total_lipid_fat", "total_lipid_fat_g"},
+	"carbs": {"carbs", "carbs_g", "carbohydrate", "carbohydrates", "carbohydrate_g",
+		"carbohydrates_total_g", "carbohydrate_by_difference", "carbohydrate_by_difference_g"},
+	"fiber": {"fiber", "fiber_g", "fibre", "dietary_fiber", "fiber_total_dietary", "fiber_total_dietary_g"},
+	"sugar": {"sugar", "sugars", "sugar_g", "sugars_g", "sugars_total", "sugars_total_g", "sugars_total_including_nlea_g"},
+	"saturated_fat": {"saturated_fat", "saturated_fat_g", "fat_saturated", "fat_saturated_g",
+		"fatty_acids_total_saturated", "fatty_acids_total_saturated_g"},
+	"cholesterol": {"cholesterol", "cholesterol_mg"},
+	"sodium":      {"sodium", "sodium_mg", "sodium_na", "sodium_na_mg"},
+	"potassium":   {"potassium", "potassium_mg", "potassium_k", "potassium_k_mg"},
+	"portion":     {"portion_g", "portion_grams", "gram_weight", "unit_weight_g"},
+}
+
+// ImportCSV reads a food-composition table with one food per row and
+// nutrients per 100 g (cholesterol, sodium and potassium in milligrams), and
+// upserts it into foods by name. The name and
+// calories columns are required, the others default to zero. It returns the
+// number of foods imported.
+func ImportCSV(r io.Reader, foods repository.FoodRepository) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read header: %w", err)
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		h = csvHeader(h)
+		for field, aliases := range csvColumns {
+			for _, alias := range aliases {
+				if _, seen := cols[field]; !seen && h == alias {
+					cols[field] = i
+				}
+			}
+		}
+	}
+	if _, ok := cols["name"]; !ok {
+		return 0, errors.New("missing name column")
+	}
+	if _, ok := cols["calories"]; !ok {
+		return 0, errors.New("missing calories column")
+	}
+
+	var batch []model.Food
+	seen := map[string]int{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		food, err := csvFood(record, cols)
+		if err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+		if food.Name == "" {
+			continue
+		}
+		// Later rows win, the database would reject both in one batch.
+		if i, dup := seen[food.Name]; dup {
+			batch[i] = food
+			continue
+		}
+		seen[food.Name] = len(batch)
+		batch = append(batch, food)
+	}
+
+	if err := foods.Upsert(batch); err != nil {
+		return 0, err
+	}
+	return len(batch), nil
+}
+
+func csvFood(record []string, cols map[string]int) (model.Food, error) {
+	field := func(name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	var err error
+	number := func(name string) float64 {
+		v := field(name)
+		if v == "" || err != nil {
+			return 0
+		}
+		n, parseErr := strconv.ParseFloat(strings.Replace(v, ",", ".", 1), 64)
+		if parseErr != nil || n < 0 {
+			err = fmt.Errorf("invalid %s value %q", name, v)
+		}
+		return n
+	}
+
+	food := model.Food{
+		Name:         strings.Join(strings.Fields(strings.ToLower(field("name"))), " "),
+		Calories:     number("calories"),
+		Protein:      number("protein"),
+		Fat:          number("fat"),
+		SaturatedFat: number("saturated_fat"),
+		Carbs:        number("carbs"),
+		Fiber:        number("fiber"),
+		Sugar:        number("sugar"),
+		Cholesterol:  number("cholesterol"),
+		Sodium:       number("sodium"),
+		Potassium:    number("potassium"),
+		PortionGrams: number("portion"),
+	}
+	return food, err
+}
+
+func csvHeader(h string) string {
+	h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+	var b strings.Builder
+	for _, r := range h {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	parts := strings.FieldsFunc(b.String(), func(r rune) bool { return r == '_' })
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,123 @@
+package nutrition
+
+import "math"
+
+// labelNutrient is one line of the nutrition-facts label. DailyValue is the
+// reference intake for a 2,000 kcal diet, zero for nutrients without one.
+type labelNutrient struct {
+	Key        string
+	Unit       string
+	DailyValue float64
+	// Parent is the nutrient this one is part of, e.g. fat for saturated
+	// fat, so labels can indent it.
+	Parent string
+	value  func(Facts) float64
+	round  func(float64) float64
+}
+
+// labelNutrients are the nutrients of the label in the order they are
+// printed, with the daily values of the FDA label.
+var labelNutrients = []labelNutrient{
+	{Key: "fat", Unit: "g", DailyValue: 78, value: func(f Facts) float64 { return f.Fat }, round: roundFat},
+	{Key: "saturated_fat", Unit: "g", DailyValue: 20, Parent: "fat", value: func(f Facts) float64 { return f.SaturatedFat }, round: roundFat},
+	{Key: "cholesterol", Unit: "mg", DailyValue: 300, value: func(f Facts) float64 { return f.Cholesterol }, round: roundCholesterol},
+	{Key: "sodium", Unit: "mg", DailyValue: 2300, value: func(f Facts) float64 { return f.Sodium }, round: roundMineral},
+	{Key: "carbs", Unit: "g", DailyValue: 275, value: func(f Facts) float64 { return f.Carbs }, round: roundGrams},
+	{Key: "fiber", Unit: "g", DailyValue: 28, Parent: "carbs", value: func(f Facts) float64 { return f.Fiber }, round: roundGrams},
+	{Key: "sugar", Unit: "g", Parent: "carbs", value: func(f Facts) float64 { return f.Sugar }, round: roundGrams},
+	{Key: "protein", Unit: "g", DailyValue: 50, value: func(f Facts) float64 { return f.Protein }, round: roundGrams},
+	{Key: "potassium", Unit: "mg", DailyValue: 4700, value: func(f Facts) float64 { return f.Potassium }, round: roundMineral},
+}
+
+// LabelLine is a nutrient as printed on the label. DailyValue is the
+// percentage of the daily value one serving covers.
+type LabelLine struct {
+	Key        string  `json:"key"`
+	Name       string  `json:"name"`
+	Parent     string  `json:"parent,omitempty"`
+	Amount     float64 `json:"amount"`
+	Unit       string  `json:"unit"`
+	DailyValue *int    `json:"daily_value,omitempty"`
+}
+
+// Label is the nutrition-facts label of one serving.
+type Label struct {
+	Servings int `json:"servings"`
+	// ServingGrams is the weight of one serving, zero when the weight of
+	// some ingredient is unknown.
+	ServingGrams float64     `json:"serving_grams,omitempty"`
+	Calories     float64     `json:"calories"`
+	Nutrients    []LabelLine `json:"nutrients"`
+}
+
+// NewLabel builds the label of one serving. Amounts are rounded the way
+// nutrition-facts labels print them; daily values are taken from the
+// unrounded amounts. Line names are left for the caller to translate.
+func NewLabel(perServing Facts, servings int, servingGrams float64) Label {
+	label := Label{
+		Servings:     servings,
+		ServingGrams: math.Round(servingGrams),
+		Calories:     roundCalories(perServing.Calories),
+		Nutrients:    make([]LabelLine, len(labelNutrients)),
+	}
+	for i, n := range labelNutrients {
+		v := n.value(perServing)
+		line := LabelLine{Key: n.Key, Parent: n.Parent, Amount: n.round(v), Unit: n.Unit}
+		if n.DailyValue > 0 {
+			dv := int(math.Round(v / n.DailyValue * 100))
+			line.DailyValue = &dv
+		}
+		label.Nutrients[i] = line
+	}
+	return label
+}
+
+// roundTo rounds v to the nearest multiple of step.
+func roundTo(v, step float64) float64 {
+	return math.Round(v/step) * step
+}
+
+func roundCalories(v float64) float64 {
+	switch {
+	case v < 5:
+		return 0
+	case v <= 50:
+		return roundTo(v, 5)
+	}
+	return roundTo(v, 10)
+}
+
+func roundFat(v float64) float64 {
+	switch {
+	case v < 0.5:
+		return 0
+	case v < 5:
+		return roundTo(v, 0.5)
+	}
+	return math.Round(v)
+}
+
+func roundCholesterol(v float64) float64 {
+	if v < 2 {
+		return 0
+	}
+	return roundTo(v, 5)
+}
+
+// roundMineral rounds sodium and potassium.
+func roundMineral(v float64) float64 {
+	switch {
+	case v < 5:
+		return 0
+	case v <= 140:
+		return roundTo(v, 5)
+	}
+	return roundTo(v, 10)
+}
+
+func roundGrams(v float64) float64 {
+	if v < 0.5 {
+		return 0
+	}
+	return math.Round(v)
+}
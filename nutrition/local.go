@@ -0,0 +1,169 @@
+package nutrition
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
+)
+
+// localReloadInterval is how long the food table is kept in memory, so
+// imports show up without a restart.
+const localReloadInterval = 5 * time.Minute
+
+// Local matches ingredients against the imported food table. Foods are
+// stored per 100 g, so only ingredients whose weight is known can be
+// matched: masses, volumes of foods with a known density, and counts of
+// foods with a portion weight.
+type Local struct {
+	foods repository.FoodRepository
+	clock internal.Clock
+
+	mu       sync.Mutex
+	index    []localFood
+	loadedAt time.Time
+}
+
+type localFood struct {
+	model.Food
+	words map[string]bool
+}
+
+func NewLocal(foods repository.FoodRepository, clock internal.Clock) *Local {
+	return &Local{foods: foods, clock: clock}
+}
+
+func (p *Local) Name() string { return "local" }
+
+func (p *Local) Lookup(_ context.Context, queries []Query) ([]Result, error) {
+	index, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+
+	results := unmatched(len(queries))
+	for i, q := range queries {
+		food, ok := match(index, q.Name)
+		if !ok {
+			continue
+		}
+
+		grams := q.Grams
+		if grams <= 0 && food.PortionGrams > 0 && q.Quantity > 0 && (q.Unit == "" || q.Unit == "piece") {
+			grams = q.Quantity * food.PortionGrams
+		}
+		if grams <= 0 {
+			continue
+		}
+
+		results[i] = Result{
+			Facts: Facts{
+				Calories:     food.Calories,
+				Protein:      food.Protein,
+				Fat:          food.Fat,
+				SaturatedFat: food.SaturatedFat,
+				Carbs:        food.Carbs,
+				Fiber:        food.Fiber,
+				Sugar:        food.Sugar,
+				Cholesterol:  food.Cholesterol,
+				Sodium:       food.Sodium,
+				Potassium:    food.Potassium,
+			}.Scale(grams / 100),
+			Matched: true,
+			Food:    food.Name,
+			Source:  p.Name(),
+		}
+	}
+	return results, nil
+}
+
+func (p *Local) load() ([]localFood, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.clock.Now()
+	if p.index != nil && now.Sub(p.loadedAt) < localReloadInterval {
+		return p.index, nil
+	}
+
+	foods, err := p.foods.All()
+	if err != nil {
+		return nil, err
+	}
+	index := make([]localFood, len(foods))
+	for i, f := range foods {
+		index[i] = localFood{Food: f, words: wordSet(f.Name)}
+	}
+	p.index, p.loadedAt = index, now
+	return index, nil
+}
+
+// fillerWords describe how an ingredient is prepared, not what it is.
+var fillerWords = map[string]bool{
+	"a": true, "and": true, "or": true, "of": true, "to": true, "the": true,
+	"fresh": true, "freshly": true, "chopped": true, "diced": true, "sliced": true,
+	"minced": true, "large": true, "medium": true, "small": true, "finely": true,
+	"roughly": true, "optional": true, "taste": true, "peeled": true, "grated": true,
+}
+
+// wordSet returns the singular, meaningful words of a name.
+func wordSet(name string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range words(name) {
+		if !fillerWords[w] {
+			set[singular(w)] = true
+		}
+	}
+	return set
+}
+
+func singular(w string) string {
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "oes") && len(w) > 4:
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && len(w) > 3:
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// match finds the food that best describes the ingredient name. A food
+// matches when all its words appear in the name ("flour" for "sifted
+// flour") or all words of the name appear in the food ("brown rice" for
+// "Rice, brown, long-grain, cooked"). More shared words win, then the food
+// with the fewest other words.
+func match(index []localFood, name string) (model.Food, bool) {
+	want := wordSet(name)
+	if len(want) == 0 {
+		return model.Food{}, false
+	}
+
+	var best *localFood
+	bestShared, bestExtra := 0, 0
+	for i := range index {
+		f := &index[i]
+		shared := 0
+		for w := range want {
+			if f.words[w] {
+				shared++
+			}
+		}
+		if shared == 0 || (shared < len(f.words) && shared < len(want)) {
+			continue
+		}
+		extra := len(f.words) - shared
+		if best == nil || shared > bestShared || (shared == bestShared && extra < bestExtra) {
+			best, bestShared, bestExtra = f, shared, extra
+		}
+	}
+	if best == nil {
+		return model.Food{}, false
+	}
+	return best.Food, true
+}
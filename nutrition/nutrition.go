@@ -0,0 +1,174 @@
+// Package nutrition looks up the nutrients of recipe ingredients. Providers
+// such as the CalorieNinjas API or the local food table are chained, so
+// ingredients one provider does not know can still be matched by the next,
+// and results are cached per ingredient.
+package nutrition
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Abb133Se/recepieshare/config"
+	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
+)
+
+// Facts are the nutrients of an ingredient, a recipe or one serving.
+// Calories are in kcal, Cholesterol, Sodium and Potassium in milligrams and
+// everything else in grams.
+type Facts struct {
+	Calories     float64 `json:"calories"`
+	Protein      float64 `json:"protein"`
+	Fat          float64 `json:"fat"`
+	SaturatedFat float64 `json:"saturated_fat"`
+	Carbs        float64 `json:"carbs"`
+	Fiber        float64 `json:"fiber"`
+	Sugar        float64 `json:"sugar"`
+	Cholesterol  float64 `json:"cholesterol"`
+	Sodium       float64 `json:"sodium"`
+	Potassium    float64 `json:"potassium"`
+}
+
+// Add returns the sum of f and o.
+func (f Facts) Add(o Facts) Facts {
+	return Facts{
+		Calories:     f.Calories + o.Calories,
+		Protein:      f.Protein + o.Protein,
+		Fat:          f.Fat + o.Fat,
+		SaturatedFat: f.SaturatedFat + o.SaturatedFat,
+		Carbs:        f.Carbs + o.Carbs,
+		Fiber:        f.Fiber + o.Fiber,
+		Sugar:        f.Sugar + o.Sugar,
+		Cholesterol:  f.Cholesterol + o.Cholesterol,
+		Sodium:       f.Sodium + o.Sodium,
+		Potassium:    f.Potassium + o.Potassium,
+	}
+}
+
+// Scale multiplies every nutrient by factor.
+func (f Facts) Scale(factor float64) Facts {
+	return Facts{
+		Calories:     f.Calories * factor,
+		Protein:      f.Protein * factor,
+		Fat:          f.Fat * factor,
+		SaturatedFat: f.SaturatedFat * factor,
+		Carbs:        f.Carbs * factor,
+		Fiber:        f.Fiber * factor,
+		Sugar:        f.Sugar * factor,
+		Cholesterol:  f.Cholesterol * factor,
+		Sodium:       f.Sodium * factor,
+		Potassium:    f.Potassium * factor,
+	}
+}
+
+// Round rounds every nutrient to one decimal.
+func (f Facts) Round() Facts {
+	r := func(v float64) float64 { return math.Round(v*10) / 10 }
+	return Facts{
+		Calories:     r(f.Calories),
+		Protein:      r(f.Protein),
+		Fat:          r(f.Fat),
+		SaturatedFat: r(f.SaturatedFat),
+		Carbs:        r(f.Carbs),
+		Fiber:        r(f.Fiber),
+		Sugar:        r(f.Sugar),
+		Cholesterol:  r(f.Cholesterol),
+		Sodium:       r(f.Sodium),
+		Potassium:    r(f.Potassium),
+	}
+}
+
+// Query is one ingredient to look up. Quantity and Unit come from the
+// parsed amount; Grams is its estimated weight, zero when unknown.
+type Query struct {
+	Name     string
+	Amount   string
+	Quantity float64
+	Unit     string
+	Grams    float64
+}
+
+// QueryFor builds the query of an ingredient from its parsed amount.
+func QueryFor(ing model.Ingredient) Query {
+	ing.Normalize()
+	return Query{Name: ing.Name, Amount: ing.Amount, Quantity: ing.Quantity, Unit: ing.Unit, Grams: ing.Grams}
+}
+
+// Text describes the ingredient in words, as "1.5 cup of flour".
+func (q Query) Text() string {
+	if q.Quantity > 0 && q.Unit != "" {
+		return fmt.Sprintf("%s %s of %s", strconv.FormatFloat(q.Quantity, 'f', -1, 64), q.Unit, q.Name)
+	}
+	return fmt.Sprintf("%s of %s", q.Amount, q.Name)
+}
+
+// Key identifies the query in the cache: the normalized name and quantity,
+// so "1 Cup  Flour" and "1 cup flour" share an entry.
+func (q Query) Key() string {
+	amount := strings.Join(words(q.Amount), " ")
+	if q.Quantity > 0 {
+		amount = strconv.FormatFloat(q.Quantity, 'f', -1, 64) + " " + q.Unit
+	}
+	return strings.Join(words(q.Name), " ") + "|" + strings.TrimSpace(amount)
+}
+
+// Result is what a provider found for a query. Unmatched results carry no
+// nutrients.
+type Result struct {
+	Facts
+	Matched bool
+	// Food is the name of the matched food, Source the provider.
+	Food   string
+	Source string
+}
+
+// Provider looks up the nutrients of ingredients. Lookup returns one result
+// per query, in order; ingredients the provider does not know come back
+// unmatched instead of failing the whole batch. Implementations must be safe
+// for concurrent use.
+type Provider interface {
+	Name() string
+	Lookup(ctx context.Context, queries []Query) ([]Result, error)
+}
+
+// New builds the provider chain configured in cfg.Providers, behind the
+// cache when cfg.CacheTTL is set. Remote providers without credentials are
+// left out of the chain.
+func New(cfg config.NutritionConfig, repos *repository.Repositories, clock internal.Clock) (Provider, error) {
+	var providers []Provider
+	for _, name := range cfg.Providers {
+		switch name {
+		case "calorieninjas":
+			if cfg.APIKey != "" {
+				providers = append(providers, NewCalorieNinjas(cfg.APIKey, cfg.Timeout.Duration))
+			}
+		case "local":
+			providers = append(providers, NewLocal(repos.Foods, clock))
+		default:
+			return nil, fmt.Errorf("unsupported nutrition provider: %s", name)
+		}
+	}
+
+	var provider Provider = Chain(providers)
+	if cfg.CacheTTL.Duration > 0 {
+		provider = NewCached(provider, repos.Nutrition, clock, cfg.CacheTTL.Duration)
+	}
+	return provider, nil
+}
+
+// unmatched returns n empty results.
+func unmatched(n int) []Result {
+	return make([]Result, n)
+}
+
+// words splits s into lower-case words of letters and digits.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	})
+}
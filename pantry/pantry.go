@@ -0,0 +1,197 @@
+// Package pantry matches recipes against the ingredients a user has at
+// home. Ingredient names are compared as sets of normalized words, so
+// "Tomatoes, diced" and "tomato" are the same ingredient and "chicken
+// breast" covers "chicken".
+package pantry
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// fillerWords describe how an ingredient is prepared, not what it is.
+var fillerWords = map[string]bool{
+	"a": true, "and": true, "or": true, "of": true, "to": true, "the": true,
+	"fresh": true, "freshly": true, "chopped": true, "diced": true, "sliced": true,
+	"minced": true, "large": true, "medium": true, "small": true, "finely": true,
+	"roughly": true, "optional": true, "taste": true, "peeled": true, "grated": true,
+	"ripe": true, "whole": true, "cold": true, "warm": true, "hot": true,
+}
+
+// Words returns the sorted, distinct, singular words of an ingredient name,
+// leaving out words that describe its preparation.
+func Words(name string) []string {
+	seen := map[string]bool{}
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if fillerWords[w] {
+			continue
+		}
+		if w = singular(w); !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// Key is the normalized form of an ingredient name that is stored with
+// ingredients and pantry items: its Words joined by spaces.
+func Key(name string) string {
+	return strings.Join(Words(name), " ")
+}
+
+func singular(w string) string {
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "oes") && len(w) > 4:
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && len(w) > 3:
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// Ingredient is one ingredient of a recipe to match.
+type Ingredient struct {
+	// Name is the ingredient as written, reported when it is missing.
+	Name string
+	Key  string
+}
+
+type Recipe struct {
+	ID          uint
+	Ingredients []Ingredient
+}
+
+type Options struct {
+	// MaxMissing leaves out recipes missing more ingredients. Negative means
+	// no limit.
+	MaxMissing int
+	// Staples are the keys of ingredients that are not counted at all, such
+	// as salt and water. A staple also leaves out the ingredients that have
+	// all of its words, so "salt" leaves out "sea salt".
+	Staples []string
+}
+
+// Match is how well a pantry covers a recipe.
+type Match struct {
+	RecipeID uint
+	// Have and Total count distinct ingredients, staples excluded.
+	Have    int
+	Total   int
+	Missing []string
+}
+
+// Coverage is the share of ingredients the pantry has, from 0 to 1.
+func (m Match) Coverage() float64 {
+	if m.Total == 0 {
+		return 0
+	}
+	return float64(m.Have) / float64(m.Total)
+}
+
+// Rank matches every recipe against the pantry, given as the keys of its
+// items, and returns those the pantry has at least one ingredient of, best
+// covered first. An ingredient is covered by an item that has all of its
+// words, so "olive oil" covers "oil" but "chicken" does not cover "chicken
+// breast".
+func Rank(pantryKeys []string, recipes []Recipe, opts Options) []Match {
+	have := make([]map[string]bool, 0, len(pantryKeys))
+	for _, key := range pantryKeys {
+		if set := wordSet(key); len(set) > 0 {
+			have = append(have, set)
+		}
+	}
+	staples := make([]map[string]bool, 0, len(opts.Staples))
+	for _, key := range opts.Staples {
+		if set := wordSet(key); len(set) > 0 {
+			staples = append(staples, set)
+		}
+	}
+
+	var matches []Match
+	for _, r := range recipes {
+		m := Match{RecipeID: r.ID}
+		seen := map[string]bool{}
+		for _, ing := range r.Ingredients {
+			if ing.Key == "" || seen[ing.Key] {
+				continue
+			}
+			seen[ing.Key] = true
+			need := wordSet(ing.Key)
+			if containsAny(need, staples) {
+				continue
+			}
+			m.Total++
+			if covered(need, have) {
+				m.Have++
+			} else {
+				m.Missing = append(m.Missing, ing.Name)
+			}
+		}
+		if m.Have == 0 || opts.MaxMissing >= 0 && len(m.Missing) > opts.MaxMissing {
+			continue
+		}
+		matches = append(matches, m)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if ca, cb := a.Coverage(), b.Coverage(); ca != cb {
+			return ca > cb
+		}
+		if len(a.Missing) != len(b.Missing) {
+			return len(a.Missing) < len(b.Missing)
+		}
+		if a.Have != b.Have {
+			return a.Have > b.Have
+		}
+		return a.RecipeID > b.RecipeID
+	})
+	return matches
+}
+
+func wordSet(key string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(key) {
+		set[w] = true
+	}
+	return set
+}
+
+// covered reports whether one of the pantry items has every word of need.
+func covered(need map[string]bool, have []map[string]bool) bool {
+	for _, h := range have {
+		if subset(need, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAny reports whether words has every word of one of the sets.
+func containsAny(words map[string]bool, sets []map[string]bool) bool {
+	for _, s := range sets {
+		if subset(s, words) {
+			return true
+		}
+	}
+	return false
+}
+
+func subset(a, b map[string]bool) bool {
+	for w := range a {
+		if !b[w] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package pantry
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Tomatoes, diced", "tomato"},
+		{"2 large Potatoes", "potato"},
+		{"freshly ground black pepper", "black ground pepper"},
+		{"Cherries", "cherry"},
+		{"peaches", "peach"},
+		{"Couscous", "couscous"},
+		{"grass", "grass"},
+		{"chopped", ""},
+	}
+	for _, tt := range tests {
+		if got := Key(tt.name); got != tt.want {
+			t.Errorf("Key(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func recipe(id uint, names ...string) Recipe {
+	r := Recipe{ID: id}
+	for _, n := range names {
+		r.Ingredients = append(r.Ingredients, Ingredient{Name: n, Key: Key(n)})
+	}
+	return r
+}
+
+func keys(names ...string) []string {
+	var out []string
+	for _, n := range names {
+		out = append(out, Key(n))
+	}
+	return out
+}
+
+func TestRank(t *testing.T) {
+	recipes := []Recipe{
+		recipe(1, "chicken breast", "rice", "salt"),
+		recipe(2, "tomatoes", "pasta", "basil", "parmesan"),
+		recipe(3, "eggs", "flour", "milk", "salt"),
+		recipe(4, "beef", "onion"),
+		recipe(5, "tomato", "Tomatoes, diced", "onion"),
+	}
+
+	tests := []struct {
+		name    string
+		pantry  []string
+		opts    Options
+		want    []uint
+		missing map[uint][]string
+	}{
+		{
+			name:   "best covered first",
+			pantry: keys("chicken breast", "rice", "tomato", "onion", "pasta"),
+			opts:   Options{MaxMissing: -1},
+			want:   []uint{5, 1, 4, 2},
+			missing: map[uint][]string{
+				1: {"salt"},
+				2: {"basil", "parmesan"},
+				4: {"beef"},
+			},
+		},
+		{
+			name:   "max missing",
+			pantry: keys("chicken breast", "rice", "tomato", "onion", "pasta"),
+			opts:   Options{MaxMissing: 1},
+			want:   []uint{5, 1, 4},
+		},
+		{
+			name:   "staples are not counted",
+			pantry: keys("chicken breast", "rice", "egg", "flour"),
+			opts:   Options{MaxMissing: 0, Staples: keys("salt")},
+			want:   []uint{1},
+		},
+		{
+			name:   "nothing in the pantry",
+			pantry: nil,
+			opts:   Options{MaxMissing: -1},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		matches := Rank(tt.pantry, recipes, tt.opts)
+		var ids []uint
+		for _, m := range matches {
+			ids = append(ids, m.RecipeID)
+			if want, ok := tt.missing[m.RecipeID]; ok && !slices.Equal(m.Missing, want) {
+				t.Errorf("%s: recipe %d missing %q, want %q", tt.name, m.RecipeID, m.Missing, want)
+			}
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("%s: Rank = %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+}
+
+func TestRankCountsDistinctIngredients(t *testing.T) {
+	matches := Rank(keys("tomato"), []Recipe{recipe(5, "tomato", "Tomatoes, diced", "onion")}, Options{MaxMissing: -1})
+	if len(matches) != 1 {
+		t.Fatalf("Rank returned %d matches, want 1", len(matches))
+	}
+	if m := matches[0]; m.Have != 1 || m.Total != 2 || m.Coverage() != 0.5 {
+		t.Errorf("match = %+v with coverage %v, want 1 of 2", m, m.Coverage())
+	}
+}
+
+func TestRankStaples(t *testing.T) {
+	recipes := []Recipe{
+		recipe(1, "rice", "sea salt", "Water, cold"),
+		recipe(2, "rice", "salted butter"),
+	}
+	matches := Rank(keys("rice"), recipes, Options{MaxMissing: -1, Staples: keys("salt", "water")})
+	if len(matches) != 2 {
+		t.Fatalf("Rank returned %d matches, want 2", len(matches))
+	}
+	// Salted butter is not salt.
+	if m := matches[0]; m.RecipeID != 1 || m.Total != 1 {
+		t.Errorf("first match = %+v, want recipe 1 counting only the rice", m)
+	}
+	if m := matches[1]; m.RecipeID != 2 || !slices.Equal(m.Missing, []string{"salted butter"}) {
+		t.Errorf("second match = %+v, want recipe 2 missing the butter", m)
+	}
+}
+
+func TestCovered(t *testing.T) {
+	tests := []struct {
+		need string
+		have string
+		want bool
+	}{
+		{"chicken", "chicken breast", true},
+		{"chicken breast", "chicken", false},
+		{"oil", "olive oil", true},
+		{"olive oil", "oil", false},
+		{"onion", "red onion", true},
+		{"Tomatoes, diced", "tomato", true},
+		{"beef", "chicken", false},
+	}
+	for _, tt := range tests {
+		if got := covered(wordSet(Key(tt.need)), []map[string]bool{wordSet(Key(tt.have))}); got != tt.want {
+			t.Errorf("covered(%q by %q) = %v, want %v", tt.need, tt.have, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Package policy is the single place that decides who may do what. Roles are
+// granted a scope per resource and action; owner-scoped permissions only
+// apply to resources the subject owns.
+package policy
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// Roles lists the known roles from least to most privileged.
+var Roles = []Role{RoleUser, RoleModerator, RoleAdmin}
+
+// ParseRole validates a role name as stored in the database or a request.
+func ParseRole(s string) (Role, bool) {
+	for _, r := range Roles {
+		if string(r) == s {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+func (r Role) rank() int {
+	for i, known := range Roles {
+		if known == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// AtLeast reports whether r is as privileged as other.
+func (r Role) AtLeast(other Role) bool {
+	return r.rank() >= 0 && r.rank() >= other.rank()
+}
+
+type Resource string
+
+const (
+	Recipe     Resource = "recipe"
+	Ingredient Resource = "ingredient"
+	Comment    Resource = "comment"
+	Rating     Resource = "rating"
+	Favorite   Resource = "favorite"
+	Tag        Resource = "tag"
+	Category   Resource = "category"
+	Image      Resource = "image"
+	User       Resource = "user"
+	Analytics  Resource = "analytics"
+	Job        Resource = "job"
+)
+
+type Action string
+
+const (
+	Read   Action = "read"
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+	// AssignRole changes the role of a user.
+	AssignRole Action = "assign_role"
+	// ReadUnpublished reads recipes that are drafts, scheduled or archived.
+	ReadUnpublished Action = "read_unpublished"
+	// ReadPrivate reads unlisted and private recipes without a share link or
+	// an invitation.
+	ReadPrivate Action = "read_private"
+	// Retry puts a dead background job back in the queue.
+	Retry Action = "retry"
+)
+
+// Scope is how far a permission reaches.
+type Scope int
+
+const (
+	Denied Scope = iota
+	// Own allows the action only on resources owned by the subject. For
+	// child resources (ingredients, images) the owner of the parent recipe
+	// counts.
+	Own
+	Any
+)
+
+type permissions map[Resource]map[Action]Scope
+
+var userRules = permissions{
+	Recipe:     {Read: Any, Create: Any, Update: Own, Delete: Own, ReadUnpublished: Own, ReadPrivate: Own},
+	Ingredient: {Read: Any, Create: Own, Delete: Own},
+	Comment:    {Read: Any, Create: Any, Delete: Own},
+	Rating:     {Read: Any, Create: Any, Update: Own, Delete: Own},
+	Favorite:   {Read: Own, Create: Any, Delete: Own},
+	Tag:        {Read: Any, Create: Any},
+	Category:   {Read: Any},
+	Image:      {Read: Any, Create: Own, Delete: Own},
+	User:       {Read: Own, Update: Own},
+}
+
+// moderatorRules extend userRules with content moderation.
+var moderatorRules = permissions{
+	Recipe:   {Delete: Any},
+	Comment:  {Delete: Any},
+	Rating:   {Delete: Any},
+	Tag:      {Update: Any, Delete: Any},
+	Category: {Create: Any, Update: Any, Delete: Any},
+	Image:    {Delete: Any},
+}
+
+// Check returns the scope role has for action on resource. Admins may do
+// everything; unknown roles may do nothing.
+func Check(role Role, resource Resource, action Action) Scope {
+	switch role {
+	case RoleAdmin:
+		return Any
+	case RoleModerator:
+		if scope := moderatorRules[resource][action]; scope != Denied {
+			return scope
+		}
+		return userRules[resource][action]
+	case RoleUser:
+		return userRules[resource][action]
+	default:
+		return Denied
+	}
+}
+
+// Subject is the authenticated caller.
+type Subject struct {
+	UserID uint
+	Role   Role
+}
+
+// Can reports whether s may perform action on a resource owned by ownerID.
+// Pass ownerID 0 for resources without an owner.
+func Can(s Subject, resource Resource, action Action, ownerID uint) bool {
+	switch Check(s.Role, resource, action) {
+	case Any:
+		return true
+	case Own:
+		return s.UserID != 0 && ownerID == s.UserID
+	default:
+		return false
+	}
+}
@@ -0,0 +1,79 @@
+package policy
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		role     Role
+		resource Resource
+		action   Action
+		want     Scope
+	}{
+		{RoleUser, Recipe, Read, Any},
+		{RoleUser, Recipe, Update, Own},
+		{RoleUser, Recipe, ReadPrivate, Own},
+		{RoleUser, Comment, Delete, Own},
+		{RoleUser, Category, Create, Denied},
+		{RoleUser, User, AssignRole, Denied},
+		{RoleUser, Job, Read, Denied},
+		{RoleModerator, Comment, Delete, Any},
+		{RoleModerator, Recipe, Delete, Any},
+		// Moderators fall back to the user rules.
+		{RoleModerator, Recipe, Update, Own},
+		{RoleModerator, Recipe, ReadPrivate, Own},
+		{RoleModerator, Favorite, Read, Own},
+		{RoleModerator, User, AssignRole, Denied},
+		{RoleAdmin, User, AssignRole, Any},
+		{RoleAdmin, Job, Retry, Any},
+		{"", Recipe, Read, Denied},
+		{"root", Recipe, Read, Denied},
+	}
+	for _, tt := range tests {
+		if got := Check(tt.role, tt.resource, tt.action); got != tt.want {
+			t.Errorf("Check(%q, %s, %s) = %v, want %v", tt.role, tt.resource, tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestCan(t *testing.T) {
+	user := Subject{UserID: 7, Role: RoleUser}
+	tests := []struct {
+		subject  Subject
+		resource Resource
+		action   Action
+		ownerID  uint
+		want     bool
+	}{
+		{user, Recipe, Update, 7, true},
+		{user, Recipe, Update, 8, false},
+		{user, Recipe, Read, 8, true},
+		{user, Category, Update, 0, false},
+		// Anonymous callers own nothing, not even resources without an owner.
+		{Subject{}, Recipe, Update, 0, false},
+		{Subject{Role: RoleUser}, Recipe, Update, 0, false},
+		{Subject{UserID: 1, Role: RoleModerator}, Comment, Delete, 9, true},
+		{Subject{UserID: 1, Role: RoleAdmin}, Recipe, Update, 9, true},
+	}
+	for _, tt := range tests {
+		if got := Can(tt.subject, tt.resource, tt.action, tt.ownerID); got != tt.want {
+			t.Errorf("Can(%+v, %s, %s, %d) = %v, want %v", tt.subject, tt.resource, tt.action, tt.ownerID, got, tt.want)
+		}
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	tests := []struct {
+		role, other Role
+		want        bool
+	}{
+		{RoleAdmin, RoleModerator, true},
+		{RoleModerator, RoleModerator, true},
+		{RoleUser, RoleModerator, false},
+		{"root", RoleUser, false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.AtLeast(tt.other); got != tt.want {
+			t.Errorf("%q.AtLeast(%q) = %v, want %v", tt.role, tt.other, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package quantity
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Density is the weight of a food per volume. Dry foods such as flour are
+// weighed in metric recipes, so their volumes convert to grams.
+type Density struct {
+	GramsPerML float64
+	Dry        bool
+}
+
+// densities holds approximate densities of common foods, keyed by name.
+var densities = map[string]Density{
+	"water":           {1, false},
+	"milk":            {1.03, false},
+	"buttermilk":      {1.03, false},
+	"cream":           {1.0, false},
+	"yogurt":          {1.05, false},
+	"yoghurt":         {1.05, false},
+	"oil":             {0.92, false},
+	"olive oil":       {0.91, false},
+	"vinegar":         {1.01, false},
+	"soy sauce":       {1.15, false},
+	"honey":           {1.42, false},
+	"maple syrup":     {1.32, false},
+	"syrup":           {1.33, false},
+	"juice":           {1.04, false},
+	"stock":           {1.0, false},
+	"broth":           {1.0, false},
+	"wine":            {0.99, false},
+	"butter":          {0.96, true},
+	"flour":           {0.53, true},
+	"whole wheat":     {0.51, true},
+	"cornstarch":      {0.54, true},
+	"sugar":           {0.85, true},
+	"brown sugar":     {0.93, true},
+	"powdered sugar":  {0.51, true},
+	"icing sugar":     {0.51, true},
+	"salt":            {1.22, true},
+	"baking powder":   {0.81, true},
+	"baking soda":     {0.93, true},
+	"cocoa":           {0.42, true},
+	"cocoa powder":    {0.42, true},
+	"rice":            {0.85, true},
+	"oats":            {0.38, true},
+	"rolled oats":     {0.38, true},
+	"breadcrumbs":     {0.45, true},
+	"lentils":         {0.81, true},
+	"chickpeas":       {0.72, true},
+	"almonds":         {0.6, true},
+	"walnuts":         {0.42, true},
+	"peanut butter":   {1.09, true},
+	"grated cheese":   {0.45, true},
+	"parmesan":        {0.42, true},
+	"chocolate chips": {0.72, true},
+	"raisins":         {0.65, true},
+	"ground coffee":   {0.38, true},
+}
+
+// densityKeys are the density names, longest first, so "brown sugar" wins
+// over "sugar".
+var densityKeys = func() []string {
+	keys := make([]string, 0, len(densities))
+	for k := range densities {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}()
+
+// DensityOf looks up the density of an ingredient by the food names its
+// name contains, e.g. "all-purpose flour" is flour. It returns the zero
+// Density for unknown foods.
+func DensityOf(ingredient string) Density {
+	words := strings.FieldsFunc(strings.ToLower(ingredient), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	name := " " + strings.Join(words, " ") + " "
+	for _, key := range densityKeys {
+		if strings.Contains(name, " "+key+" ") {
+			return densities[key]
+		}
+	}
+	return Density{}
+}
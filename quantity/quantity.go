@@ -0,0 +1,277 @@
+// Package quantity parses the free-form ingredient amounts users type, such
+// as "1 1/2 cups", "200g" or "2-3 eggs", so they can be scaled, converted
+// between metric and imperial units and printed again with kitchen-friendly
+// rounding.
+package quantity
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Quantity is a parsed amount. Max is set for ranges like "2-3" and zero
+// otherwise. Unit is the registry name of the unit, empty for plain counts,
+// and Note the text after it, e.g. "large" in "2 large" or "finely chopped"
+// in "200 g, finely chopped".
+type Quantity struct {
+	Value float64 `json:"value"`
+	Max   float64 `json:"max,omitempty"`
+	Unit  string  `json:"unit,omitempty"`
+	Note  string  `json:"note,omitempty"`
+}
+
+var vulgarFractions = map[rune]float64{
+	'½': 1.0 / 2, '⅓': 1.0 / 3, '⅔': 2.0 / 3, '¼': 1.0 / 4, '¾': 3.0 / 4,
+	'⅕': 1.0 / 5, '⅖': 2.0 / 5, '⅗': 3.0 / 5, '⅘': 4.0 / 5, '⅙': 1.0 / 6,
+	'⅚': 5.0 / 6, '⅛': 1.0 / 8, '⅜': 3.0 / 8, '⅝': 5.0 / 8, '⅞': 7.0 / 8,
+}
+
+// Parse reads an amount that starts with a number. It reports false for
+// amounts without one, such as "a pinch", which cannot be scaled.
+func Parse(s string) (Quantity, bool) {
+	s = strings.TrimSpace(s)
+
+	value, rest, ok := parseNumber(s)
+	if !ok {
+		return Quantity{}, false
+	}
+	q := Quantity{Value: value}
+
+	// Ranges: "2-3", "2 – 3", "2 to 3".
+	trimmed := strings.TrimLeftFunc(rest, unicode.IsSpace)
+	for _, sep := range []string{"-", "–", "to "} {
+		if !strings.HasPrefix(trimmed, sep) {
+			continue
+		}
+		if upper, after, ok := parseNumber(strings.TrimSpace(trimmed[len(sep):])); ok && upper > value {
+			q.Max = upper
+			rest = after
+		}
+		break
+	}
+
+	if u, after, ok := splitUnit(rest); ok {
+		q.Unit = u.Name
+		rest = after
+	}
+	q.Note = cleanNote(rest)
+	return q, true
+}
+
+// cleanNote trims the separators between a unit and the note after it.
+func cleanNote(s string) string {
+	s = strings.TrimLeft(strings.TrimSpace(s), ",;:")
+	s = strings.TrimSpace(s)
+	if s == "of" {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(s, "of "))
+}
+
+// parseNumber reads a leading integer, decimal, fraction, mixed number or
+// unicode fraction and returns the text after it.
+func parseNumber(s string) (float64, string, bool) {
+	whole, rest, ok := parseSimple(s)
+	if !ok {
+		return 0, s, false
+	}
+
+	// A whole number may be followed by a fraction: "1 1/2", "1½", "1 ½".
+	if whole == math.Trunc(whole) && !strings.ContainsAny(s[:len(s)-len(rest)], "/.,") {
+		after := strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if frac, tail, ok := parseSimple(after); ok && frac < 1 && isFraction(after[:len(after)-len(tail)]) {
+			return whole + frac, tail, true
+		}
+	}
+	return whole, rest, true
+}
+
+func isFraction(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	_, vulgar := vulgarFractions[r]
+	return vulgar || strings.Contains(s, "/")
+}
+
+// parseSimple reads one of: a unicode fraction, "a/b", or a decimal number
+// with a dot or comma separator.
+func parseSimple(s string) (float64, string, bool) {
+	if r, size := utf8.DecodeRuneInString(s); size > 0 {
+		if v, ok := vulgarFractions[r]; ok {
+			return v, s[size:], true
+		}
+	}
+
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.' || s[end] == ',') {
+		end++
+	}
+	// A trailing separator belongs to the text, as in "2, chopped".
+	for end > 0 && (s[end-1] == '.' || s[end-1] == ',') {
+		end--
+	}
+	if end == 0 {
+		return 0, s, false
+	}
+
+	num, err := strconv.ParseFloat(strings.Replace(s[:end], ",", ".", 1), 64)
+	if err != nil {
+		return 0, s, false
+	}
+	rest := s[end:]
+
+	if strings.HasPrefix(rest, "/") && !strings.ContainsAny(s[:end], ".,") {
+		denEnd := 1
+		for denEnd < len(rest) && rest[denEnd] >= '0' && rest[denEnd] <= '9' {
+			denEnd++
+		}
+		if den, err := strconv.Atoi(rest[1:denEnd]); err == nil && den > 0 {
+			return num / float64(den), rest[denEnd:], true
+		}
+	}
+	return num, rest, true
+}
+
+// Scale multiplies the amount by factor.
+func (q Quantity) Scale(factor float64) Quantity {
+	q.Value *= factor
+	q.Max *= factor
+	return q
+}
+
+// Convert expresses the quantity in the given system, picking the unit that
+// reads best for its size. Volumes of dry foods become weights in metric.
+// Counts, unknown units and Original leave the quantity unchanged.
+func (q Quantity) Convert(to System, density Density) Quantity {
+	u, ok := LookupUnit(q.Unit)
+	if !ok || u.Kind == Count || to == Original || u.System == to {
+		return q
+	}
+
+	kind, base := u.Kind, u.Base
+	if to == Metric && kind == Volume && density.Dry && density.GramsPerML > 0 {
+		kind, base = Mass, base*density.GramsPerML
+	}
+
+	target := preferredUnit(to, kind, q.Value*base)
+	q.Value = q.Value * base / target.Base
+	q.Max = q.Max * base / target.Base
+	q.Unit = target.Name
+	return q
+}
+
+// Grams estimates the weight of the quantity, taking the middle of ranges.
+// Volumes need the density of the food; counts cannot be weighed.
+func (q Quantity) Grams(density Density) (float64, bool) {
+	u, ok := LookupUnit(q.Unit)
+	if !ok || u.Kind == Count {
+		return 0, false
+	}
+	value := q.Value
+	if q.Max > 0 {
+		value = (q.Value + q.Max) / 2
+	}
+	grams := value * u.Base
+	if u.Kind == Volume {
+		if density.GramsPerML <= 0 {
+			return 0, false
+		}
+		grams *= density.GramsPerML
+	}
+	return grams, true
+}
+
+// String formats the quantity with rounding suited to its unit: metric
+// weights and volumes get round numbers, everything else kitchen fractions.
+func (q Quantity) String() string {
+	u, known := LookupUnit(q.Unit)
+	metric := known && u.System == Metric
+	out := FormatNumber(q.Value, metric)
+	if q.Max > 0 {
+		upper := FormatNumber(q.Max, metric)
+		if strings.Contains(out+upper, " ") {
+			// "1 - 1 1/2" reads better than "1-1 1/2".
+			out += " - " + upper
+		} else {
+			out += "-" + upper
+		}
+	}
+	if known {
+		if q.Max > 0 || q.Value > 1 {
+			out += " " + u.Plural
+		} else {
+			out += " " + u.Name
+		}
+	}
+	if q.Note == "" {
+		return out
+	}
+	switch {
+	case strings.HasPrefix(q.Note, "("):
+		return out + " " + q.Note
+	case known:
+		return out + ", " + q.Note
+	default:
+		return out + " " + q.Note
+	}
+}
+
+// kitchenFractions are the fractions measuring cups and spoons come in.
+var kitchenFractions = []struct {
+	value float64
+	text  string
+}{
+	{0, ""}, {1.0 / 8, "1/8"}, {1.0 / 4, "1/4"}, {1.0 / 3, "1/3"}, {3.0 / 8, "3/8"},
+	{1.0 / 2, "1/2"}, {5.0 / 8, "5/8"}, {2.0 / 3, "2/3"}, {3.0 / 4, "3/4"},
+	{7.0 / 8, "7/8"}, {1, ""},
+}
+
+// FormatNumber rounds v for display. Metric amounts are rounded to whole
+// units (to 5 above 100, one decimal below 10); other amounts to the nearest
+// kitchen fraction, or a whole number from 20 on.
+func FormatNumber(v float64, metric bool) string {
+	if v <= 0 {
+		return "0"
+	}
+	if metric {
+		switch {
+		case v >= 100:
+			v = math.Round(v/5) * 5
+		case v >= 10:
+			v = math.Round(v)
+		default:
+			v = math.Round(v*10) / 10
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	if v >= 20 {
+		return strconv.FormatFloat(math.Round(v), 'f', -1, 64)
+	}
+
+	whole := math.Floor(v)
+	frac := v - whole
+	best := kitchenFractions[0]
+	for _, f := range kitchenFractions[1:] {
+		if math.Abs(frac-f.value) < math.Abs(frac-best.value) {
+			best = f
+		}
+	}
+	if best.value == 1 {
+		whole++
+	}
+
+	switch {
+	case whole == 0 && best.text == "":
+		// Never round a real amount away entirely.
+		return kitchenFractions[1].text
+	case whole == 0:
+		return best.text
+	case best.text == "":
+		return strconv.FormatFloat(whole, 'f', -1, 64)
+	default:
+		return strconv.FormatFloat(whole, 'f', -1, 64) + " " + best.text
+	}
+}
@@ -0,0 +1,117 @@
+package quantity
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Quantity
+		ok   bool
+	}{
+		{"2", Quantity{Value: 2}, true},
+		{"1 1/2 cups", Quantity{Value: 1.5, Unit: "cup"}, true},
+		{"1½ cups", Quantity{Value: 1.5, Unit: "cup"}, true},
+		{"½ tsp", Quantity{Value: 0.5, Unit: "tsp"}, true},
+		{"3/4 cup", Quantity{Value: 0.75, Unit: "cup"}, true},
+		{"200g", Quantity{Value: 200, Unit: "g"}, true},
+		{"0,5 l", Quantity{Value: 0.5, Unit: "l"}, true},
+		{"2-3 eggs", Quantity{Value: 2, Max: 3, Note: "eggs"}, true},
+		{"2 to 3 Tbsp.", Quantity{Value: 2, Max: 3, Unit: "tbsp"}, true},
+		{"2 large", Quantity{Value: 2, Note: "large"}, true},
+		{"200 g, finely chopped", Quantity{Value: 200, Unit: "g", Note: "finely chopped"}, true},
+		{"1 cup of flour", Quantity{Value: 1, Unit: "cup", Note: "flour"}, true},
+		{"2 fl oz", Quantity{Value: 2, Unit: "fl oz"}, true},
+		{"a pinch", Quantity{}, false},
+		{"", Quantity{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.in)
+		if ok != tt.ok {
+			t.Errorf("Parse(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			continue
+		}
+		if !approx(got.Value, tt.want.Value) || !approx(got.Max, tt.want.Max) || got.Unit != tt.want.Unit || got.Note != tt.want.Note {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		in     string
+		factor float64
+		want   string
+	}{
+		{"1 cup", 2, "2 cups"},
+		{"1 1/2 cups", 0.5, "3/4 cup"},
+		{"2-3 eggs", 2, "4-6 eggs"},
+		{"1 cup", 1.5, "1 1/2 cups"},
+		{"1/3 cup", 0.5, "1/8 cup"},
+		{"250 g", 1.5, "375 g"},
+		{"7 g", 0.5, "3.5 g"},
+		{"2 cloves, minced", 3, "6 cloves, minced"},
+	}
+	for _, tt := range tests {
+		q, ok := Parse(tt.in)
+		if !ok {
+			t.Fatalf("Parse(%q) failed", tt.in)
+		}
+		if got := q.Scale(tt.factor).String(); got != tt.want {
+			t.Errorf("%q scaled by %v = %q, want %q", tt.in, tt.factor, got, tt.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		in      string
+		to      System
+		density Density
+		want    string
+	}{
+		{"1 cup", Metric, Density{}, "235 ml"},
+		{"1 cup", Metric, Density{GramsPerML: 0.53, Dry: true}, "125 g"},
+		{"500 g", Imperial, Density{}, "1 1/8 lb"},
+		{"100 g", Imperial, Density{}, "3 1/2 oz"},
+		{"2 cloves", Metric, Density{}, "2 cloves"},
+		{"1 cup", Original, Density{}, "1 cup"},
+	}
+	for _, tt := range tests {
+		q, ok := Parse(tt.in)
+		if !ok {
+			t.Fatalf("Parse(%q) failed", tt.in)
+		}
+		if got := q.Convert(tt.to, tt.density).String(); got != tt.want {
+			t.Errorf("%q in %s = %q, want %q", tt.in, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		v      float64
+		metric bool
+		want   string
+	}{
+		{0, false, "0"},
+		{0.33, false, "1/3"},
+		{1.5, false, "1 1/2"},
+		{2.97, false, "3"},
+		{23.4, false, "23"},
+		{3.14, true, "3.1"},
+		{12.6, true, "13"},
+		{452, true, "450"},
+	}
+	for _, tt := range tests {
+		if got := FormatNumber(tt.v, tt.metric); got != tt.want {
+			t.Errorf("FormatNumber(%v, %v) = %q, want %q", tt.v, tt.metric, got, tt.want)
+		}
+	}
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
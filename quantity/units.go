@@ -0,0 +1,162 @@
+package quantity
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Kind is what a unit measures. Only units of the same kind convert into
+// each other, except volumes of dry foods which metric recipes weigh.
+type Kind int
+
+const (
+	Count Kind = iota
+	Mass
+	Volume
+)
+
+// System is a measurement system amounts can be rendered in.
+type System string
+
+const (
+	// Original keeps the units the author wrote.
+	Original System = "original"
+	Metric   System = "metric"
+	Imperial System = "imperial"
+)
+
+// ParseSystem reads a system name. The empty string is Original.
+func ParseSystem(s string) (System, bool) {
+	switch sys := System(strings.ToLower(strings.TrimSpace(s))); sys {
+	case "", Original:
+		return Original, true
+	case Metric, Imperial:
+		return sys, true
+	}
+	return "", false
+}
+
+// Unit is an entry of the unit registry. Base is the size of the unit in
+// grams for masses and milliliters for volumes; count units have none.
+type Unit struct {
+	Name   string
+	Plural string
+	Kind   Kind
+	System System
+	Base   float64
+}
+
+// units is the unit registry. The first alias of every entry is its name.
+var units = []struct {
+	Unit
+	aliases []string
+}{
+	{Unit{"mg", "mg", Mass, Metric, 0.001}, []string{"mg", "milligram", "milligrams"}},
+	{Unit{"g", "g", Mass, Metric, 1}, []string{"g", "gr", "gram", "grams", "gramme", "grammes"}},
+	{Unit{"kg", "kg", Mass, Metric, 1000}, []string{"kg", "kgs", "kilo", "kilos", "kilogram", "kilograms"}},
+	{Unit{"oz", "oz", Mass, Imperial, 28.349523125}, []string{"oz", "ounce", "ounces"}},
+	{Unit{"lb", "lb", Mass, Imperial, 453.59237}, []string{"lb", "lbs", "pound", "pounds"}},
+
+	{Unit{"ml", "ml", Volume, Metric, 1}, []string{"ml", "milliliter", "milliliters", "millilitre", "millilitres"}},
+	{Unit{"cl", "cl", Volume, Metric, 10}, []string{"cl", "centiliter", "centiliters", "centilitre", "centilitres"}},
+	{Unit{"dl", "dl", Volume, Metric, 100}, []string{"dl", "deciliter", "deciliters", "decilitre", "decilitres"}},
+	{Unit{"l", "l", Volume, Metric, 1000}, []string{"l", "liter", "liters", "litre", "litres"}},
+	{Unit{"tsp", "tsp", Volume, Imperial, 4.92892159375}, []string{"tsp", "tsps", "teaspoon", "teaspoons"}},
+	{Unit{"tbsp", "tbsp", Volume, Imperial, 14.78676478125}, []string{"tbsp", "tbsps", "tbs", "tbl", "tablespoon", "tablespoons"}},
+	{Unit{"fl oz", "fl oz", Volume, Imperial, 29.5735295625}, []string{"fl oz", "fluid ounce", "fluid ounces"}},
+	{Unit{"cup", "cups", Volume, Imperial, 236.5882365}, []string{"cup", "cups"}},
+	{Unit{"pint", "pints", Volume, Imperial, 473.176473}, []string{"pint", "pints", "pt"}},
+	{Unit{"quart", "quarts", Volume, Imperial, 946.352946}, []string{"quart", "quarts", "qt"}},
+	{Unit{"gallon", "gallons", Volume, Imperial, 3785.411784}, []string{"gallon", "gallons", "gal"}},
+
+	{Unit{"piece", "pieces", Count, "", 0}, []string{"piece", "pieces", "pc", "pcs"}},
+	{Unit{"clove", "cloves", Count, "", 0}, []string{"clove", "cloves"}},
+	{Unit{"slice", "slices", Count, "", 0}, []string{"slice", "slices"}},
+	{Unit{"can", "cans", Count, "", 0}, []string{"can", "cans", "tin", "tins"}},
+	{Unit{"package", "packages", Count, "", 0}, []string{"package", "packages", "pkg", "pack", "packs"}},
+	{Unit{"bunch", "bunches", Count, "", 0}, []string{"bunch", "bunches"}},
+	{Unit{"sprig", "sprigs", Count, "", 0}, []string{"sprig", "sprigs"}},
+	{Unit{"stick", "sticks", Count, "", 0}, []string{"stick", "sticks"}},
+	{Unit{"handful", "handfuls", Count, "", 0}, []string{"handful", "handfuls"}},
+	{Unit{"pinch", "pinches", Count, "", 0}, []string{"pinch", "pinches"}},
+	{Unit{"dash", "dashes", Count, "", 0}, []string{"dash", "dashes"}},
+}
+
+var unitsByAlias = map[string]Unit{}
+
+func init() {
+	for _, u := range units {
+		for _, alias := range u.aliases {
+			unitsByAlias[alias] = u.Unit
+		}
+	}
+}
+
+// LookupUnit finds a unit by any of its spellings, ignoring case and a
+// trailing dot as in "Tbsp.".
+func LookupUnit(name string) (Unit, bool) {
+	u, ok := unitsByAlias[strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))]
+	return u, ok
+}
+
+// splitUnit reads a unit from the start of s, trying two-word units such as
+// "fl oz" first, and returns the text after it.
+func splitUnit(s string) (Unit, string, bool) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	first, rest := nextWord(s)
+	if first == "" {
+		return Unit{}, s, false
+	}
+	if second, after := nextWord(strings.TrimLeftFunc(rest, unicode.IsSpace)); second != "" {
+		if u, ok := LookupUnit(first + " " + second); ok {
+			return u, after, true
+		}
+	}
+	if u, ok := LookupUnit(first); ok {
+		return u, rest, true
+	}
+	return Unit{}, s, false
+}
+
+// nextWord splits a leading run of letters, optionally ending in a dot.
+func nextWord(s string) (string, string) {
+	end := 0
+	for end < len(s) {
+		r := rune(s[end])
+		if r >= 0x80 || !unicode.IsLetter(r) {
+			break
+		}
+		end++
+	}
+	if end > 0 && end < len(s) && s[end] == '.' {
+		end++
+	}
+	return s[:end], s[end:]
+}
+
+// preferredUnits are the units amounts are converted to, largest first. An
+// amount takes the first unit it reaches at least min of.
+var preferredUnits = map[System]map[Kind][]struct {
+	unit string
+	min  float64
+}{
+	Metric: {
+		Mass:   {{"kg", 1}, {"g", 0}},
+		Volume: {{"l", 1}, {"ml", 0}},
+	},
+	Imperial: {
+		Mass:   {{"lb", 1}, {"oz", 0}},
+		Volume: {{"cup", 0.25}, {"tbsp", 1}, {"tsp", 0}},
+	},
+}
+
+func preferredUnit(system System, kind Kind, base float64) Unit {
+	candidates := preferredUnits[system][kind]
+	for _, c := range candidates {
+		u := unitsByAlias[c.unit]
+		if base >= c.min*u.Base {
+			return u
+		}
+	}
+	return unitsByAlias[candidates[len(candidates)-1].unit]
+}
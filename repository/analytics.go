@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/model"
+	"gorm.io/gorm"
+)
+
+type TimeSeriesData struct {
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+type AnalyticsRepository interface {
+	RecordSiteVisit(visit *model.SiteVisit) error
+	// TimeSeries counts the rows of table whose column is at or after since,
+	// grouped by day or month.
+	TimeSeries(table, column string, bucket internal.DateBucket, since time.Time) ([]TimeSeriesData, error)
+}
+
+type analyticsRepository struct {
+	db      *gorm.DB
+	dialect internal.Dialect
+}
+
+func NewAnalyticsRepository(db *gorm.DB, dialect internal.Dialect) AnalyticsRepository {
+	return &analyticsRepository{db: db, dialect: dialect}
+}
+
+func (r *analyticsRepository) RecordSiteVisit(visit *model.SiteVisit) error {
+	return r.db.Create(visit).Error
+}
+
+// TimeSeries interpolates table and column into the query, so callers must
+// only pass trusted identifiers (see utils.GetTableAndColumn).
+func (r *analyticsRepository) TimeSeries(table, column string, bucket internal.DateBucket, since time.Time) ([]TimeSeriesData, error) {
+	label := r.dialect.DateLabel(column, bucket)
+	query := fmt.Sprintf(`
+		SELECT %s AS label, COUNT(*) AS count
+		FROM %s
+		WHERE %s >= ?
+		GROUP BY %s
+		ORDER BY %s
+	`, label, table, column, label, label)
+
+	var results []TimeSeriesData
+	err := r.db.Raw(query, since).Scan(&results).Error
+	return results, err
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/utils"
+	"gorm.io/gorm"
+)
+
+type CategoryRepository interface {
+	FindByID(id uint) (*model.Category, error)
+	FindByName(name string) (*model.Category, error)
+	FindByIDs(ids []uint) ([]model.Category, error)
+	// List applies utils.ApplyCatSorting and returns one page of categories
+	// together with the total count.
+	List(sort string, limit, offset int) ([]model.Category, int64, error)
+	Create(category *model.Category) error
+	Save(category *model.Category) error
+	// AttachRecipes links the category to the given recipes, skipping unknown IDs.
+	AttachRecipes(category *model.Category, recipeIDs []uint) error
+	// DetachRecipes removes every recipe association of the category.
+	DetachRecipes(category *model.Category) error
+	Delete(category *model.Category) error
+	// Usage returns every category with the number of published public
+	// recipes in it.
+	Usage() ([]UsageCount, error)
+}
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) FindByID(id uint) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) FindByName(name string) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.Where("name = ?", name).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) FindByIDs(ids []uint) ([]model.Category, error) {
+	var categories []model.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+func (r *categoryRepository) List(sort string, limit, offset int) ([]model.Category, int64, error) {
+	query := r.db.Model(&model.Category{})
+	query = utils.ApplyCatSorting(query, sort)
+
+	total, err := utils.Count(query, "categories")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var categories []model.Category
+	if err := utils.Paginate(query, limit, offset, &categories); err != nil {
+		return nil, 0, err
+	}
+	return categories, total, nil
+}
+
+func (r *categoryRepository) Create(category *model.Category) error {
+	return r.db.Create(category).Error
+}
+
+func (r *categoryRepository) Save(category *model.Category) error {
+	return r.db.Save(category).Error
+}
+
+func (r *categoryRepository) AttachRecipes(category *model.Category, recipeIDs []uint) error {
+	for _, id := range recipeIDs {
+		var recipe model.Recipe
+		if err := r.db.First(&recipe, id).Error; err != nil {
+			continue
+		}
+		if err := r.db.Model(category).Association("Recipes").Append(&recipe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *categoryRepository) DetachRecipes(category *model.Category) error {
+	return r.db.Model(category).Association("Recipes").Clear()
+}
+
+func (r *categoryRepository) Delete(category *model.Category) error {
+	return r.db.Delete(category).Error
+}
+
+func (r *categoryRepository) Usage() ([]UsageCount, error) {
+	var usage []UsageCount
+	err := r.db.Model(&model.Category{}).
+		Select("categories.id, categories.name, COUNT(recipes.id) AS count").
+		Joins("LEFT JOIN recipe_categories rc ON rc.category_id = categories.id").
+		Joins("LEFT JOIN recipes ON recipes.id = rc.recipe_id AND recipes.status = ? AND recipes.visibility = ?", model.StatusPublished, model.VisibilityPublic).
+		Group("categories.id, categories.name").
+		Scan(&usage).Error
+	return usage, err
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/utils"
+	"gorm.io/gorm"
+)
+
+type CommentWithUserName struct {
+	model.Comment
+	UserName string `json:"user_name"`
+}
+
+type CommentWithDetails struct {
+	CommentID    uint   `json:"comment_id"`
+	CommentTitle string `json:"comment_title"`
+	RecipeID     uint   `json:"recipe_id"`
+	RecipeTitle  string `json:"recipe_title"`
+	UserID       uint   `json:"user_id"`
+	UserName     string `json:"user_name"`
+	Likes        int    `json:"likes"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type CommentRepository interface {
+	FindByID(id uint) (*model.Comment, error)
+	FindByUserAndRecipe(userID, recipeID uint) (*model.Comment, error)
+	Create(comment *model.Comment) error
+	Delete(comment *model.Comment) error
+	// AddLikes adjusts the like counter by delta and reports whether the
+	// comment exists.
+	AddLikes(id uint, delta int) (bool, error)
+
+	ListForRecipe(recipeID uint, sort string, limit, offset int) ([]CommentWithUserName, int64, error)
+	// ListAll lists the comments of every recipe, or only of recipes with
+	// the given status and visibility when they are not empty.
+	ListAll(status, visibility, sort string, limit, offset int) ([]CommentWithDetails, int64, error)
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) FindByID(id uint) (*model.Comment, error) {
+	var comment model.Comment
+	if err := r.db.First(&comment, id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) FindByUserAndRecipe(userID, recipeID uint) (*model.Comment, error) {
+	var comment model.Comment
+	if err := r.db.Where("user_id = ? AND recipe_id = ?", userID, recipeID).First(&comment).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) Create(comment *model.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+func (r *commentRepository) Delete(comment *model.Comment) error {
+	return r.db.Delete(comment).Error
+}
+
+func (r *commentRepository) AddLikes(id uint, delta int) (bool, error) {
+	result := r.db.Exec("update comments set likes = likes + ? where id = ?", delta, id)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *commentRepository) ListForRecipe(recipeID uint, sort string, limit, offset int) ([]CommentWithUserName, int64, error) {
+	query := r.db.Model(&model.Comment{}).
+		Select("comments.*, users.name AS user_name").
+		Joins("JOIN users ON users.id = comments.user_id").
+		Where("comments.recipe_id = ?", recipeID)
+	query = utils.ApplyCommentSorting(query, sort)
+
+	total, err := utils.Count(query, "comments")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var comments []CommentWithUserName
+	if err := utils.Paginate(query, limit, offset, &comments); err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+func (r *commentRepository) ListAll(status, visibility, sort string, limit, offset int) ([]CommentWithDetails, int64, error) {
+	query := r.db.Table("comments").
+		Select(`comments.id as comment_id,
+                comments.title as comment_title,
+				comments.likes as likes,
+                recipes.id as recipe_id,
+                recipes.title as recipe_title,
+                users.id as user_id,
+                users.name as user_name`).
+		Joins("JOIN recipes ON comments.recipe_id = recipes.id").
+		Joins("JOIN users ON comments.user_id = users.id")
+	query = utils.ApplyRecipeFilters(query, map[string]string{"status": status, "visibility": visibility})
+	query = utils.ApplyCommentSorting(query, sort)
+
+	total, err := utils.Count(query, "comments")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var comments []CommentWithDetails
+	if err := utils.Paginate(query, limit, offset, &comments); err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
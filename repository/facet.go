@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"database/sql"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/utils"
+	"gorm.io/gorm"
+)
+
+// Facets that count recipes per value or per rating. Every nutrient and time
+// column is a histogram facet under its own name.
+const (
+	FacetTags       = "tags"
+	FacetCategories = "categories"
+	FacetAuthors    = "authors"
+	FacetRating     = "rating"
+)
+
+// RecipeFacets lists the names of every facet.
+var RecipeFacets = slices.Concat(
+	[]string{FacetTags, FacetCategories, FacetAuthors, FacetRating},
+	utils.NutrientColumns,
+	utils.TimeColumns,
+)
+
+const (
+	// maxFacetValues caps how many tags, categories or authors a facet lists.
+	maxFacetValues = 20
+	// histogramBuckets is roughly how many buckets a histogram is split into;
+	// bucket widths are rounded to 1, 2 or 5 times a power of ten.
+	histogramBuckets = 8
+)
+
+// Facet counts the matching recipes per value of one facet.
+type Facet struct {
+	// Values is set for tags, categories and authors, most common first.
+	Values []FacetValue `json:"values,omitempty"`
+	// Buckets is set for the rating and the histogram facets. Rating buckets
+	// are cumulative and closed: each counts the recipes averaging from From
+	// up to and including To, 5 stars.
+	Buckets []FacetBucket `json:"buckets,omitempty"`
+}
+
+type FacetValue struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// FacetBucket counts the recipes with a value from From up to, but not
+// including, To. Rating buckets include To.
+type FacetBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int64   `json:"count"`
+}
+
+type facetRow struct {
+	ID       uint
+	Name     string
+	LastName string
+	Count    int64
+}
+
+func (r *recipeRepository) Facets(names []string, params map[string]string, within []uint) (map[string]Facet, error) {
+	facets := make(map[string]Facet, len(names))
+	for _, name := range names {
+		if within != nil && len(within) == 0 {
+			facets[name] = Facet{}
+			continue
+		}
+
+		query := r.facetQuery(name, params, within)
+		var facet Facet
+		var err error
+		switch name {
+		case FacetTags:
+			facet.Values, err = facetValues(query.
+				Joins("JOIN recipe_tags ft ON ft.recipe_id = recipes.id").
+				Joins("JOIN tags t ON t.id = ft.tag_id").
+				Select("t.id AS id, t.name AS name, COUNT(*) AS count").
+				Group("t.id, t.name"))
+		case FacetCategories:
+			facet.Values, err = facetValues(query.
+				Joins("JOIN recipe_categories fc ON fc.recipe_id = recipes.id").
+				Joins("JOIN categories c ON c.id = fc.category_id").
+				Select("c.id AS id, c.name AS name, COUNT(*) AS count").
+				Group("c.id, c.name"))
+		case FacetAuthors:
+			facet.Values, err = facetValues(query.
+				Joins("JOIN users u ON u.id = recipes.user_id").
+				Select("u.id AS id, u.name AS name, u.last_name AS last_name, COUNT(*) AS count").
+				Group("u.id, u.name, u.last_name"))
+		case FacetRating:
+			facet.Buckets, err = ratingBuckets(query)
+		default:
+			facet.Buckets, err = r.histogram(query, name, params["per_serving"] == "true")
+		}
+		if err != nil {
+			return nil, err
+		}
+		facets[name] = facet
+	}
+	return facets, nil
+}
+
+// facetQuery selects the recipes matching params except for the filter of
+// the facet itself: its own parameters and the terms on its field in the
+// filter expression are left out.
+func (r *recipeRepository) facetQuery(name string, params map[string]string, within []uint) *gorm.DB {
+	own := maps.Clone(params)
+	field := name
+	switch name {
+	case FacetTags:
+		field = "tag"
+		delete(own, "tag_ids")
+		delete(own, "tag_match")
+		delete(own, "exclude_tag_ids")
+	case FacetCategories:
+		field = "category"
+		delete(own, "category_ids")
+		delete(own, "category_match")
+		delete(own, "exclude_category_ids")
+	case FacetAuthors:
+		field = "author"
+		delete(own, "user_id")
+		delete(own, "exclude_user_ids")
+	case FacetRating:
+		delete(own, "rating")
+	default:
+		delete(own, "min_"+name)
+		delete(own, "max_"+name)
+	}
+	delete(own, "filter")
+
+	query := r.db.Model(&model.Recipe{})
+	if within != nil {
+		query = query.Where("recipes.id IN ?", within)
+	}
+	query = utils.ApplyRecipeFilters(query, own)
+	if expr := params["filter"]; expr != "" {
+		if f, err := utils.ParseRecipeFilter(expr); err == nil {
+			if f = f.Without(field); f != nil {
+				query = f.Apply(query, params["per_serving"] == "true")
+			}
+		}
+	}
+	return query
+}
+
+func facetValues(query *gorm.DB) ([]FacetValue, error) {
+	var rows []facetRow
+	if err := query.Order("count DESC").Order("name").Limit(maxFacetValues).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	values := make([]FacetValue, len(rows))
+	for i, row := range rows {
+		values[i] = FacetValue{ID: row.ID, Name: strings.TrimSpace(row.Name + " " + row.LastName), Count: row.Count}
+	}
+	return values, nil
+}
+
+func ratingBuckets(query *gorm.DB) ([]FacetBucket, error) {
+	averages := query.
+		Joins("JOIN ratings fr ON fr.recipe_id = recipes.id").
+		Group("recipes.id").
+		Select("AVG(fr.score) AS score")
+
+	buckets := make([]FacetBucket, 0, 5)
+	var conds []string
+	var args []any
+	for stars := 5; stars >= 1; stars-- {
+		buckets = append(buckets, FacetBucket{From: float64(stars), To: 5})
+		conds = append(conds, "a.score >= ?")
+		args = append(args, stars)
+	}
+	counts, err := countBuckets(query.Session(&gorm.Session{NewDB: true}).Table("(?) AS a", averages), conds, args)
+	if err != nil {
+		return nil, err
+	}
+	for i := range buckets {
+		buckets[i].Count = counts[i]
+	}
+	return buckets, nil
+}
+
+// histogram buckets the values of a nutrient or time column. Recipes
+// without a time are left out.
+func (r *recipeRepository) histogram(query *gorm.DB, column string, perServing bool) ([]FacetBucket, error) {
+	expr := "recipes." + column
+	if slices.Contains(utils.NutrientColumns, column) {
+		expr = utils.NutrientExpr(column, perServing)
+		if perServing {
+			query = query.Where("recipes.servings > 0")
+		}
+	} else {
+		query = query.Where(expr + " > 0")
+	}
+	query = query.Session(&gorm.Session{})
+
+	var lo, hi sql.NullFloat64
+	if err := query.Select("MIN("+expr+"), MAX("+expr+")").Row().Scan(&lo, &hi); err != nil {
+		return nil, err
+	}
+	if !lo.Valid {
+		return nil, nil
+	}
+
+	buckets := histogramRanges(lo.Float64, hi.Float64)
+	conds := make([]string, len(buckets))
+	args := make([]any, 0, 2*len(buckets))
+	for i, b := range buckets {
+		if i == len(buckets)-1 {
+			conds[i] = expr + " >= ?"
+			args = append(args, b.From)
+		} else {
+			conds[i] = expr + " >= ? AND " + expr + " < ?"
+			args = append(args, b.From, b.To)
+		}
+	}
+	counts, err := countBuckets(query, conds, args)
+	if err != nil {
+		return nil, err
+	}
+	for i := range buckets {
+		buckets[i].Count = counts[i]
+	}
+	return buckets, nil
+}
+
+// histogramRanges splits the range from lo to hi into empty buckets.
+func histogramRanges(lo, hi float64) []FacetBucket {
+	width := niceWidth((hi - lo) / histogramBuckets)
+	// Rounding the ratio keeps a bound such as 0.3 from flooring to 0.2.
+	start := math.Floor(roundBound(lo/width)) * width
+
+	var buckets []FacetBucket
+	for i := 0; ; i++ {
+		from := roundBound(start + float64(i)*width)
+		if from > hi {
+			return buckets
+		}
+		buckets = append(buckets, FacetBucket{From: from, To: roundBound(start + float64(i+1)*width)})
+	}
+}
+
+// countBuckets counts the rows of query matching each of conds in a single
+// query. args are the arguments of every condition in turn.
+func countBuckets(query *gorm.DB, conds []string, args []any) ([]int64, error) {
+	columns := make([]string, len(conds))
+	for i, cond := range conds {
+		columns[i] = "COUNT(CASE WHEN " + cond + " THEN 1 END)"
+	}
+	counts := make([]int64, len(conds))
+	dest := make([]any, len(conds))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	err := query.Select(strings.Join(columns, ", "), args...).Row().Scan(dest...)
+	return counts, err
+}
+
+// niceWidth rounds a bucket width up to 1, 2 or 5 times a power of ten.
+func niceWidth(width float64) float64 {
+	if width <= 0 {
+		return 1
+	}
+	scale := math.Pow(10, math.Floor(math.Log10(width)))
+	switch f := width / scale; {
+	case f <= 1:
+		return scale
+	case f <= 2:
+		return 2 * scale
+	case f <= 5:
+		return 5 * scale
+	}
+	return 10 * scale
+}
+
+// roundBound drops the floating point noise of multiplying the bucket width.
+func roundBound(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Abb133Se/recepieshare/model"
+)
+
+func TestFacets(t *testing.T) {
+	db, repos := newTestDB(t)
+	seedRecipes(t, db)
+	listed := map[string]string{"status": "published", "visibility": "public"}
+
+	facets, err := repos.Recipes.Facets(RecipeFacets, listed, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		facet string
+		want  []FacetValue
+	}{
+		// Most common first, then by name.
+		{FacetTags, []FacetValue{{3, "spicy", 2}, {1, "vegan", 2}, {2, "quick", 1}}},
+		{FacetCategories, []FacetValue{{1, "dinner", 3}, {2, "dessert", 1}}},
+		{FacetAuthors, []FacetValue{{1, "Alice", 2}, {2, "Bob Baker", 2}}},
+	}
+	for _, tt := range tests {
+		got := facets[tt.facet].Values
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s facet = %v, want %v", tt.facet, got, tt.want)
+		}
+	}
+
+	var ratingCounts []int64
+	for _, b := range facets[FacetRating].Buckets {
+		if b.To != 5 {
+			t.Errorf("rating bucket %+v does not end at 5 stars", b)
+		}
+		ratingCounts = append(ratingCounts, b.Count)
+	}
+	// Averages are 4.5, 3 and 4; the cake is unrated. The buckets go up to and
+	// include 5 stars.
+	if want := []int64{0, 2, 3, 3, 3}; !slices.Equal(ratingCounts, want) {
+		t.Errorf("rating buckets = %v, want %v", ratingCounts, want)
+	}
+
+	// Times of 20, 45 and 30 minutes; the cake has none.
+	var total int64
+	for _, b := range facets["total_minutes"].Buckets {
+		total += b.Count
+		if b.From >= b.To {
+			t.Errorf("total_minutes bucket %+v is empty", b)
+		}
+	}
+	if total != 3 {
+		t.Errorf("total_minutes buckets count %d recipes, want 3", total)
+	}
+}
+
+func TestFacetsIgnoreTheirOwnFilter(t *testing.T) {
+	db, repos := newTestDB(t)
+	seedRecipes(t, db)
+	params := map[string]string{"status": "published", "visibility": "public", "tag_ids": "2", "category_ids": "1"}
+
+	facets, err := repos.Recipes.Facets([]string{FacetTags, FacetCategories}, params, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The tag facet still counts every tag within the dinner category, while
+	// the category facet only counts quick recipes.
+	if got := len(facets[FacetTags].Values); got != 3 {
+		t.Errorf("tag facet has %d values, want 3", got)
+	}
+	if got := facets[FacetCategories].Values; len(got) != 1 || got[0].Name != "dinner" || got[0].Count != 1 {
+		t.Errorf("category facet = %v, want dinner: 1", got)
+	}
+}
+
+func TestFacetsIgnoreTheirOwnFilterTerms(t *testing.T) {
+	db, repos := newTestDB(t)
+	seedRecipes(t, db)
+	params := map[string]string{"status": "published", "visibility": "public", "filter": "tag:quick cuisine:italian OR tag:spicy"}
+
+	facets, err := repos.Recipes.Facets([]string{FacetTags, FacetCategories}, params, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without its tag terms the filter is cuisine:italian, the tomato soup.
+	if want := []FacetValue{{2, "quick", 1}, {1, "vegan", 1}}; !slices.Equal(facets[FacetTags].Values, want) {
+		t.Errorf("tag facet = %v, want %v", facets[FacetTags].Values, want)
+	}
+	// The category facet still applies the whole filter: the soup and both curries.
+	if want := []FacetValue{{1, "dinner", 3}}; !slices.Equal(facets[FacetCategories].Values, want) {
+		t.Errorf("category facet = %v, want %v", facets[FacetCategories].Values, want)
+	}
+}
+
+func TestFacetsWithin(t *testing.T) {
+	db, repos := newTestDB(t)
+	seedRecipes(t, db)
+
+	facets, err := repos.Recipes.Facets([]string{FacetAuthors}, map[string]string{}, []uint{2, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := facets[FacetAuthors].Values; len(got) != 1 || got[0].ID != 2 || got[0].Count != 2 {
+		t.Errorf("author facet = %v, want bob: 2", got)
+	}
+
+	facets, err = repos.Recipes.Facets([]string{FacetAuthors}, map[string]string{}, []uint{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := facets[FacetAuthors].Values; len(got) != 0 {
+		t.Errorf("author facet within no recipes = %v, want none", got)
+	}
+}
+
+func TestFacetsHistogram(t *testing.T) {
+	db, repos := newTestDB(t)
+	if err := db.Create(&model.User{ID: 1, Name: "Alice", Email: "alice@example.com", Role: "user"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, fat := range []float64{0.1, 0.35} {
+		if err := db.Create(&model.Recipe{Title: "Broth", Text: "Cook it.", UserID: 1, Fat: fat}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	facets, err := repos.Recipes.Facets([]string{"fat"}, map[string]string{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 0.35 lands in its own bucket, not the one below it.
+	want := []FacetBucket{{0.1, 0.15, 1}, {0.15, 0.2, 0}, {0.2, 0.25, 0}, {0.25, 0.3, 0}, {0.3, 0.35, 0}, {0.35, 0.4, 1}}
+	if got := facets["fat"].Buckets; !slices.Equal(got, want) {
+		t.Errorf("fat buckets = %v, want %v", got, want)
+	}
+}
+
+func TestHistogramRanges(t *testing.T) {
+	tests := []struct {
+		lo, hi float64
+		want   []FacetBucket
+	}{
+		{5, 5, []FacetBucket{{5, 6, 0}}},
+		{10, 45, []FacetBucket{{10, 15, 0}, {15, 20, 0}, {20, 25, 0}, {25, 30, 0}, {30, 35, 0}, {35, 40, 0}, {40, 45, 0}, {45, 50, 0}}},
+		// 0.3 / 0.1 is just below 3 in floating point.
+		{0.3, 0.7, []FacetBucket{{0.3, 0.35, 0}, {0.35, 0.4, 0}, {0.4, 0.45, 0}, {0.45, 0.5, 0}, {0.5, 0.55, 0}, {0.55, 0.6, 0}, {0.6, 0.65, 0}, {0.65, 0.7, 0}, {0.7, 0.75, 0}}},
+	}
+	for _, tt := range tests {
+		if got := histogramRanges(tt.lo, tt.hi); !slices.Equal(got, tt.want) {
+			t.Errorf("histogramRanges(%v, %v) = %v, want %v", tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
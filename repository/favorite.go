@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/utils"
+	"gorm.io/gorm"
+)
+
+type FavoriteWithTitle struct {
+	model.Favorite
+	RecipeTitle string `json:"recipe_title"`
+}
+
+type FavoriteWithDetails struct {
+	FavoriteID  uint   `json:"favorite_id"`
+	UserID      uint   `json:"user_id"`
+	UserName    string `json:"user_name"`
+	RecipeID    uint   `json:"recipe_id"`
+	RecipeTitle string `json:"recipe_title"`
+}
+
+// FavoriteStats is the favorite count of a recipe and whether a given user
+// is among the users who favorited it.
+type FavoriteStats struct {
+	Count       int64
+	UserFavored bool
+}
+
+type FavoriteRepository interface {
+	FindByUserAndRecipe(userID, recipeID uint) (*model.Favorite, error)
+	// FirstOrCreate inserts the favorite unless the user already favorited the recipe.
+	FirstOrCreate(favorite *model.Favorite) error
+	Delete(favorite *model.Favorite) error
+	Stats(recipeID, userID uint) (*FavoriteStats, error)
+
+	ListByUser(userID uint, limit, offset int) ([]FavoriteWithTitle, int64, error)
+	ListAll(limit, offset int) ([]FavoriteWithDetails, int64, error)
+}
+
+type favoriteRepository struct {
+	db *gorm.DB
+}
+
+func NewFavoriteRepository(db *gorm.DB) FavoriteRepository {
+	return &favoriteRepository{db: db}
+}
+
+func (r *favoriteRepository) FindByUserAndRecipe(userID, recipeID uint) (*model.Favorite, error) {
+	var favorite model.Favorite
+	if err := r.db.Where("user_id = ? AND recipe_id = ?", userID, recipeID).First(&favorite).Error; err != nil {
+		return nil, err
+	}
+	return &favorite, nil
+}
+
+func (r *favoriteRepository) FirstOrCreate(favorite *model.Favorite) error {
+	return r.db.FirstOrCreate(favorite, model.Favorite{UserID: favorite.UserID, RecipeID: favorite.RecipeID}).Error
+}
+
+func (r *favoriteRepository) Delete(favorite *model.Favorite) error {
+	return r.db.Delete(favorite).Error
+}
+
+func (r *favoriteRepository) Stats(recipeID, userID uint) (*FavoriteStats, error) {
+	var stats FavoriteStats
+	err := r.db.Model(&model.Favorite{}).
+		Select("COUNT(*) as count, SUM(CASE WHEN user_id = ? THEN 1 ELSE 0 END) > 0 as user_favored", userID).
+		Where("recipe_id = ?", recipeID).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (r *favoriteRepository) ListByUser(userID uint, limit, offset int) ([]FavoriteWithTitle, int64, error) {
+	query := r.db.Table("favorites").
+		Select("favorites.id, favorites.recipe_id, recipes.title as recipe_title").
+		Joins("JOIN recipes ON favorites.recipe_id = recipes.id").
+		Where("favorites.user_id = ?", userID)
+
+	total, err := utils.Count(query, "favorites")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var favorites []FavoriteWithTitle
+	if err := utils.Paginate(query, limit, offset, &favorites); err != nil {
+		return nil, 0, err
+	}
+	return favorites, total, nil
+}
+
+func (r *favoriteRepository) ListAll(limit, offset int) ([]FavoriteWithDetails, int64, error) {
+	query := r.db.Table("favorites").
+		Select(`favorites.id as favorite_id,
+                users.id as user_id,
+                users.name as user_name,
+                recipes.id as recipe_id,
+                recipes.title as recipe_title`).
+		Joins("JOIN users ON favorites.user_id = users.id").
+		Joins("JOIN recipes ON favorites.recipe_id = recipes.id")
+
+	total, err := utils.Count(query, "favorites")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var favorites []FavoriteWithDetails
+	if err := utils.Paginate(query, limit, offset, &favorites); err != nil {
+		return nil, 0, err
+	}
+	return favorites, total, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"github.com/Abb133Se/recepieshare/model"
+	"gorm.io/gorm"
+)
+
+type ImageRepository interface {
+	// Find loads an image only if it belongs to the given entity.
+	Find(entityType string, entityID, imageID uint) (*model.Image, error)
+	// FindFirst returns the oldest image of the entity, e.g. a user's profile image.
+	FindFirst(entityType string, entityID uint) (*model.Image, error)
+	ListForEntity(entityType string, entityID uint) ([]model.Image, error)
+	// ListForEntities returns the images of several entities of the same type at once.
+	ListForEntities(entityType string, entityIDs []uint) ([]model.Image, error)
+	ListByType(entityType string) ([]model.Image, error)
+	CountForEntity(entityType string, entityID uint) (int64, error)
+	Create(img *model.Image) error
+	Delete(img *model.Image) error
+	DeleteForEntity(entityType string, entityID uint) error
+}
+
+type imageRepository struct {
+	db *gorm.DB
+}
+
+func NewImageRepository(db *gorm.DB) ImageRepository {
+	return &imageRepository{db: db}
+}
+
+func (r *imageRepository) Find(entityType string, entityID, imageID uint) (*model.Image, error) {
+	var img model.Image
+	err := r.db.Where("id = ? AND entity_type = ? AND entity_id = ?", imageID, entityType, entityID).First(&img).Error
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+func (r *imageRepository) FindFirst(entityType string, entityID uint) (*model.Image, error) {
+	var img model.Image
+	err := r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).First(&img).Error
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
+
+func (r *imageRepository) ListForEntity(entityType string, entityID uint) ([]model.Image, error) {
+	var images []model.Image
+	err := r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Find(&images).Error
+	return images, err
+}
+
+func (r *imageRepository) ListForEntities(entityType string, entityIDs []uint) ([]model.Image, error) {
+	var images []model.Image
+	if len(entityIDs) == 0 {
+		return images, nil
+	}
+	err := r.db.Where("entity_type = ? AND entity_id IN ?", entityType, entityIDs).Order("id").Find(&images).Error
+	return images, err
+}
+
+func (r *imageRepository) ListByType(entityType string) ([]model.Image, error) {
+	var images []model.Image
+	err := r.db.Where("entity_type = ?", entityType).Find(&images).Error
+	return images, err
+}
+
+func (r *imageRepository) CountForEntity(entityType string, entityID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Image{}).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *imageRepository) Create(img *model.Image) error {
+	return r.db.Create(img).Error
+}
+
+func (r *imageRepository) Delete(img *model.Image) error {
+	return r.db.Delete(img).Error
+}
+
+func (r *imageRepository) DeleteForEntity(entityType string, entityID uint) error {
+	return r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Delete(&model.Image{}).Error
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"gorm.io/gorm"
+)
+
+// InvitedUser is a user with access to a private recipe.
+type InvitedUser struct {
+	UserID    uint      `json:"user_id"`
+	Name      string    `json:"name"`
+	LastName  string    `json:"last_name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InviteRepository stores who may see private recipes besides their owner.
+type InviteRepository interface {
+	// Create adds the invite unless the user is already invited.
+	Create(invite *model.RecipeInvite) error
+	Delete(recipeID, userID uint) error
+	Exists(recipeID, userID uint) (bool, error)
+	ListByRecipe(recipeID uint) ([]InvitedUser, error)
+}
+
+type inviteRepository struct {
+	db *gorm.DB
+}
+
+func NewInviteRepository(db *gorm.DB) InviteRepository {
+	return &inviteRepository{db: db}
+}
+
+func (r *inviteRepository) Create(invite *model.RecipeInvite) error {
+	return r.db.Where(model.RecipeInvite{RecipeID: invite.RecipeID, UserID: invite.UserID}).
+		FirstOrCreate(invite).Error
+}
+
+func (r *inviteRepository) Delete(recipeID, userID uint) error {
+	result := r.db.Where("recipe_id = ? AND user_id = ?", recipeID, userID).Delete(&model.RecipeInvite{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *inviteRepository) Exists(recipeID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.RecipeInvite{}).
+		Where("recipe_id = ? AND user_id = ?", recipeID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *inviteRepository) ListByRecipe(recipeID uint) ([]InvitedUser, error) {
+	var users []InvitedUser
+	err := r.db.Model(&model.RecipeInvite{}).
+		Select("users.id AS user_id, users.name, users.last_name, users.email, recipe_invites.created_at").
+		Joins("JOIN users ON users.id = recipe_invites.user_id").
+		Where("recipe_invites.recipe_id = ?", recipeID).
+		Order("recipe_invites.created_at").
+		Scan(&users).Error
+	return users, err
+}
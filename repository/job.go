@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/utils"
+	"gorm.io/gorm"
+)
+
+// JobRepository stores the background job queue.
+type JobRepository interface {
+	Enqueue(job *model.Job) error
+	// Claim marks up to limit pending jobs due at now as running and returns
+	// them. A job is only handed out once, even to concurrent callers.
+	Claim(now time.Time, limit int) ([]model.Job, error)
+	// Complete, Reschedule and Bury record the outcome of the run claimed at
+	// lockedAt. They return ErrNotFound when that claim is gone because the
+	// job was requeued as stale in the meantime.
+	Complete(id uint, lockedAt, now time.Time) error
+	// Reschedule puts a failed job back in the queue to run at runAt.
+	Reschedule(id uint, lockedAt, runAt time.Time, lastErr string) error
+	// Bury moves a failed job to the dead letters.
+	Bury(id uint, lockedAt, now time.Time, lastErr string) error
+	// RequeueStale puts jobs that started running before t back in the
+	// queue, they were lost by a stopped server or overran their timeout.
+	// Jobs without attempts left are buried instead.
+	RequeueStale(t, now time.Time) (requeued, buried int64, err error)
+	// Retry puts a dead job back in the queue with a fresh set of attempts.
+	// It returns ErrNotFound when no dead job has the given ID.
+	Retry(id uint, now time.Time) error
+	FindByID(id uint) (*model.Job, error)
+	List(status model.JobStatus, jobType string, limit, offset int) ([]model.Job, int64, error)
+	// CountByStatus returns the number of jobs per status.
+	CountByStatus() (map[model.JobStatus]int64, error)
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Enqueue(job *model.Job) error {
+	if job.Status == "" {
+		job.Status = model.JobPending
+	}
+	return r.db.Create(job).Error
+}
+
+func (r *jobRepository) Claim(now time.Time, limit int) ([]model.Job, error) {
+	var ids []uint
+	err := r.db.Model(&model.Job{}).
+		Where("status = ? AND run_at <= ?", model.JobPending, now).
+		Order("run_at, id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	// Another worker may have claimed some of them in between; only the
+	// jobs still pending are ours.
+	var claimed []uint
+	for _, id := range ids {
+		result := r.db.Model(&model.Job{}).
+			Where("id = ? AND status = ?", id, model.JobPending).
+			Updates(map[string]any{
+				"status":    model.JobRunning,
+				"locked_at": now,
+				"attempts":  gorm.Expr("attempts + 1"),
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			claimed = append(claimed, id)
+		}
+	}
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+
+	var jobs []model.Job
+	err = r.db.Where("id IN ?", claimed).Order("run_at, id").Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *jobRepository) Complete(id uint, lockedAt, now time.Time) error {
+	return r.finish(id, lockedAt, map[string]any{
+		"status":      model.JobDone,
+		"locked_at":   nil,
+		"finished_at": now,
+		"last_error":  "",
+	})
+}
+
+func (r *jobRepository) Reschedule(id uint, lockedAt, runAt time.Time, lastErr string) error {
+	return r.finish(id, lockedAt, map[string]any{
+		"status":     model.JobPending,
+		"locked_at":  nil,
+		"run_at":     runAt,
+		"last_error": lastErr,
+	})
+}
+
+func (r *jobRepository) Bury(id uint, lockedAt, now time.Time, lastErr string) error {
+	return r.finish(id, lockedAt, map[string]any{
+		"status":      model.JobDead,
+		"locked_at":   nil,
+		"finished_at": now,
+		"last_error":  lastErr,
+	})
+}
+
+// finish applies updates to a running job that is still locked by the claim
+// made at lockedAt.
+func (r *jobRepository) finish(id uint, lockedAt time.Time, updates map[string]any) error {
+	result := r.db.Model(&model.Job{}).
+		Where("id = ? AND status = ? AND locked_at = ?", id, model.JobRunning, lockedAt).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *jobRepository) RequeueStale(t, now time.Time) (requeued, buried int64, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		stale := func() *gorm.DB {
+			return tx.Model(&model.Job{}).Where("status = ? AND locked_at < ?", model.JobRunning, t)
+		}
+
+		result := stale().
+			Where("attempts >= max_attempts").
+			Updates(map[string]any{
+				"status":      model.JobDead,
+				"locked_at":   nil,
+				"finished_at": now,
+				"last_error":  "timed out",
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		buried = result.RowsAffected
+
+		result = stale().Updates(map[string]any{
+			"status":    model.JobPending,
+			"locked_at": nil,
+			"run_at":    now,
+		})
+		requeued = result.RowsAffected
+		return result.Error
+	})
+	return requeued, buried, err
+}
+
+func (r *jobRepository) Retry(id uint, now time.Time) error {
+	result := r.db.Model(&model.Job{}).
+		Where("id = ? AND status = ?", id, model.JobDead).
+		Updates(map[string]any{
+			"status":      model.JobPending,
+			"attempts":    0,
+			"run_at":      now,
+			"finished_at": nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *jobRepository) FindByID(id uint) (*model.Job, error) {
+	var job model.Job
+	if err := r.db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) List(status model.JobStatus, jobType string, limit, offset int) ([]model.Job, int64, error) {
+	query := r.db.Model(&model.Job{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if jobType != "" {
+		query = query.Where("type = ?", jobType)
+	}
+
+	total, err := utils.Count(query, "jobs")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var jobs []model.Job
+	if err := utils.Paginate(query.Order("id DESC"), limit, offset, &jobs); err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+func (r *jobRepository) CountByStatus() (map[model.JobStatus]int64, error) {
+	var rows []struct {
+		Status model.JobStatus
+		Count  int64
+	}
+	err := r.db.Model(&model.Job{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[model.JobStatus]int64{
+		model.JobPending: 0,
+		model.JobRunning: 0,
+		model.JobDone:    0,
+		model.JobDead:    0,
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/model"
+)
+
+func enqueueJobs(t *testing.T, repos *Repositories, runAt ...time.Time) {
+	t.Helper()
+	for _, at := range runAt {
+		if err := repos.Jobs.Enqueue(&model.Job{Type: "test", MaxAttempts: 3, RunAt: at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func jobIDs(jobs []model.Job) []uint {
+	var ids []uint
+	for _, j := range jobs {
+		ids = append(ids, j.ID)
+	}
+	return ids
+}
+
+func TestJobClaim(t *testing.T) {
+	_, repos := newTestDB(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	// Job 3 is the oldest, job 4 is not due yet.
+	enqueueJobs(t, repos, now.Add(-time.Minute), now, now.Add(-time.Hour), now.Add(time.Minute))
+
+	jobs, err := repos.Jobs.Claim(now, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := jobIDs(jobs); !slices.Equal(got, []uint{3, 1}) {
+		t.Fatalf("first claim = %v, want [3 1]", got)
+	}
+	for _, j := range jobs {
+		if j.Status != model.JobRunning || j.Attempts != 1 || j.LockedAt == nil || !j.LockedAt.Equal(now) {
+			t.Errorf("claimed job %d = %s, %d attempts, locked at %v", j.ID, j.Status, j.Attempts, j.LockedAt)
+		}
+	}
+
+	jobs, err = repos.Jobs.Claim(now, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := jobIDs(jobs); !slices.Equal(got, []uint{2}) {
+		t.Errorf("second claim = %v, want [2]", got)
+	}
+
+	jobs, err = repos.Jobs.Claim(now, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 0 {
+		t.Errorf("third claim = %v, want none", jobIDs(jobs))
+	}
+}
+
+func TestJobClaimConcurrently(t *testing.T) {
+	_, repos := newTestDB(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for range 20 {
+		enqueueJobs(t, repos, now)
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		claimed []uint
+	)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				jobs, err := repos.Jobs.Claim(now, 3)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(jobs) == 0 {
+					return
+				}
+				mu.Lock()
+				claimed = append(claimed, jobIDs(jobs)...)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	slices.Sort(claimed)
+	if len(claimed) != 20 || len(slices.Compact(claimed)) != 20 {
+		t.Errorf("claimed %v, want every job exactly once", claimed)
+	}
+}
+
+func TestJobRequeueStale(t *testing.T) {
+	db, repos := newTestDB(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	enqueueJobs(t, repos, now, now)
+	if err := repos.Jobs.Enqueue(&model.Job{Type: "test", MaxAttempts: 1, RunAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Jobs 1 and 3 run since now, job 2 since ten minutes later.
+	if _, err := repos.Jobs.Claim(now, 1); err != nil {
+		t.Fatal(err)
+	}
+	later := now.Add(10 * time.Minute)
+	if _, err := repos.Jobs.Claim(later, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&model.Job{}).Where("id = 3").
+		Updates(map[string]any{"status": model.JobRunning, "locked_at": now, "attempts": 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	requeued, buried, err := repos.Jobs.RequeueStale(now.Add(5*time.Minute), later)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requeued != 1 || buried != 1 {
+		t.Errorf("requeued %d and buried %d jobs, want 1 and 1", requeued, buried)
+	}
+
+	tests := []struct {
+		id     uint
+		status model.JobStatus
+		locked bool
+	}{
+		{1, model.JobPending, false},
+		{2, model.JobRunning, true},
+		// Job 3 had no attempts left.
+		{3, model.JobDead, false},
+	}
+	for _, tt := range tests {
+		job, err := repos.Jobs.FindByID(tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != tt.status || (job.LockedAt != nil) != tt.locked {
+			t.Errorf("job %d = %s, locked at %v, want %s", tt.id, job.Status, job.LockedAt, tt.status)
+		}
+	}
+	if job, _ := repos.Jobs.FindByID(1); !job.RunAt.Equal(later) {
+		t.Errorf("requeued job runs at %v, want %v", job.RunAt, later)
+	}
+}
+
+func TestJobFinishNeedsTheClaim(t *testing.T) {
+	_, repos := newTestDB(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	enqueueJobs(t, repos, now)
+
+	first, err := repos.Jobs.Claim(now, 1)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("claim = %v, %v", first, err)
+	}
+	// The first run overruns and the job is handed out again.
+	later := now.Add(time.Hour)
+	if _, _, err := repos.Jobs.RequeueStale(later, later); err != nil {
+		t.Fatal(err)
+	}
+	second, err := repos.Jobs.Claim(later, 1)
+	if err != nil || len(second) != 1 {
+		t.Fatalf("second claim = %v, %v", second, err)
+	}
+
+	stale := *first[0].LockedAt
+	if err := repos.Jobs.Complete(1, stale, later); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Complete with a stale claim = %v, want ErrNotFound", err)
+	}
+	if err := repos.Jobs.Reschedule(1, stale, later, "boom"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Reschedule with a stale claim = %v, want ErrNotFound", err)
+	}
+	if err := repos.Jobs.Bury(1, stale, later, "boom"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Bury with a stale claim = %v, want ErrNotFound", err)
+	}
+	if err := repos.Jobs.Complete(1, *second[0].LockedAt, later); err != nil {
+		t.Errorf("Complete with the current claim = %v", err)
+	}
+	if job, _ := repos.Jobs.FindByID(1); job.Status != model.JobDone || job.Attempts != 2 {
+		t.Errorf("job = %s after %d attempts, want done after 2", job.Status, job.Attempts)
+	}
+}
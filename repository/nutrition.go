@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// nutrientColumns are the nutrient columns shared by foods and cache entries.
+var nutrientColumns = []string{
+	"calories", "protein", "fat", "saturated_fat", "carbs", "fiber", "sugar",
+	"cholesterol", "sodium", "potassium",
+}
+
+// FoodRepository stores the local food-composition table.
+type FoodRepository interface {
+	All() ([]model.Food, error)
+	// Upsert inserts the foods, replacing the nutrients of those whose name
+	// already exists.
+	Upsert(foods []model.Food) error
+	Count() (int64, error)
+}
+
+type foodRepository struct {
+	db *gorm.DB
+}
+
+func NewFoodRepository(db *gorm.DB) FoodRepository {
+	return &foodRepository{db: db}
+}
+
+func (r *foodRepository) All() ([]model.Food, error) {
+	var foods []model.Food
+	err := r.db.Order("id").Find(&foods).Error
+	return foods, err
+}
+
+func (r *foodRepository) Upsert(foods []model.Food) error {
+	if len(foods) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns(append([]string{"portion_grams", "updated_at"}, nutrientColumns...)),
+	}).CreateInBatches(foods, 500).Error
+}
+
+func (r *foodRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Food{}).Count(&count).Error
+	return count, err
+}
+
+// NutritionCacheRepository stores the nutrition looked up per ingredient.
+type NutritionCacheRepository interface {
+	// Find returns the entries for keys cached after since, by key.
+	Find(keys []string, since time.Time) (map[string]model.NutritionCacheEntry, error)
+	// Put stores the entries, replacing older ones with the same key.
+	Put(entries []model.NutritionCacheEntry) error
+	// DeleteBefore removes the entries cached before t.
+	DeleteBefore(t time.Time) (int64, error)
+}
+
+type nutritionCacheRepository struct {
+	db *gorm.DB
+}
+
+func NewNutritionCacheRepository(db *gorm.DB) NutritionCacheRepository {
+	return &nutritionCacheRepository{db: db}
+}
+
+func (r *nutritionCacheRepository) Find(keys []string, since time.Time) (map[string]model.NutritionCacheEntry, error) {
+	out := map[string]model.NutritionCacheEntry{}
+	if len(keys) == 0 {
+		return out, nil
+	}
+	var entries []model.NutritionCacheEntry
+	if err := r.db.Where("cache_key IN ? AND cached_at >= ?", keys, since).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		out[e.CacheKey] = e
+	}
+	return out, nil
+}
+
+func (r *nutritionCacheRepository) Put(entries []model.NutritionCacheEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cache_key"}},
+		DoUpdates: clause.AssignmentColumns(append([]string{"source", "food", "cached_at"}, nutrientColumns...)),
+	}).Create(&entries).Error
+}
+
+func (r *nutritionCacheRepository) DeleteBefore(t time.Time) (int64, error) {
+	result := r.db.Where("cached_at < ?", t).Delete(&model.NutritionCacheEntry{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"github.com/Abb133Se/recepieshare/model"
+	"gorm.io/gorm"
+)
+
+// PantryRepository stores the ingredients users have at home.
+type PantryRepository interface {
+	// List returns the items of a user in name order.
+	List(userID uint) ([]model.PantryItem, error)
+	// Find loads an item of the user, ErrNotFound when it belongs to
+	// someone else.
+	Find(userID, id uint) (*model.PantryItem, error)
+	// FindByKey loads the item of the user with the given name key.
+	FindByKey(userID uint, key string) (*model.PantryItem, error)
+	Create(item *model.PantryItem) error
+	Save(item *model.PantryItem) error
+	Delete(item *model.PantryItem) error
+}
+
+type pantryRepository struct {
+	db *gorm.DB
+}
+
+func NewPantryRepository(db *gorm.DB) PantryRepository {
+	return &pantryRepository{db: db}
+}
+
+func (r *pantryRepository) List(userID uint) ([]model.PantryItem, error) {
+	var items []model.PantryItem
+	err := r.db.Where("user_id = ?", userID).Order("name").Order("id").Find(&items).Error
+	return items, err
+}
+
+func (r *pantryRepository) Find(userID, id uint) (*model.PantryItem, error) {
+	var item model.PantryItem
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *pantryRepository) FindByKey(userID uint, key string) (*model.PantryItem, error) {
+	var item model.PantryItem
+	if err := r.db.Where("user_id = ? AND name_key = ?", userID, key).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *pantryRepository) Create(item *model.PantryItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r *pantryRepository) Save(item *model.PantryItem) error {
+	return r.db.Save(item).Error
+}
+
+func (r *pantryRepository) Delete(item *model.PantryItem) error {
+	return r.db.Delete(item).Error
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/utils"
+	"gorm.io/gorm"
+)
+
+type RatingWithDetails struct {
+	RatingID    uint   `json:"rating_id"`
+	Score       uint   `json:"score"`
+	UserID      uint   `json:"user_id"`
+	UserName    string `json:"user_name"`
+	RecipeID    uint   `json:"recipe_id"`
+	RecipeTitle string `json:"recipe_title"`
+}
+
+type RatingRepository interface {
+	FindByID(id uint) (*model.Rating, error)
+	FindByUserAndRecipe(userID, recipeID uint) (*model.Rating, error)
+	Create(rating *model.Rating) error
+	Save(rating *model.Rating) error
+	Delete(rating *model.Rating) error
+
+	ListForRecipe(recipeID uint) ([]model.Rating, error)
+	ListByUser(userID uint, limit, offset int) ([]model.Rating, int64, error)
+	// ListAll lists the ratings of every recipe, or only of recipes with the
+	// given status and visibility when they are not empty.
+	ListAll(status, visibility string, limit, offset int) ([]RatingWithDetails, int64, error)
+}
+
+type ratingRepository struct {
+	db *gorm.DB
+}
+
+func NewRatingRepository(db *gorm.DB) RatingRepository {
+	return &ratingRepository{db: db}
+}
+
+func (r *ratingRepository) FindByID(id uint) (*model.Rating, error) {
+	var rating model.Rating
+	if err := r.db.First(&rating, id).Error; err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+func (r *ratingRepository) FindByUserAndRecipe(userID, recipeID uint) (*model.Rating, error) {
+	var rating model.Rating
+	if err := r.db.Where("user_id = ? AND recipe_id = ?", userID, recipeID).First(&rating).Error; err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+func (r *ratingRepository) Create(rating *model.Rating) error {
+	return r.db.Create(rating).Error
+}
+
+func (r *ratingRepository) Save(rating *model.Rating) error {
+	return r.db.Save(rating).Error
+}
+
+func (r *ratingRepository) Delete(rating *model.Rating) error {
+	return r.db.Delete(rating).Error
+}
+
+func (r *ratingRepository) ListForRecipe(recipeID uint) ([]model.Rating, error) {
+	var ratings []model.Rating
+	err := r.db.Where("recipe_id = ?", recipeID).Find(&ratings).Error
+	return ratings, err
+}
+
+func (r *ratingRepository) ListByUser(userID uint, limit, offset int) ([]model.Rating, int64, error) {
+	query := r.db.Model(&model.Rating{}).Where("user_id = ?", userID)
+
+	total, err := utils.Count(query, "ratings")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var ratings []model.Rating
+	if err := utils.Paginate(query, limit, offset, &ratings); err != nil {
+		return nil, 0, err
+	}
+	return ratings, total, nil
+}
+
+func (r *ratingRepository) ListAll(status, visibility string, limit, offset int) ([]RatingWithDetails, int64, error) {
+	query := r.db.Table("ratings").
+		Select(`ratings.id as rating_id,
+                ratings.score as score,
+                users.id as user_id,
+                users.name as user_name,
+                recipes.id as recipe_id,
+                recipes.title as recipe_title`).
+		Joins("JOIN users ON ratings.user_id = users.id").
+		Joins("JOIN recipes ON ratings.recipe_id = recipes.id")
+	query = utils.ApplyRecipeFilters(query, map[string]string{"status": status, "visibility": visibility})
+
+	total, err := utils.Count(query, "ratings")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var ratings []RatingWithDetails
+	if err := utils.Paginate(query, limit, offset, &ratings); err != nil {
+		return nil, 0, err
+	}
+	return ratings, total, nil
+}
@@ -0,0 +1,455 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/utils"
+	"gorm.io/gorm"
+)
+
+type TopRatedRecipe struct {
+	RecipeID   uint    `json:"recipe_id"`
+	Title      string  `json:"title"`
+	Average    float64 `json:"average"`
+	TotalVotes int64   `json:"total_votes"`
+}
+
+type MostPopularRecipe struct {
+	RecipeID      uint   `json:"recipe_id"`
+	Title         string `json:"title"`
+	FavoriteCount int64  `json:"favorite_count"`
+}
+
+// RecipeActivity is how often a recipe was favorited and viewed.
+type RecipeActivity struct {
+	ID        uint
+	Title     string
+	Favorites int64
+	Views     int64
+}
+
+// UsageCount is how many listed recipes use a tag, category or ingredient
+// name. ID is zero for ingredient names.
+type UsageCount struct {
+	ID    uint
+	Name  string
+	Count int64
+}
+
+// IngredientKey is one ingredient of a recipe with its normalized name.
+type IngredientKey struct {
+	RecipeID uint
+	Name     string
+	NameKey  string
+}
+
+// RecipeRepository covers the recipe aggregate: the recipe itself, its
+// ingredients and steps, and its tag/category associations.
+type RecipeRepository interface {
+	// FindByID loads a recipe, preloading the named associations.
+	FindByID(id uint, preloads ...string) (*model.Recipe, error)
+	// FindByShareToken loads the recipe an unlisted share link points to.
+	FindByShareToken(token string, preloads ...string) (*model.Recipe, error)
+	Create(recipe *model.Recipe) error
+	Save(recipe *model.Recipe) error
+	// SaveNutrition persists the nutrients of the recipe and of its
+	// ingredients, leaving every other column and deleted rows alone.
+	SaveNutrition(recipe *model.Recipe) error
+	// Delete removes the recipe, its join table rows and (by cascade) its children.
+	Delete(recipe *model.Recipe) error
+	// IDs returns the IDs of every recipe.
+	IDs() ([]uint, error)
+
+	// Search applies utils.ApplyRecipeFilters and utils.ApplyRecipeSorting and
+	// returns one page of recipes together with the total match count. Pass
+	// params["status"] and params["visibility"] to restrict the result to one
+	// publication state and visibility.
+	Search(params map[string]string, sort string, limit, offset int) ([]model.Recipe, int64, error)
+	// MatchIDs narrows ids to the recipes matching the filters of Search and
+	// returns them in sort order. An empty sort keeps no particular order.
+	// When ids is nil every recipe is considered.
+	MatchIDs(ids []uint, params map[string]string, sort string) ([]uint, error)
+	// FindByIDs loads the recipes with the given IDs in the same order,
+	// skipping IDs that no longer exist.
+	FindByIDs(ids []uint, preloads ...string) ([]model.Recipe, error)
+	// Facets computes the named facets of RecipeFacets for the recipes
+	// matching params, leaving out the filter of each facet when computing
+	// it. When within is not nil only those recipes are counted.
+	Facets(names []string, params map[string]string, within []uint) (map[string]Facet, error)
+	ListByUser(userID uint, limit, offset int) ([]model.Recipe, int64, error)
+	// ListSharedWith returns the published private recipes the user was
+	// invited to.
+	ListSharedWith(userID uint, limit, offset int) ([]model.Recipe, int64, error)
+	TopRated(limit, offset int) ([]TopRatedRecipe, error)
+	MostPopular(limit, offset int) ([]MostPopularRecipe, error)
+	// ListedActivity returns the favorite and view counts of every
+	// published public recipe.
+	ListedActivity() ([]RecipeActivity, error)
+	// IngredientUsage counts the published public recipes using each
+	// ingredient name, spelled as stored.
+	IngredientUsage() ([]UsageCount, error)
+	// IngredientKeys returns the ingredients of the recipes matching the
+	// filters of Search, ordered by recipe.
+	IngredientKeys(params map[string]string) ([]IngredientKey, error)
+	// PublishDue publishes the scheduled recipes whose publish time is not
+	// after now and returns how many there were.
+	PublishDue(now time.Time) (int64, error)
+	RecordView(view *model.RecipeView) error
+
+	FindIngredient(id uint) (*model.Ingredient, error)
+	ListIngredients(recipeID uint) ([]model.Ingredient, error)
+	CreateIngredient(ingredient *model.Ingredient) error
+	DeleteIngredient(id uint) error
+	// RefreshDietaryLabels recomputes the allergens and diets of the recipe
+	// from its stored ingredients.
+	RefreshDietaryLabels(recipeID uint) error
+
+	ReplaceIngredients(recipe *model.Recipe, ingredients []model.Ingredient) error
+	ReplaceSteps(recipe *model.Recipe, steps []model.Step) error
+	ReplaceTags(recipe *model.Recipe, tags []model.Tag) error
+	ClearTags(recipe *model.Recipe) error
+	ReplaceCategories(recipe *model.Recipe, categories []model.Category) error
+	ClearCategories(recipe *model.Recipe) error
+}
+
+type recipeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecipeRepository(db *gorm.DB) RecipeRepository {
+	return &recipeRepository{db: db}
+}
+
+func (r *recipeRepository) FindByID(id uint, preloads ...string) (*model.Recipe, error) {
+	query := r.db
+	for _, p := range preloads {
+		query = query.Preload(p)
+	}
+
+	var recipe model.Recipe
+	if err := query.First(&recipe, id).Error; err != nil {
+		return nil, err
+	}
+	return &recipe, nil
+}
+
+func (r *recipeRepository) FindByShareToken(token string, preloads ...string) (*model.Recipe, error) {
+	query := r.db
+	for _, p := range preloads {
+		query = query.Preload(p)
+	}
+
+	var recipe model.Recipe
+	if err := query.Where("share_token = ?", token).First(&recipe).Error; err != nil {
+		return nil, err
+	}
+	return &recipe, nil
+}
+
+func (r *recipeRepository) Create(recipe *model.Recipe) error {
+	return r.db.Create(recipe).Error
+}
+
+func (r *recipeRepository) Save(recipe *model.Recipe) error {
+	return r.db.Save(recipe).Error
+}
+
+// SaveNutrition only writes the nutrient columns: the recipe may have been
+// edited since it was loaded, and rows deleted in the meantime stay deleted.
+func (r *recipeRepository) SaveNutrition(recipe *model.Recipe) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Recipe{}).Where("id = ?", recipe.ID).UpdateColumns(map[string]any{
+			"calories":      recipe.Calories,
+			"protein":       recipe.Protein,
+			"fat":           recipe.Fat,
+			"saturated_fat": recipe.SaturatedFat,
+			"carbs":         recipe.Carbs,
+			"fiber":         recipe.Fiber,
+			"sugar":         recipe.Sugar,
+			"cholesterol":   recipe.Cholesterol,
+			"sodium":        recipe.Sodium,
+			"potassium":     recipe.Potassium,
+		}).Error
+		if err != nil {
+			return err
+		}
+		for _, ing := range recipe.Ingredients {
+			err := tx.Model(&model.Ingredient{}).Where("id = ? AND recipe_id = ?", ing.ID, recipe.ID).UpdateColumns(map[string]any{
+				"calories":         ing.Calories,
+				"protein":          ing.Protein,
+				"fat":              ing.Fat,
+				"saturated_fat":    ing.SaturatedFat,
+				"carbs":            ing.Carbs,
+				"fiber":            ing.Fiber,
+				"sugar":            ing.Sugar,
+				"cholesterol":      ing.Cholesterol,
+				"sodium":           ing.Sodium,
+				"potassium":        ing.Potassium,
+				"nutrition_source": ing.NutritionSource,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *recipeRepository) Delete(recipe *model.Recipe) error {
+	// Clear M2M associations (join tables must be cleaned manually)
+	_ = r.db.Model(recipe).Association("Tags").Clear()
+	_ = r.db.Model(recipe).Association("Categories").Clear()
+
+	// Delete recipe (cascade takes care of children)
+	return r.db.Delete(recipe).Error
+}
+
+func (r *recipeRepository) IDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.Recipe{}).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *recipeRepository) Search(params map[string]string, sort string, limit, offset int) ([]model.Recipe, int64, error) {
+	query := r.db.Model(&model.Recipe{}).
+		Preload("Ingredients").
+		Preload("Tags").
+		Preload("Categories").
+		Preload("Steps")
+	query = utils.ApplyRecipeFilters(query, params)
+	query = utils.ApplyRecipeSorting(query, sort)
+
+	total, err := utils.Count(query, "recipes")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var recipes []model.Recipe
+	if err := utils.Paginate(query, limit, offset, &recipes); err != nil {
+		return nil, 0, err
+	}
+	return recipes, total, nil
+}
+
+func (r *recipeRepository) MatchIDs(ids []uint, params map[string]string, sort string) ([]uint, error) {
+	if ids != nil && len(ids) == 0 {
+		return nil, nil
+	}
+	query := r.db.Model(&model.Recipe{})
+	if ids != nil {
+		query = query.Where("recipes.id IN ?", ids)
+	}
+	query = utils.ApplyRecipeFilters(query, params)
+	if sort != "" {
+		query = utils.ApplyRecipeSorting(query, sort)
+	}
+
+	var matched []uint
+	err := query.Pluck("recipes.id", &matched).Error
+	return matched, err
+}
+
+func (r *recipeRepository) FindByIDs(ids []uint, preloads ...string) ([]model.Recipe, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	query := r.db
+	for _, p := range preloads {
+		query = query.Preload(p)
+	}
+
+	var found []model.Recipe
+	if err := query.Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]model.Recipe, len(found))
+	for _, recipe := range found {
+		byID[recipe.ID] = recipe
+	}
+	recipes := make([]model.Recipe, 0, len(found))
+	for _, id := range ids {
+		if recipe, ok := byID[id]; ok {
+			recipes = append(recipes, recipe)
+		}
+	}
+	return recipes, nil
+}
+
+func (r *recipeRepository) ListByUser(userID uint, limit, offset int) ([]model.Recipe, int64, error) {
+	query := r.db.Model(&model.Recipe{}).Where("user_id = ?", userID)
+
+	total, err := utils.Count(query, "recipes")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var recipes []model.Recipe
+	if err := utils.Paginate(query, limit, offset, &recipes); err != nil {
+		return nil, 0, err
+	}
+	return recipes, total, nil
+}
+
+func (r *recipeRepository) ListSharedWith(userID uint, limit, offset int) ([]model.Recipe, int64, error) {
+	query := r.db.Model(&model.Recipe{}).
+		Select("recipes.*").
+		Joins("JOIN recipe_invites ON recipe_invites.recipe_id = recipes.id").
+		Where("recipe_invites.user_id = ? AND recipes.visibility = ? AND recipes.status = ?",
+			userID, model.VisibilityPrivate, model.StatusPublished)
+
+	total, err := utils.Count(query, "recipes")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var recipes []model.Recipe
+	if err := utils.Paginate(query.Order("recipe_invites.created_at DESC"), limit, offset, &recipes); err != nil {
+		return nil, 0, err
+	}
+	return recipes, total, nil
+}
+
+func (r *recipeRepository) TopRated(limit, offset int) ([]TopRatedRecipe, error) {
+	var results []TopRatedRecipe
+	err := r.db.Table("ratings").
+		Select("recipes.id AS recipe_id, recipes.title, AVG(ratings.score) AS average, COUNT(ratings.id) AS total_votes").
+		Joins("JOIN recipes ON recipes.id = ratings.recipe_id").
+		Where("recipes.status = ? AND recipes.visibility = ?", model.StatusPublished, model.VisibilityPublic).
+		Group("recipes.id, recipes.title").
+		Order("average DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&results).Error
+	return results, err
+}
+
+func (r *recipeRepository) MostPopular(limit, offset int) ([]MostPopularRecipe, error) {
+	var results []MostPopularRecipe
+	err := r.db.Table("recipes").
+		Select("recipes.id as recipe_id, recipes.title, COUNT(favorites.id) as favorite_count").
+		Joins("LEFT JOIN favorites ON recipes.id = favorites.recipe_id").
+		Where("recipes.status = ? AND recipes.visibility = ?", model.StatusPublished, model.VisibilityPublic).
+		Group("recipes.id").
+		Order("favorite_count DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&results).Error
+	return results, err
+}
+
+func (r *recipeRepository) ListedActivity() ([]RecipeActivity, error) {
+	var activity []RecipeActivity
+	err := r.db.Model(&model.Recipe{}).
+		Select(`recipes.id, recipes.title,
+			(SELECT COUNT(*) FROM favorites f WHERE f.recipe_id = recipes.id) AS favorites,
+			(SELECT COUNT(*) FROM recipe_views v WHERE v.recipe_id = recipes.id) AS views`).
+		Scopes(listed).
+		Scan(&activity).Error
+	return activity, err
+}
+
+func (r *recipeRepository) IngredientUsage() ([]UsageCount, error) {
+	var usage []UsageCount
+	err := r.db.Model(&model.Recipe{}).
+		Joins("JOIN ingredients i ON i.recipe_id = recipes.id").
+		Select("i.name AS name, COUNT(DISTINCT recipes.id) AS count").
+		Scopes(listed).
+		Group("i.name").
+		Scan(&usage).Error
+	return usage, err
+}
+
+func (r *recipeRepository) IngredientKeys(params map[string]string) ([]IngredientKey, error) {
+	var keys []IngredientKey
+	query := r.db.Model(&model.Recipe{}).
+		Joins("JOIN ingredients pi ON pi.recipe_id = recipes.id").
+		Select("recipes.id AS recipe_id, pi.name AS name, pi.name_key AS name_key")
+	err := utils.ApplyRecipeFilters(query, params).
+		Order("recipes.id").
+		Order("pi.id").
+		Scan(&keys).Error
+	return keys, err
+}
+
+// listed restricts a recipe query to the recipes anyone may find: published
+// and public.
+func listed(db *gorm.DB) *gorm.DB {
+	return db.Where("recipes.status = ? AND recipes.visibility = ?", model.StatusPublished, model.VisibilityPublic)
+}
+
+func (r *recipeRepository) PublishDue(now time.Time) (int64, error) {
+	result := r.db.Model(&model.Recipe{}).
+		Where("status = ? AND publish_at <= ?", model.StatusScheduled, now).
+		Updates(map[string]any{
+			"status":       model.StatusPublished,
+			"published_at": gorm.Expr("publish_at"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *recipeRepository) RecordView(view *model.RecipeView) error {
+	return r.db.Create(view).Error
+}
+
+func (r *recipeRepository) FindIngredient(id uint) (*model.Ingredient, error) {
+	var ingredient model.Ingredient
+	if err := r.db.First(&ingredient, id).Error; err != nil {
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+func (r *recipeRepository) ListIngredients(recipeID uint) ([]model.Ingredient, error) {
+	var ingredients []model.Ingredient
+	err := r.db.Where("recipe_id = ?", recipeID).Find(&ingredients).Error
+	return ingredients, err
+}
+
+func (r *recipeRepository) CreateIngredient(ingredient *model.Ingredient) error {
+	return r.db.Create(ingredient).Error
+}
+
+func (r *recipeRepository) DeleteIngredient(id uint) error {
+	return r.db.Delete(&model.Ingredient{}, id).Error
+}
+
+func (r *recipeRepository) RefreshDietaryLabels(recipeID uint) error {
+	ingredients, err := r.ListIngredients(recipeID)
+	if err != nil {
+		return err
+	}
+	recipe := model.Recipe{ID: recipeID}
+	recipe.SetDietaryLabels(ingredients)
+	return r.db.Model(&recipe).Select("Allergens", "Diets").UpdateColumns(&recipe).Error
+}
+
+func (r *recipeRepository) ReplaceIngredients(recipe *model.Recipe, ingredients []model.Ingredient) error {
+	for i := range ingredients {
+		ingredients[i].RecipeID = recipe.ID
+	}
+	return r.db.Model(recipe).Association("Ingredients").Replace(ingredients)
+}
+
+func (r *recipeRepository) ReplaceSteps(recipe *model.Recipe, steps []model.Step) error {
+	for i := range steps {
+		steps[i].RecipeID = recipe.ID
+	}
+	return r.db.Model(recipe).Association("Steps").Replace(steps)
+}
+
+func (r *recipeRepository) ReplaceTags(recipe *model.Recipe, tags []model.Tag) error {
+	return r.db.Model(recipe).Association("Tags").Replace(tags)
+}
+
+func (r *recipeRepository) ClearTags(recipe *model.Recipe) error {
+	return r.db.Model(recipe).Association("Tags").Clear()
+}
+
+func (r *recipeRepository) ReplaceCategories(recipe *model.Recipe, categories []model.Category) error {
+	return r.db.Model(recipe).Association("Categories").Replace(categories)
+}
+
+func (r *recipeRepository) ClearCategories(recipe *model.Recipe) error {
+	return r.db.Model(recipe).Association("Categories").Clear()
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestApplyRecipeFilters(t *testing.T) {
+	db, repos := newTestDB(t)
+	seedRecipes(t, db)
+	all := []uint{1, 2, 3, 4, 5, 6}
+
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   []uint
+	}{
+		{"no filters", map[string]string{}, all},
+		{"status and visibility", map[string]string{"status": "published", "visibility": "public"}, []uint{1, 2, 3, 4}},
+		{"title", map[string]string{"title": "CURRY"}, []uint{2, 4}},
+		{"ingredient", map[string]string{"ingredient": "coconut"}, []uint{2, 4}},
+		{"exclude ingredients", map[string]string{"exclude_ingredients": "coconut, beef"}, []uint{1, 3, 6}},
+		{"any tag", map[string]string{"tag_ids": "2,3"}, []uint{1, 2, 4}},
+		{"all tags", map[string]string{"tag_ids": "1,3", "tag_match": "all"}, []uint{4}},
+		{"exclude tags", map[string]string{"exclude_tag_ids": "1"}, []uint{2, 3, 5}},
+		{"all categories", map[string]string{"category_ids": "1,2", "category_match": "all"}, nil},
+		{"exclude categories", map[string]string{"exclude_category_ids": "1"}, []uint{3, 6}},
+		{"author", map[string]string{"user_id": "1"}, []uint{1, 3, 5}},
+		{"exclude authors", map[string]string{"exclude_user_ids": "1"}, []uint{2, 4, 6}},
+		{"created from date", map[string]string{"created_from": "2024-03-01"}, []uint{3, 4, 5, 6}},
+		// A date without a time covers the whole day.
+		{"created to date", map[string]string{"created_to": "2024-03-01"}, []uint{1, 2, 3}},
+		{"created to time", map[string]string{"created_to": "2024-03-01T12:00:00Z"}, []uint{1, 2, 3}},
+		{"rating", map[string]string{"rating": "4"}, []uint{1, 4}},
+		{"votes", map[string]string{"min_votes": "2"}, []uint{1}},
+		{"diet", map[string]string{"diet": "vegan"}, []uint{1, 3, 4, 6}},
+		{"cuisine", map[string]string{"cuisine": "indian,italian"}, []uint{1, 2, 4}},
+		{"max time leaves out unknown times", map[string]string{"max_total_minutes": "30"}, []uint{1, 4, 6}},
+		{"min time", map[string]string{"min_total_minutes": "45"}, []uint{2, 5}},
+		{"calories", map[string]string{"max_calories": "600"}, []uint{1, 4, 6}},
+		{"calories per serving", map[string]string{"max_calories": "250", "per_serving": "true"}, []uint{1, 2, 3, 6}},
+		{"filter expression", map[string]string{"filter": "(tag:spicy OR tag:quick) -cuisine:italian"}, []uint{2, 4}},
+		{"filter and params", map[string]string{"filter": "rating:>=4", "exclude_tag_ids": "3"}, []uint{1}},
+		// Unrated recipes and unknown times fail the comparison, so NOT keeps them.
+		{"filter not rating", map[string]string{"filter": "NOT rating:>=4"}, []uint{2, 3, 5, 6}},
+		{"filter not time", map[string]string{"filter": "NOT total_minutes:<=30"}, []uint{2, 3, 5}},
+		{"filter by date", map[string]string{"filter": "created:2024-03-01"}, []uint{3}},
+	}
+	for _, tt := range tests {
+		ids, err := repos.Recipes.MatchIDs(all, tt.params, "")
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := sortedIDs(ids); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSearchCountsAndPages(t *testing.T) {
+	db, repos := newTestDB(t)
+	seedRecipes(t, db)
+
+	recipes, total, err := repos.Recipes.Search(map[string]string{"tag_ids": "1"}, "created_asc", 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || len(recipes) != 2 || recipes[0].ID != 1 || recipes[1].ID != 4 {
+		t.Errorf("Search = %d recipes of %d, want [1 4] of 3", len(recipes), total)
+	}
+	if len(recipes[0].Tags) != 2 || len(recipes[0].Ingredients) != 2 {
+		t.Errorf("Search did not preload tags and ingredients: %+v", recipes[0])
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"github.com/Abb133Se/recepieshare/internal"
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned by repository lookups that match no row.
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// Repositories bundles one repository per aggregate so they can be passed
+// around (and swapped out in tests) as a single dependency.
+type Repositories struct {
+	Recipes    RecipeRepository
+	Users      UserRepository
+	Comments   CommentRepository
+	Ratings    RatingRepository
+	Favorites  FavoriteRepository
+	Tags       TagRepository
+	Categories CategoryRepository
+	Images     ImageRepository
+	Analytics  AnalyticsRepository
+	Tokens     TokenRepository
+	Revisions  RevisionRepository
+	Invites    InviteRepository
+	Foods      FoodRepository
+	Nutrition  NutritionCacheRepository
+	Jobs       JobRepository
+	Pantry     PantryRepository
+
+	db      *gorm.DB
+	dialect internal.Dialect
+}
+
+// New wires the GORM-backed repositories around db.
+func New(db *gorm.DB, dialect internal.Dialect) *Repositories {
+	return &Repositories{
+		Recipes:    NewRecipeRepository(db),
+		Users:      NewUserRepository(db),
+		Comments:   NewCommentRepository(db),
+		Ratings:    NewRatingRepository(db),
+		Favorites:  NewFavoriteRepository(db),
+		Tags:       NewTagRepository(db),
+		Categories: NewCategoryRepository(db),
+		Images:     NewImageRepository(db),
+		Analytics:  NewAnalyticsRepository(db, dialect),
+		Tokens:     NewTokenRepository(db),
+		Revisions:  NewRevisionRepository(db),
+		Invites:    NewInviteRepository(db),
+		Foods:      NewFoodRepository(db),
+		Nutrition:  NewNutritionCacheRepository(db),
+		Jobs:       NewJobRepository(db),
+		Pantry:     NewPantryRepository(db),
+		db:         db,
+		dialect:    dialect,
+	}
+}
+
+// Transaction runs fn with repositories bound to a single database
+// transaction. The transaction is rolled back if fn returns an error.
+func (r *Repositories) Transaction(fn func(tx *Repositories) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(New(tx, r.dialect))
+	})
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/migrate"
+	"github.com/Abb133Se/recepieshare/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	// Keep the migration progress out of the test output.
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// newTestDB opens a fresh SQLite database with every migration applied.
+func newTestDB(t *testing.T) (*gorm.DB, *Repositories) {
+	t.Helper()
+	dialect, err := internal.NewDialect("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(dialect.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrate.New(db).Up(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db, New(db, dialect)
+}
+
+// day returns the start of a date in the local time zone, which GORM stores
+// timestamps in.
+func day(s string) time.Time {
+	t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// seedRecipes stores the recipes the filter and facet tests run against:
+//
+//	1 Tomato soup     alice  vegan, quick  dinner   20 min   300 kcal / 2   rated 5, 4
+//	2 Chicken curry   bob    spicy         dinner   45 min   800 kcal / 4   rated 3
+//	3 Chocolate cake  alice  -             dessert  no time  2000 kcal / 8  unrated
+//	4 Chickpea curry  bob    vegan, spicy  dinner   30 min   600 kcal / 2   rated 4
+//	5 Secret stew     alice  -             dinner   60 min   900 kcal / 3   private
+//	6 Draft salad     bob    vegan         -        10 min   200 kcal / 1   draft
+func seedRecipes(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	alice := model.User{ID: 1, Name: "Alice", Email: "alice@example.com", Role: "user"}
+	bob := model.User{ID: 2, Name: "Bob", LastName: "Baker", Email: "bob@example.com", Role: "user"}
+	vegan, quick, spicy := model.Tag{ID: 1, Name: "vegan"}, model.Tag{ID: 2, Name: "quick"}, model.Tag{ID: 3, Name: "spicy"}
+	dinner, dessert := model.Category{ID: 1, Name: "dinner"}, model.Category{ID: 2, Name: "dessert"}
+	for _, v := range []any{&alice, &bob, &vegan, &quick, &spicy, &dinner, &dessert} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ingredients := func(names ...string) []model.Ingredient {
+		var out []model.Ingredient
+		for _, n := range names {
+			out = append(out, model.Ingredient{Name: n, Amount: "1"})
+		}
+		return out
+	}
+	recipes := []model.Recipe{
+		{ID: 1, Title: "Tomato soup", UserID: 1, Tags: []model.Tag{vegan, quick}, Categories: []model.Category{dinner},
+			Ingredients: ingredients("tomatoes", "onion"), TotalMinutes: 20, Calories: 300, Servings: 2, Cuisine: "italian",
+			CreatedAt: day("2024-01-10")},
+		{ID: 2, Title: "Chicken curry", UserID: 2, Tags: []model.Tag{spicy}, Categories: []model.Category{dinner},
+			Ingredients: ingredients("chicken breast", "coconut milk"), TotalMinutes: 45, Calories: 800, Servings: 4, Cuisine: "indian",
+			CreatedAt: day("2024-02-15")},
+		{ID: 3, Title: "Chocolate cake", UserID: 1, Categories: []model.Category{dessert},
+			Ingredients: ingredients("flour", "chocolate", "hazelnuts"), Calories: 2000, Servings: 8,
+			CreatedAt: day("2024-03-01")},
+		{ID: 4, Title: "Chickpea curry", UserID: 2, Tags: []model.Tag{vegan, spicy}, Categories: []model.Category{dinner},
+			Ingredients: ingredients("chickpeas", "coconut milk"), TotalMinutes: 30, Calories: 600, Servings: 2, Cuisine: "indian",
+			CreatedAt: day("2024-03-20")},
+		{ID: 5, Title: "Secret stew", UserID: 1, Categories: []model.Category{dinner}, Visibility: model.VisibilityPrivate,
+			Ingredients: ingredients("beef", "carrots"), TotalMinutes: 60, Calories: 900, Servings: 3,
+			CreatedAt: day("2024-04-01")},
+		{ID: 6, Title: "Draft salad", UserID: 2, Tags: []model.Tag{vegan}, Status: model.StatusDraft,
+			Ingredients: ingredients("lettuce"), TotalMinutes: 10, Calories: 200, Servings: 1,
+			CreatedAt: day("2024-04-02")},
+	}
+	for i := range recipes {
+		r := &recipes[i]
+		r.Text = "Cook it."
+		r.SetDietaryLabels(r.Ingredients)
+		if err := db.Create(r).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, r := range []model.Rating{
+		{RecipeID: 1, UserID: 1, Score: 5}, {RecipeID: 1, UserID: 2, Score: 4},
+		{RecipeID: 2, UserID: 1, Score: 3},
+		{RecipeID: 4, UserID: 1, Score: 4},
+	} {
+		if err := db.Create(&r).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func sortedIDs(ids []uint) []uint {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/utils"
+	"gorm.io/gorm"
+)
+
+// RevisionRepository stores the version history of recipes.
+type RevisionRepository interface {
+	Create(rev *model.RecipeRevision) error
+	// LatestVersion returns the highest version of the recipe, 0 if it has none.
+	LatestVersion(recipeID uint) (int, error)
+	// ListByRecipe returns one page of revisions, newest first.
+	ListByRecipe(recipeID uint, limit, offset int) ([]model.RecipeRevision, int64, error)
+	FindByVersion(recipeID uint, version int) (*model.RecipeRevision, error)
+}
+
+type revisionRepository struct {
+	db *gorm.DB
+}
+
+func NewRevisionRepository(db *gorm.DB) RevisionRepository {
+	return &revisionRepository{db: db}
+}
+
+func (r *revisionRepository) Create(rev *model.RecipeRevision) error {
+	return r.db.Create(rev).Error
+}
+
+func (r *revisionRepository) LatestVersion(recipeID uint) (int, error) {
+	var version int
+	err := r.db.Model(&model.RecipeRevision{}).
+		Where("recipe_id = ?", recipeID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error
+	return version, err
+}
+
+func (r *revisionRepository) ListByRecipe(recipeID uint, limit, offset int) ([]model.RecipeRevision, int64, error) {
+	query := r.db.Model(&model.RecipeRevision{}).Where("recipe_id = ?", recipeID)
+
+	total, err := utils.Count(query, "recipe_revisions")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var revisions []model.RecipeRevision
+	if err := utils.Paginate(query.Order("version DESC"), limit, offset, &revisions); err != nil {
+		return nil, 0, err
+	}
+	return revisions, total, nil
+}
+
+func (r *revisionRepository) FindByVersion(recipeID uint, version int) (*model.RecipeRevision, error) {
+	var rev model.RecipeRevision
+	if err := r.db.Where("recipe_id = ? AND version = ?", recipeID, version).First(&rev).Error; err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"gorm.io/gorm"
+)
+
+type TagRepository interface {
+	FindByID(id uint) (*model.Tag, error)
+	FindByName(name string) (*model.Tag, error)
+	// FindByNameFold looks a tag up by name, ignoring case.
+	FindByNameFold(name string) (*model.Tag, error)
+	FindByIDs(ids []uint) ([]model.Tag, error)
+	// FindOrCreateByName returns the tag with the given name, creating it first if needed.
+	FindOrCreateByName(name string) (*model.Tag, error)
+	// List returns every tag, sorted by name_asc, name_desc, created_asc or created_desc.
+	List(sort string) ([]model.Tag, error)
+	Create(tag *model.Tag) error
+	Save(tag *model.Tag) error
+	// AttachRecipes links the tag to the given recipes, skipping unknown IDs.
+	AttachRecipes(tag *model.Tag, recipeIDs []uint) error
+	// DetachRecipes removes every recipe association of the tag.
+	DetachRecipes(tag *model.Tag) error
+	Delete(tag *model.Tag) error
+	// Usage returns every tag with the number of published public recipes
+	// carrying it.
+	Usage() ([]UsageCount, error)
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) FindByID(id uint) (*model.Tag, error) {
+	var tag model.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) FindByName(name string) (*model.Tag, error) {
+	var tag model.Tag
+	if err := r.db.Where("name = ?", name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) FindByNameFold(name string) (*model.Tag, error) {
+	var tag model.Tag
+	if err := r.db.Where("LOWER(name) = ?", strings.ToLower(name)).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) FindByIDs(ids []uint) ([]model.Tag, error) {
+	var tags []model.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&tags).Error
+	return tags, err
+}
+
+func (r *tagRepository) FindOrCreateByName(name string) (*model.Tag, error) {
+	tag, err := r.FindByName(name)
+	if err == nil {
+		return tag, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	tag = &model.Tag{Name: name}
+	if err := r.db.Create(tag).Error; err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (r *tagRepository) List(sort string) ([]model.Tag, error) {
+	query := r.db.Model(&model.Tag{})
+	switch sort {
+	case "name_desc":
+		query = query.Order("name DESC")
+	case "name_asc":
+		query = query.Order("name ASC")
+	case "created_desc":
+		query = query.Order("created_at DESC")
+	case "created_asc":
+		query = query.Order("created_at ASC")
+	}
+
+	var tags []model.Tag
+	err := query.Find(&tags).Error
+	return tags, err
+}
+
+func (r *tagRepository) Create(tag *model.Tag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *tagRepository) Save(tag *model.Tag) error {
+	return r.db.Save(tag).Error
+}
+
+func (r *tagRepository) AttachRecipes(tag *model.Tag, recipeIDs []uint) error {
+	for _, id := range recipeIDs {
+		var recipe model.Recipe
+		if err := r.db.First(&recipe, id).Error; err != nil {
+			continue
+		}
+		if err := r.db.Model(tag).Association("Recipes").Append(&recipe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *tagRepository) DetachRecipes(tag *model.Tag) error {
+	return r.db.Model(tag).Association("Recipes").Clear()
+}
+
+func (r *tagRepository) Delete(tag *model.Tag) error {
+	return r.db.Delete(tag).Error
+}
+
+func (r *tagRepository) Usage() ([]UsageCount, error) {
+	var usage []UsageCount
+	err := r.db.Model(&model.Tag{}).
+		Select("tags.id, tags.name, COUNT(recipes.id) AS count").
+		Joins("LEFT JOIN recipe_tags rt ON rt.tag_id = tags.id").
+		Joins("LEFT JOIN recipes ON recipes.id = rt.recipe_id AND recipes.status = ? AND recipes.visibility = ?", model.StatusPublished, model.VisibilityPublic).
+		Group("tags.id, tags.name").
+		Scan(&usage).Error
+	return usage, err
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepository interface {
+	CreateRefreshToken(rt *model.RefreshToken) error
+	FindRefreshTokenByHash(hash string) (*model.RefreshToken, error)
+	// RevokeRefreshToken marks a single token as used. It reports false when
+	// the token had already been revoked, which callers treat as reuse.
+	RevokeRefreshToken(id uint, replacedBy *uint, at time.Time) (bool, error)
+	RevokeFamily(familyID string, at time.Time) error
+	RevokeAllForUser(userID uint, at time.Time) error
+	RevokeAccessToken(jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	// DeleteExpired purges refresh tokens and deny-list entries that expired
+	// before the given time.
+	DeleteExpired(before time.Time) error
+}
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(rt *model.RefreshToken) error {
+	return r.db.Create(rt).Error
+}
+
+func (r *tokenRepository) FindRefreshTokenByHash(hash string) (*model.RefreshToken, error) {
+	var rt model.RefreshToken
+	if err := r.db.Where("token_hash = ?", hash).First(&rt).Error; err != nil {
+		return nil, err
+	}
+	return &rt, nil
+}
+
+func (r *tokenRepository) RevokeRefreshToken(id uint, replacedBy *uint, at time.Time) (bool, error) {
+	res := r.db.Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{"revoked_at": at, "replaced_by": replacedBy})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *tokenRepository) RevokeFamily(familyID string, at time.Time) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
+
+func (r *tokenRepository) RevokeAllForUser(userID uint, at time.Time) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+func (r *tokenRepository) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *tokenRepository) DeleteExpired(before time.Time) error {
+	if err := r.db.Where("expires_at < ?", before).Delete(&model.RefreshToken{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("expires_at < ?", before).Delete(&model.RevokedToken{}).Error
+}
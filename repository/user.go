@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"github.com/Abb133Se/recepieshare/model"
+	"gorm.io/gorm"
+)
+
+// UserStats holds computed statistics for a user
+// @Description Aggregated statistics based on the user's activity in the system.
+type UserStats struct {
+	// Total number of recipes created by this user
+	TotalRecipes int64 `json:"total_recipes" example:"12"`
+
+	// Total number of comments posted by this user
+	TotalComments int64 `json:"total_comments" example:"34"`
+
+	// Total number of times this user's recipes were favorited by others
+	TotalFavorites int64 `json:"total_favorites" example:"87"`
+
+	// Average rating across all this user's recipes (scale 1–5)
+	AverageRating float64 `json:"average_rating" example:"4.5"`
+
+	// Sum of likes across all comments posted by this user
+	TotalLikesOnComments int64 `json:"total_likes_on_comments" example:"56"`
+
+	// Average number of likes per comment posted by this user
+	AverageLikesPerComment float64 `json:"average_likes_per_comment" example:"2.3"`
+
+	// Total number of ratings this user has given to other recipes
+	TotalRatingsGiven int64 `json:"total_ratings_given" example:"19"`
+
+	// Average rating value this user gives when rating other recipes (scale 1–5)
+	AverageRatingGiven float64 `json:"average_rating_given" example:"3.8"`
+
+	// Total number of recipes this user has favorited
+	TotalFavoritesGiven int64 `json:"total_favorites_given" example:"25"`
+}
+
+// RecipeSummary provides a summary of a recipe used in profile highlights
+// @Description Minimal recipe representation used to highlight "most popular" and "highest rated" recipes.
+type RecipeSummary struct {
+	// Unique identifier of the recipe
+	ID uint `json:"id" example:"7"`
+
+	// Title of the recipe
+	Title string `json:"title" example:"Classic Pancakes"`
+
+	// Rating score for highest-rated recipe (scale 1–5); only present if relevant
+	Score float64 `json:"score,omitempty" example:"4.8"`
+
+	// Favorite count for most-popular recipe; only present if relevant
+	Count int64 `json:"count,omitempty" example:"120"`
+}
+
+type UserRepository interface {
+	FindByID(id uint) (*model.User, error)
+	FindByEmail(email string) (*model.User, error)
+	FindByResetToken(token string) (*model.User, error)
+	FindByVerificationToken(token string) (*model.User, error)
+	// FindProfile loads the user with their recipes (and the recipes'
+	// children), comments, favorites and ratings.
+	FindProfile(id uint) (*model.User, error)
+	List() ([]model.User, error)
+	Create(user *model.User) error
+	Save(user *model.User) error
+	Updates(user *model.User, fields map[string]any) error
+
+	Stats(userID uint) (*UserStats, error)
+	// MostPopularRecipe returns the user's recipe with the most favorites,
+	// or nil if the user has no recipes.
+	MostPopularRecipe(userID uint) (*RecipeSummary, error)
+	// HighestRatedRecipe returns the user's recipe with the best average
+	// rating, or nil if the user has no recipes.
+	HighestRatedRecipe(userID uint) (*RecipeSummary, error)
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) FindByID(id uint) (*model.User, error) {
+	var user model.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(email string) (*model.User, error) {
+	var user model.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByResetToken(token string) (*model.User, error) {
+	var user model.User
+	if err := r.db.Where("password_reset_token = ?", token).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByVerificationToken(token string) (*model.User, error) {
+	var user model.User
+	if err := r.db.Where("verification_token = ?", token).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindProfile(id uint) (*model.User, error) {
+	var user model.User
+	err := r.db.Preload("Recipes.Ingredients").
+		Preload("Recipes.Comments").
+		Preload("Recipes.Favorites").
+		Preload("Recipes.Ratings").
+		Preload("Recipes.Tags").
+		Preload("Recipes.Categories").
+		Preload("Recipes.Steps").
+		Preload("Comments").
+		Preload("Favorites").
+		Preload("Ratings").
+		First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) List() ([]model.User, error) {
+	var users []model.User
+	err := r.db.Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) Create(user *model.User) error {
+	return r.db.Create(user).Error
+}
+
+func (r *userRepository) Save(user *model.User) error {
+	return r.db.Save(user).Error
+}
+
+func (r *userRepository) Updates(user *model.User, fields map[string]any) error {
+	return r.db.Model(user).Updates(fields).Error
+}
+
+func (r *userRepository) Stats(userID uint) (*UserStats, error) {
+	var stats UserStats
+	err := r.db.Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM recipes r WHERE r.user_id = ?) AS total_recipes,
+			(SELECT COUNT(*) FROM comments c WHERE c.user_id = ?) AS total_comments,
+			(SELECT COUNT(*) FROM favorites f JOIN recipes r2 ON f.recipe_id = r2.id WHERE r2.user_id = ?) AS total_favorites,
+			(SELECT COALESCE(AVG(rt.score),0) FROM ratings rt JOIN recipes r3 ON rt.recipe_id = r3.id WHERE r3.user_id = ?) AS average_rating,
+			(SELECT COALESCE(SUM(c2.likes),0) FROM comments c2 WHERE c2.user_id = ?) AS total_likes_on_comments,
+			(SELECT COALESCE(AVG(c3.likes),0) FROM comments c3 WHERE c3.user_id = ?) AS average_likes_per_comment,
+			(SELECT COUNT(*) FROM ratings r4 WHERE r4.user_id = ?) AS total_ratings_given,
+			(SELECT COALESCE(AVG(r5.score),0) FROM ratings r5 WHERE r5.user_id = ?) AS average_rating_given,
+			(SELECT COUNT(*) FROM favorites f2 WHERE f2.user_id = ?) AS total_favorites_given
+	`, userID, userID, userID, userID, userID, userID, userID, userID, userID).Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (r *userRepository) MostPopularRecipe(userID uint) (*RecipeSummary, error) {
+	var summary RecipeSummary
+	err := r.db.Raw(`
+		SELECT r.id, r.title, COUNT(f.id) AS count
+		FROM recipes r
+		LEFT JOIN favorites f ON f.recipe_id = r.id
+		WHERE r.user_id = ?
+		GROUP BY r.id, r.title
+		ORDER BY COUNT(f.id) DESC
+		LIMIT 1
+	`, userID).Scan(&summary).Error
+	if err != nil || summary.ID == 0 {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+func (r *userRepository) HighestRatedRecipe(userID uint) (*RecipeSummary, error) {
+	var summary RecipeSummary
+	err := r.db.Raw(`
+		SELECT r.id, r.title, COALESCE(AVG(rt.score),0) AS score
+		FROM recipes r
+		LEFT JOIN ratings rt ON rt.recipe_id = r.id
+		WHERE r.user_id = ?
+		GROUP BY r.id, r.title
+		ORDER BY AVG(rt.score) DESC
+		LIMIT 1
+	`, userID).Scan(&summary).Error
+	if err != nil || summary.ID == 0 {
+		return nil, err
+	}
+	return &summary, nil
+}
@@ -1,158 +1,235 @@
 package routes
 
 import (
+	"time"
+
+	"github.com/Abb133Se/recepieshare/app"
 	"github.com/Abb133Se/recepieshare/controller"
 	"github.com/Abb133Se/recepieshare/middleware"
+	"github.com/Abb133Se/recepieshare/policy"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func AddRoutes(r *gin.Engine) {
+// NewRouter builds the gin engine with global middleware and all routes.
+func NewRouter(a *app.App) *gin.Engine {
+	r := gin.Default()
+
+	r.Use(middleware.SiteVisitMiddleware(a.Repos.Analytics))
+	r.Use(middleware.SetLanguage())
+
+	// for future use the specific methods and config needed
+	r.Use(cors.New(cors.Config{
+		AllowOrigins:     a.Config.Server.CORSOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		ExposeHeaders:    []string{"Content-Length"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}))
+
+	AddRoutes(r, controller.NewHandler(a), a.Repos)
+
+	return r
+}
+
+func AddRoutes(r *gin.Engine, h *controller.Handler, repos *repository.Repositories) {
+	tokens := repos.Tokens
 	public := r.Group("/")
-	public.Use(middleware.SetLanguage())
 	{
 		// Recipe read endpoints
 
-		public.GET("/recipe/list", controller.GetAllRecipesHandler)
-		public.GET("/recipe/:id/ingredients", controller.GetAllRecipeIngredientHandler)
-		public.GET("/recipe/:id/comments", controller.GetAllRecipeCommentsHandler)
-		public.GET("/recipe/:id/rating", controller.GetAverageRatingHandler)
-		public.GET("/recipe/:id/calories", controller.GetRecipeNutritionHandler)
-		public.GET("/recipes/top-rated", controller.GetTopRatedRecipesHandler)
-		public.GET("/recipes/most-popular", controller.GetMostPopularRecipesHandler)
-		public.GET("/recipes/search", controller.SearchRecipesHandler)
+		public.GET("/recipe/list", h.GetAllRecipesHandler)
+		// Recipe owners and invited users may read their non-public recipes
+		// here too, so a token is picked up when present.
+		optionalAuth := middleware.ExtractUserFromToken(tokens)
+		public.GET("/recipe/:id/ingredients", optionalAuth, h.GetAllRecipeIngredientHandler)
+		public.GET("/recipe/:id/comments", optionalAuth, h.GetAllRecipeCommentsHandler)
+		public.GET("/recipe/:id/rating", optionalAuth, h.GetAverageRatingHandler)
+		public.GET("/recipe/:id/calories", optionalAuth, h.GetRecipeNutritionHandler)
+		public.GET("/recipe/:id/nutrition-label", optionalAuth, h.GetRecipeNutritionLabelHandler)
+		public.GET("/shared/:token", optionalAuth, h.GetSharedRecipeHandler)
+		public.GET("/recipes/top-rated", h.GetTopRatedRecipesHandler)
+		public.GET("/recipes/most-popular", h.GetMostPopularRecipesHandler)
+		public.GET("/recipes/search", h.SearchRecipesHandler)
+		public.GET("/recipes/options", h.GetRecipeOptionsHandler)
+		public.GET("/suggest", h.GetSuggestHandler)
 
 		// Ingredient read endpoint
-		public.GET("/ingredient/:id", controller.GetIngredientHandler)
+		public.GET("/ingredient/:id", optionalAuth, h.GetIngredientHandler)
 
 		// Tag endpoints (read)
-		public.GET("/tag/:id", controller.GetTagHandler)
-		public.GET("/tags", controller.GetAllTagsHandler)
+		public.GET("/tag/:id", h.GetTagHandler)
+		public.GET("/tags", h.GetAllTagsHandler)
 
 		// Category endpoints (read)
-		public.GET("/category/:id", controller.GetCategoryHandler)
-		public.GET("/categories", controller.GetAllCategoriesHandler)
+		public.GET("/category/:id", h.GetCategoryHandler)
+		public.GET("/categories", h.GetAllCategoriesHandler)
 
 		// Authentication routes
-		public.POST("/signup", controller.Signup)
-		public.POST("/login", controller.Login)
-		public.POST("/forgot-password", controller.ForgotPasswordHandler)
-		public.POST("/reset-password", controller.ResetPasswordHandler)
+		public.POST("/signup", h.Signup)
+		public.POST("/login", h.Login)
+		public.POST("/token/refresh", h.RefreshTokenHandler)
+		public.POST("/verify-email", h.VerifyEmailHandler)
+		public.POST("/forgot-password", h.ForgotPasswordHandler)
+		public.POST("/reset-password", h.ResetPasswordHandler)
 
 		// Swagger routes
 		public.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
 
 	protected := r.Group("/")
-	protected.Use(middleware.AuthenticatJWT())
-	protected.Use(middleware.SetLanguage())
+	protected.Use(middleware.AuthenticatJWT(tokens))
 	{
+		// Posting content requires a verified email address
+		verified := middleware.RequireVerifiedEmail(repos.Users)
+
+		protected.POST("/logout", h.LogoutHandler)
+		protected.POST("/verify-email/resend", h.ResendVerificationHandler)
+
 		// Recipe management
-		protected.GET("/recipe/:id", middleware.ExtractUserFromToken(), controller.GetRecipeHandler)
-		protected.POST("/recipe", controller.PostRecipeHandler)
-		protected.PUT("/recipe/:id", controller.PutRecipeUpdateHandler)
-		protected.DELETE("/recipe/:id", controller.DeleteRecipeHandler)
+		protected.GET("/recipe/:id", middleware.ExtractUserFromToken(tokens), h.GetRecipeHandler)
+		protected.POST("/recipe", verified, h.PostRecipeHandler)
+		protected.PUT("/recipe/:id", verified, h.PutRecipeUpdateHandler)
+		protected.DELETE("/recipe/:id", h.DeleteRecipeHandler)
+		protected.PUT("/recipe/:id/status", verified, h.PutRecipeStatusHandler)
+
+		// Recipe visibility and sharing
+		protected.PUT("/recipe/:id/visibility", verified, h.PutRecipeVisibilityHandler)
+		protected.GET("/recipe/:id/share-link", h.GetRecipeShareLinkHandler)
+		protected.POST("/recipe/:id/share-link", h.PostRotateRecipeShareLinkHandler)
+		protected.GET("/recipe/:id/invites", h.GetRecipeInvitesHandler)
+		protected.POST("/recipe/:id/invites", verified, h.PostRecipeInviteHandler)
+		protected.DELETE("/recipe/:id/invites/:userId", h.DeleteRecipeInviteHandler)
 
 		// Recipe tags & categories management
-		protected.PUT("/recipe/:id/tags", controller.PutRecipeTagsHandler)
-		protected.DELETE("/recipe/:id/tags", controller.DeleteRecipeTagsHandler)
-		protected.GET("/recipe/:id/categories", controller.GetRecipeCategoriesHandler)
-		protected.GET("/recipe/:id/tags", controller.GetRecipeTagsHandler)
-		protected.DELETE("/recipe/:id/categories", controller.DeleteRecipeCategoriesHandler)
+		protected.PUT("/recipe/:id/tags", verified, h.PutRecipeTagsHandler)
+		protected.DELETE("/recipe/:id/tags", h.DeleteRecipeTagsHandler)
+		protected.GET("/recipe/:id/categories", h.GetRecipeCategoriesHandler)
+		protected.GET("/recipe/:id/tags", h.GetRecipeTagsHandler)
+		protected.DELETE("/recipe/:id/categories", h.DeleteRecipeCategoriesHandler)
+
+		// Recipe revisions
+		protected.GET("/recipe/:id/revisions", h.GetRecipeRevisionsHandler)
+		protected.GET("/recipe/:id/revisions/diff", h.GetRecipeRevisionDiffHandler)
+		protected.GET("/recipe/:id/revisions/:version", h.GetRecipeRevisionHandler)
+		protected.POST("/recipe/:id/revisions/:version/restore", verified, h.PostRestoreRecipeRevisionHandler)
 
 		// Ingredient management
-		protected.POST("/ingredient", controller.PostIngredientHandler)
-		protected.DELETE("/ingredient/:id", controller.DeleteIngredientHandler)
+		protected.POST("/ingredient", verified, h.PostIngredientHandler)
+		protected.DELETE("/ingredient/:id", h.DeleteIngredientHandler)
 
 		// Comment management
-		protected.POST("/comment", controller.PostCommentHandler)
-		protected.DELETE("/comment/:id", controller.DeleteCommentHandler)
-		protected.POST("/comment/:id/like", controller.PostCommentLikeIncHandler)
-		protected.POST("/comment/:id/dislike", controller.PostCommentLikeDecHandler)
+		protected.POST("/comment", verified, h.PostCommentHandler)
+		protected.DELETE("/comment/:id", h.DeleteCommentHandler)
+		protected.POST("/comment/:id/like", h.PostCommentLikeIncHandler)
+		protected.POST("/comment/:id/dislike", h.PostCommentLikeDecHandler)
 
 		// Favorite management
-		protected.POST("/favorite", controller.PostFavoriteHandler)
-		protected.DELETE("/unfavorite", controller.DeleteFavoriteHandler)
+		protected.POST("/favorite", h.PostFavoriteHandler)
+		protected.DELETE("/unfavorite", h.DeleteFavoriteHandler)
 
 		// Rating management
-		protected.POST("/rating", controller.PostRatingHandler)
-		protected.PUT("/rating/:id", controller.PutUpdateRatingHandler)
-		protected.DELETE("/rating/:id", controller.DeleteRatingHandler)
+		protected.POST("/rating", verified, h.PostRatingHandler)
+		protected.PUT("/rating/:id", verified, h.PutUpdateRatingHandler)
+		protected.DELETE("/rating/:id", h.DeleteRatingHandler)
 
 		// User-specific data
-		protected.GET("/user/recipes", controller.GetUserRecipesHandler)
-		protected.GET("/user/favorites", controller.GetUserFavoritesHandler)
-		protected.GET("/user/ratings", controller.GetUserRatingsHandler)
-		protected.GET("/user/profile", controller.GetUserProfile)
+		protected.GET("/user/recipes", h.GetUserRecipesHandler)
+		protected.GET("/user/favorites", h.GetUserFavoritesHandler)
+		protected.GET("/user/ratings", h.GetUserRatingsHandler)
+		protected.GET("/user/profile", h.GetUserProfile)
+		protected.GET("/user/shared-recipes", h.GetSharedWithMeHandler)
+		protected.PUT("/user/preferences", h.PutUserPreferencesHandler)
+
+		// Pantry
+		protected.GET("/pantry", h.GetPantryHandler)
+		protected.POST("/pantry", h.PostPantryItemHandler)
+		protected.GET("/pantry/recipes", h.GetPantryRecipesHandler)
+		protected.PUT("/pantry/:id", h.PutPantryItemHandler)
+		protected.DELETE("/pantry/:id", h.DeletePantryItemHandler)
 
 		// Tag management
-		protected.POST("/tag", controller.PostTagHandler)
-		protected.PUT("/tag/:id", controller.PutTagHandler)
-		protected.DELETE("/tag/:id", controller.DeleteTagHandler)
+		protected.POST("/tag", verified, h.PostTagHandler)
+		protected.PUT("/tag/:id", verified, middleware.Authorize(policy.Tag, policy.Update, policy.Any), h.PutTagHandler)
+		protected.DELETE("/tag/:id", middleware.Authorize(policy.Tag, policy.Delete, policy.Any), h.DeleteTagHandler)
 
 		// Category management
-		protected.POST("/category", controller.PostCategoryHandler)
-		protected.PUT("/category/:id", controller.PutCategoryHandler)
-		protected.DELETE("/category/:id", controller.DeleteCategoryHandler)
+		protected.POST("/category", verified, middleware.Authorize(policy.Category, policy.Create, policy.Any), h.PostCategoryHandler)
+		protected.PUT("/category/:id", verified, middleware.Authorize(policy.Category, policy.Update, policy.Any), h.PutCategoryHandler)
+		protected.DELETE("/category/:id", middleware.Authorize(policy.Category, policy.Delete, policy.Any), h.DeleteCategoryHandler)
 
 		// Recipe image routes
-		protected.POST("/recipe/:id/image", controller.PostUploadRecipeImageHandler)
-		protected.GET("/recipe/:id/image/:imageId", controller.GetServeRecipeImageHandler)
-		protected.DELETE("/recipe/:id/image/:imageId", controller.DeleteRecipeImageHandler)
+		protected.POST("/recipe/:id/image", verified, h.PostUploadRecipeImageHandler)
+		protected.GET("/recipe/:id/image/:imageId", h.GetServeRecipeImageHandler)
+		protected.DELETE("/recipe/:id/image/:imageId", h.DeleteRecipeImageHandler)
 
 		// User profile image routes
-		protected.POST("/user/profile-image", controller.PostUploadUserProfileImageHandler)
-		protected.GET("/user/profile-image/:imageId", controller.GetServeUserProfileImageHandler)
-		protected.DELETE("/user/profile-image/:imageId", controller.DeleteUserProfileImageHandler)
+		protected.POST("/user/profile-image", verified, h.PostUploadUserProfileImageHandler)
+		protected.GET("/user/profile-image/:imageId", h.GetServeUserProfileImageHandler)
+		protected.DELETE("/user/profile-image/:imageId", h.DeleteUserProfileImageHandler)
 
 		// Generic image routes
-		protected.GET("/image/:entity/:entityId/:imageId", controller.GetImageHandler)
+		protected.GET("/image/:entity/:entityId/:imageId", h.GetImageHandler)
 	}
 
 	admin := r.Group("/admin")
-	admin.Use(middleware.AuthenticatJWT())
-	admin.Use(middleware.AdminOnly())
-	admin.Use(middleware.SetLanguage())
+	admin.Use(middleware.AuthenticatJWT(tokens))
+	admin.Use(middleware.RequireRole(policy.RoleModerator))
 	{
 		// Users routes
-		admin.GET("/users", controller.GetAllUsersHandler)
-		admin.GET("user/:id", controller.GetUserProfile)
-		admin.GET("/user/:id/recipes", controller.GetUserRecipesHandler)
-		admin.GET("/user/:id/favorites", controller.GetUserFavoritesHandler)
-		admin.GET("/user/:id/ratings", controller.GetUserRatingsHandler)
+		readUsers := middleware.Authorize(policy.User, policy.Read, policy.Any)
+		admin.GET("/users", readUsers, h.GetAllUsersHandler)
+		admin.GET("user/:id", readUsers, h.GetUserProfile)
+		admin.GET("/user/:id/recipes", readUsers, h.GetUserRecipesHandler)
+		admin.GET("/user/:id/favorites", middleware.Authorize(policy.Favorite, policy.Read, policy.Any), h.GetUserFavoritesHandler)
+		admin.GET("/user/:id/ratings", readUsers, h.GetUserRatingsHandler)
+		admin.PUT("/user/:id/role", middleware.Authorize(policy.User, policy.AssignRole, policy.Any), h.PutUserRoleHandler)
 
 		// Recipe routes
-		admin.GET("/recipe-list", controller.GetAllRecipesHandler)
-		admin.GET("/recipe/:id", controller.GetRecipeHandler)
-		admin.PUT("/recipe/:id", controller.PutRecipeUpdateHandler)
-		admin.DELETE("/user/:userID/recipe/:id", controller.DeleteRecipeHandler)
+		readRecipes := middleware.Authorize(policy.Recipe, policy.Read, policy.Any)
+		updateRecipes := middleware.Authorize(policy.Recipe, policy.Update, policy.Any)
+		admin.GET("/recipe-list", readRecipes, h.GetAllRecipesHandler)
+		admin.GET("/recipe/:id", readRecipes, h.GetRecipeHandler)
+		admin.PUT("/recipe/:id", updateRecipes, h.PutRecipeUpdateHandler)
+		admin.PUT("/recipe/:id/status", updateRecipes, h.PutRecipeStatusHandler)
+		admin.DELETE("/user/:userID/recipe/:id", middleware.Authorize(policy.Recipe, policy.Delete, policy.Any), h.DeleteRecipeHandler)
 
 		// Category routes
-		admin.GET("/categories", controller.GetAllCategoriesHandler)
-		admin.GET("category/:id", controller.GetCategoryHandler)
-		admin.POST("/category", controller.PostCategoryHandler)
-		admin.PUT("/category/:id", controller.PutCategoryHandler)
-		admin.DELETE("/category/:id", controller.DeleteCategoryHandler)
+		readCategories := middleware.Authorize(policy.Category, policy.Read, policy.Any)
+		admin.GET("/categories", readCategories, h.GetAllCategoriesHandler)
+		admin.GET("category/:id", readCategories, h.GetCategoryHandler)
+		admin.POST("/category", middleware.Authorize(policy.Category, policy.Create, policy.Any), h.PostCategoryHandler)
+		admin.PUT("/category/:id", middleware.Authorize(policy.Category, policy.Update, policy.Any), h.PutCategoryHandler)
+		admin.DELETE("/category/:id", middleware.Authorize(policy.Category, policy.Delete, policy.Any), h.DeleteCategoryHandler)
 
 		// Comments routes
-		admin.GET("/comments", controller.GetAllComments)
-		admin.DELETE("/user/:userID/comment/:id", controller.DeleteCommentHandler)
+		admin.GET("/comments", middleware.Authorize(policy.Comment, policy.Read, policy.Any), h.GetAllComments)
+		admin.DELETE("/user/:userID/comment/:id", middleware.Authorize(policy.Comment, policy.Delete, policy.Any), h.DeleteCommentHandler)
 
 		// Tags routes
-		admin.GET("/tags", controller.GetAllTagsHandler)
-		admin.POST("/tag", controller.PostTagHandler)
-		admin.PUT("/tag/:id", controller.PutTagHandler)
-		admin.DELETE("/tag/:id", controller.DeleteTagHandler)
+		admin.GET("/tags", middleware.Authorize(policy.Tag, policy.Read, policy.Any), h.GetAllTagsHandler)
+		admin.POST("/tag", middleware.Authorize(policy.Tag, policy.Create, policy.Any), h.PostTagHandler)
+		admin.PUT("/tag/:id", middleware.Authorize(policy.Tag, policy.Update, policy.Any), h.PutTagHandler)
+		admin.DELETE("/tag/:id", middleware.Authorize(policy.Tag, policy.Delete, policy.Any), h.DeleteTagHandler)
 
 		// ratings routes
-		admin.GET("/ratings", controller.GetAllRatings)
-		admin.DELETE("/user/:userID/rating/:id", controller.DeleteRatingHandler)
+		admin.GET("/ratings", middleware.Authorize(policy.Rating, policy.Read, policy.Any), h.GetAllRatings)
+		admin.DELETE("/user/:userID/rating/:id", middleware.Authorize(policy.Rating, policy.Delete, policy.Any), h.DeleteRatingHandler)
 
 		// Favorites routes
-		admin.GET("/favorites", controller.GetAllFavorites)
-		admin.DELETE("/user/:userID/unfavorite/:id", controller.DeleteFavoriteHandler)
+		admin.GET("/favorites", middleware.Authorize(policy.Favorite, policy.Read, policy.Any), h.GetAllFavorites)
+		admin.DELETE("/user/:userID/unfavorite/:id", middleware.Authorize(policy.Favorite, policy.Delete, policy.Any), h.DeleteFavoriteHandler)
+
+		admin.GET("/analytics", middleware.Authorize(policy.Analytics, policy.Read, policy.Any), h.GetAnalytics)
 
-		admin.GET("/analytics", controller.GetAnalytics)
+		// Background jobs routes
+		readJobs := middleware.Authorize(policy.Job, policy.Read, policy.Any)
+		admin.GET("/jobs", readJobs, h.GetJobsHandler)
+		admin.GET("/jobs/:id", readJobs, h.GetJobHandler)
+		admin.POST("/jobs/:id/retry", middleware.Authorize(policy.Job, policy.Retry, policy.Any), h.PostRetryJobHandler)
 	}
 }
@@ -0,0 +1,114 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token is an analyzed word and its position in the text. Positions count
+// every word, stop words included, so phrases keep their gaps.
+type Token struct {
+	Term string
+	Pos  int
+}
+
+// Analyze splits text into normalized, stemmed terms without stop words.
+// Words in Arabic script are treated as Persian, everything else as
+// English.
+func Analyze(text string) []Token {
+	var tokens []Token
+	for pos, word := range words(text) {
+		if term, ok := analyzeWord(word); ok {
+			tokens = append(tokens, Token{Term: term, Pos: pos})
+		}
+	}
+	return tokens
+}
+
+// analyzeWord stems one normalized word, reporting false for stop words.
+func analyzeWord(word string) (string, bool) {
+	if persianStopWords[word] || englishStopWords[word] {
+		return "", false
+	}
+	return stem(word), true
+}
+
+func stem(word string) string {
+	if isPersian(word) {
+		return stemPersian(word)
+	}
+	return stemEnglish(word)
+}
+
+// words splits normalized text into words of letters and digits.
+func words(text string) []string {
+	return strings.FieldsFunc(normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// normalize lower-cases text and folds the Arabic variants of Persian
+// letters and digits, so "كتاب" and "کتاب" are the same word. Diacritics,
+// tatweels and zero-width non-joiners are dropped, so "کتاب‌ها" becomes
+// "کتابها".
+func normalize(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == 'ي' || r == 'ى':
+			r = 'ی'
+		case r == 'ك':
+			r = 'ک'
+		case r == 'ة':
+			r = 'ه'
+		case r == 'أ' || r == 'إ':
+			r = 'ا'
+		case r == 'ؤ':
+			r = 'و'
+		case r >= '۰' && r <= '۹':
+			r = '0' + r - '۰'
+		case r >= '٠' && r <= '٩':
+			r = '0' + r - '٠'
+		case r >= 0x064B && r <= 0x065F, r == 0x0670, r == 0x0640, r == 0x200C:
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func isPersian(word string) bool {
+	for _, r := range word {
+		if unicode.Is(unicode.Arabic, r) {
+			return true
+		}
+	}
+	return false
+}
+
+var englishStopWords = setOf(
+	"a", "about", "after", "all", "also", "an", "and", "any", "are", "as", "at",
+	"be", "been", "before", "but", "by", "can", "do", "for", "from", "had",
+	"has", "have", "how", "i", "if", "in", "into", "is", "it", "its", "just",
+	"me", "my", "no", "not", "of", "on", "or", "our", "out", "so", "some",
+	"such", "than", "that", "the", "their", "them", "then", "there", "these",
+	"they", "this", "to", "up", "until", "was", "we", "were", "when", "which",
+	"while", "will", "with", "you", "your",
+)
+
+var persianStopWords = setOf(
+	"و", "در", "به", "از", "که", "این", "آن", "با", "را", "برای", "تا", "یا",
+	"هم", "یک", "است", "بود", "شد", "شود", "می", "نمی", "بر", "هر", "اگر",
+	"پس", "سپس", "ما", "شما", "او", "آنها", "خود", "نیز", "باید", "کنید",
+	"کنیم", "کند", "کرد", "کرده", "شده", "دیگر", "روی", "زیر", "بعد", "قبل",
+	"تو", "من", "اما", "چون", "همه", "ای",
+)
+
+func setOf(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
@@ -0,0 +1,42 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func terms(tokens []Token) []string {
+	var out []string
+	for _, t := range tokens {
+		out = append(out, t.Term)
+	}
+	return out
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"The Best Tomatoes", []string{"best", "tomato"}},
+		{"stir-fried noodles", []string{"stir", "fri", "noodl"}},
+		{"Baking cookies", []string{"bake", "cooki"}},
+		{"كتاب", []string{"کتاب"}},
+		{"کتاب‌ها", []string{"کتاب"}},
+		{"۱۲ تخم مرغ", []string{"12", "تخم", "مرغ"}},
+		{"و در به", nil},
+	}
+	for _, tt := range tests {
+		if got := terms(Analyze(tt.text)); !slices.Equal(got, tt.want) {
+			t.Errorf("Analyze(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeKeepsPositions(t *testing.T) {
+	tokens := Analyze("salt and pepper")
+	want := []Token{{Term: "salt", Pos: 0}, {Term: "pepper", Pos: 2}}
+	if !slices.Equal(tokens, want) {
+		t.Errorf("Analyze = %v, want %v", tokens, want)
+	}
+}
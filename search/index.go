@@ -0,0 +1,55 @@
+// Package search provides full-text search over recipes: text analysis for
+// English and Persian, query parsing and a ranked index.
+package search
+
+// Field is a part of a recipe that is indexed separately, so that matches
+// in the title count more than matches in the steps.
+type Field int
+
+const (
+	Title Field = iota
+	Tags
+	Ingredients
+	Steps
+	Text
+
+	fieldCount
+)
+
+// fieldWeights scale how much a match in each field adds to the score.
+var fieldWeights = [fieldCount]float64{
+	Title:       3,
+	Tags:        2,
+	Ingredients: 2,
+	Steps:       1,
+	Text:        1,
+}
+
+// valueGap separates the values of a multi-valued field, such as the names
+// of two ingredients, so a phrase never matches across them.
+const valueGap = 100
+
+// Document is a recipe as given to the index. A field may hold several
+// values, one per ingredient, step or tag.
+type Document struct {
+	ID     uint
+	Fields map[Field][]string
+}
+
+// Hit is a matching document and its relevance score; higher is better.
+type Hit struct {
+	ID    uint
+	Score float64
+}
+
+// Index stores documents and finds those matching a query, best first.
+// Implementations are safe for concurrent use.
+type Index interface {
+	// Put adds the document or replaces the one with the same ID.
+	Put(doc Document)
+	Delete(id uint)
+	// Replace drops every document and adds docs in their place.
+	Replace(docs []Document)
+	Search(q Query) []Hit
+	Len() int
+}
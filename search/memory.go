@@ -0,0 +1,311 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BM25 parameters: k1 limits how much repeating a term raises the score, b
+// how much long fields are penalized.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// maxPrefixTerms caps how many indexed terms one prefix expands to.
+const maxPrefixTerms = 50
+
+// posting holds the positions of a term in each field of one document.
+type posting [fieldCount][]int
+
+type docEntry struct {
+	lengths [fieldCount]int
+	terms   []string
+}
+
+// MemoryIndex is an in-process inverted index ranking documents with BM25F,
+// BM25 over weighted fields.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	postings map[string]map[uint]*posting
+	docs     map[uint]*docEntry
+	totalLen [fieldCount]int
+
+	// sorted lists the indexed terms in order for prefix queries. It is
+	// rebuilt on the first prefix query after terms were added or removed.
+	sortedMu sync.Mutex
+	sorted   []string
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		postings: map[string]map[uint]*posting{},
+		docs:     map[uint]*docEntry{},
+	}
+}
+
+func (m *MemoryIndex) Put(doc Document) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(doc.ID)
+	m.add(doc)
+}
+
+func (m *MemoryIndex) Delete(id uint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(id)
+}
+
+func (m *MemoryIndex) Replace(docs []Document) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.postings = map[string]map[uint]*posting{}
+	m.docs = map[uint]*docEntry{}
+	m.totalLen = [fieldCount]int{}
+	m.sorted = nil
+	for _, doc := range docs {
+		m.remove(doc.ID)
+		m.add(doc)
+	}
+}
+
+func (m *MemoryIndex) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.docs)
+}
+
+func (m *MemoryIndex) add(doc Document) {
+	entry := &docEntry{}
+	for field, values := range doc.Fields {
+		if field < 0 || field >= fieldCount {
+			continue
+		}
+		offset := 0
+		for _, value := range values {
+			tokens := Analyze(value)
+			for _, t := range tokens {
+				docs, ok := m.postings[t.Term]
+				if !ok {
+					docs = map[uint]*posting{}
+					m.postings[t.Term] = docs
+					m.sorted = nil
+				}
+				p, ok := docs[doc.ID]
+				if !ok {
+					p = &posting{}
+					docs[doc.ID] = p
+					entry.terms = append(entry.terms, t.Term)
+				}
+				p[field] = append(p[field], offset+t.Pos)
+			}
+			entry.lengths[field] += len(tokens)
+			if len(tokens) > 0 {
+				offset += tokens[len(tokens)-1].Pos + valueGap
+			}
+		}
+		m.totalLen[field] += entry.lengths[field]
+	}
+	m.docs[doc.ID] = entry
+}
+
+func (m *MemoryIndex) remove(id uint) {
+	entry, ok := m.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range entry.terms {
+		docs := m.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(m.postings, term)
+			m.sorted = nil
+		}
+	}
+	for f := range fieldCount {
+		m.totalLen[f] -= entry.lengths[f]
+	}
+	delete(m.docs, id)
+}
+
+// Search returns the documents matching every clause of q, best first.
+// Documents with the same score are ordered newest, that is highest ID,
+// first.
+func (m *MemoryIndex) Search(q Query) []Hit {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if q.Empty() || len(m.docs) == 0 {
+		return nil
+	}
+
+	var scores map[uint]float64
+	for i, clause := range q.Clauses {
+		clauseScores := m.scoreClause(clause)
+		if i == 0 {
+			scores = clauseScores
+		} else {
+			for id := range scores {
+				s, ok := clauseScores[id]
+				if !ok {
+					delete(scores, id)
+					continue
+				}
+				scores[id] += s
+			}
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID > hits[j].ID
+	})
+	return hits
+}
+
+func (m *MemoryIndex) scoreClause(c Clause) map[uint]float64 {
+	switch {
+	case len(c.Prefixes) > 0:
+		// A prefix scores as the best of the terms it expands to, so "tom*"
+		// does not rank a recipe higher for mentioning both tomato and tomatillo.
+		scores := map[uint]float64{}
+		for _, term := range m.expand(c.Prefixes) {
+			for id, s := range m.scoreTerm(term) {
+				scores[id] = max(scores[id], s)
+			}
+		}
+		return scores
+	case len(c.Terms) > 1:
+		return m.scorePhrase(c.Terms)
+	case len(c.Terms) == 1:
+		return m.scoreTerm(c.Terms[0].Term)
+	}
+	return nil
+}
+
+func (m *MemoryIndex) scoreTerm(term string) map[uint]float64 {
+	docs := m.postings[term]
+	idf := m.idf(len(docs))
+	scores := make(map[uint]float64, len(docs))
+	for id, p := range docs {
+		var tf [fieldCount]int
+		for f := range fieldCount {
+			tf[f] = len(p[f])
+		}
+		scores[id] = m.bm25(idf, tf, m.docs[id])
+	}
+	return scores
+}
+
+// scorePhrase scores documents where the terms appear at their relative
+// positions within one field value. The phrase counts as a single term
+// whose rarity is the sum of its words'.
+func (m *MemoryIndex) scorePhrase(terms []Token) map[uint]float64 {
+	lists := make([]map[uint]*posting, len(terms))
+	idf := 0.0
+	for i, t := range terms {
+		lists[i] = m.postings[t.Term]
+		if len(lists[i]) == 0 {
+			return nil
+		}
+		idf += m.idf(len(lists[i]))
+	}
+
+	scores := map[uint]float64{}
+	for id, first := range lists[0] {
+		var tf [fieldCount]int
+		found := false
+		for f := range fieldCount {
+			for _, pos := range first[f] {
+				if phraseAt(lists, id, Field(f), terms, pos) {
+					tf[f]++
+					found = true
+				}
+			}
+		}
+		if found {
+			scores[id] = m.bm25(idf, tf, m.docs[id])
+		}
+	}
+	return scores
+}
+
+func phraseAt(lists []map[uint]*posting, id uint, f Field, terms []Token, start int) bool {
+	for i := 1; i < len(terms); i++ {
+		p, ok := lists[i][id]
+		if !ok {
+			return false
+		}
+		positions := p[f]
+		want := start + terms[i].Pos
+		j := sort.SearchInts(positions, want)
+		if j == len(positions) || positions[j] != want {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *MemoryIndex) idf(df int) float64 {
+	n := float64(len(m.docs))
+	return math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+}
+
+// bm25 combines the term frequencies of all fields, each weighted and
+// normalized by the field length, before saturating them once.
+func (m *MemoryIndex) bm25(idf float64, tf [fieldCount]int, doc *docEntry) float64 {
+	n := float64(len(m.docs))
+	weighted := 0.0
+	for f := range fieldCount {
+		if tf[f] == 0 {
+			continue
+		}
+		avg := float64(m.totalLen[f]) / n
+		norm := 1.0
+		if avg > 0 {
+			norm = 1 - bm25B + bm25B*float64(doc.lengths[f])/avg
+		}
+		weighted += fieldWeights[f] * float64(tf[f]) / norm
+	}
+	return idf * weighted / (bm25K1 + weighted)
+}
+
+// expand returns the indexed terms starting with any of the prefixes.
+func (m *MemoryIndex) expand(prefixes []string) []string {
+	m.sortedMu.Lock()
+	if m.sorted == nil {
+		m.sorted = make([]string, 0, len(m.postings))
+		for term := range m.postings {
+			m.sorted = append(m.sorted, term)
+		}
+		sort.Strings(m.sorted)
+	}
+	sorted := m.sorted
+	m.sortedMu.Unlock()
+
+	seen := map[string]bool{}
+	var terms []string
+	for _, prefix := range prefixes {
+		for i := sort.SearchStrings(sorted, prefix); i < len(sorted) && strings.HasPrefix(sorted[i], prefix); i++ {
+			if len(terms) == maxPrefixTerms {
+				return terms
+			}
+			if !seen[sorted[i]] {
+				seen[sorted[i]] = true
+				terms = append(terms, sorted[i])
+			}
+		}
+	}
+	return terms
+}
@@ -0,0 +1,84 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func testIndex() *MemoryIndex {
+	idx := NewMemoryIndex()
+	idx.Replace([]Document{
+		{ID: 1, Fields: map[Field][]string{
+			Title:       {"Tomato soup"},
+			Ingredients: {"tomatoes", "onion", "vegetable stock"},
+			Steps:       {"Simmer the tomatoes with the onion."},
+		}},
+		{ID: 2, Fields: map[Field][]string{
+			Title:       {"Chicken curry"},
+			Ingredients: {"chicken breast", "tomato paste", "coconut milk"},
+			Steps:       {"Brown the chicken.", "Add the paste and the milk."},
+		}},
+		{ID: 3, Fields: map[Field][]string{
+			Title:       {"Roast chicken"},
+			Tags:        {"sunday"},
+			Ingredients: {"whole chicken", "lemon"},
+		}},
+		{ID: 4, Fields: map[Field][]string{
+			Title:       {"Lemon tart"},
+			Ingredients: {"lemon", "butter"},
+			Steps:       {"Serve with tomato salad on the side."},
+		}},
+	})
+	return idx
+}
+
+func hitIDs(hits []Hit) []uint {
+	var ids []uint
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+	return ids
+}
+
+func TestMemoryIndexSearch(t *testing.T) {
+	tests := []struct {
+		q    string
+		want []uint
+	}{
+		// A title match outranks matches in ingredients and steps.
+		{"tomato", []uint{1, 2, 4}},
+		{"chicken", []uint{3, 2}},
+		{"chicken lemon", []uint{3}},
+		{`"tomato paste"`, []uint{2}},
+		// Phrases do not match across two ingredients.
+		{`"onion vegetable"`, nil},
+		{`"tomato soup"`, []uint{1}},
+		{"chick*", []uint{3, 2}},
+		{"coco*", []uint{2}},
+		{"sunday", []uint{3}},
+		{"pizza", nil},
+		{"the", nil},
+	}
+	idx := testIndex()
+	for _, tt := range tests {
+		if got := hitIDs(idx.Search(ParseQuery(tt.q))); !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryIndexPutDelete(t *testing.T) {
+	idx := testIndex()
+	idx.Put(Document{ID: 1, Fields: map[Field][]string{Title: {"Pea soup"}}})
+	idx.Delete(4)
+
+	if idx.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", idx.Len())
+	}
+	if got := hitIDs(idx.Search(ParseQuery("tomato"))); !slices.Equal(got, []uint{2}) {
+		t.Errorf("Search(tomato) = %v, want [2]", got)
+	}
+	if got := hitIDs(idx.Search(ParseQuery("pea"))); !slices.Equal(got, []uint{1}) {
+		t.Errorf("Search(pea) = %v, want [1]", got)
+	}
+}
@@ -0,0 +1,26 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// persianSuffixes are the plural and comparative endings stripped from
+// Persian words, longest first.
+var persianSuffixes = []string{"ترین", "های", "تر", "ها", "ان", "ات"}
+
+// stemPersian strips one plural or comparative suffix from a normalized
+// Persian word. At least three letters are kept, so short words such as
+// "نان" are left alone.
+func stemPersian(word string) string {
+	for _, suffix := range persianSuffixes {
+		if !strings.HasSuffix(word, suffix) {
+			continue
+		}
+		stem := strings.TrimSuffix(word, suffix)
+		if utf8.RuneCountInString(stem) >= 3 {
+			return stem
+		}
+	}
+	return word
+}
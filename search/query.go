@@ -0,0 +1,83 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// minPrefixLength is the shortest prefix a "word*" query expands, so "a*"
+// does not match half the index.
+const minPrefixLength = 2
+
+// Query is a parsed search. A document matches when it matches every
+// clause.
+type Query struct {
+	Clauses []Clause
+}
+
+// Clause is one part of a query: a single term, a phrase of terms at fixed
+// distances from each other, or the start of a word.
+type Clause struct {
+	// Terms holds one term, or the terms of a phrase with positions relative
+	// to its first word.
+	Terms []Token
+	// Prefixes is set instead of Terms for a prefix clause. It holds the
+	// normalized prefix and its stem, which indexed terms may start with.
+	Prefixes []string
+}
+
+// Empty reports whether the query has nothing to match, for example when it
+// only holds stop words.
+func (q Query) Empty() bool {
+	return len(q.Clauses) == 0
+}
+
+// ParseQuery parses a user query. Text in double quotes is a phrase, a word
+// ending in * matches every word starting with it, and all other words must
+// appear somewhere in the document. An unclosed quote runs to the end of the
+// query.
+func ParseQuery(q string) Query {
+	var query Query
+	for i, part := range strings.Split(q, `"`) {
+		if i%2 == 1 {
+			query.addPhrase(part)
+			continue
+		}
+		for _, field := range strings.Fields(part) {
+			query.addWords(field)
+		}
+	}
+	return query
+}
+
+func (q *Query) addPhrase(text string) {
+	tokens := Analyze(text)
+	if len(tokens) == 0 {
+		return
+	}
+	first := tokens[0].Pos
+	for i := range tokens {
+		tokens[i].Pos -= first
+	}
+	q.Clauses = append(q.Clauses, Clause{Terms: tokens})
+}
+
+// addWords adds the words of one whitespace separated field, which may hold
+// several words as in "stir-fry", as separate clauses.
+func (q *Query) addWords(field string) {
+	prefix := strings.HasSuffix(field, "*")
+	ws := words(field)
+	for i, w := range ws {
+		if prefix && i == len(ws)-1 && utf8.RuneCountInString(w) >= minPrefixLength {
+			prefixes := []string{w}
+			if s := stem(w); s != w {
+				prefixes = append(prefixes, s)
+			}
+			q.Clauses = append(q.Clauses, Clause{Prefixes: prefixes})
+			continue
+		}
+		if term, ok := analyzeWord(w); ok {
+			q.Clauses = append(q.Clauses, Clause{Terms: []Token{{Term: term}}})
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		q           string
+		clauses     int
+		phrase      bool
+		prefix      bool
+		wantEmpty   bool
+		firstPrefix []string
+	}{
+		{q: "chicken soup", clauses: 2},
+		{q: `"chicken soup" spicy`, clauses: 2, phrase: true},
+		{q: "tom*", clauses: 1, prefix: true, firstPrefix: []string{"tom"}},
+		{q: "a*", wantEmpty: true},
+		{q: "the and of", wantEmpty: true},
+		{q: `"unclosed phrase`, clauses: 1, phrase: true},
+	}
+	for _, tt := range tests {
+		q := ParseQuery(tt.q)
+		if q.Empty() != tt.wantEmpty {
+			t.Errorf("ParseQuery(%q).Empty() = %v, want %v", tt.q, q.Empty(), tt.wantEmpty)
+			continue
+		}
+		if tt.wantEmpty {
+			continue
+		}
+		if len(q.Clauses) != tt.clauses {
+			t.Errorf("ParseQuery(%q) has %d clauses, want %d", tt.q, len(q.Clauses), tt.clauses)
+			continue
+		}
+		first := q.Clauses[0]
+		if tt.phrase && len(first.Terms) < 2 {
+			t.Errorf("ParseQuery(%q) first clause %+v is not a phrase", tt.q, first)
+		}
+		if tt.prefix && !slices.Equal(first.Prefixes, tt.firstPrefix) {
+			t.Errorf("ParseQuery(%q) prefixes = %q, want %q", tt.q, first.Prefixes, tt.firstPrefix)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Match qualities of a suggestion before popularity is taken into account.
+const (
+	exactQuality  = 1.0
+	prefixQuality = 0.8
+	wordQuality   = 0.6
+	fuzzyQuality  = 0.4
+)
+
+// SuggestEntry is a text that can be suggested, such as a recipe title or a
+// tag name.
+type SuggestEntry struct {
+	Type string
+	// ID is zero for entries without an ID of their own, such as
+	// ingredient names.
+	ID   uint
+	Text string
+	// Popularity is a non-negative count such as favorites, views or uses.
+	Popularity float64
+}
+
+// Suggestion is a suggested entry with its score; higher is better.
+type Suggestion struct {
+	Type  string  `json:"type"`
+	ID    uint    `json:"id,omitempty"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+// Suggester completes partial input to known entries. Entries are kept in a
+// trie under their whole text and under every later word of it, so "sal"
+// finds "Chicken salad". When too few entries start with the input it falls
+// back to entries that start with something within a few typos of it.
+// It is safe for concurrent use.
+type Suggester struct {
+	mu   sync.RWMutex
+	root *trieNode
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	keys     []trieKey
+}
+
+// trieKey is an entry stored at the node where one of its keys ends. Whole
+// is set for the key of the whole text, as opposed to a later word.
+type trieKey struct {
+	entry *SuggestEntry
+	whole bool
+}
+
+func NewSuggester() *Suggester {
+	return &Suggester{root: &trieNode{}}
+}
+
+// Replace drops every entry and adds entries in their place.
+func (s *Suggester) Replace(entries []SuggestEntry) {
+	root := &trieNode{}
+	for i := range entries {
+		e := &entries[i]
+		ws := words(e.Text)
+		for w := range ws {
+			root.insert([]rune(strings.Join(ws[w:], " ")), trieKey{entry: e, whole: w == 0})
+		}
+	}
+
+	s.mu.Lock()
+	s.root = root
+	s.mu.Unlock()
+}
+
+func (n *trieNode) insert(key []rune, k trieKey) {
+	for _, r := range key {
+		child, ok := n.children[r]
+		if !ok {
+			if n.children == nil {
+				n.children = map[rune]*trieNode{}
+			}
+			child = &trieNode{}
+			n.children[r] = child
+		}
+		n = child
+	}
+	n.keys = append(n.keys, k)
+}
+
+// each calls fn for every key stored at n or below it.
+func (n *trieNode) each(fn func(trieKey)) {
+	for _, k := range n.keys {
+		fn(k)
+	}
+	for _, child := range n.children {
+		child.each(fn)
+	}
+}
+
+// Suggest returns up to limit entries of the given types, all types when
+// types is empty, that complete q. Each scores its match quality, from an
+// exact match down to a word of the entry starting with q, scaled by the
+// logarithm of its popularity. Fuzzy matches only fill the places left
+// after that, best first.
+func (s *Suggester) Suggest(q string, types []string, limit int) []Suggestion {
+	query := []rune(SuggestKey(q))
+	if len(query) == 0 || limit <= 0 {
+		return nil
+	}
+	wanted := func(e *SuggestEntry) bool {
+		if len(types) == 0 {
+			return true
+		}
+		for _, t := range types {
+			if e.Type == t {
+				return true
+			}
+		}
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	quality := map[*SuggestEntry]float64{}
+	if node := s.root.find(query); node != nil {
+		node.each(func(k trieKey) {
+			q := wordQuality
+			switch {
+			case k.whole && k.entry.matches(query):
+				q = exactQuality
+			case k.whole:
+				q = prefixQuality
+			}
+			if wanted(k.entry) && q > quality[k.entry] {
+				quality[k.entry] = q
+			}
+		})
+	}
+	suggestions := rank(quality, limit)
+
+	if maxEdits := allowedEdits(len(query)); len(suggestions) < limit && maxEdits > 0 {
+		fuzzy := map[*SuggestEntry]float64{}
+		row := make([]int, len(query)+1)
+		for i := range row {
+			row[i] = i
+		}
+		s.root.fuzzy(query, row, maxEdits+1, maxEdits, func(k trieKey, dist int) {
+			if _, found := quality[k.entry]; found || dist == 0 || !wanted(k.entry) {
+				return
+			}
+			if q := fuzzyQuality / float64(dist); q > fuzzy[k.entry] {
+				fuzzy[k.entry] = q
+			}
+		})
+		suggestions = append(suggestions, rank(fuzzy, limit-len(suggestions))...)
+	}
+	return suggestions
+}
+
+func (e *SuggestEntry) matches(query []rune) bool {
+	return SuggestKey(e.Text) == string(query)
+}
+
+// rank scores the entries and returns the best limit of them.
+func rank(quality map[*SuggestEntry]float64, limit int) []Suggestion {
+	suggestions := make([]Suggestion, 0, len(quality))
+	for e, q := range quality {
+		suggestions = append(suggestions, Suggestion{
+			Type:  e.Type,
+			ID:    e.ID,
+			Text:  e.Text,
+			Score: q * (1 + math.Log1p(e.Popularity)),
+		})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Text != b.Text {
+			return a.Text < b.Text
+		}
+		return a.ID < b.ID
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+func (n *trieNode) find(key []rune) *trieNode {
+	for _, r := range key {
+		n = n.children[r]
+		if n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// allowedEdits is how many typos a query of n letters may contain: none
+// below three letters, one up to five and two beyond.
+func allowedEdits(n int) int {
+	switch {
+	case n < 3:
+		return 0
+	case n <= 5:
+		return 1
+	}
+	return 2
+}
+
+// fuzzy visits the keys whose start is within maxEdits edits of query,
+// with that distance. prev is the edit distance row of the path to n and
+// best the smallest distance between query and any prefix of that path.
+func (n *trieNode) fuzzy(query []rune, prev []int, best, maxEdits int, visit func(trieKey, int)) {
+	for r, child := range n.children {
+		row := make([]int, len(prev))
+		row[0] = prev[0] + 1
+		lowest := row[0]
+		for i := 1; i < len(row); i++ {
+			cost := 1
+			if query[i-1] == r {
+				cost = 0
+			}
+			row[i] = min(row[i-1]+1, prev[i]+1, prev[i-1]+cost)
+			lowest = min(lowest, row[i])
+		}
+		dist := min(best, row[len(row)-1])
+
+		switch {
+		case lowest <= maxEdits:
+			for _, k := range child.keys {
+				if dist <= maxEdits {
+					visit(k, dist)
+				}
+			}
+			child.fuzzy(query, row, dist, maxEdits, visit)
+		case dist <= maxEdits:
+			// No longer path gets closer, but the query is already a fuzzy
+			// prefix of everything below.
+			child.each(func(k trieKey) { visit(k, dist) })
+		}
+	}
+}
+
+// SuggestKey normalizes text the way the suggester compares it, for
+// merging entries that only differ in case or spelling variants.
+func SuggestKey(text string) string {
+	return strings.Join(words(text), " ")
+}
@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"path/filepath"
-	"time"
 
 	"github.com/Abb133Se/recepieshare/internal"
 	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,22 +18,27 @@ var allowedFormats = map[string]bool{
 	"image/webp": true,
 }
 
-func UploadImage(c *gin.Context, entityType string, entityID uint, backend internal.StorageBackend) (*model.Image, error) {
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		return nil, fmt.Errorf("database connection error: %w", err)
-	}
+// ImageService stores uploaded images in a StorageBackend and keeps their
+// metadata in the images table.
+type ImageService struct {
+	repos   *repository.Repositories
+	backend internal.StorageBackend
+	clock   internal.Clock
+}
 
+func NewImageService(repos *repository.Repositories, backend internal.StorageBackend, clock internal.Clock) *ImageService {
+	return &ImageService{repos: repos, backend: backend, clock: clock}
+}
+
+func (s *ImageService) UploadImage(c *gin.Context, entityType string, entityID uint) (*model.Image, error) {
 	// 1. making sure entities exist
 	switch entityType {
 	case "user":
-		var user model.User
-		if err := db.First(&user, entityID).Error; err != nil {
+		if _, err := s.repos.Users.FindByID(entityID); err != nil {
 			return nil, errors.New("user not found")
 		}
 	case "recipe":
-		var recipe model.Recipe
-		if err := db.First(&recipe, entityID).Error; err != nil {
+		if _, err := s.repos.Recipes.FindByID(entityID); err != nil {
 			return nil, errors.New("recipe not found")
 		}
 	default:
@@ -42,10 +47,8 @@ func UploadImage(c *gin.Context, entityType string, entityID uint, backend inter
 
 	// 2. Enforce only one image for users
 	if entityType == "user" {
-		var count int64
-		if err := db.Model(&model.Image{}).
-			Where("entity_type = ? AND entity_id = ?", entityType, entityID).
-			Count(&count).Error; err != nil {
+		count, err := s.repos.Images.CountForEntity(entityType, entityID)
+		if err != nil {
 			return nil, fmt.Errorf("failed to check existing user images: %w", err)
 		}
 		if count > 0 {
@@ -91,10 +94,11 @@ func UploadImage(c *gin.Context, entityType string, entityID uint, backend inter
 		}
 	}
 
-	filename := fmt.Sprintf("%d%s", time.Now().UnixNano(), ext)
+	now := s.clock.Now()
+	filename := fmt.Sprintf("%d%s", now.UnixNano(), ext)
 	dstPath := filepath.Join(entityType, fmt.Sprintf("%d", entityID), filename)
 
-	if err := backend.Save(dstPath, file); err != nil {
+	if err := s.backend.Save(dstPath, file); err != nil {
 		return nil, fmt.Errorf("failed to save file: %v", err)
 	}
 
@@ -104,32 +108,26 @@ func UploadImage(c *gin.Context, entityType string, entityID uint, backend inter
 		Path:       dstPath,
 		Format:     contentType,
 		Size:       fileHeader.Size,
-		CreatedAt:  time.Now(),
+		CreatedAt:  now,
 	}
 
-	if err := db.Create(&img).Error; err != nil {
-		_ = backend.Delete(dstPath)
+	if err := s.repos.Images.Create(&img); err != nil {
+		_ = s.backend.Delete(dstPath)
 		return nil, err
 	}
 
 	return &img, nil
 }
 
-func ServeImage(c *gin.Context, entityType string, entityID, imageID uint, backend internal.StorageBackend) error {
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		return fmt.Errorf("database connection error: %w", err)
-	}
-
-	var img model.Image
-	err = db.Where("id = ? AND entity_type = ? AND entity_id = ?", imageID, entityType, entityID).First(&img).Error
+func (s *ImageService) ServeImage(c *gin.Context, entityType string, entityID, imageID uint) error {
+	img, err := s.repos.Images.Find(entityType, entityID, imageID)
 	if err != nil {
 		return err
 	}
 
 	c.Header("Content-Type", img.Format)
 
-	localBackend, ok := backend.(*internal.LocalStorage)
+	localBackend, ok := s.backend.(*internal.LocalStorage)
 	if ok {
 		fullPath := filepath.Join(localBackend.BasePath, img.Path)
 		c.File(fullPath)
@@ -139,37 +137,26 @@ func ServeImage(c *gin.Context, entityType string, entityID, imageID uint, backe
 	return fmt.Errorf("ServeImage not implemented for this backend")
 }
 
-func DeleteImage(entityType string, entityID, imageID uint, backend internal.StorageBackend) error {
-	db, err := internal.GetGormInstance()
-	if err != nil {
-		return fmt.Errorf("database connection error: %w", err)
-	}
-
-	var img model.Image
-	err = db.Where("id = ? AND entity_type = ? AND entity_id = ?", imageID, entityType, entityID).First(&img).Error
+func (s *ImageService) DeleteImage(entityType string, entityID, imageID uint) error {
+	img, err := s.repos.Images.Find(entityType, entityID, imageID)
 	if err != nil {
 		return err
 	}
 
-	if err := backend.Delete(img.Path); err != nil {
+	if err := s.backend.Delete(img.Path); err != nil {
 		return fmt.Errorf("failed to delete image file: %v", err)
 	}
 
-	if err := db.Delete(&img).Error; err != nil {
+	if err := s.repos.Images.Delete(img); err != nil {
 		return fmt.Errorf("failed to delete image record: %v", err)
 	}
 
 	return nil
 }
 
-func GetImageIDsForEntity(entityType string, entityID uint) ([]uint, error) {
-	db, err := internal.GetGormInstance()
+func (s *ImageService) GetImageIDsForEntity(entityType string, entityID uint) ([]uint, error) {
+	images, err := s.repos.Images.ListForEntity(entityType, entityID)
 	if err != nil {
-		return nil, fmt.Errorf("database connection error: %w", err)
-	}
-
-	var images []model.Image
-	if err := db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Find(&images).Error; err != nil {
 		return nil, err
 	}
 
@@ -181,24 +168,34 @@ func GetImageIDsForEntity(entityType string, entityID uint) ([]uint, error) {
 	return ids, nil
 }
 
-func DeleteImagesForEntity(entityType string, entityID uint, backend internal.StorageBackend) error {
-	db, err := internal.GetGormInstance()
+// GetImageIDsForEntities is the batch form of GetImageIDsForEntity, keyed by entity ID.
+func (s *ImageService) GetImageIDsForEntities(entityType string, entityIDs []uint) (map[uint][]uint, error) {
+	images, err := s.repos.Images.ListForEntities(entityType, entityIDs)
 	if err != nil {
-		return fmt.Errorf("database connection error: %w", err)
+		return nil, err
+	}
+
+	ids := make(map[uint][]uint, len(entityIDs))
+	for _, img := range images {
+		ids[img.EntityID] = append(ids[img.EntityID], img.ID)
 	}
 
-	var images []model.Image
-	if err := db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Find(&images).Error; err != nil {
+	return ids, nil
+}
+
+func (s *ImageService) DeleteImagesForEntity(entityType string, entityID uint) error {
+	images, err := s.repos.Images.ListForEntity(entityType, entityID)
+	if err != nil {
 		return err
 	}
 
 	for _, img := range images {
-		if err := backend.Delete(img.Path); err != nil {
+		if err := s.backend.Delete(img.Path); err != nil {
 			return fmt.Errorf("failed to delete image file %s: %v", img.Path, err)
 		}
 	}
 
-	if err := db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Delete(&model.Image{}).Error; err != nil {
+	if err := s.repos.Images.DeleteForEntity(entityType, entityID); err != nil {
 		return err
 	}
 
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/config"
+	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
+)
+
+// JobHandler runs one job with the payload it was enqueued with. Returning
+// an error retries the job later.
+type JobHandler func(ctx context.Context, payload []byte) error
+
+// JobQueue runs slow side effects, such as nutrition estimates, in the
+// background. Jobs are stored in the database, so they survive restarts and
+// can be enqueued in the same transaction as the change that caused them.
+// Failed jobs are retried with exponential backoff and dead-lettered after
+// the configured number of attempts. A job that runs longer than StaleAfter
+// is cancelled and handed to another worker.
+type JobQueue struct {
+	repos    *repository.Repositories
+	clock    internal.Clock
+	cfg      config.JobsConfig
+	handlers map[string]JobHandler
+	wake     chan struct{}
+	running  sync.WaitGroup
+}
+
+func NewJobQueue(repos *repository.Repositories, clock internal.Clock, cfg config.JobsConfig) *JobQueue {
+	return &JobQueue{
+		repos:    repos,
+		clock:    clock,
+		cfg:      cfg,
+		handlers: map[string]JobHandler{},
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register sets the handler of jobType. It must be called before Start.
+func (q *JobQueue) Register(jobType string, handler JobHandler) {
+	q.handlers[jobType] = handler
+}
+
+// Enqueue stores a job of jobType with payload encoded as JSON. Pass the
+// repositories of a transaction to enqueue the job only if it commits.
+func (q *JobQueue) Enqueue(repos *repository.Repositories, jobType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	job := model.Job{
+		Type:        jobType,
+		Payload:     string(data),
+		MaxAttempts: q.cfg.MaxAttempts,
+		RunAt:       q.clock.Now(),
+	}
+	if err := repos.Jobs.Enqueue(&job); err != nil {
+		return err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Retry puts a dead job back in the queue.
+func (q *JobQueue) Retry(id uint) error {
+	if err := q.repos.Jobs.Retry(id, q.clock.Now()); err != nil {
+		return err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start runs the workers until ctx is cancelled. Due jobs are picked up
+// every poll interval, or right away when a job is enqueued. Use Wait to let
+// the workers finish after cancelling ctx.
+func (q *JobQueue) Start(ctx context.Context) {
+	jobs := make(chan model.Job)
+	idle := make(chan struct{}, q.cfg.Workers)
+	q.running.Add(q.cfg.Workers + 1)
+	for range q.cfg.Workers {
+		idle <- struct{}{}
+		go func() {
+			defer q.running.Done()
+			for job := range jobs {
+				q.run(ctx, job)
+				idle <- struct{}{}
+			}
+		}()
+	}
+
+	go func() {
+		defer q.running.Done()
+		defer close(jobs)
+
+		ticker := time.NewTicker(q.cfg.PollInterval.Duration)
+		defer ticker.Stop()
+
+		for {
+			q.requeueStale()
+			q.dispatch(jobs, idle)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-q.wake:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the workers stopped after the context passed to Start
+// was cancelled. Jobs that are running when it is cancelled see their
+// context cancelled too and are retried later.
+func (q *JobQueue) Wait() {
+	q.running.Wait()
+}
+
+// dispatch claims as many due jobs as there are idle workers and hands them
+// out.
+func (q *JobQueue) dispatch(jobs chan<- model.Job, idle chan struct{}) {
+	free := 0
+count:
+	for free < cap(idle) {
+		select {
+		case <-idle:
+			free++
+		default:
+			break count
+		}
+	}
+	if free == 0 {
+		return
+	}
+
+	claimed, err := q.repos.Jobs.Claim(q.clock.Now(), free)
+	if err != nil {
+		log.Printf("failed to claim jobs: %v", err)
+	}
+	for _, job := range claimed {
+		jobs <- job
+	}
+	for range free - len(claimed) {
+		idle <- struct{}{}
+	}
+}
+
+func (q *JobQueue) requeueStale() {
+	now := q.clock.Now()
+	requeued, buried, err := q.repos.Jobs.RequeueStale(now.Add(-q.cfg.StaleAfter.Duration), now)
+	if err != nil {
+		log.Printf("failed to requeue stale jobs: %v", err)
+		return
+	}
+	if requeued > 0 {
+		log.Printf("requeued %d stale job(s)", requeued)
+	}
+	if buried > 0 {
+		log.Printf("buried %d stale job(s) without attempts left", buried)
+	}
+}
+
+func (q *JobQueue) run(ctx context.Context, job model.Job) {
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		q.fail(job, fmt.Errorf("no handler for job type %q", job.Type), true)
+		return
+	}
+
+	// Past StaleAfter the job counts as lost and may be claimed again, so
+	// the handler must give up by then.
+	ctx, cancel := context.WithTimeout(ctx, q.cfg.StaleAfter.Duration)
+	defer cancel()
+
+	if err := q.call(ctx, handler, job); err != nil {
+		q.fail(job, err, job.Attempts >= job.MaxAttempts)
+		return
+	}
+	q.record(job, q.repos.Jobs.Complete(job.ID, *job.LockedAt, q.clock.Now()))
+}
+
+// call runs handler, turning a panic into an error so one bad job cannot
+// take the server down.
+func (q *JobQueue) call(ctx context.Context, handler JobHandler, job model.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, []byte(job.Payload))
+}
+
+func (q *JobQueue) fail(job model.Job, cause error, dead bool) {
+	now := q.clock.Now()
+	if dead {
+		log.Printf("job %d (%s) failed for good after %d attempt(s): %v", job.ID, job.Type, job.Attempts, cause)
+		q.record(job, q.repos.Jobs.Bury(job.ID, *job.LockedAt, now, cause.Error()))
+		return
+	}
+	delay := q.backoff(job.Attempts)
+	log.Printf("job %d (%s) failed, retrying in %s: %v", job.ID, job.Type, delay, cause)
+	q.record(job, q.repos.Jobs.Reschedule(job.ID, *job.LockedAt, now.Add(delay), cause.Error()))
+}
+
+// record logs when the outcome of a run could not be stored.
+func (q *JobQueue) record(job model.Job, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		log.Printf("job %d (%s) was requeued as stale while running, dropping its outcome", job.ID, job.Type)
+	case err != nil:
+		log.Printf("failed to record outcome of job %d: %v", job.ID, err)
+	}
+}
+
+// backoff is the delay before retrying a job that failed attempts times:
+// the configured backoff, doubled for every further attempt, up to the
+// maximum.
+func (q *JobQueue) backoff(attempts int) time.Duration {
+	delay := q.cfg.Backoff.Duration
+	for i := 1; i < attempts && delay < q.cfg.MaxBackoff.Duration; i++ {
+		delay *= 2
+	}
+	return min(delay, q.cfg.MaxBackoff.Duration)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/config"
+)
+
+func TestJobQueueBackoff(t *testing.T) {
+	q := &JobQueue{cfg: config.JobsConfig{
+		Backoff:    config.Duration{Duration: 30 * time.Second},
+		MaxBackoff: config.Duration{Duration: 5 * time.Minute},
+	}}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{5, 5 * time.Minute},
+		{50, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := q.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
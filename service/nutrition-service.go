@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/nutrition"
+	"github.com/Abb133Se/recepieshare/repository"
+)
+
+// JobEstimateNutrition is the job type that estimates the nutrition of a
+// recipe in the background, with an EstimateNutritionPayload.
+const JobEstimateNutrition = "nutrition.estimate"
+
+type EstimateNutritionPayload struct {
+	RecipeID uint `json:"recipe_id"`
+}
+
+// NutritionReport tells how many ingredients of a recipe have nutrition
+// values. Totals of partially matched recipes only cover the matched
+// ingredients.
+type NutritionReport struct {
+	Matched   int      `json:"matched"`
+	Total     int      `json:"total"`
+	Complete  bool     `json:"complete"`
+	Unmatched []string `json:"unmatched,omitempty"`
+}
+
+// NutritionService estimates the nutrition of recipes through the
+// configured providers.
+type NutritionService struct {
+	repos    *repository.Repositories
+	provider nutrition.Provider
+}
+
+func NewNutritionService(repos *repository.Repositories, provider nutrition.Provider) *NutritionService {
+	return &NutritionService{repos: repos, provider: provider}
+}
+
+// Estimate looks up every ingredient of the recipe and fills in the
+// nutrients of those that matched, clearing the others, and the recipe
+// totals. The recipe is left untouched when the providers fail.
+func (s *NutritionService) Estimate(ctx context.Context, recipe *model.Recipe) (NutritionReport, error) {
+	queries := make([]nutrition.Query, len(recipe.Ingredients))
+	for i, ing := range recipe.Ingredients {
+		queries[i] = nutrition.QueryFor(ing)
+	}
+
+	results, err := s.provider.Lookup(ctx, queries)
+	if err != nil {
+		return NutritionReport{}, err
+	}
+
+	var total nutrition.Facts
+	for i, res := range results {
+		ing := &recipe.Ingredients[i]
+		setIngredientFacts(ing, res.Facts)
+		ing.NutritionSource = res.Source
+		total = total.Add(res.Facts)
+	}
+	setRecipeFacts(recipe, total)
+
+	return NutritionReportFor(recipe), nil
+}
+
+// EstimateJob is the JobHandler of JobEstimateNutrition. It estimates the
+// recipe as currently stored and saves the result; recipes deleted in the
+// meantime are skipped.
+func (s *NutritionService) EstimateJob(ctx context.Context, payload []byte) error {
+	var p EstimateNutritionPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+
+	recipe, err := s.repos.Recipes.FindByID(p.RecipeID, "Ingredients")
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.Estimate(ctx, recipe); err != nil {
+		return err
+	}
+	return s.repos.Recipes.SaveNutrition(recipe)
+}
+
+// NutritionReportFor reports which ingredients of the recipe have nutrition
+// values, as stored by the last estimate.
+func NutritionReportFor(recipe *model.Recipe) NutritionReport {
+	report := NutritionReport{Total: len(recipe.Ingredients)}
+	for _, ing := range recipe.Ingredients {
+		if ing.NutritionSource != "" {
+			report.Matched++
+		} else {
+			report.Unmatched = append(report.Unmatched, ing.Name)
+		}
+	}
+	report.Complete = report.Matched == report.Total
+	return report
+}
+
+// IngredientFacts returns the stored nutrients of an ingredient.
+func IngredientFacts(ing *model.Ingredient) nutrition.Facts {
+	return nutrition.Facts{
+		Calories:     ing.Calories,
+		Protein:      ing.Protein,
+		Fat:          ing.Fat,
+		SaturatedFat: ing.SaturatedFat,
+		Carbs:        ing.Carbs,
+		Fiber:        ing.Fiber,
+		Sugar:        ing.Sugar,
+		Cholesterol:  ing.Cholesterol,
+		Sodium:       ing.Sodium,
+		Potassium:    ing.Potassium,
+	}
+}
+
+// RecipeFacts returns the stored nutrient totals of a recipe.
+func RecipeFacts(recipe *model.Recipe) nutrition.Facts {
+	return nutrition.Facts{
+		Calories:     recipe.Calories,
+		Protein:      recipe.Protein,
+		Fat:          recipe.Fat,
+		SaturatedFat: recipe.SaturatedFat,
+		Carbs:        recipe.Carbs,
+		Fiber:        recipe.Fiber,
+		Sugar:        recipe.Sugar,
+		Cholesterol:  recipe.Cholesterol,
+		Sodium:       recipe.Sodium,
+		Potassium:    recipe.Potassium,
+	}
+}
+
+func setIngredientFacts(ing *model.Ingredient, f nutrition.Facts) {
+	ing.Calories, ing.Protein, ing.Fat, ing.Carbs, ing.Fiber, ing.Sugar = f.Calories, f.Protein, f.Fat, f.Carbs, f.Fiber, f.Sugar
+	ing.SaturatedFat, ing.Cholesterol, ing.Sodium, ing.Potassium = f.SaturatedFat, f.Cholesterol, f.Sodium, f.Potassium
+}
+
+func setRecipeFacts(recipe *model.Recipe, f nutrition.Facts) {
+	recipe.Calories, recipe.Protein, recipe.Fat, recipe.Carbs, recipe.Fiber, recipe.Sugar = f.Calories, f.Protein, f.Fat, f.Carbs, f.Fiber, f.Sugar
+	recipe.SaturatedFat, recipe.Cholesterol, recipe.Sodium, recipe.Potassium = f.SaturatedFat, f.Cholesterol, f.Sodium, f.Potassium
+}
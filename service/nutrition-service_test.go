@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/migrate"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/nutrition"
+	"github.com/Abb133Se/recepieshare/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	// Keep the migration progress out of the test output.
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// newTestDB opens a fresh SQLite database with every migration applied.
+func newTestDB(t *testing.T) (*gorm.DB, *repository.Repositories) {
+	t.Helper()
+	dialect, err := internal.NewDialect("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(dialect.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrate.New(db).Up(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db, repository.New(db, dialect)
+}
+
+// slowProvider matches every ingredient at 100 kcal after running during,
+// which stands in for changes made while a slow lookup is in flight.
+type slowProvider struct {
+	during func()
+}
+
+func (p slowProvider) Name() string { return "slow" }
+
+func (p slowProvider) Lookup(ctx context.Context, queries []nutrition.Query) ([]nutrition.Result, error) {
+	p.during()
+	results := make([]nutrition.Result, len(queries))
+	for i := range results {
+		results[i] = nutrition.Result{Facts: nutrition.Facts{Calories: 100}, Matched: true, Source: "slow"}
+	}
+	return results, nil
+}
+
+func TestEstimateJobKeepsConcurrentChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(db *gorm.DB) error
+		check  func(t *testing.T, db *gorm.DB)
+	}{
+		{
+			"recipe edited and an ingredient deleted",
+			func(db *gorm.DB) error {
+				err := db.Model(&model.Recipe{}).Where("id = 1").
+					Updates(map[string]any{"title": "New", "visibility": model.VisibilityPrivate}).Error
+				if err != nil {
+					return err
+				}
+				return db.Delete(&model.Ingredient{}, 2).Error
+			},
+			func(t *testing.T, db *gorm.DB) {
+				var recipe model.Recipe
+				if err := db.Preload("Ingredients").First(&recipe, 1).Error; err != nil {
+					t.Fatal(err)
+				}
+				if recipe.Title != "New" || recipe.Visibility != model.VisibilityPrivate {
+					t.Errorf("recipe = %q, %s, want the edit kept", recipe.Title, recipe.Visibility)
+				}
+				if len(recipe.Ingredients) != 1 || recipe.Ingredients[0].Calories != 100 {
+					t.Errorf("ingredients = %+v, want the remaining one estimated", recipe.Ingredients)
+				}
+			},
+		},
+		{
+			"recipe deleted",
+			func(db *gorm.DB) error {
+				return db.Select("Ingredients").Delete(&model.Recipe{ID: 1}).Error
+			},
+			func(t *testing.T, db *gorm.DB) {
+				var recipes, ingredients int64
+				db.Model(&model.Recipe{}).Count(&recipes)
+				db.Model(&model.Ingredient{}).Count(&ingredients)
+				if recipes != 0 || ingredients != 0 {
+					t.Errorf("%d recipes and %d ingredients left, want none", recipes, ingredients)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, repos := newTestDB(t)
+			if err := db.Create(&model.User{ID: 1, Name: "Alice", Email: "alice@example.com", Role: "user"}).Error; err != nil {
+				t.Fatal(err)
+			}
+			recipe := model.Recipe{ID: 1, Title: "Old", Text: "Cook it.", UserID: 1, Ingredients: []model.Ingredient{
+				{Name: "tomatoes", Amount: "2"},
+				{Name: "onion", Amount: "1"},
+			}}
+			if err := db.Create(&recipe).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			var changeErr error
+			s := NewNutritionService(repos, slowProvider{during: func() { changeErr = tt.change(db) }})
+			payload, _ := json.Marshal(EstimateNutritionPayload{RecipeID: 1})
+			if err := s.EstimateJob(context.Background(), payload); err != nil {
+				t.Fatal(err)
+			}
+			if changeErr != nil {
+				t.Fatal(changeErr)
+			}
+			tt.check(t, db)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/pantry"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/utils"
+)
+
+// PantryMatch is a recipe with how well a pantry covers it.
+type PantryMatch struct {
+	Recipe model.Recipe
+	pantry.Match
+}
+
+// PantryService finds the recipes a user can cook with what they have.
+type PantryService struct {
+	repos   *repository.Repositories
+	staples []string
+}
+
+// NewPantryService takes the staple ingredients as configured; they are
+// keyed here so they compare like ingredient names.
+func NewPantryService(repos *repository.Repositories, staples []string) *PantryService {
+	keys := make([]string, 0, len(staples))
+	for _, s := range staples {
+		keys = append(keys, pantry.Key(s))
+	}
+	return &PantryService{repos: repos, staples: keys}
+}
+
+// Match ranks the recipes matching the filters in params by the share of
+// their ingredients in the pantry of the user and returns one page of them,
+// together with the total match count. Recipes missing more than maxMissing
+// ingredients are left out unless maxMissing is negative; with
+// ignoreStaples the configured staples are not counted.
+func (s *PantryService) Match(userID uint, params map[string]string, maxMissing int, ignoreStaples bool, limit, offset int) ([]PantryMatch, int64, error) {
+	items, err := s.repos.Pantry.List(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(items) == 0 {
+		return nil, 0, nil
+	}
+	have := make([]string, len(items))
+	for i, item := range items {
+		have[i] = item.NameKey
+	}
+
+	rows, err := s.repos.Recipes.IngredientKeys(params)
+	if err != nil {
+		return nil, 0, err
+	}
+	var recipes []pantry.Recipe
+	for _, row := range rows {
+		if n := len(recipes); n == 0 || recipes[n-1].ID != row.RecipeID {
+			recipes = append(recipes, pantry.Recipe{ID: row.RecipeID})
+		}
+		r := &recipes[len(recipes)-1]
+		r.Ingredients = append(r.Ingredients, pantry.Ingredient{Name: row.Name, Key: row.NameKey})
+	}
+
+	opts := pantry.Options{MaxMissing: maxMissing}
+	if ignoreStaples {
+		opts.Staples = s.staples
+	}
+	matches := pantry.Rank(have, recipes, opts)
+
+	start, end := utils.PageBounds(len(matches), limit, offset)
+	page := matches[start:end]
+	ids := make([]uint, len(page))
+	for i, m := range page {
+		ids[i] = m.RecipeID
+	}
+	found, err := s.repos.Recipes.FindByIDs(ids, "Ingredients", "Tags", "Categories", "Steps")
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint]model.Recipe, len(found))
+	for _, r := range found {
+		byID[r.ID] = r
+	}
+
+	results := make([]PantryMatch, 0, len(page))
+	for _, m := range page {
+		if r, ok := byID[m.RecipeID]; ok {
+			results = append(results, PantryMatch{Recipe: r, Match: m})
+		}
+	}
+	return results, int64(len(matches)), nil
+}
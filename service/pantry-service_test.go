@@ -0,0 +1,48 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Abb133Se/recepieshare/model"
+)
+
+func TestPantryMatchPages(t *testing.T) {
+	db, repos := newTestDB(t)
+	if err := db.Create(&model.User{ID: 1, Name: "Alice", Email: "alice@example.com", Role: "user"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"tomatoes", "onion"} {
+		if err := db.Create(&model.PantryItem{UserID: 1, Name: name}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, title := range []string{"Tomato soup", "Onion soup"} {
+		recipe := model.Recipe{Title: title, Text: "Cook it.", UserID: 1, Ingredients: []model.Ingredient{{Name: "tomatoes", Amount: "2"}, {Name: "onion", Amount: "1"}}}
+		if err := db.Create(&recipe).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewPantryService(repos, nil)
+
+	tests := []struct {
+		limit, offset int
+		want          int
+	}{
+		{1, 1, 1},
+		{1, 2, 0},
+		{math.MaxInt, 0, 2},
+		// Large values neither overflow nor fail the request.
+		{math.MaxInt, math.MaxInt, 0},
+		{2, math.MaxInt / 2, 0},
+	}
+	for _, tt := range tests {
+		page, total, err := s.Match(1, map[string]string{}, -1, false, tt.limit, tt.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total != 2 || len(page) != tt.want {
+			t.Errorf("limit %d, offset %d: %d matches of %d, want %d of 2", tt.limit, tt.offset, len(page), total, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/repository"
+)
+
+// PublishScheduler periodically publishes scheduled recipes whose publish
+// time has come.
+type PublishScheduler struct {
+	repos     *repository.Repositories
+	clock     internal.Clock
+	interval  time.Duration
+	onPublish []func()
+}
+
+func NewPublishScheduler(repos *repository.Repositories, clock internal.Clock, interval time.Duration) *PublishScheduler {
+	return &PublishScheduler{repos: repos, clock: clock, interval: interval}
+}
+
+// OnPublish registers fn to be called after scheduled recipes were
+// published. Register before calling Start.
+func (s *PublishScheduler) OnPublish(fn func()) {
+	s.onPublish = append(s.onPublish, fn)
+}
+
+// PublishDue publishes every recipe that is due now.
+func (s *PublishScheduler) PublishDue() (int64, error) {
+	n, err := s.repos.Recipes.PublishDue(s.clock.Now())
+	if n > 0 {
+		for _, fn := range s.onPublish {
+			fn()
+		}
+	}
+	return n, err
+}
+
+// Start runs PublishDue right away and then every interval until ctx is
+// cancelled. Errors are logged and retried on the next tick.
+func (s *PublishScheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			if n, err := s.PublishDue(); err != nil {
+				log.Printf("failed to publish scheduled recipes: %v", err)
+			} else if n > 0 {
+				log.Printf("published %d scheduled recipe(s)", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
+)
+
+// ErrRevisionNotFound is returned when a recipe has no revision with the
+// requested version.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// FieldChange is one field that differs between two revisions. Collections
+// also list the items that were added and removed.
+type FieldChange struct {
+	Field   string   `json:"field"`
+	From    any      `json:"from"`
+	To      any      `json:"to"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// RevisionService records the version history of recipes, compares versions
+// and restores old ones.
+type RevisionService struct {
+	repos *repository.Repositories
+}
+
+func NewRevisionService(repos *repository.Repositories) *RevisionService {
+	return &RevisionService{repos: repos}
+}
+
+// Record snapshots the current state of the recipe as its next version. Pass
+// the transaction the change was made in as repos so both commit together.
+func (s *RevisionService) Record(repos *repository.Repositories, recipeID, userID uint, restoredFrom *int) (*model.RecipeRevision, error) {
+	recipe, err := repos.Recipes.FindByID(recipeID, "Ingredients", "Steps", "Tags", "Categories")
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := repos.Revisions.LatestVersion(recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	rev := &model.RecipeRevision{
+		RecipeID:     recipeID,
+		Version:      latest + 1,
+		UserID:       userID,
+		RestoredFrom: restoredFrom,
+		Snapshot:     recipe.Snapshot(),
+	}
+	if err := repos.Revisions.Create(rev); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// Find returns one version of the recipe.
+func (s *RevisionService) Find(recipeID uint, version int) (*model.RecipeRevision, error) {
+	rev, err := s.repos.Revisions.FindByVersion(recipeID, version)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+// Diff compares two versions of the recipe field by field.
+func (s *RevisionService) Diff(recipeID uint, from, to int) ([]FieldChange, error) {
+	a, err := s.Find(recipeID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.Find(recipeID, to)
+	if err != nil {
+		return nil, err
+	}
+	return DiffSnapshots(a.Snapshot, b.Snapshot), nil
+}
+
+// Restore writes the content of an old version back to the recipe and
+// records the result as a new version.
+func (s *RevisionService) Restore(recipeID uint, version int, userID uint) (*model.RecipeRevision, error) {
+	old, err := s.Find(recipeID, version)
+	if err != nil {
+		return nil, err
+	}
+	snap := old.Snapshot
+
+	var rev *model.RecipeRevision
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		recipe, err := tx.Recipes.FindByID(recipeID)
+		if err != nil {
+			return err
+		}
+
+		recipe.Title = snap.Title
+		recipe.Text = snap.Text
+		// Snapshots taken before servings existed leave them alone.
+		if snap.Servings > 0 {
+			recipe.Servings = snap.Servings
+			recipe.Yield = snap.Yield
+		}
+		if m := snap.Metadata; m != nil {
+			recipe.PrepMinutes, recipe.CookMinutes, recipe.TotalMinutes = m.PrepMinutes, m.CookMinutes, m.TotalMinutes
+			recipe.Difficulty, recipe.DifficultyLevel = m.Difficulty, m.DifficultyLevel
+			recipe.Cuisine, recipe.Equipment = m.Cuisine, m.Equipment
+		}
+		ingredients := make([]model.Ingredient, 0, len(snap.Ingredients))
+		for _, i := range snap.Ingredients {
+			ingredients = append(ingredients, model.Ingredient{Name: i.Name, Amount: i.Amount})
+		}
+		recipe.SetDietaryLabels(ingredients)
+		if err := tx.Recipes.Save(recipe); err != nil {
+			return err
+		}
+
+		if err := tx.Recipes.ReplaceIngredients(recipe, ingredients); err != nil {
+			return err
+		}
+
+		steps := make([]model.Step, 0, len(snap.Steps))
+		for _, st := range snap.Steps {
+			steps = append(steps, model.Step{Order: st.Order, Text: st.Text})
+		}
+		if err := tx.Recipes.ReplaceSteps(recipe, steps); err != nil {
+			return err
+		}
+
+		// Tags deleted since the snapshot are recreated by name, deleted
+		// categories are dropped as only moderators may create them.
+		tags := make([]model.Tag, 0, len(snap.Tags))
+		for _, ref := range snap.Tags {
+			tag, err := tx.Tags.FindByID(ref.ID)
+			if errors.Is(err, repository.ErrNotFound) {
+				tag, err = tx.Tags.FindOrCreateByName(ref.Name)
+			}
+			if err != nil {
+				return err
+			}
+			tags = append(tags, *tag)
+		}
+		if err := tx.Recipes.ReplaceTags(recipe, tags); err != nil {
+			return err
+		}
+
+		categoryIDs := make([]uint, 0, len(snap.Categories))
+		for _, ref := range snap.Categories {
+			categoryIDs = append(categoryIDs, ref.ID)
+		}
+		categories, err := tx.Categories.FindByIDs(categoryIDs)
+		if err != nil {
+			return err
+		}
+		if err := tx.Recipes.ReplaceCategories(recipe, categories); err != nil {
+			return err
+		}
+
+		rev, err = s.Record(tx, recipeID, userID, &version)
+		return err
+	})
+	return rev, err
+}
+
+func diffMetadata(a, b model.SnapshotMetadata) []FieldChange {
+	var changes []FieldChange
+	if a.PrepMinutes != b.PrepMinutes {
+		changes = append(changes, FieldChange{Field: "prep_minutes", From: a.PrepMinutes, To: b.PrepMinutes})
+	}
+	if a.CookMinutes != b.CookMinutes {
+		changes = append(changes, FieldChange{Field: "cook_minutes", From: a.CookMinutes, To: b.CookMinutes})
+	}
+	if a.TotalMinutes != b.TotalMinutes {
+		changes = append(changes, FieldChange{Field: "total_minutes", From: a.TotalMinutes, To: b.TotalMinutes})
+	}
+	if a.Difficulty != b.Difficulty {
+		changes = append(changes, FieldChange{Field: "difficulty", From: a.Difficulty, To: b.Difficulty})
+	}
+	if a.Cuisine != b.Cuisine {
+		changes = append(changes, FieldChange{Field: "cuisine", From: a.Cuisine, To: b.Cuisine})
+	}
+	if c, ok := diffList("equipment", a.Equipment, b.Equipment, func(s string) string { return s }); ok {
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// DiffSnapshots lists the fields that differ between a and b.
+func DiffSnapshots(a, b model.RecipeSnapshot) []FieldChange {
+	changes := []FieldChange{}
+
+	if a.Title != b.Title {
+		changes = append(changes, FieldChange{Field: "title", From: a.Title, To: b.Title})
+	}
+	if a.Text != b.Text {
+		changes = append(changes, FieldChange{Field: "text", From: a.Text, To: b.Text})
+	}
+	if a.Servings > 0 && b.Servings > 0 {
+		if a.Servings != b.Servings {
+			changes = append(changes, FieldChange{Field: "servings", From: a.Servings, To: b.Servings})
+		}
+		if a.Yield != b.Yield {
+			changes = append(changes, FieldChange{Field: "yield", From: a.Yield, To: b.Yield})
+		}
+	}
+	if a.Metadata != nil && b.Metadata != nil {
+		changes = append(changes, diffMetadata(*a.Metadata, *b.Metadata)...)
+	}
+
+	ingredient := func(i model.SnapshotIngredient) string { return fmt.Sprintf("%s (%s)", i.Name, i.Amount) }
+	if c, ok := diffList("ingredients", a.Ingredients, b.Ingredients, ingredient); ok {
+		changes = append(changes, c)
+	}
+
+	step := func(s model.SnapshotStep) string { return fmt.Sprintf("%d. %s", s.Order, s.Text) }
+	if c, ok := diffList("steps", a.Steps, b.Steps, step); ok {
+		changes = append(changes, c)
+	}
+
+	ref := func(r model.SnapshotRef) string { return r.Name }
+	if c, ok := diffList("tags", a.Tags, b.Tags, ref); ok {
+		changes = append(changes, c)
+	}
+	if c, ok := diffList("categories", a.Categories, b.Categories, ref); ok {
+		changes = append(changes, c)
+	}
+
+	return changes
+}
+
+// diffList compares two collections by the string form of their items. The
+// order matters, so reordered steps count as a change without any items
+// being added or removed.
+func diffList[T any](field string, from, to []T, str func(T) string) (FieldChange, bool) {
+	fromItems := make([]string, len(from))
+	for i, item := range from {
+		fromItems[i] = str(item)
+	}
+	toItems := make([]string, len(to))
+	for i, item := range to {
+		toItems[i] = str(item)
+	}
+
+	same := len(fromItems) == len(toItems)
+	for i := 0; same && i < len(fromItems); i++ {
+		same = fromItems[i] == toItems[i]
+	}
+	if same {
+		return FieldChange{}, false
+	}
+
+	return FieldChange{
+		Field:   field,
+		From:    from,
+		To:      to,
+		Added:   subtract(toItems, fromItems),
+		Removed: subtract(fromItems, toItems),
+	}, true
+}
+
+// subtract returns the items of a that are not in b, counting duplicates.
+func subtract(a, b []string) []string {
+	left := map[string]int{}
+	for _, item := range b {
+		left[item]++
+	}
+	var out []string
+	for _, item := range a {
+		if left[item] > 0 {
+			left[item]--
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/nutrition"
+	"github.com/Abb133Se/recepieshare/quantity"
+)
+
+// PerServing divides the nutrition totals of the recipe by its servings,
+// rounded to one decimal.
+func PerServing(recipe *model.Recipe) nutrition.Facts {
+	return RecipeFacts(recipe).Scale(1 / float64(max(recipe.Servings, 1))).Round()
+}
+
+// ServingGrams is the weight of one serving, zero unless the weight of
+// every ingredient is known.
+func ServingGrams(recipe *model.Recipe) float64 {
+	var total float64
+	for _, ing := range recipe.Ingredients {
+		if ing.Grams <= 0 {
+			return 0
+		}
+		total += ing.Grams
+	}
+	return total / float64(max(recipe.Servings, 1))
+}
+
+// ScaleRecipe rewrites the recipe in place for the given number of servings:
+// ingredient amounts are scaled and rounded for the kitchen, nutrition
+// values scaled along. It returns the factor applied and the names of the
+// ingredients whose amount has no number and was left as is.
+func ScaleRecipe(recipe *model.Recipe, servings int) (float64, []string) {
+	factor := float64(servings) / float64(max(recipe.Servings, 1))
+
+	var unscaled []string
+	for i := range recipe.Ingredients {
+		ing := &recipe.Ingredients[i]
+
+		if q, ok := ing.ParsedQuantity(); ok {
+			ing.SetQuantity(q.Scale(factor))
+		} else {
+			unscaled = append(unscaled, ing.Name)
+		}
+
+		setIngredientFacts(ing, IngredientFacts(ing).Scale(factor))
+	}
+
+	setRecipeFacts(recipe, RecipeFacts(recipe).Scale(factor))
+	recipe.Servings = servings
+
+	return factor, unscaled
+}
+
+// ConvertIngredients rewrites the ingredient amounts in the given
+// measurement system. Amounts without a number or with a count unit keep
+// their wording.
+func ConvertIngredients(ingredients []model.Ingredient, system quantity.System) {
+	if system == quantity.Original {
+		return
+	}
+	for i := range ingredients {
+		ing := &ingredients[i]
+		if q, ok := ing.ParsedQuantity(); ok {
+			ing.SetQuantity(q.Convert(system, quantity.DensityOf(ing.Name)))
+		}
+	}
+}
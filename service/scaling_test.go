@@ -0,0 +1,49 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Abb133Se/recepieshare/model"
+)
+
+func TestScaleRecipe(t *testing.T) {
+	tests := []struct {
+		servings     int
+		wantFactor   float64
+		wantAmounts  []string
+		wantCalories float64
+	}{
+		{8, 2, []string{"3 cups, sifted", "4-6", "a pinch"}, 1600},
+		{2, 0.5, []string{"3/4 cup, sifted", "1 - 1 1/2", "a pinch"}, 400},
+		{4, 1, []string{"1 1/2 cups, sifted", "2-3", "a pinch"}, 800},
+	}
+	for _, tt := range tests {
+		recipe := model.Recipe{Servings: 4, Calories: 800, Ingredients: []model.Ingredient{
+			{Name: "flour", Amount: "1 1/2 cups, sifted"},
+			{Name: "eggs", Amount: "2-3"},
+			{Name: "salt", Amount: "a pinch"},
+		}}
+		for i := range recipe.Ingredients {
+			recipe.Ingredients[i].Normalize()
+		}
+
+		factor, unscaled := ScaleRecipe(&recipe, tt.servings)
+		if factor != tt.wantFactor {
+			t.Errorf("%d servings: factor = %v, want %v", tt.servings, factor, tt.wantFactor)
+		}
+		if !slices.Equal(unscaled, []string{"salt"}) {
+			t.Errorf("%d servings: unscaled = %v, want [salt]", tt.servings, unscaled)
+		}
+		var amounts []string
+		for _, ing := range recipe.Ingredients {
+			amounts = append(amounts, ing.Amount)
+		}
+		if !slices.Equal(amounts, tt.wantAmounts) {
+			t.Errorf("%d servings: amounts = %q, want %q", tt.servings, amounts, tt.wantAmounts)
+		}
+		if recipe.Calories != tt.wantCalories || recipe.Servings != tt.servings {
+			t.Errorf("%d servings: calories %v for %d servings, want %v", tt.servings, recipe.Calories, recipe.Servings, tt.wantCalories)
+		}
+	}
+}
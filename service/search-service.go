@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/search"
+	"github.com/Abb133Se/recepieshare/utils"
+)
+
+// SortRelevance orders search results by how well they match the query.
+const SortRelevance = "relevance"
+
+const (
+	// matchBatch is the most hits that are narrowed down in the database by
+	// their IDs. Broader queries filter every recipe and keep the hits among
+	// them instead, so results and counts stay exact.
+	matchBatch = 5000
+	// maxFacetHits caps how many of the best hits facets are counted over,
+	// as the hits are passed to the database by their IDs.
+	maxFacetHits = 5000
+	rebuildBatch = 500
+)
+
+// SearchService keeps the full-text index in step with the recipes and
+// answers queries against it.
+type SearchService struct {
+	repos    *repository.Repositories
+	index    search.Index
+	interval time.Duration
+}
+
+func NewSearchService(repos *repository.Repositories, index search.Index, interval time.Duration) *SearchService {
+	return &SearchService{repos: repos, index: index, interval: interval}
+}
+
+// Rebuild reindexes every recipe from the database.
+func (s *SearchService) Rebuild() error {
+	ids, err := s.repos.Recipes.IDs()
+	if err != nil {
+		return err
+	}
+
+	docs := make([]search.Document, 0, len(ids))
+	for start := 0; start < len(ids); start += rebuildBatch {
+		batch := ids[start:min(start+rebuildBatch, len(ids))]
+		recipes, err := s.repos.Recipes.FindByIDs(batch, "Ingredients", "Steps", "Tags")
+		if err != nil {
+			return err
+		}
+		for _, r := range recipes {
+			docs = append(docs, recipeDocument(r))
+		}
+	}
+	s.index.Replace(docs)
+	return nil
+}
+
+// Refresh reindexes one recipe after it changed, or drops it from the index
+// when it no longer exists. Failures are logged rather than returned as the
+// change itself went through; the next rebuild catches up.
+func (s *SearchService) Refresh(recipeID uint) {
+	recipe, err := s.repos.Recipes.FindByID(recipeID, "Ingredients", "Steps", "Tags")
+	switch {
+	case err == nil:
+		s.index.Put(recipeDocument(*recipe))
+	case errors.Is(err, repository.ErrNotFound):
+		s.index.Delete(recipeID)
+	default:
+		log.Printf("failed to index recipe %d: %v", recipeID, err)
+	}
+}
+
+// Find returns one page of the recipes matching q and the filters in params,
+// together with the total match count. Sort takes the values of
+// utils.ApplyRecipeSorting, or SortRelevance or "" for the best matches
+// first.
+func (s *SearchService) Find(q search.Query, params map[string]string, sort string, limit, offset int) ([]model.Recipe, int64, error) {
+	if sort == SortRelevance {
+		sort = ""
+	}
+	matched, err := s.match(s.hitIDs(q), params, sort)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	start, end := utils.PageBounds(len(matched), limit, offset)
+	recipes, err := s.repos.Recipes.FindByIDs(matched[start:end], "Ingredients", "Tags", "Categories", "Steps")
+	if err != nil {
+		return nil, 0, err
+	}
+	return recipes, int64(len(matched)), nil
+}
+
+// match narrows hits, best first, to the recipes matching params and returns
+// them in sort order, or in the order of hits for an empty sort.
+func (s *SearchService) match(hits []uint, params map[string]string, sort string) ([]uint, error) {
+	ids := hits
+	if len(hits) > matchBatch {
+		ids = nil
+	}
+	matched, err := s.repos.Recipes.MatchIDs(ids, params, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case sort == "":
+		return intersect(hits, matched), nil
+	case ids == nil:
+		return intersect(matched, hits), nil
+	}
+	return matched, nil
+}
+
+// intersect returns the IDs of order that are also in other, keeping their
+// order.
+func intersect(order, other []uint) []uint {
+	keep := make(map[uint]bool, len(other))
+	for _, id := range other {
+		keep[id] = true
+	}
+	result := make([]uint, 0, min(len(order), len(other)))
+	for _, id := range order {
+		if keep[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// Facets computes the named facets of repository.RecipeFacets for the
+// recipes matching q and the filters in params. Only the maxFacetHits best
+// matches of q are counted; truncated reports whether q had more.
+func (s *SearchService) Facets(q search.Query, params map[string]string, names []string) (facets map[string]repository.Facet, truncated bool, err error) {
+	hits := s.hitIDs(q)
+	if len(hits) > maxFacetHits {
+		hits, truncated = hits[:maxFacetHits], true
+	}
+	facets, err = s.repos.Recipes.Facets(names, params, hits)
+	return facets, truncated, err
+}
+
+// hitIDs returns the IDs of every match of q, best first. The slice is never
+// nil, so an empty one still restricts queries to no recipes.
+func (s *SearchService) hitIDs(q search.Query) []uint {
+	hits := s.index.Search(q)
+	ids := make([]uint, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	return ids
+}
+
+// Start rebuilds the index every interval until ctx is cancelled, picking up
+// changes that did not go through this server. A zero interval disables it.
+func (s *SearchService) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := s.Rebuild(); err != nil {
+				log.Printf("failed to rebuild search index: %v", err)
+			}
+		}
+	}()
+}
+
+func recipeDocument(r model.Recipe) search.Document {
+	doc := search.Document{
+		ID: r.ID,
+		Fields: map[search.Field][]string{
+			search.Title: {r.Title},
+			search.Text:  {r.Text},
+		},
+	}
+	for _, i := range r.Ingredients {
+		doc.Fields[search.Ingredients] = append(doc.Fields[search.Ingredients], i.Name)
+	}
+	for _, st := range r.Steps {
+		doc.Fields[search.Steps] = append(doc.Fields[search.Steps], st.Text)
+	}
+	for _, t := range r.Tags {
+		doc.Fields[search.Tags] = append(doc.Fields[search.Tags], t.Name)
+	}
+	return doc
+}
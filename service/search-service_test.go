@@ -0,0 +1,142 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/search"
+)
+
+// evenRecipes stands in for the database: recipes 1 to n exist and only the
+// even ones match the filters. Any sort order lists them by ID.
+type evenRecipes struct {
+	repository.RecipeRepository
+	n int
+	// lists records the length of every ID list passed to MatchIDs.
+	lists []int
+}
+
+func (r *evenRecipes) MatchIDs(ids []uint, params map[string]string, sort string) ([]uint, error) {
+	r.lists = append(r.lists, len(ids))
+	if ids == nil {
+		for id := 1; id <= r.n; id++ {
+			ids = append(ids, uint(id))
+		}
+	}
+	var matched []uint
+	for _, id := range ids {
+		if id%2 == 0 {
+			matched = append(matched, id)
+		}
+	}
+	if sort != "" {
+		slices.Sort(matched)
+	}
+	return matched, nil
+}
+
+func (r *evenRecipes) FindByIDs(ids []uint, preloads ...string) ([]model.Recipe, error) {
+	recipes := make([]model.Recipe, len(ids))
+	for i, id := range ids {
+		recipes[i] = model.Recipe{ID: id}
+	}
+	return recipes, nil
+}
+
+func recipeIDs(recipes []model.Recipe) []uint {
+	var ids []uint
+	for _, r := range recipes {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestSearchFind(t *testing.T) {
+	tests := []struct {
+		name      string
+		n         int
+		sort      string
+		wantCount int64
+		wantPage  []uint
+		// wantList is the length of the ID list MatchIDs gets, 0 for nil.
+		wantList int
+	}{
+		{"few hits by relevance", 10, SortRelevance, 5, []uint{10, 8, 6}, 10},
+		{"few hits sorted", 10, "title_asc", 5, []uint{2, 4, 6}, 10},
+		{"many hits by relevance", matchBatch + 1000, "", matchBatch/2 + 500, []uint{matchBatch + 1000, matchBatch + 998, matchBatch + 996}, 0},
+		{"many hits sorted", matchBatch + 1000, "title_asc", matchBatch/2 + 500, []uint{2, 4, 6}, 0},
+	}
+	for _, tt := range tests {
+		// Every recipe matches equally well, so the latest ones rank first.
+		index := search.NewMemoryIndex()
+		docs := make([]search.Document, tt.n)
+		for i := range docs {
+			docs[i] = search.Document{ID: uint(i + 1), Fields: map[search.Field][]string{search.Title: {"soup"}}}
+		}
+		index.Replace(docs)
+		recipes := &evenRecipes{n: tt.n}
+		s := NewSearchService(&repository.Repositories{Recipes: recipes}, index, 0)
+
+		page, count, err := s.Find(search.ParseQuery("soup"), nil, tt.sort, 3, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != tt.wantCount {
+			t.Errorf("%s: count = %d, want %d", tt.name, count, tt.wantCount)
+		}
+		if got := recipeIDs(page); !slices.Equal(got, tt.wantPage) {
+			t.Errorf("%s: page = %v, want %v", tt.name, got, tt.wantPage)
+		}
+		if !slices.Equal(recipes.lists, []int{tt.wantList}) {
+			t.Errorf("%s: MatchIDs got ID lists of %v, want [%d]", tt.name, recipes.lists, tt.wantList)
+		}
+	}
+}
+
+func TestSearchFindPastTheEnd(t *testing.T) {
+	index := search.NewMemoryIndex()
+	index.Replace([]search.Document{{ID: 2, Fields: map[search.Field][]string{search.Title: {"soup"}}}})
+	s := NewSearchService(&repository.Repositories{Recipes: &evenRecipes{n: 2}}, index, 0)
+
+	for _, offset := range []int{1, math.MaxInt} {
+		page, count, err := s.Find(search.ParseQuery("soup"), nil, "", math.MaxInt, offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 || len(page) != 0 {
+			t.Errorf("offset %d: %d recipes of %d, want none of 1", offset, len(page), count)
+		}
+	}
+}
+
+func (r *evenRecipes) Facets(names []string, params map[string]string, within []uint) (map[string]repository.Facet, error) {
+	r.lists = append(r.lists, len(within))
+	return map[string]repository.Facet{}, nil
+}
+
+func TestSearchFacetsTruncated(t *testing.T) {
+	for _, n := range []int{10, maxFacetHits, maxFacetHits + 1} {
+		index := search.NewMemoryIndex()
+		docs := make([]search.Document, n)
+		for i := range docs {
+			docs[i] = search.Document{ID: uint(i + 1), Fields: map[search.Field][]string{search.Title: {"soup"}}}
+		}
+		index.Replace(docs)
+		recipes := &evenRecipes{n: n}
+		s := NewSearchService(&repository.Repositories{Recipes: recipes}, index, 0)
+
+		_, truncated, err := s.Facets(search.ParseQuery("soup"), nil, []string{repository.FacetTags})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := n > maxFacetHits; truncated != want {
+			t.Errorf("%d hits: truncated = %v, want %v", n, truncated, want)
+		}
+		if want := min(n, maxFacetHits); !slices.Equal(recipes.lists, []int{want}) {
+			t.Errorf("%d hits: facets counted over %v, want %d", n, recipes.lists, want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/search"
+)
+
+// Suggestion types, as used in the types filter of Suggest.
+const (
+	SuggestRecipe     = "recipe"
+	SuggestIngredient = "ingredient"
+	SuggestTag        = "tag"
+	SuggestCategory   = "category"
+)
+
+// SuggestTypes lists every suggestion type.
+var SuggestTypes = []string{SuggestRecipe, SuggestIngredient, SuggestTag, SuggestCategory}
+
+const (
+	// favoriteWeight is how many views a favorite is worth in the
+	// popularity of a recipe.
+	favoriteWeight = 5
+	// invalidateDelay collects the changes of a burst of edits into a
+	// single rebuild.
+	invalidateDelay = time.Second
+)
+
+// SuggestService answers autocomplete queries from recipe titles,
+// ingredient names, tags and categories of listed recipes.
+type SuggestService struct {
+	repos     *repository.Repositories
+	suggester *search.Suggester
+	interval  time.Duration
+
+	mu      sync.Mutex
+	pending bool
+}
+
+func NewSuggestService(repos *repository.Repositories, suggester *search.Suggester, interval time.Duration) *SuggestService {
+	return &SuggestService{repos: repos, suggester: suggester, interval: interval}
+}
+
+// Suggest returns up to limit completions of q of the given types, all
+// types when types is empty.
+func (s *SuggestService) Suggest(q string, types []string, limit int) []search.Suggestion {
+	return s.suggester.Suggest(q, types, limit)
+}
+
+// Rebuild reloads every entry and its popularity from the database.
+func (s *SuggestService) Rebuild() error {
+	var entries []search.SuggestEntry
+
+	recipes, err := s.repos.Recipes.ListedActivity()
+	if err != nil {
+		return err
+	}
+	for _, r := range recipes {
+		entries = append(entries, search.SuggestEntry{
+			Type:       SuggestRecipe,
+			ID:         r.ID,
+			Text:       r.Title,
+			Popularity: float64(favoriteWeight*r.Favorites + r.Views),
+		})
+	}
+
+	ingredients, err := s.repos.Recipes.IngredientUsage()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, ingredientEntries(ingredients)...)
+
+	tags, err := s.repos.Tags.Usage()
+	if err != nil {
+		return err
+	}
+	for _, t := range tags {
+		entries = append(entries, search.SuggestEntry{Type: SuggestTag, ID: t.ID, Text: t.Name, Popularity: float64(t.Count)})
+	}
+
+	categories, err := s.repos.Categories.Usage()
+	if err != nil {
+		return err
+	}
+	for _, c := range categories {
+		entries = append(entries, search.SuggestEntry{Type: SuggestCategory, ID: c.ID, Text: c.Name, Popularity: float64(c.Count)})
+	}
+
+	s.suggester.Replace(entries)
+	return nil
+}
+
+// ingredientEntries merges ingredient names that only differ in case or
+// spelling variants, suggesting the most used spelling.
+func ingredientEntries(usage []repository.UsageCount) []search.SuggestEntry {
+	type merged struct {
+		text      string
+		textCount int64
+		total     int64
+	}
+	byKey := map[string]*merged{}
+	var keys []string
+	for _, u := range usage {
+		key := search.SuggestKey(u.Name)
+		if key == "" {
+			continue
+		}
+		m, ok := byKey[key]
+		if !ok {
+			m = &merged{}
+			byKey[key] = m
+			keys = append(keys, key)
+		}
+		m.total += u.Count
+		if u.Count > m.textCount {
+			m.text, m.textCount = u.Name, u.Count
+		}
+	}
+
+	entries := make([]search.SuggestEntry, 0, len(keys))
+	for _, key := range keys {
+		m := byKey[key]
+		entries = append(entries, search.SuggestEntry{Type: SuggestIngredient, Text: m.text, Popularity: float64(m.total)})
+	}
+	return entries
+}
+
+// Invalidate schedules a rebuild after a recipe, tag or category changed.
+// Changes arriving before it runs are picked up by the same rebuild.
+func (s *SuggestService) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending {
+		return
+	}
+	s.pending = true
+
+	time.AfterFunc(invalidateDelay, func() {
+		s.mu.Lock()
+		s.pending = false
+		s.mu.Unlock()
+
+		if err := s.Rebuild(); err != nil {
+			log.Printf("failed to rebuild suggestions: %v", err)
+		}
+	})
+}
+
+// Start rebuilds the suggestions every interval until ctx is cancelled, so
+// popularity follows new favorites and views. A zero interval disables it.
+func (s *SuggestService) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := s.Rebuild(); err != nil {
+				log.Printf("failed to rebuild suggestions: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Abb133Se/recepieshare/internal"
+	"github.com/Abb133Se/recepieshare/model"
+	"github.com/Abb133Se/recepieshare/repository"
+	"github.com/Abb133Se/recepieshare/token"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused means an already rotated refresh token was
+	// presented again. The whole token family has been revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// TokenPair is what clients receive on login and refresh.
+type TokenPair struct {
+	Access       token.AccessToken
+	RefreshToken string
+}
+
+// TokenService issues access/refresh token pairs, rotates refresh tokens and
+// keeps the access-token deny-list.
+type TokenService struct {
+	repos      *repository.Repositories
+	clock      internal.Clock
+	refreshTTL time.Duration
+}
+
+func NewTokenService(repos *repository.Repositories, clock internal.Clock, refreshTTL time.Duration) *TokenService {
+	return &TokenService{repos: repos, clock: clock, refreshTTL: refreshTTL}
+}
+
+// Issue starts a new token family for user, i.e. a new login session.
+func (s *TokenService) Issue(user *model.User) (TokenPair, error) {
+	familyID, err := token.GenerateID()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	pair, _, err := s.issue(s.repos, user, familyID)
+	return pair, err
+}
+
+func (s *TokenService) issue(repos *repository.Repositories, user *model.User, familyID string) (TokenPair, *model.RefreshToken, error) {
+	access, err := token.GenerateToken(user.ID, user.Email, user.Role)
+	if err != nil {
+		return TokenPair{}, nil, err
+	}
+
+	refresh, err := token.GenerateRefreshToken()
+	if err != nil {
+		return TokenPair{}, nil, err
+	}
+
+	rt := &model.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: token.HashToken(refresh),
+		ExpiresAt: s.clock.Now().Add(s.refreshTTL),
+	}
+	if err := repos.Tokens.CreateRefreshToken(rt); err != nil {
+		return TokenPair{}, nil, err
+	}
+
+	return TokenPair{Access: access, RefreshToken: refresh}, rt, nil
+}
+
+// Refresh exchanges a refresh token for a new pair in the same family. The
+// presented token is revoked; presenting it again revokes the family.
+func (s *TokenService) Refresh(refreshToken string) (TokenPair, uint, error) {
+	now := s.clock.Now()
+
+	current, err := s.repos.Tokens.FindRefreshTokenByHash(token.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return TokenPair{}, 0, ErrInvalidRefreshToken
+		}
+		return TokenPair{}, 0, err
+	}
+
+	if current.RevokedAt != nil {
+		return TokenPair{}, 0, s.revokeReused(current, now)
+	}
+	if current.ExpiresAt.Before(now) {
+		return TokenPair{}, 0, ErrInvalidRefreshToken
+	}
+
+	// Load the user again so role changes take effect on the next access token.
+	user, err := s.repos.Users.FindByID(current.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return TokenPair{}, 0, ErrInvalidRefreshToken
+		}
+		return TokenPair{}, 0, err
+	}
+
+	var pair TokenPair
+	err = s.repos.Transaction(func(tx *repository.Repositories) error {
+		var next *model.RefreshToken
+		var err error
+		pair, next, err = s.issue(tx, user, current.FamilyID)
+		if err != nil {
+			return err
+		}
+
+		revoked, err := tx.Tokens.RevokeRefreshToken(current.ID, &next.ID, now)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			// Lost a race against another refresh with the same token.
+			return ErrRefreshTokenReused
+		}
+		return nil
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		return TokenPair{}, 0, s.revokeReused(current, now)
+	}
+	if err != nil {
+		return TokenPair{}, 0, err
+	}
+
+	return pair, user.ID, nil
+}
+
+func (s *TokenService) revokeReused(rt *model.RefreshToken, now time.Time) error {
+	log.Printf("refresh token reuse detected for user %d, revoking family %s", rt.UserID, rt.FamilyID)
+	if err := s.repos.Tokens.RevokeFamily(rt.FamilyID, now); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Logout revokes the access token identified by jti and, when given, the
+// family of refreshToken if it belongs to the same user.
+func (s *TokenService) Logout(userID uint, jti string, accessExpiresAt time.Time, refreshToken string) error {
+	now := s.clock.Now()
+
+	if refreshToken != "" {
+		rt, err := s.repos.Tokens.FindRefreshTokenByHash(token.HashToken(refreshToken))
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		if rt != nil && rt.UserID == userID {
+			if err := s.repos.Tokens.RevokeFamily(rt.FamilyID, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.repos.Tokens.RevokeAccessToken(jti, accessExpiresAt); err != nil {
+		return err
+	}
+
+	// Housekeeping: nothing that already expired needs to be remembered.
+	if err := s.repos.Tokens.DeleteExpired(now); err != nil {
+		log.Printf("failed to purge expired tokens: %v", err)
+	}
+	return nil
+}
+
+// RevokeAll ends every session of the user, e.g. after a password reset.
+func (s *TokenService) RevokeAll(userID uint) error {
+	return s.repos.Tokens.RevokeAllForUser(userID, s.clock.Now())
+}
+
+func (s *TokenService) IsRevoked(jti string) (bool, error) {
+	return s.repos.Tokens.IsAccessTokenRevoked(jti)
+}
@@ -2,28 +2,58 @@ package token
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/Abb133Se/recepieshare/config"
 	"github.com/golang-jwt/jwt"
 )
 
-var secretKey = []byte("secret-key")
+var (
+	secretKey []byte
+	tokenTTL  = 15 * time.Minute
+)
+
+// AccessToken is a signed JWT together with the claims needed to revoke it.
+type AccessToken struct {
+	Token     string
+	JTI       string
+	ExpiresAt time.Time
+}
 
-func GenerateToken(userID uint, email string, role string) (string, error) {
+// Init configures the signing secret and lifetime of issued tokens.
+func Init(cfg config.JWTConfig) {
+	secretKey = []byte(cfg.Secret)
+	if cfg.TTL.Duration > 0 {
+		tokenTTL = cfg.TTL.Duration
+	}
+}
+
+func GenerateToken(userID uint, email string, role string) (AccessToken, error) {
+	jti, err := GenerateID()
+	if err != nil {
+		return AccessToken{}, err
+	}
+	expiresAt := time.Now().Add(tokenTTL)
 
 	claims := jwt.MapClaims{
 		"sub":   userID,
 		"email": email,
 		"role":  role,
-		"exp":   time.Now().Add(time.Hour * 24).Unix(),
+		"jti":   jti,
+		"exp":   expiresAt.Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	return token.SignedString(secretKey)
+	signed, err := token.SignedString(secretKey)
+	if err != nil {
+		return AccessToken{}, err
+	}
+	return AccessToken{Token: signed, JTI: jti, ExpiresAt: expiresAt}, nil
 }
 
 func VerifyToken(tokenString string) (jwt.MapClaims, error) {
@@ -49,6 +79,11 @@ func VerifyToken(tokenString string) (jwt.MapClaims, error) {
 			return nil, errors.New("token has expired")
 		}
 
+		// Tokens without an ID cannot be revoked, so they are not accepted.
+		if jti, ok := claims["jti"].(string); !ok || jti == "" {
+			return nil, errors.New("invalid token: missing jti claim")
+		}
+
 		return claims, nil
 
 	}
@@ -57,7 +92,34 @@ func VerifyToken(tokenString string) (jwt.MapClaims, error) {
 }
 
 func GenerateResetToken() (string, error) {
-	b := make([]byte, 32)
+	return randomHex(32)
+}
+
+// GenerateRefreshToken returns an opaque refresh token. Only its HashToken
+// digest is stored.
+func GenerateRefreshToken() (string, error) {
+	return randomHex(32)
+}
+
+// HashToken returns the hex SHA-256 digest of an opaque token. Refresh tokens
+// are high-entropy random values, so a fast hash is sufficient.
+func HashToken(t string) string {
+	sum := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateShareToken returns the unguessable token of an unlisted recipe link.
+func GenerateShareToken() (string, error) {
+	return randomHex(16)
+}
+
+// GenerateID returns a random identifier, used for token IDs and families.
+func GenerateID() (string, error) {
+	return randomHex(16)
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
 	_, err := rand.Read(b)
 	if err != nil {
 		return "", err
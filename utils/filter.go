@@ -0,0 +1,500 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Abb133Se/recepieshare/dietary"
+	"gorm.io/gorm"
+)
+
+// Limits on a filter expression, so a single request cannot build an
+// arbitrarily large query.
+const (
+	MaxFilterTerms  = 20
+	MaxFilterLength = 1000
+)
+
+// FilterErrorKind tells why a filter expression was rejected.
+type FilterErrorKind int
+
+const (
+	// FilterSyntax is a token that does not fit the grammar. An empty Token
+	// means the expression ended too early.
+	FilterSyntax FilterErrorKind = iota
+	FilterUnknownField
+	FilterBadValue
+	FilterTooComplex
+)
+
+// FilterError is a rejected filter expression. Pos is the 1-based character
+// position of Token in the expression.
+type FilterError struct {
+	Kind  FilterErrorKind
+	Pos   int
+	Token string
+	// Field is the field whose value is invalid, for FilterBadValue.
+	Field string
+}
+
+func (e *FilterError) Error() string {
+	switch e.Kind {
+	case FilterUnknownField:
+		return fmt.Sprintf("unknown filter field %q at %d", e.Token, e.Pos)
+	case FilterBadValue:
+		return fmt.Sprintf("invalid value %q for filter field %s at %d", e.Token, e.Field, e.Pos)
+	case FilterTooComplex:
+		return "filter too complex"
+	case FilterSyntax:
+		if e.Token == "" {
+			return "unexpected end of filter"
+		}
+	}
+	return fmt.Sprintf("unexpected %q at %d in filter", e.Token, e.Pos)
+}
+
+// RecipeFilter is a parsed filter expression such as
+//
+//	tag:vegan AND NOT ingredient:nuts
+//	(cuisine:italian OR cuisine:french) rating:>=4 total_minutes:<=30
+//
+// Terms are field:value pairs; values with spaces go in double quotes.
+// Terms combine with AND, OR and NOT, or the shorthands of a space for AND
+// and a leading - for NOT, and group with parentheses. NOT binds tightest,
+// then AND, then OR. Numeric and date fields take a comparison after the
+// colon: =, <, <=, > or >=, = when left out. Values are only ever bound as
+// query arguments.
+//
+// Every term is either true or false for a recipe, never unknown, so NOT
+// matches exactly the recipes the term does not. An unrated recipe, or one
+// without the time compared, fails every rating or time comparison: neither
+// rating:>=4 nor rating:<4 matches it, and NOT rating:>=4 does. It has no
+// votes, so votes:<1 matches it.
+type RecipeFilter struct {
+	root filterNode
+}
+
+// FilterFields lists the fields a filter expression may use.
+var FilterFields = slices.Concat(
+	[]string{
+		"tag", "category", "ingredient", "title", "author", "cuisine", "difficulty",
+		"diet", "allergen", "equipment", "rating", "votes", "created",
+	},
+	NutrientColumns,
+	TimeColumns,
+)
+
+// ParseRecipeFilter parses a filter expression. Errors are *FilterError.
+func ParseRecipeFilter(s string) (*RecipeFilter, error) {
+	if len(s) > MaxFilterLength {
+		return nil, &FilterError{Kind: FilterTooComplex}
+	}
+	tokens, err := lexFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != filterEnd {
+		return nil, &FilterError{Kind: FilterSyntax, Pos: t.pos, Token: t.text}
+	}
+	return &RecipeFilter{root: root}, nil
+}
+
+// Without returns the filter with every term on field left out, or nil when
+// no term is left. A term is dropped from its AND or OR, together with a NOT
+// around it, so the rest of the expression applies as written: the tag
+// terms of "tag:vegan OR cuisine:thai" leave "cuisine:thai".
+func (f *RecipeFilter) Without(field string) *RecipeFilter {
+	root, ok := without(f.root, field)
+	if !ok {
+		return nil
+	}
+	return &RecipeFilter{root: root}
+}
+
+func without(node filterNode, field string) (filterNode, bool) {
+	switch n := node.(type) {
+	case filterFieldTerm:
+		return n, n.field != field
+	case filterNegation:
+		inner, ok := without(n.node, field)
+		return filterNegation{inner}, ok
+	case filterJoin:
+		var nodes []filterNode
+		for _, child := range n.nodes {
+			if child, ok := without(child, field); ok {
+				nodes = append(nodes, child)
+			}
+		}
+		switch len(nodes) {
+		case 0:
+			return nil, false
+		case 1:
+			return nodes[0], true
+		}
+		return filterJoin{op: n.op, nodes: nodes}, true
+	}
+	return node, true
+}
+
+// Apply restricts query to the recipes matching the filter. Nutrient
+// comparisons apply to one serving when perServing is set.
+func (f *RecipeFilter) Apply(query *gorm.DB, perServing bool) *gorm.DB {
+	var b strings.Builder
+	var args []any
+	f.root.sql(&b, &args, perServing)
+	return query.Where(b.String(), args...)
+}
+
+type filterTokenKind int
+
+const (
+	filterEnd filterTokenKind = iota
+	filterTerm
+	filterAnd
+	filterOr
+	filterNot
+	filterOpen
+	filterClose
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	pos  int
+	text string
+	// field and value are set for terms, with the quotes of the value
+	// removed.
+	field, value string
+}
+
+func lexFilter(s string) ([]filterToken, error) {
+	rs := []rune(s)
+	var tokens []filterToken
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: filterOpen, pos: i + 1, text: "("})
+			i++
+			continue
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: filterClose, pos: i + 1, text: ")"})
+			i++
+			continue
+		case r == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]):
+			tokens = append(tokens, filterToken{kind: filterNot, pos: i + 1, text: "-"})
+			i++
+			continue
+		}
+
+		start := i
+		field := ""
+		var value strings.Builder
+		for i < len(rs) && !unicode.IsSpace(rs[i]) && rs[i] != '(' && rs[i] != ')' {
+			if rs[i] == ':' && field == "" {
+				field = string(rs[start:i])
+				i++
+				if i < len(rs) && rs[i] == '"' {
+					end := slices.Index(rs[i+1:], '"')
+					if end < 0 {
+						return nil, &FilterError{Kind: FilterSyntax, Pos: i + 1, Token: `"`}
+					}
+					value.WriteString(string(rs[i+1 : i+1+end]))
+					i += end + 2
+					break
+				}
+				continue
+			}
+			if field != "" {
+				value.WriteRune(rs[i])
+			}
+			i++
+		}
+		t := filterToken{pos: start + 1, text: string(rs[start:i])}
+		switch {
+		case field != "":
+			t.kind, t.field, t.value = filterTerm, strings.ToLower(field), strings.TrimSpace(value.String())
+		case strings.EqualFold(t.text, "AND"):
+			t.kind = filterAnd
+		case strings.EqualFold(t.text, "OR"):
+			t.kind = filterOr
+		case strings.EqualFold(t.text, "NOT"):
+			t.kind = filterNot
+		default:
+			return nil, &FilterError{Kind: FilterSyntax, Pos: t.pos, Token: t.text}
+		}
+		tokens = append(tokens, t)
+	}
+	return append(tokens, filterToken{kind: filterEnd, pos: len(rs) + 1}), nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	next   int
+	terms  int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.next]
+}
+
+func (p *filterParser) take() filterToken {
+	t := p.tokens[p.next]
+	if t.kind != filterEnd {
+		p.next++
+	}
+	return t
+}
+
+func (p *filterParser) or() (filterNode, error) {
+	node, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	nodes := filterJoin{op: " OR ", nodes: []filterNode{node}}
+	for p.peek().kind == filterOr {
+		p.take()
+		if node, err = p.and(); err != nil {
+			return nil, err
+		}
+		nodes.nodes = append(nodes.nodes, node)
+	}
+	if len(nodes.nodes) == 1 {
+		return node, nil
+	}
+	return nodes, nil
+}
+
+func (p *filterParser) and() (filterNode, error) {
+	node, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	nodes := filterJoin{op: " AND ", nodes: []filterNode{node}}
+	for {
+		switch p.peek().kind {
+		case filterAnd:
+			p.take()
+		case filterTerm, filterNot, filterOpen:
+		default:
+			if len(nodes.nodes) == 1 {
+				return node, nil
+			}
+			return nodes, nil
+		}
+		if node, err = p.unary(); err != nil {
+			return nil, err
+		}
+		nodes.nodes = append(nodes.nodes, node)
+	}
+}
+
+func (p *filterParser) unary() (filterNode, error) {
+	t := p.take()
+	switch t.kind {
+	case filterNot:
+		node, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNegation{node}, nil
+	case filterOpen:
+		node, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.take(); t.kind != filterClose {
+			return nil, &FilterError{Kind: FilterSyntax, Pos: t.pos, Token: t.text}
+		}
+		return node, nil
+	case filterTerm:
+		if p.terms++; p.terms > MaxFilterTerms {
+			return nil, &FilterError{Kind: FilterTooComplex}
+		}
+		cond, err := parseFilterTerm(t)
+		if err != nil {
+			return nil, err
+		}
+		return filterFieldTerm{field: t.field, filterNode: cond}, nil
+	}
+	return nil, &FilterError{Kind: FilterSyntax, Pos: t.pos, Token: t.text}
+}
+
+type filterNode interface {
+	sql(b *strings.Builder, args *[]any, perServing bool)
+}
+
+type filterJoin struct {
+	op    string
+	nodes []filterNode
+}
+
+func (j filterJoin) sql(b *strings.Builder, args *[]any, perServing bool) {
+	b.WriteString("(")
+	for i, n := range j.nodes {
+		if i > 0 {
+			b.WriteString(j.op)
+		}
+		n.sql(b, args, perServing)
+	}
+	b.WriteString(")")
+}
+
+type filterNegation struct {
+	node filterNode
+}
+
+func (n filterNegation) sql(b *strings.Builder, args *[]any, perServing bool) {
+	b.WriteString("NOT ")
+	n.node.sql(b, args, perServing)
+}
+
+// filterFieldTerm is a term with the field it tests.
+type filterFieldTerm struct {
+	field string
+	filterNode
+}
+
+// filterCondition is a term compiled to SQL; expr is called with whether
+// nutrients are compared per serving.
+type filterCondition func(perServing bool) (string, []any)
+
+func (c filterCondition) sql(b *strings.Builder, args *[]any, perServing bool) {
+	expr, values := c(perServing)
+	b.WriteString("(" + expr + ")")
+	*args = append(*args, values...)
+}
+
+// fixed is a condition that does not depend on per_serving.
+func fixed(expr string, args ...any) filterCondition {
+	return func(bool) (string, []any) { return expr, args }
+}
+
+var filterOperators = []string{">=", "<=", ">", "<", "="}
+
+func parseFilterTerm(t filterToken) (filterNode, error) {
+	bad := &FilterError{Kind: FilterBadValue, Pos: t.pos, Token: t.value, Field: t.field}
+	if !slices.Contains(FilterFields, t.field) {
+		return nil, &FilterError{Kind: FilterUnknownField, Pos: t.pos, Token: t.field}
+	}
+	if t.value == "" {
+		return nil, bad
+	}
+	lower := strings.ToLower(t.value)
+	id, idErr := strconv.ParseUint(t.value, 10, 64)
+
+	switch t.field {
+	case "tag":
+		if idErr == nil {
+			return fixed("EXISTS (SELECT 1 FROM recipe_tags xt WHERE xt.recipe_id = recipes.id AND xt.tag_id = ?)", id), nil
+		}
+		return fixed("EXISTS (SELECT 1 FROM recipe_tags xt JOIN tags t ON t.id = xt.tag_id WHERE xt.recipe_id = recipes.id AND LOWER(t.name) = ?)", lower), nil
+	case "category":
+		if idErr == nil {
+			return fixed("EXISTS (SELECT 1 FROM recipe_categories xc WHERE xc.recipe_id = recipes.id AND xc.category_id = ?)", id), nil
+		}
+		return fixed("EXISTS (SELECT 1 FROM recipe_categories xc JOIN categories c ON c.id = xc.category_id WHERE xc.recipe_id = recipes.id AND LOWER(c.name) = ?)", lower), nil
+	case "ingredient":
+		return fixed("EXISTS (SELECT 1 FROM ingredients i WHERE i.recipe_id = recipes.id AND LOWER(i.name) LIKE ?)", "%"+lower+"%"), nil
+	case "title":
+		return fixed("LOWER(recipes.title) LIKE ?", "%"+lower+"%"), nil
+	case "author":
+		if idErr != nil {
+			return nil, bad
+		}
+		return fixed("recipes.user_id = ?", id), nil
+	case "cuisine", "difficulty":
+		return fixed("LOWER(recipes."+t.field+") = ?", lower), nil
+	case "equipment":
+		return fixed("LOWER(recipes.equipment) LIKE ?", `%"`+lower+`"%`), nil
+	case "diet":
+		if _, ok := dietary.ParseDiet(lower); !ok {
+			return nil, bad
+		}
+		return fixed("recipes.diets LIKE ?", `%"`+lower+`"%`), nil
+	case "allergen":
+		if _, ok := dietary.ParseAllergen(lower); !ok {
+			return nil, bad
+		}
+		return fixed("COALESCE(recipes.allergens, '') LIKE ?", `%"`+lower+`"%`), nil
+	}
+
+	// The remaining fields compare numbers or dates.
+	op, value := "=", t.value
+	for _, o := range filterOperators {
+		if strings.HasPrefix(value, o) {
+			op, value = o, value[len(o):]
+			break
+		}
+	}
+	bad.Token = value
+
+	if t.field == "created" {
+		at, wholeDay, ok := ParseDate(value)
+		if !ok {
+			return nil, bad
+		}
+		if !wholeDay {
+			return fixed("recipes.created_at "+op+" ?", at), nil
+		}
+		// A date stands for the whole day.
+		next := at.AddDate(0, 0, 1)
+		switch op {
+		case "=":
+			return fixed("recipes.created_at >= ? AND recipes.created_at < ?", at, next), nil
+		case ">":
+			return fixed("recipes.created_at >= ?", next), nil
+		case "<=":
+			return fixed("recipes.created_at < ?", next), nil
+		}
+		return fixed("recipes.created_at "+op+" ?", at), nil
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, bad
+	}
+	switch {
+	case t.field == "rating":
+		return fixed(knownComparison(RatingExpr, op), n), nil
+	case t.field == "votes":
+		return fixed(VotesExpr+" "+op+" ?", n), nil
+	case slices.Contains(TimeColumns, t.field):
+		return fixed(knownComparison("recipes."+t.field, op), n), nil
+	}
+	return filterCondition(func(perServing bool) (string, []any) {
+		return NutrientExpr(t.field, perServing) + " " + op + " ?", []any{n}
+	}), nil
+}
+
+// knownComparison compares expr, which is 0 when the value is unknown, so
+// that unknown values never match.
+func knownComparison(expr, op string) string {
+	return expr + " > 0 AND " + expr + " " + op + " ?"
+}
+
+// ParseDate parses a date as YYYY-MM-DD or a time in RFC 3339. Both are
+// returned in the local time zone, which GORM stores timestamps in: SQLite
+// compares them as text, so the bound value must carry the same offset. A
+// date is the start of that day in the local time zone. wholeDay reports a
+// date without a time of day.
+func ParseDate(s string) (t time.Time, wholeDay bool, ok bool) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, true, true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Local(), false, true
+	}
+	return time.Time{}, false, false
+}
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func filterSQL(f *RecipeFilter, perServing bool) (string, []any) {
+	var b strings.Builder
+	var args []any
+	f.root.sql(&b, &args, perServing)
+	return b.String(), args
+}
+
+func TestParseRecipeFilter(t *testing.T) {
+	const (
+		tagName  = "EXISTS (SELECT 1 FROM recipe_tags xt JOIN tags t ON t.id = xt.tag_id WHERE xt.recipe_id = recipes.id AND LOWER(t.name) = ?)"
+		tagID    = "EXISTS (SELECT 1 FROM recipe_tags xt WHERE xt.recipe_id = recipes.id AND xt.tag_id = ?)"
+		hasNuts  = "EXISTS (SELECT 1 FROM ingredients i WHERE i.recipe_id = recipes.id AND LOWER(i.name) LIKE ?)"
+		cuisine  = "LOWER(recipes.cuisine) = ?"
+		rating   = "COALESCE((SELECT AVG(r.score) FROM ratings r WHERE r.recipe_id = recipes.id), 0)"
+		votes    = "(SELECT COUNT(*) FROM ratings r WHERE r.recipe_id = recipes.id)"
+		created  = "recipes.created_at"
+		calories = "recipes.calories"
+	)
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	next := day.AddDate(0, 0, 1)
+
+	tests := []struct {
+		expr string
+		sql  string
+		args []any
+	}{
+		{"tag:Vegan", "(" + tagName + ")", []any{"vegan"}},
+		{"tag:3 -ingredient:nuts", "((" + tagID + ") AND NOT (" + hasNuts + "))", []any{uint64(3), "%nuts%"}},
+		// AND binds tighter than OR.
+		{"cuisine:italian OR cuisine:french rating:>=4",
+			"((" + cuisine + ") OR ((" + cuisine + ") AND (" + rating + " > 0 AND " + rating + " >= ?)))", []any{"italian", "french", 4.0}},
+		{"(cuisine:italian OR cuisine:french) AND NOT difficulty:hard",
+			"(((" + cuisine + ") OR (" + cuisine + ")) AND NOT (LOWER(recipes.difficulty) = ?))", []any{"italian", "french", "hard"}},
+		{"cuisine:italian or cuisine:french", "((" + cuisine + ") OR (" + cuisine + "))", []any{"italian", "french"}},
+		{`title:"Pea soup"`, "(LOWER(recipes.title) LIKE ?)", []any{"%pea soup%"}},
+		{"NOT NOT votes:>3", "NOT NOT (" + votes + " > ?)", []any{3.0}},
+		{"calories:<500", "(" + calories + " < ?)", []any{500.0}},
+		{"total_minutes:<=30", "(recipes.total_minutes > 0 AND recipes.total_minutes <= ?)", []any{30.0}},
+		{"diet:vegan allergen:tree_nut", `((recipes.diets LIKE ?) AND (COALESCE(recipes.allergens, '') LIKE ?))`, []any{`%"vegan"%`, `%"tree_nut"%`}},
+		{"author:7", "(recipes.user_id = ?)", []any{uint64(7)}},
+		// A date covers the whole day.
+		{"created:2024-05-01", "(" + created + " >= ? AND " + created + " < ?)", []any{day, next}},
+		{"created:>2024-05-01", "(" + created + " >= ?)", []any{next}},
+		{"created:<=2024-05-01", "(" + created + " < ?)", []any{next}},
+		{"created:<2024-05-01", "(" + created + " < ?)", []any{day}},
+		{"created:>=2024-05-01T10:00:00+02:00", "(" + created + " >= ?)", []any{time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC).Local()}},
+	}
+	for _, tt := range tests {
+		f, err := ParseRecipeFilter(tt.expr)
+		if err != nil {
+			t.Errorf("ParseRecipeFilter(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		sql, args := filterSQL(f, false)
+		if sql != tt.sql {
+			t.Errorf("ParseRecipeFilter(%q) SQL\n got %s\nwant %s", tt.expr, sql, tt.sql)
+		}
+		if !slices.EqualFunc(args, tt.args, func(a, b any) bool { return fmt.Sprint(a) == fmt.Sprint(b) }) {
+			t.Errorf("ParseRecipeFilter(%q) args = %v, want %v", tt.expr, args, tt.args)
+		}
+	}
+}
+
+func TestRecipeFilterWithout(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{"tag:vegan cuisine:thai", "cuisine:thai"},
+		{"tag:vegan OR cuisine:thai", "cuisine:thai"},
+		{"(tag:vegan OR tag:quick) -ingredient:nuts", "-ingredient:nuts"},
+		{"cuisine:thai AND NOT (tag:vegan OR calories:<500)", "cuisine:thai AND NOT calories:<500"},
+		{"cuisine:thai", "cuisine:thai"},
+		// Nothing is left.
+		{"NOT tag:vegan", ""},
+		{"tag:vegan OR (tag:quick -tag:spicy)", ""},
+	}
+	for _, tt := range tests {
+		f, err := ParseRecipeFilter(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := f.Without("tag")
+		if tt.want == "" {
+			if got != nil {
+				sql, _ := filterSQL(got, false)
+				t.Errorf("Without(%q) = %s, want nil", tt.expr, sql)
+			}
+			continue
+		}
+		want, err := ParseRecipeFilter(tt.want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == nil {
+			t.Errorf("Without(%q) = nil, want %s", tt.expr, tt.want)
+			continue
+		}
+		gotSQL, gotArgs := filterSQL(got, false)
+		wantSQL, wantArgs := filterSQL(want, false)
+		if gotSQL != wantSQL || !slices.Equal(gotArgs, wantArgs) {
+			t.Errorf("Without(%q) = %s %v, want %s %v", tt.expr, gotSQL, gotArgs, wantSQL, wantArgs)
+		}
+	}
+}
+
+func TestParseRecipeFilterPerServing(t *testing.T) {
+	f, err := ParseRecipeFilter("protein:>=20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql, _ := filterSQL(f, true); sql != "(recipes.protein / recipes.servings >= ?)" {
+		t.Errorf("per serving SQL = %s", sql)
+	}
+	if sql, _ := filterSQL(f, false); sql != "(recipes.protein >= ?)" {
+		t.Errorf("per recipe SQL = %s", sql)
+	}
+}
+
+func TestParseRecipeFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want FilterError
+	}{
+		{"color:red", FilterError{Kind: FilterUnknownField, Pos: 1, Token: "color"}},
+		{"tag:", FilterError{Kind: FilterBadValue, Pos: 1, Field: "tag"}},
+		{"diet:carnivore", FilterError{Kind: FilterBadValue, Pos: 1, Token: "carnivore", Field: "diet"}},
+		{"tag:a allergen:nuts", FilterError{Kind: FilterBadValue, Pos: 7, Token: "nuts", Field: "allergen"}},
+		{"author:bob", FilterError{Kind: FilterBadValue, Pos: 1, Token: "bob", Field: "author"}},
+		{"rating:>=x", FilterError{Kind: FilterBadValue, Pos: 1, Token: "x", Field: "rating"}},
+		{"created:yesterday", FilterError{Kind: FilterBadValue, Pos: 1, Token: "yesterday", Field: "created"}},
+		{"soup", FilterError{Kind: FilterSyntax, Pos: 1, Token: "soup"}},
+		{"OR tag:a", FilterError{Kind: FilterSyntax, Pos: 1, Token: "OR"}},
+		{"(tag:a", FilterError{Kind: FilterSyntax, Pos: 7}},
+		{"tag:a)", FilterError{Kind: FilterSyntax, Pos: 6, Token: ")"}},
+		{`tag:"unclosed`, FilterError{Kind: FilterSyntax, Pos: 5, Token: `"`}},
+		{strings.Repeat("tag:a ", MaxFilterTerms+1), FilterError{Kind: FilterTooComplex}},
+		{"title:" + strings.Repeat("a", MaxFilterLength), FilterError{Kind: FilterTooComplex}},
+	}
+	for _, tt := range tests {
+		_, err := ParseRecipeFilter(tt.expr)
+		var fe *FilterError
+		if !errors.As(err, &fe) {
+			t.Errorf("ParseRecipeFilter(%.30q) error = %v, want %+v", tt.expr, err, tt.want)
+			continue
+		}
+		if *fe != tt.want {
+			t.Errorf("ParseRecipeFilter(%.30q) error = %+v, want %+v", tt.expr, *fe, tt.want)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in       string
+		want     time.Time
+		wholeDay bool
+		ok       bool
+	}{
+		// A date starts at midnight in the server's time zone.
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), true, true},
+		{"2024-05-01T10:30:00Z", time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC), false, true},
+		{"2024-05-01T01:30:00+03:30", time.Date(2024, 4, 30, 22, 0, 0, 0, time.UTC), false, true},
+		{"2024-02-30", time.Time{}, false, false},
+		{"01/05/2024", time.Time{}, false, false},
+		{"", time.Time{}, false, false},
+	}
+	for _, tt := range tests {
+		got, wholeDay, ok := ParseDate(tt.in)
+		if ok != tt.ok || wholeDay != tt.wholeDay || !got.Equal(tt.want) || ok && got.Location() != time.Local {
+			t.Errorf("ParseDate(%q) = %v, %v, %v, want %v, %v, %v", tt.in, got, wholeDay, ok, tt.want, tt.wholeDay, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestVerifyPassword(t *testing.T) {
+	const password = "Passw0rd!x"
+
+	current, err := HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A hash with weaker parameters than DefaultArgon2Params.
+	salt := []byte("0123456789abcdef")
+	weak := fmt.Sprintf("$argon2id$v=%d$m=1024,t=1,p=1$%s$%s", argon2.Version,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(argon2.IDKey([]byte(password), salt, 1, 1024, 1, 32)))
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		password    string
+		encoded     string
+		legacySalt  string
+		ok          bool
+		needsRehash bool
+		err         error
+	}{
+		{"argon2id", password, current, "", true, false, nil},
+		{"argon2id wrong password", "wrong", current, "", false, false, nil},
+		{"argon2id weak parameters", password, weak, "", true, true, nil},
+		{"argon2id weak parameters wrong password", "wrong", weak, "", false, false, nil},
+		{"bcrypt", password, string(bcryptHash), "", true, true, nil},
+		{"bcrypt wrong password", "wrong", string(bcryptHash), "", false, false, nil},
+		{"legacy", password, legacyHashPassword(password, "pepper"), "pepper", true, true, nil},
+		{"legacy wrong salt", password, legacyHashPassword(password, "pepper"), "salt", false, false, nil},
+		{"unknown scheme", password, "$scrypt$abc", "", false, false, ErrInvalidHash},
+		{"argon2id truncated", password, "$argon2id$v=19$m=65536,t=3,p=2$abc", "", false, false, ErrInvalidHash},
+		{"argon2id wrong version", password, "$argon2id$v=16$m=65536,t=3,p=2$YWJj$YWJj", "", false, false, ErrInvalidHash},
+		{"argon2id bad salt", password, "$argon2id$v=19$m=65536,t=3,p=2$!!$YWJj", "", false, false, ErrInvalidHash},
+	}
+	for _, tt := range tests {
+		ok, needsRehash, err := VerifyPassword(tt.password, tt.encoded, tt.legacySalt)
+		if ok != tt.ok || needsRehash != tt.needsRehash || !errors.Is(err, tt.err) {
+			t.Errorf("%s: VerifyPassword = %v, %v, %v, want %v, %v, %v", tt.name, ok, needsRehash, err, tt.ok, tt.needsRehash, tt.err)
+		}
+	}
+}
+
+func TestHashPasswordSalts(t *testing.T) {
+	a, err := HashPassword("same")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := HashPassword("same")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Error("HashPassword returned the same hash twice")
+	}
+}
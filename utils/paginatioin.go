@@ -7,7 +7,9 @@ import (
 func Count(query *gorm.DB, primaryTable string) (int64, error) {
 	var total int64
 
-	countQuery := query.Session(&gorm.Session{})
+	// Passing the context makes the session copy the statement, so removing
+	// ORDER BY below leaves the sorting of query alone.
+	countQuery := query.Session(&gorm.Session{Context: query.Statement.Context})
 
 	// Use qualified id for counting
 	countQuery = countQuery.Select(primaryTable + ".id")
@@ -24,3 +26,18 @@ func Count(query *gorm.DB, primaryTable string) (int64, error) {
 func Paginate[T any](query *gorm.DB, limit, offset int, result *[]T) error {
 	return query.Limit(limit).Offset(offset * limit).Find(result).Error
 }
+
+// MaxPageSize caps the limit of pages cut from a list in memory.
+const MaxPageSize = 100
+
+// PageBounds returns the bounds of page offset, counted from 0 like in
+// Paginate, of a list of n items cut into pages of limit items. The limit is
+// capped at MaxPageSize and pages past the end are empty.
+func PageBounds(n, limit, offset int) (start, end int) {
+	limit = min(limit, MaxPageSize)
+	if limit <= 0 || offset > n/limit {
+		return n, n
+	}
+	start = min(offset*limit, n)
+	return start, min(start+limit, n)
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPageBounds(t *testing.T) {
+	tests := []struct {
+		n, limit, offset int
+		start, end       int
+	}{
+		{10, 3, 0, 0, 3},
+		{10, 3, 3, 9, 10},
+		{10, 3, 4, 10, 10},
+		{10, 0, 0, 10, 10},
+		{500, 1000, 0, 0, MaxPageSize},
+		// Neither the offset nor the limit overflows.
+		{10, math.MaxInt, 2, 10, 10},
+		{10, 3, math.MaxInt, 10, 10},
+		{10, math.MaxInt, math.MaxInt, 10, 10},
+	}
+	for _, tt := range tests {
+		start, end := PageBounds(tt.n, tt.limit, tt.offset)
+		if start != tt.start || end != tt.end {
+			t.Errorf("PageBounds(%d, %d, %d) = %d, %d, want %d, %d", tt.n, tt.limit, tt.offset, start, end, tt.start, tt.end)
+		}
+	}
+}
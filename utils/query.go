@@ -22,6 +22,51 @@ func ParseUintSlice(s string) []uint {
 	return result
 }
 
+// ParseList splits a comma-separated query value, dropping blanks.
+func ParseList(s string) []string {
+	var result []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// NutrientColumns are the nutrient columns of recipes that can be filtered
+// with min_<column> and max_<column>.
+var NutrientColumns = []string{
+	"calories", "protein", "fat", "saturated_fat", "carbs", "fiber", "sugar",
+	"cholesterol", "sodium", "potassium",
+}
+
+// TimeColumns are the time columns of recipes, in minutes, that can be
+// filtered with min_<column> and max_<column>. Recipes without a time never
+// match.
+var TimeColumns = []string{"prep_minutes", "cook_minutes", "total_minutes"}
+
+// RatingExpr is the SQL expression of a recipe's average score, 0 when it is
+// unrated.
+const RatingExpr = "COALESCE((SELECT AVG(r.score) FROM ratings r WHERE r.recipe_id = recipes.id), 0)"
+
+// VotesExpr is the SQL expression of a recipe's number of ratings.
+const VotesExpr = "(SELECT COUNT(*) FROM ratings r WHERE r.recipe_id = recipes.id)"
+
+// NutrientExpr is the SQL expression of a nutrient column for the recipe, or
+// for one serving of it.
+func NutrientExpr(column string, perServing bool) string {
+	if perServing {
+		return "recipes." + column + " / recipes.servings"
+	}
+	return "recipes." + column
+}
+
+// Match modes of the tag_ids and category_ids filters.
+const (
+	MatchAny = "any"
+	MatchAll = "all"
+)
+
 func ApplyRecipeFilters(query *gorm.DB, params map[string]string) *gorm.DB {
 	if title, ok := params["title"]; ok && title != "" {
 		query = query.Where("LOWER(recipes.title) LIKE ?", "%"+strings.ToLower(title)+"%")
@@ -34,91 +79,135 @@ func ApplyRecipeFilters(query *gorm.DB, params map[string]string) *gorm.DB {
 		)
 	}
 
-	if tagIDsStr, ok := params["tag_ids"]; ok && tagIDsStr != "" {
-		tagIDs := ParseUintSlice(tagIDsStr)
-		if len(tagIDs) > 0 {
-			query = query.Where(
-				"EXISTS (SELECT 1 FROM recipe_tags rt WHERE rt.recipe_id = recipes.id AND rt.tag_id IN ?)",
-				tagIDs,
-			)
+	// Exclusions leave out recipes with any of the listed ingredients,
+	// matched like the ingredient filter.
+	for _, ingredient := range ParseList(params["exclude_ingredients"]) {
+		query = query.Where(
+			"NOT EXISTS (SELECT 1 FROM ingredients i WHERE i.recipe_id = recipes.id AND LOWER(i.name) LIKE ?)",
+			"%"+strings.ToLower(ingredient)+"%",
+		)
+	}
+
+	// Recipes match any of the listed tags and categories, or all of them
+	// with tag_match=all and category_match=all.
+	if tagIDs := ParseUintSlice(params["tag_ids"]); len(tagIDs) > 0 {
+		const tagged = "EXISTS (SELECT 1 FROM recipe_tags rt WHERE rt.recipe_id = recipes.id AND rt.tag_id IN ?)"
+		if params["tag_match"] == MatchAll {
+			for _, id := range tagIDs {
+				query = query.Where(tagged, []uint{id})
+			}
+		} else {
+			query = query.Where(tagged, tagIDs)
 		}
 	}
+	if tagIDs := ParseUintSlice(params["exclude_tag_ids"]); len(tagIDs) > 0 {
+		query = query.Where(
+			"NOT EXISTS (SELECT 1 FROM recipe_tags rt WHERE rt.recipe_id = recipes.id AND rt.tag_id IN ?)",
+			tagIDs,
+		)
+	}
 
-	if categoryIDsStr, ok := params["category_ids"]; ok && categoryIDsStr != "" {
-		categoryIDs := ParseUintSlice(categoryIDsStr)
-		if len(categoryIDs) > 0 {
-			query = query.Where(
-				"EXISTS (SELECT 1 FROM recipe_categories rc WHERE rc.recipe_id = recipes.id AND rc.category_id IN ?)",
-				categoryIDs,
-			)
+	if categoryIDs := ParseUintSlice(params["category_ids"]); len(categoryIDs) > 0 {
+		const categorized = "EXISTS (SELECT 1 FROM recipe_categories rc WHERE rc.recipe_id = recipes.id AND rc.category_id IN ?)"
+		if params["category_match"] == MatchAll {
+			for _, id := range categoryIDs {
+				query = query.Where(categorized, []uint{id})
+			}
+		} else {
+			query = query.Where(categorized, categoryIDs)
 		}
 	}
+	if categoryIDs := ParseUintSlice(params["exclude_category_ids"]); len(categoryIDs) > 0 {
+		query = query.Where(
+			"NOT EXISTS (SELECT 1 FROM recipe_categories rc WHERE rc.recipe_id = recipes.id AND rc.category_id IN ?)",
+			categoryIDs,
+		)
+	}
+
+	if status, ok := params["status"]; ok && status != "" {
+		query = query.Where("recipes.status = ?", status)
+	}
+
+	if visibility, ok := params["visibility"]; ok && visibility != "" {
+		query = query.Where("recipes.visibility = ?", visibility)
+	}
 
 	if userIDStr, ok := params["user_id"]; ok && userIDStr != "" {
 		if userID, err := strconv.ParseUint(userIDStr, 10, 64); err == nil {
 			query = query.Where("recipes.user_id = ?", userID)
 		}
 	}
+	if userIDs := ParseUintSlice(params["exclude_user_ids"]); len(userIDs) > 0 {
+		query = query.Where("recipes.user_id NOT IN ?", userIDs)
+	}
 
-	if minCalStr, ok := params["min_calories"]; ok && minCalStr != "" {
-		if minCal, err := strconv.ParseFloat(minCalStr, 64); err == nil {
-			query = query.Where("recipes.calories >= ?", minCal)
-		}
+	// Creation dates are inclusive; a date without a time covers the whole
+	// day.
+	if at, _, ok := ParseDate(params["created_from"]); ok {
+		query = query.Where("recipes.created_at >= ?", at)
 	}
-	if maxCalStr, ok := params["max_calories"]; ok && maxCalStr != "" {
-		if maxCal, err := strconv.ParseFloat(maxCalStr, 64); err == nil {
-			fmt.Println("SQL:", query.Statement.SQL.String())
-			query = query.Where("recipes.calories <= ?", maxCal)
+	if at, wholeDay, ok := ParseDate(params["created_to"]); ok {
+		if wholeDay {
+			query = query.Where("recipes.created_at < ?", at.AddDate(0, 0, 1))
+		} else {
+			query = query.Where("recipes.created_at <= ?", at)
 		}
 	}
-	if minProteinStr, ok := params["min_protein"]; ok && minProteinStr != "" {
-		if minProtein, err := strconv.ParseFloat(minProteinStr, 64); err == nil {
-			query = query.Where("recipes.protein >= ?", minProtein)
-		}
+
+	// rating is the lowest average score to include; unrated recipes never
+	// match.
+	if rating, err := strconv.ParseFloat(params["rating"], 64); err == nil {
+		query = query.Where(knownComparison(RatingExpr, ">="), rating)
 	}
-	if maxProteinStr, ok := params["max_protein"]; ok && maxProteinStr != "" {
-		if maxProtein, err := strconv.ParseFloat(maxProteinStr, 64); err == nil {
-			query = query.Where("recipes.protein <= ?", maxProtein)
-		}
+	if votes, err := strconv.Atoi(params["min_votes"]); err == nil {
+		query = query.Where(VotesExpr+" >= ?", votes)
 	}
-	if minFatStr, ok := params["min_fat"]; ok && minFatStr != "" {
-		if minFat, err := strconv.ParseFloat(minFatStr, 64); err == nil {
-			query = query.Where("recipes.fat >= ?", minFat)
-		}
+
+	// Labels are stored as JSON arrays, so a quoted name only matches the
+	// whole label. Every diet must apply and none of the allergens may.
+	for _, diet := range ParseList(params["diet"]) {
+		query = query.Where("recipes.diets LIKE ?", `%"`+diet+`"%`)
 	}
-	if maxFatStr, ok := params["max_fat"]; ok && maxFatStr != "" {
-		if maxFat, err := strconv.ParseFloat(maxFatStr, 64); err == nil {
-			query = query.Where("recipes.fat <= ?", maxFat)
-		}
+	for _, allergen := range ParseList(params["exclude_allergens"]) {
+		query = query.Where("COALESCE(recipes.allergens, '') NOT LIKE ?", `%"`+allergen+`"%`)
 	}
-	if minCarbsStr, ok := params["min_carbs"]; ok && minCarbsStr != "" {
-		if minCarbs, err := strconv.ParseFloat(minCarbsStr, 64); err == nil {
-			query = query.Where("recipes.carbs >= ?", minCarbs)
-		}
+
+	if difficulties := ParseList(params["difficulty"]); len(difficulties) > 0 {
+		query = query.Where("recipes.difficulty IN ?", difficulties)
 	}
-	if maxCarbsStr, ok := params["max_carbs"]; ok && maxCarbsStr != "" {
-		if maxCarbs, err := strconv.ParseFloat(maxCarbsStr, 64); err == nil {
-			query = query.Where("recipes.carbs <= ?", maxCarbs)
-		}
+	if cuisines := ParseList(params["cuisine"]); len(cuisines) > 0 {
+		query = query.Where("recipes.cuisine IN ?", cuisines)
 	}
-	if minFiberStr, ok := params["min_fiber"]; ok && minFiberStr != "" {
-		if minFiber, err := strconv.ParseFloat(minFiberStr, 64); err == nil {
-			query = query.Where("recipes.fiber >= ?", minFiber)
-		}
+	for _, equipment := range ParseList(params["equipment"]) {
+		query = query.Where("recipes.equipment LIKE ?", `%"`+equipment+`"%`)
 	}
-	if maxFiberStr, ok := params["max_fiber"]; ok && maxFiberStr != "" {
-		if maxFiber, err := strconv.ParseFloat(maxFiberStr, 64); err == nil {
-			query = query.Where("recipes.fiber <= ?", maxFiber)
+	for _, column := range TimeColumns {
+		expr := "recipes." + column
+		if v, err := strconv.Atoi(params["min_"+column]); err == nil {
+			query = query.Where(expr+" > 0 AND "+expr+" >= ?", v)
+		}
+		if v, err := strconv.Atoi(params["max_"+column]); err == nil {
+			query = query.Where(expr+" > 0 AND "+expr+" <= ?", v)
 		}
 	}
-	if minSugarStr, ok := params["min_sugar"]; ok && minSugarStr != "" {
-		if minSugar, err := strconv.ParseFloat(minSugarStr, 64); err == nil {
-			query = query.Where("recipes.sugar >= ?", minSugar)
+
+	// Nutrient ranges apply to the recipe totals, or to one serving with
+	// per_serving=true.
+	perServing := params["per_serving"] == "true"
+	for _, column := range NutrientColumns {
+		expr := NutrientExpr(column, perServing)
+		if v, err := strconv.ParseFloat(params["min_"+column], 64); err == nil {
+			query = query.Where(expr+" >= ?", v)
+		}
+		if v, err := strconv.ParseFloat(params["max_"+column], 64); err == nil {
+			query = query.Where(expr+" <= ?", v)
 		}
 	}
-	if maxSugarStr, ok := params["max_sugar"]; ok && maxSugarStr != "" {
-		if maxSugar, err := strconv.ParseFloat(maxSugarStr, 64); err == nil {
-			query = query.Where("recipes.sugar <= ?", maxSugar)
+
+	// filter holds an expression of ParseRecipeFilter.
+	if expr := params["filter"]; expr != "" {
+		if f, err := ParseRecipeFilter(expr); err == nil {
+			query = f.Apply(query, perServing)
 		}
 	}
 
@@ -169,6 +258,23 @@ func ApplyRecipeSorting(query *gorm.DB, sortParam string) *gorm.DB {
 		return query.Order("recipes.sugar ASC")
 	case "sugar_desc":
 		return query.Order("recipes.sugar DESC")
+	// Recipes without a time or difficulty come last either way.
+	case "prep_time_asc":
+		return query.Order("recipes.prep_minutes = 0").Order("recipes.prep_minutes ASC")
+	case "prep_time_desc":
+		return query.Order("recipes.prep_minutes = 0").Order("recipes.prep_minutes DESC")
+	case "cook_time_asc":
+		return query.Order("recipes.cook_minutes = 0").Order("recipes.cook_minutes ASC")
+	case "cook_time_desc":
+		return query.Order("recipes.cook_minutes = 0").Order("recipes.cook_minutes DESC")
+	case "total_time_asc":
+		return query.Order("recipes.total_minutes = 0").Order("recipes.total_minutes ASC")
+	case "total_time_desc":
+		return query.Order("recipes.total_minutes = 0").Order("recipes.total_minutes DESC")
+	case "difficulty_asc":
+		return query.Order("recipes.difficulty_level = 0").Order("recipes.difficulty_level ASC")
+	case "difficulty_desc":
+		return query.Order("recipes.difficulty_level = 0").Order("recipes.difficulty_level DESC")
 	default:
 		return query.Order("recipes.created_at DESC")
 	}